@@ -1,30 +1,83 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"log"
+	"os"
 	"time"
+
+	"golang-Roadmap-2025/02-Advanced-Go-Features/examples/Goroutines-Intro/timeline"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
 )
 
-func say(s string) {
+var htmlOut = flag.String("html", "", "將 Goroutine 時間軸另外輸出成 HTML 檔案 (例如 timeline.html)")
+
+// say 印出 s 三次。時間來源由 clk 提供，測試時可以換成假時鐘。
+func say(clk clock.Clock, s string) {
 	for i := 0; i < 3; i++ {
 		fmt.Println(s)
-		// time.Sleep 讓 Goroutine 暫停一下，以便觀察交錯執行的效果
-		time.Sleep(100 * time.Millisecond)
+		// Sleep 讓 Goroutine 暫停一下，以便觀察交錯執行的效果
+		clk.Sleep(100 * time.Millisecond)
 	}
 }
 
-func main() {
-	fmt.Println("---\nGoroutines Intro---")
+// tracedSay 和 say 做一樣的事，但會把每次輸出與睡眠記錄到泳道上。
+func tracedSay(l *timeline.Lane, s string) {
+	for i := 0; i < 3; i++ {
+		fmt.Println(s)
+		l.Mark(s)
+		l.Sleep(100 * time.Millisecond)
+	}
+}
+
+// timelineDemo 重新執行 say 的交錯範例，並把每個 Goroutine 的執行過程畫成時間軸，
+// 讓我們不必猜測輸出順序，而是直接看到排程、阻塞與結束的先後。
+func timelineDemo(clk clock.Clock) {
+	rec := timeline.NewWithClock(clk)
+
+	rec.Go("World", func(l *timeline.Lane) {
+		tracedSay(l, "World")
+	})
+
+	mainLane := rec.Lane("main")
+	tracedSay(mainLane, "Hello")
+	mainLane.Exit()
+
+	// 與上面的範例不同，這裡會等待 "World" Goroutine 結束，時間軸才會完整。
+	rec.Wait()
+
+	fmt.Println()
+	if err := rec.WriteASCII(os.Stdout, 60); err != nil {
+		log.Fatal(err)
+	}
 
+	if *htmlOut != "" {
+		f, err := os.Create(*htmlOut)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		if err := rec.WriteHTML(f); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Timeline written to", *htmlOut)
+	}
+}
+
+// interleave 讓 say("World") 與 say("Hello") 並發執行。
+func interleave(clk clock.Clock) {
 	// 使用 `go` 關鍵字啟動一個新的 Goroutine
 	// `say("World")` 將會和 `main` 函式並發執行
-	go say("World")
+	go say(clk, "World")
 
 	// 在主 Goroutine 中執行 say("Hello")
 	// 主 Goroutine 的執行會給 `say("World")` Goroutine 一些執行的時間
-	say("Hello")
+	say(clk, "Hello")
+}
 
-	fmt.Println("\n---\nGoroutine Exit Demo---")
+// exitDemo 啟動一個 Goroutine 但不等待它，呼叫者返回時它可能還沒執行。
+func exitDemo(clk clock.Clock) {
 	// 在這個例子中，`main` 函式可能在 Goroutine 開始執行前就退出了
 	// 因此您可能看不到 "I am a goroutine" 的輸出
 	go func() {
@@ -33,7 +86,21 @@ func main() {
 
 	// 我們在這裡短暫睡眠，只是為了演示目的，以增加看到上面 Goroutine 輸出的機會。
 	// 這不是一個可靠的同步方法！
-	time.Sleep(50 * time.Millisecond)
+	clk.Sleep(50 * time.Millisecond)
+}
+
+func main() {
+	flag.Parse()
+	clk := clock.Real()
+
+	fmt.Println("---\nGoroutines Intro---")
+	interleave(clk)
+
+	fmt.Println("\n---\nGoroutine Exit Demo---")
+	exitDemo(clk)
+
+	fmt.Println("\n---\nGoroutine Timeline---")
+	timelineDemo(clk)
 
 	fmt.Println("Main function finished.")
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+// 這兩個範例都沒有等待自己啟動的 Goroutine，
+// leakcheck 會在測試結束時重試一小段時間，確認它們最終都有結束。
+
+func TestInterleaveNoLeak(t *testing.T) {
+	leakcheck.Check(t)
+	start := time.Unix(0, 0)
+	fake := clock.NewFake(start)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		interleave(fake)
+	}()
+	fake.RunUntil(done)
+
+	// 兩個 Goroutine 各 Sleep 三次 100ms 且同時進行；"World" 的最後一次 Sleep
+	// 與 "Hello" 的同時到期，所以 interleave 返回時它也已經被喚醒，只剩下結束。
+	if elapsed := fake.Now().Sub(start); elapsed != 300*time.Millisecond {
+		t.Errorf("interleave took %v of fake time; 預期為 300ms", elapsed)
+	}
+	if n := fake.Pending(); n != 0 {
+		t.Errorf("Pending() = %d; 預期為 0", n)
+	}
+}
+
+func TestExitDemoNoLeak(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		exitDemo(fake)
+	}()
+	// 快轉 exitDemo 中的 Sleep，測試不需要真的等待 50ms。
+	fake.RunUntil(done)
+}
@@ -0,0 +1,221 @@
+package timeline
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// ASCII 泳道中使用的符號。
+const (
+	symAlive = '-'
+	symBlock = '.'
+	symMark  = '*'
+	symStart = '>'
+	symExit  = 'x'
+)
+
+// WriteASCII 以 width 個字元寬的 ASCII 泳道輸出時間軸，並在下方列出依序發生的事件。
+//
+//	World |>-*.....*.....*.....x        |
+//	Hello |>*.....*.....*.....x         |
+func (r *Recorder) WriteASCII(w io.Writer, width int) error {
+	if width < 10 {
+		width = 10
+	}
+	lanes := r.Lanes()
+	events := r.Events()
+	total := span(events)
+
+	nameWidth := 0
+	for _, name := range lanes {
+		nameWidth = max(nameWidth, len(name))
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, name := range lanes {
+		row := laneRow(name, events, total, width)
+		fmt.Fprintf(bw, "%-*s |%s|\n", nameWidth, name, string(row))
+	}
+	fmt.Fprintf(bw, "%-*s  0%*s\n", nameWidth, "", width-1, formatDuration(total))
+	fmt.Fprintf(bw, "legend: %c start  %c running  %c blocked  %c event  %c exit\n\n",
+		symStart, symAlive, symBlock, symMark, symExit)
+
+	for i, e := range events {
+		label := e.Label
+		if e.Kind == Block {
+			label = fmt.Sprintf("%s (%s)", e.Label, formatDuration(e.End-e.At))
+		}
+		fmt.Fprintf(bw, "%3d  %9s  %-*s  %-5s  %s\n", i+1, formatDuration(e.At), nameWidth, e.Lane, e.Kind, label)
+	}
+	return bw.Flush()
+}
+
+func laneRow(name string, events []Event, total time.Duration, width int) []rune {
+	row := []rune(strings.Repeat(" ", width))
+	col := func(d time.Duration) int {
+		return int(int64(d) * int64(width-1) / int64(total))
+	}
+
+	first, last := -1, width-1
+	for _, e := range events {
+		if e.Lane != name {
+			continue
+		}
+		switch e.Kind {
+		case Start:
+			first = col(e.At)
+		case Exit:
+			last = col(e.At)
+		}
+	}
+	if first < 0 {
+		return row
+	}
+	for i := first; i <= last; i++ {
+		row[i] = symAlive
+	}
+	// 依序畫出區間、瞬間事件、開始與結束，後畫的符號優先，避免短暫的事件被區間蓋掉。
+	for _, e := range events {
+		if e.Lane == name && e.Kind == Block {
+			for i := col(e.At); i <= col(e.End); i++ {
+				row[i] = symBlock
+			}
+		}
+	}
+	for _, e := range events {
+		if e.Lane == name && e.Kind == Mark {
+			row[col(e.At)] = symMark
+		}
+	}
+	for _, e := range events {
+		if e.Lane != name {
+			continue
+		}
+		switch e.Kind {
+		case Start:
+			row[col(e.At)] = symStart
+		case Exit:
+			row[col(e.At)] = symExit
+		}
+	}
+	return row
+}
+
+// span 回傳整條時間軸的長度，至少為 1ns 以避免除以零。
+func span(events []Event) time.Duration {
+	var total time.Duration
+	for _, e := range events {
+		total = max(total, e.At, e.End)
+	}
+	return max(total, 1)
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(10 * time.Microsecond).String()
+}
+
+var htmlTemplate = template.Must(template.New("timeline").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Goroutine Timeline</title>
+<style>
+body { font-family: monospace; margin: 2em; }
+.lane { display: flex; align-items: center; margin: 4px 0; }
+.name { width: 8em; }
+.track { position: relative; flex: 1; height: 22px; background: #f4f4f4; }
+.alive { position: absolute; top: 9px; height: 4px; background: #8fbc8f; }
+.block { position: absolute; top: 4px; height: 14px; background: #f0c36d; opacity: .8; }
+.point { position: absolute; top: 2px; width: 2px; height: 18px; }
+.start { background: #2e8b57; } .mark { background: #4169e1; } .exit { background: #b22222; }
+table { border-collapse: collapse; margin-top: 2em; }
+td, th { padding: 2px 8px; text-align: left; }
+</style>
+</head>
+<body>
+<h1>Goroutine Timeline ({{.Total}})</h1>
+{{range .Lanes}}<div class="lane"><div class="name">{{.Name}}</div><div class="track">
+{{- range .Bars}}<div class="{{.Class}}" style="left:{{.Left}}%;width:{{.Width}}%" title="{{.Title}}"></div>{{end}}
+{{- range .Points}}<div class="point {{.Class}}" style="left:{{.Left}}%" title="{{.Title}}"></div>{{end}}
+</div></div>
+{{end}}
+<table>
+<tr><th>#</th><th>at</th><th>lane</th><th>kind</th><th>label</th></tr>
+{{range $i, $e := .Events}}<tr><td>{{$e.Seq}}</td><td>{{$e.At}}</td><td>{{$e.Lane}}</td><td>{{$e.Kind}}</td><td>{{$e.Label}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+type htmlShape struct {
+	Class       string
+	Left, Width string
+	Title       string
+}
+
+type htmlLane struct {
+	Name   string
+	Bars   []htmlShape
+	Points []htmlShape
+}
+
+type htmlEvent struct {
+	Seq   int
+	At    string
+	Lane  string
+	Kind  Kind
+	Label string
+}
+
+// WriteHTML 把時間軸輸出成一個獨立的 HTML 頁面，每個 Goroutine 一條泳道。
+func (r *Recorder) WriteHTML(w io.Writer) error {
+	events := r.Events()
+	total := span(events)
+	pct := func(d time.Duration) string {
+		return fmt.Sprintf("%.3f", float64(d)*100/float64(total))
+	}
+
+	var data struct {
+		Total  string
+		Lanes  []htmlLane
+		Events []htmlEvent
+	}
+	data.Total = formatDuration(total)
+
+	for _, name := range r.Lanes() {
+		lane := htmlLane{Name: name}
+		begin, end := time.Duration(-1), total
+		for _, e := range events {
+			if e.Lane != name {
+				continue
+			}
+			title := fmt.Sprintf("%s %s @%s", e.Kind, e.Label, formatDuration(e.At))
+			switch e.Kind {
+			case Start:
+				begin = e.At
+				lane.Points = append(lane.Points, htmlShape{Class: "start", Left: pct(e.At), Title: title})
+			case Exit:
+				end = e.At
+				lane.Points = append(lane.Points, htmlShape{Class: "exit", Left: pct(e.At), Title: title})
+			case Mark:
+				lane.Points = append(lane.Points, htmlShape{Class: "mark", Left: pct(e.At), Title: title})
+			case Block:
+				lane.Bars = append(lane.Bars, htmlShape{Class: "block", Left: pct(e.At), Width: pct(e.End - e.At), Title: title})
+			}
+		}
+		if begin >= 0 {
+			alive := htmlShape{Class: "alive", Left: pct(begin), Width: pct(end - begin)}
+			lane.Bars = append([]htmlShape{alive}, lane.Bars...)
+		}
+		data.Lanes = append(data.Lanes, lane)
+	}
+
+	for i, e := range events {
+		data.Events = append(data.Events, htmlEvent{Seq: i + 1, At: formatDuration(e.At), Lane: e.Lane, Kind: e.Kind, Label: e.Label})
+	}
+	return htmlTemplate.Execute(w, data)
+}
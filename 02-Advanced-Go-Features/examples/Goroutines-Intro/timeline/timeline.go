@@ -0,0 +1,226 @@
+// Package timeline 記錄多個 Goroutine 的執行事件，並把它們畫成每個 Goroutine 一條泳道 (swimlane) 的時間軸。
+//
+// 單純靠 fmt.Println 與 time.Sleep 很難看出 Goroutine 之間「誰先誰後」，
+// 這個套件讓每個 Goroutine 在關鍵時刻留下紀錄 (啟動、事件、阻塞、結束)，
+// 最後輸出成 ASCII 或 HTML，讓排程、阻塞與結束的順序一目了然。
+//
+// 所有紀錄同時也會送進 runtime/trace (Task、Region 與 Log)，
+// 搭配 Trace 即可用 `go tool trace` 觀察同一次執行。
+package timeline
+
+import (
+	"context"
+	"io"
+	"runtime/trace"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+)
+
+// Kind 表示事件的種類。
+type Kind int
+
+const (
+	// Start 表示泳道 (Goroutine) 開始執行。
+	Start Kind = iota
+	// Mark 是一個瞬間事件，例如印出一行文字。
+	Mark
+	// Block 是一段阻塞區間，例如 Sleep 或等待 channel。
+	Block
+	// Exit 表示泳道 (Goroutine) 結束。
+	Exit
+)
+
+// String 回傳事件種類的名稱。
+func (k Kind) String() string {
+	switch k {
+	case Start:
+		return "start"
+	case Mark:
+		return "mark"
+	case Block:
+		return "block"
+	case Exit:
+		return "exit"
+	}
+	return "unknown"
+}
+
+// Event 是一筆被記錄下來的事件。
+// At 與 End 都是相對於 Recorder 建立時間的偏移量；只有 Block 事件的 End 會大於 At。
+type Event struct {
+	Seq   int
+	Lane  string
+	Kind  Kind
+	Label string
+	At    time.Duration
+	End   time.Duration
+}
+
+// Recorder 收集所有泳道的事件。它可以安全地被多個 Goroutine 同時使用。
+type Recorder struct {
+	clock clock.Clock
+	start time.Time
+	ctx   context.Context
+	wg    sync.WaitGroup
+
+	mu     sync.Mutex
+	seq    int
+	lanes  []string
+	events []Event
+}
+
+// New 建立一個新的 Recorder，時間軸的零點就是呼叫 New 的時刻。
+func New() *Recorder {
+	return NewWithClock(clock.Real())
+}
+
+// NewWithClock 與 New 相同，但使用 c 作為時間來源；搭配 clock.Fake 可以得到固定的時間軸。
+func NewWithClock(c clock.Clock) *Recorder {
+	return &Recorder{clock: c, start: c.Now(), ctx: context.Background()}
+}
+
+// Trace 開始把 runtime/trace 的資料寫到 w，回傳的 stop 函式用來結束追蹤。
+// 追蹤是整個程序共用的，同一時間只能有一個，因此它不屬於任何 Recorder。
+// 產生的檔案可以用 `go tool trace <file>` 開啟。
+func Trace(w io.Writer) (stop func(), err error) {
+	if err := trace.Start(w); err != nil {
+		return nil, err
+	}
+	return trace.Stop, nil
+}
+
+// Lane 為目前的 Goroutine 建立一條泳道並記錄 Start 事件。
+// 通常用在 main Goroutine 上；由 Recorder.Go 啟動的 Goroutine 會自動取得泳道。
+//
+// 泳道名稱在同一個 Recorder 中是唯一的：name 已經被使用時，依序加上 "#2"、"#3" 等後綴
+// (例如在迴圈中以同一個名稱啟動多個 worker)，實際的名稱可以從 Lane.Name 取得。
+func (r *Recorder) Lane(name string) *Lane {
+	r.mu.Lock()
+	name = r.uniqueLocked(name)
+	r.lanes = append(r.lanes, name)
+	r.mu.Unlock()
+
+	ctx, task := trace.NewTask(r.ctx, name)
+	l := &Lane{r: r, name: name, ctx: ctx, task: task}
+	r.add(name, Start, "", r.since(), 0)
+	return l
+}
+
+// uniqueLocked 在持有鎖時回傳尚未被使用的泳道名稱。
+func (r *Recorder) uniqueLocked(name string) string {
+	if !slices.Contains(r.lanes, name) {
+		return name
+	}
+	for n := 2; ; n++ {
+		if candidate := name + "#" + strconv.Itoa(n); !slices.Contains(r.lanes, candidate) {
+			return candidate
+		}
+	}
+}
+
+// Go 在新的 Goroutine 中執行 fn，並自動記錄該泳道的 Start 與 Exit 事件。
+func (r *Recorder) Go(name string, fn func(l *Lane)) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		l := r.Lane(name)
+		defer l.Exit()
+		fn(l)
+	}()
+}
+
+// Wait 等待所有由 Go 啟動的 Goroutine 結束。
+func (r *Recorder) Wait() {
+	r.wg.Wait()
+}
+
+// Lanes 依照建立順序回傳所有泳道名稱。
+func (r *Recorder) Lanes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.lanes...)
+}
+
+// Events 回傳依時間 (相同時間則依記錄順序) 排序後的事件副本。
+func (r *Recorder) Events() []Event {
+	r.mu.Lock()
+	events := append([]Event(nil), r.events...)
+	r.mu.Unlock()
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].At != events[j].At {
+			return events[i].At < events[j].At
+		}
+		return events[i].Seq < events[j].Seq
+	})
+	return events
+}
+
+func (r *Recorder) since() time.Duration {
+	return r.clock.Now().Sub(r.start)
+}
+
+func (r *Recorder) add(lane string, kind Kind, label string, at, end time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if end < at {
+		end = at
+	}
+	r.seq++
+	r.events = append(r.events, Event{Seq: r.seq, Lane: lane, Kind: kind, Label: label, At: at, End: end})
+}
+
+// Lane 代表一個 Goroutine 的泳道，只應該在擁有它的 Goroutine 中使用。
+type Lane struct {
+	r    *Recorder
+	name string
+	ctx  context.Context
+	task *trace.Task
+	done bool
+}
+
+// Name 回傳泳道名稱。
+func (l *Lane) Name() string {
+	return l.name
+}
+
+// Mark 記錄一個瞬間事件。
+func (l *Lane) Mark(label string) {
+	trace.Log(l.ctx, l.name, label)
+	l.r.add(l.name, Mark, label, l.r.since(), 0)
+}
+
+// Block 開始一段阻塞區間，呼叫回傳的函式代表阻塞結束。
+//
+//	end := lane.Block("wait result")
+//	v := <-ch
+//	end()
+func (l *Lane) Block(label string) (end func()) {
+	region := trace.StartRegion(l.ctx, label)
+	at := l.r.since()
+	return func() {
+		region.End()
+		l.r.add(l.name, Block, label, at, l.r.since())
+	}
+}
+
+// Sleep 使用 Recorder 的時鐘睡眠 d，並把睡眠期間記錄成一段 Block。
+func (l *Lane) Sleep(d time.Duration) {
+	end := l.Block("sleep")
+	l.r.clock.Sleep(d)
+	end()
+}
+
+// Exit 記錄泳道結束。重複呼叫不會產生多筆 Exit 事件。
+func (l *Lane) Exit() {
+	if l.done {
+		return
+	}
+	l.done = true
+	l.r.add(l.name, Exit, "", l.r.since(), 0)
+	l.task.End()
+}
@@ -0,0 +1,168 @@
+package timeline
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+)
+
+// TestEventOrder 用 channel 強制兩個泳道的先後順序，確認事件依時間排序。
+func TestEventOrder(t *testing.T) {
+	rec := New()
+	ping := make(chan struct{})
+
+	rec.Go("worker", func(l *Lane) {
+		end := l.Block("wait ping")
+		<-ping
+		end()
+		l.Mark("got ping")
+	})
+
+	m := rec.Lane("main")
+	m.Mark("send ping")
+	ping <- struct{}{}
+	m.Exit()
+	rec.Wait()
+
+	var got []string
+	for _, e := range rec.Events() {
+		if e.Kind == Mark {
+			got = append(got, e.Label)
+		}
+	}
+	want := []string{"send ping", "got ping"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("marks = %v; 預期為 %v", got, want)
+	}
+
+	var exits int
+	for _, e := range rec.Events() {
+		if e.Kind == Exit {
+			exits++
+		}
+	}
+	if exits != 2 {
+		t.Errorf("exit events = %d; 預期為 2", exits)
+	}
+}
+
+// TestDuplicateLaneNames 確認同名的泳道不會被合併成一條。
+func TestDuplicateLaneNames(t *testing.T) {
+	rec := New()
+	for range 3 {
+		rec.Go("worker", func(l *Lane) {})
+	}
+	rec.Wait()
+	rec.Lane("worker#2").Exit()
+
+	lanes := rec.Lanes()
+	slices.Sort(lanes)
+	want := []string{"worker", "worker#2", "worker#2#2", "worker#3"}
+	if !slices.Equal(lanes, want) {
+		t.Errorf("Lanes() = %q; 預期為 %q", lanes, want)
+	}
+	starts := map[string]int{}
+	for _, e := range rec.Events() {
+		if e.Kind == Start {
+			starts[e.Lane]++
+		}
+	}
+	for _, name := range want {
+		if starts[name] != 1 {
+			t.Errorf("泳道 %q 的 start 事件 = %d; 預期為 1", name, starts[name])
+		}
+	}
+}
+
+// TestWriteASCII 用固定的事件驗證泳道的繪製結果。
+func TestWriteASCII(t *testing.T) {
+	rec := &Recorder{lanes: []string{"A", "B"}}
+	rec.events = []Event{
+		{Seq: 1, Lane: "A", Kind: Start, At: 0},
+		{Seq: 2, Lane: "B", Kind: Start, At: 10 * time.Millisecond},
+		{Seq: 3, Lane: "A", Kind: Block, Label: "sleep", At: 20 * time.Millisecond, End: 50 * time.Millisecond},
+		{Seq: 4, Lane: "B", Kind: Mark, Label: "hi", At: 60 * time.Millisecond},
+		{Seq: 5, Lane: "A", Kind: Exit, At: 70 * time.Millisecond},
+		{Seq: 6, Lane: "B", Kind: Exit, At: 100 * time.Millisecond},
+	}
+
+	var buf bytes.Buffer
+	if err := rec.WriteASCII(&buf, 11); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+
+	testCases := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"lane A", lines[0], "A |>-....-x   |"},
+		{"lane B", lines[1], "B | >----*---x|"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.got != tc.want {
+				t.Errorf("got %q; 預期為 %q", tc.got, tc.want)
+			}
+		})
+	}
+	if !strings.Contains(buf.String(), "sleep (30ms)") {
+		t.Errorf("event list should include block duration, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	rec := New()
+	rec.Go("<worker>", func(l *Lane) { l.Mark("hello") })
+	rec.Wait()
+
+	var buf bytes.Buffer
+	if err := rec.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "&lt;worker&gt;") {
+		t.Errorf("lane name should be escaped in HTML output")
+	}
+	if !strings.Contains(out, `class="point mark"`) {
+		t.Errorf("HTML output should contain the mark event")
+	}
+}
+
+// TestFakeClock 用假時鐘讓 Sleep 的時間軸完全固定。
+func TestFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	rec := NewWithClock(fake)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l := rec.Lane("main")
+		l.Sleep(100 * time.Millisecond)
+		l.Mark("woke")
+		l.Exit()
+	}()
+	fake.RunUntil(done)
+
+	events := rec.Events()
+	var block, mark Event
+	for _, e := range events {
+		switch e.Kind {
+		case Block:
+			block = e
+		case Mark:
+			mark = e
+		}
+	}
+	if block.End-block.At != 100*time.Millisecond {
+		t.Errorf("block duration = %v; 預期為 100ms", block.End-block.At)
+	}
+	if mark.At != 100*time.Millisecond {
+		t.Errorf("mark at %v; 預期為 100ms", mark.At)
+	}
+}
@@ -0,0 +1,224 @@
+// Package actor 在 Goroutine 與 channel 之上實作 actor 模型。
+//
+// SafeCounter 用 Mutex 保護共享的狀態；actor 則反過來：狀態只屬於一個 Goroutine，
+// 其他人只能透過訊息 (message) 要求它做事，因此完全不需要鎖。
+//
+//   - 每個 actor 有自己的型別化信箱 (typed mailbox)，Ref[M] 只接受型別為 M 的訊息。
+//   - Tell 送出訊息後立即返回；Ask 送出帶有 Reply 的訊息並等待回覆，可以設定逾時。
+//   - actor 可以建立子 actor，形成監督樹 (supervision tree)。子 actor 失敗時
+//     (Receive 回傳錯誤或 panic)，由父 actor 依 Strategy 決定只重啟它 (OneForOne)
+//     或重啟所有兄弟 (AllForOne)；重啟次數超過上限時停止它，並把失敗往上回報 (escalate)。
+//   - PreStart、PostStop、PreRestart、PostRestart 等生命週期 hook 是選擇性實作的介面。
+package actor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutines/group"
+)
+
+var (
+	// ErrStopped 表示 actor 已經停止，不再接受訊息。
+	ErrStopped = errors.New("actor: stopped")
+	// ErrMailboxFull 表示有界信箱已滿。
+	ErrMailboxFull = errors.New("actor: mailbox full")
+	// ErrTimeout 表示 Ask 在逾時前沒有收到回覆。
+	ErrTimeout = errors.New("actor: ask timed out")
+	// ErrTooManyRestarts 表示子 actor 在時間窗內重啟太多次，失敗被往上回報。
+	ErrTooManyRestarts = errors.New("actor: too many restarts")
+	// ErrDuplicateName 表示同一個父 actor 底下已經有同名的子 actor。
+	ErrDuplicateName = errors.New("actor: duplicate name")
+)
+
+// Actor 處理型別為 M 的訊息。同一個 actor 的 Receive 永遠只在一個 Goroutine 中依序執行，
+// 因此可以自由存取自己的欄位而不需要鎖。
+//
+// 回傳錯誤 (或 panic) 代表 actor 的狀態已經不可信，交由父 actor 監督處理；
+// 一般的業務錯誤 (例如餘額不足) 應該透過 Reply 回覆給請求者。
+type Actor[M any] interface {
+	Receive(ctx *Context[M], msg M) error
+}
+
+// Func 把一個函式轉成沒有狀態的 Actor。
+type Func[M any] func(ctx *Context[M], msg M) error
+
+// Receive 呼叫 f。
+func (f Func[M]) Receive(ctx *Context[M], msg M) error {
+	return f(ctx, msg)
+}
+
+// PreStarter 在 actor 第一次啟動時被呼叫；回傳錯誤視同失敗。
+type PreStarter[M any] interface {
+	PreStart(ctx *Context[M]) error
+}
+
+// PostStopper 在 actor 停止時被呼叫，此時子 actor 都已經停止。
+type PostStopper[M any] interface {
+	PostStop(ctx *Context[M])
+}
+
+// PreRestarter 在舊的實例被替換之前被呼叫；沒有實作時改為呼叫 PostStop。
+type PreRestarter[M any] interface {
+	PreRestart(ctx *Context[M], reason error)
+}
+
+// PostRestarter 在新的實例啟動時被呼叫；沒有實作時改為呼叫 PreStart。
+type PostRestarter[M any] interface {
+	PostRestart(ctx *Context[M], reason error) error
+}
+
+// Parent 是可以建立子 actor 的對象：*System 與 *Context[M]。
+type Parent interface {
+	parentNode() *node
+}
+
+// Spawn 在 parent 底下建立一個名為 name 的 actor。
+// newActor 在啟動與每次重啟時被呼叫，重啟後的 actor 會從全新的狀態開始。
+func Spawn[M any](parent Parent, name string, newActor func() Actor[M], opts ...Option) (*Ref[M], error) {
+	if name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("actor: invalid name %q", name)
+	}
+	p := parent.parentNode()
+	cfg := newConfig(opts)
+	c := &cell[M]{
+		node:     newNode(p.sys, p, p.path+"/"+name, cfg.supervision),
+		newActor: newActor,
+		mailbox:  newMailbox[M](cfg.mailbox),
+	}
+	c.ref = &Ref[M]{c: c}
+	if err := p.addChild(c.node); err != nil {
+		return nil, err
+	}
+	go c.run()
+	return c.ref, nil
+}
+
+// Ref 是 actor 的位址。它可以安全地在多個 Goroutine 之間共用，
+// actor 重啟時 Ref 保持不變，信箱中尚未處理的訊息也會保留。
+type Ref[M any] struct {
+	c *cell[M]
+}
+
+// Tell 把 msg 放進信箱後立即返回。actor 已停止時回傳 ErrStopped，
+// 有界信箱已滿時回傳 ErrMailboxFull。
+func (r *Ref[M]) Tell(msg M) error {
+	return r.c.mailbox.push(msg)
+}
+
+// Path 回傳 actor 在監督樹中的路徑，例如 "/bank/alice"。
+func (r *Ref[M]) Path() string {
+	return r.c.path
+}
+
+// Stop 要求 actor 在處理完目前的訊息後停止 (先停止它的子 actor)。不會等待停止完成。
+func (r *Ref[M]) Stop() {
+	r.c.request(ctlStop, nil)
+}
+
+// Done 回傳一個在 actor 完全停止後關閉的 channel。
+func (r *Ref[M]) Done() <-chan struct{} {
+	return r.c.done
+}
+
+// Context 是 actor 處理訊息時的環境。每次重啟都會建立新的 Context。
+type Context[M any] struct {
+	c   *cell[M]
+	ctx context.Context
+}
+
+// Self 回傳 actor 自己的 Ref。
+func (x *Context[M]) Self() *Ref[M] {
+	return x.c.ref
+}
+
+// Path 回傳 actor 在監督樹中的路徑。
+func (x *Context[M]) Path() string {
+	return x.c.path
+}
+
+// Context 回傳一個在 actor 停止或重啟時被取消的 context.Context，
+// 讓耗時的 Receive 可以提早結束。
+func (x *Context[M]) Context() context.Context {
+	return x.ctx
+}
+
+func (x *Context[M]) parentNode() *node {
+	return x.c.node
+}
+
+// Reply 是 Ask 用來接收回覆的對象，通常放在訊息的欄位中。
+// 只有第一次的 Send 或 Fail 有效，而且永遠不會阻塞 actor。
+type Reply[R any] struct {
+	ch chan result[R]
+}
+
+type result[R any] struct {
+	val R
+	err error
+}
+
+// Send 回覆 v。
+func (r Reply[R]) Send(v R) {
+	r.send(result[R]{val: v})
+}
+
+// Fail 回覆一個錯誤，Ask 會回傳它。
+func (r Reply[R]) Fail(err error) {
+	r.send(result[R]{err: err})
+}
+
+func (r Reply[R]) send(res result[R]) {
+	select {
+	case r.ch <- res:
+	default:
+	}
+}
+
+// Ask 用 build 建立一個帶有 Reply 的訊息送給 ref，並等待回覆。
+// timeout <= 0 代表只受 ctx 限制。逾時回傳 ErrTimeout；actor 在回覆前停止則回傳 ErrStopped。
+// 若 actor 在處理這則訊息時失敗，訊息不會被重新處理，Ask 會一直等到逾時。
+func Ask[M, R any](ctx context.Context, ref *Ref[M], timeout time.Duration, build func(Reply[R]) M) (R, error) {
+	var zero R
+	reply := Reply[R]{ch: make(chan result[R], 1)}
+	if err := ref.Tell(build(reply)); err != nil {
+		return zero, err
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := ref.c.sys.clock.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C()
+	}
+	select {
+	case res := <-reply.ch:
+		return res.val, res.err
+	case <-expired:
+		return zero, fmt.Errorf("%w after %v: %s", ErrTimeout, timeout, ref.Path())
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case <-ref.Done():
+		// actor 可能在停止前剛好回覆了。
+		select {
+		case res := <-reply.ch:
+			return res.val, res.err
+		default:
+			return zero, ErrStopped
+		}
+	}
+}
+
+// protect 執行 f，並把 panic 轉成 *group.PanicError。
+func protect(f func() error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &group.PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return f()
+}
@@ -0,0 +1,314 @@
+package actor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+var errBoom = errors.New("boom")
+
+// events 記錄生命週期 hook 的呼叫順序。
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(s string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, s)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.list)
+}
+
+// cmd 是測試用 counter 的訊息。
+type cmd struct {
+	op    string // add、get、fail、panic、block、ignore
+	n     int
+	reply Reply[int]
+}
+
+// counter 是一個有狀態的 actor，並記錄 PreStart 與 PostStop。
+type counter struct {
+	name string
+	log  *events
+	n    int
+}
+
+func (c *counter) Receive(ctx *Context[cmd], msg cmd) error {
+	switch msg.op {
+	case "add":
+		c.n += msg.n
+	case "get":
+		msg.reply.Send(c.n)
+	case "fail":
+		return errBoom
+	case "panic":
+		panic("kaboom")
+	case "block":
+		<-ctx.Context().Done()
+	}
+	return nil
+}
+
+func (c *counter) PreStart(ctx *Context[cmd]) error {
+	c.log.add(c.name + " start")
+	return nil
+}
+
+func (c *counter) PostStop(ctx *Context[cmd]) {
+	c.log.add(c.name + " stop")
+}
+
+func newCounter(name string, log *events) func() Actor[cmd] {
+	return func() Actor[cmd] { return &counter{name: name, log: log} }
+}
+
+// newTestSystem 建立一個在測試結束時關閉的 System。
+func newTestSystem(t *testing.T, opts ...Option) *System {
+	t.Helper()
+	leakcheck.Check(t)
+	sys := NewSystem(opts...)
+	t.Cleanup(sys.Shutdown)
+	return sys
+}
+
+func spawn(t *testing.T, parent Parent, name string, newActor func() Actor[cmd], opts ...Option) *Ref[cmd] {
+	t.Helper()
+	ref, err := Spawn(parent, name, newActor, opts...)
+	if err != nil {
+		t.Fatalf("Spawn(%q) 錯誤 = %v", name, err)
+	}
+	return ref
+}
+
+func get(t *testing.T, ref *Ref[cmd]) int {
+	t.Helper()
+	n, err := Ask(context.Background(), ref, time.Second, func(r Reply[int]) cmd {
+		return cmd{op: "get", reply: r}
+	})
+	if err != nil {
+		t.Fatalf("Ask(%s, get) 錯誤 = %v", ref.Path(), err)
+	}
+	return n
+}
+
+func TestTellAndAsk(t *testing.T) {
+	sys := newTestSystem(t)
+	ref := spawn(t, sys, "counter", newCounter("counter", &events{}))
+
+	// 100 個 Goroutine 同時送訊息，actor 依序處理，不需要任何鎖。
+	var wg sync.WaitGroup
+	for i := 1; i <= 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ref.Tell(cmd{op: "add", n: i})
+		}()
+	}
+	wg.Wait()
+	if got := get(t, ref); got != 5050 {
+		t.Errorf("get = %d; 預期為 5050", got)
+	}
+	if ref.Path() != "/counter" {
+		t.Errorf("Path() = %q; 預期為 \"/counter\"", ref.Path())
+	}
+	if _, err := Spawn(sys, "counter", newCounter("dup", &events{})); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("重複名稱的 Spawn 錯誤 = %v; 預期為 ErrDuplicateName", err)
+	}
+}
+
+func TestAskTimeoutAndStopped(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	sys := newTestSystem(t, WithClock(fake))
+	ref := spawn(t, sys, "silent", newCounter("silent", &events{}))
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := Ask(context.Background(), ref, time.Second, func(r Reply[int]) cmd {
+			return cmd{op: "ignore", reply: r}
+		})
+		errc <- err
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	if err := <-errc; !errors.Is(err, ErrTimeout) {
+		t.Errorf("沒有回覆的 Ask 錯誤 = %v; 預期為 ErrTimeout", err)
+	}
+
+	ref.Stop()
+	<-ref.Done()
+	if err := ref.Tell(cmd{op: "add"}); !errors.Is(err, ErrStopped) {
+		t.Errorf("停止後 Tell 錯誤 = %v; 預期為 ErrStopped", err)
+	}
+	if _, err := Ask(context.Background(), ref, 0, func(r Reply[int]) cmd { return cmd{op: "get", reply: r} }); !errors.Is(err, ErrStopped) {
+		t.Errorf("停止後 Ask 錯誤 = %v; 預期為 ErrStopped", err)
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		strategy Strategy
+		failOp   string
+		wantB    int // a 失敗後 b 的狀態
+	}{
+		{"OneForOne 只重啟失敗的 actor", OneForOne, "fail", 7},
+		{"AllForOne 重啟所有兄弟", AllForOne, "fail", 0},
+		{"panic 也視為失敗", OneForOne, "panic", 7},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			log := &events{}
+			sys := newTestSystem(t, WithSupervisor(tc.strategy, 3, time.Minute))
+			a := spawn(t, sys, "a", newCounter("a", log))
+			b := spawn(t, sys, "b", newCounter("b", log))
+			a.Tell(cmd{op: "add", n: 5})
+			b.Tell(cmd{op: "add", n: 7})
+			// 確定 b 已經處理完 add；信箱中的訊息在重啟後仍會被處理。
+			get(t, b)
+
+			a.Tell(cmd{op: tc.failOp})
+			// a 的狀態隨著重啟歸零；信箱中之後的訊息仍會被處理。
+			if got := get(t, a); got != 0 {
+				t.Errorf("a = %d; 預期重啟後為 0", got)
+			}
+			if got := get(t, b); got != tc.wantB {
+				t.Errorf("b = %d; 預期為 %d", got, tc.wantB)
+			}
+		})
+	}
+}
+
+func TestRestartLimitEscalates(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	sys := newTestSystem(t, WithClock(fake), WithSupervisor(OneForOne, 2, time.Minute))
+	log := &events{}
+	a := spawn(t, sys, "a", newCounter("a", log))
+
+	// 時間窗外的重啟不計入上限。
+	a.Tell(cmd{op: "fail"})
+	a.Tell(cmd{op: "fail"})
+	get(t, a)
+	fake.Advance(time.Minute)
+	a.Tell(cmd{op: "fail"})
+	get(t, a)
+
+	// 1 分鐘內第 3 次失敗超過上限：a 被停止，失敗往上回報，整個 System 停止。
+	a.Tell(cmd{op: "fail"})
+	a.Tell(cmd{op: "fail"})
+	<-sys.Done()
+	if err := sys.Err(); !errors.Is(err, ErrTooManyRestarts) || !errors.Is(err, errBoom) {
+		t.Errorf("Err() = %v; 預期包含 ErrTooManyRestarts 與 %v", err, errBoom)
+	}
+	if _, err := Spawn(sys, "late", newCounter("late", log)); !errors.Is(err, ErrStopped) {
+		t.Errorf("停止後 Spawn 錯誤 = %v; 預期為 ErrStopped", err)
+	}
+}
+
+// supervisor 在 PreStart 時建立一個子 actor，並把它的 Ref 交給測試。
+type supervisor struct {
+	log      *events
+	children chan *Ref[cmd]
+}
+
+func (s *supervisor) Receive(ctx *Context[string], msg string) error {
+	return nil
+}
+
+func (s *supervisor) PreStart(ctx *Context[string]) error {
+	s.log.add("sup start")
+	child, err := Spawn(ctx, "child", newCounter("child", s.log))
+	if err != nil {
+		return err
+	}
+	s.children <- child
+	return nil
+}
+
+func (s *supervisor) PreRestart(ctx *Context[string], reason error) {
+	s.log.add("sup pre-restart")
+}
+
+func (s *supervisor) PostRestart(ctx *Context[string], reason error) error {
+	s.log.add("sup post-restart")
+	return s.PreStart(ctx)
+}
+
+func (s *supervisor) PostStop(ctx *Context[string]) {
+	s.log.add("sup stop")
+}
+
+func TestSupervisionTree(t *testing.T) {
+	sys := newTestSystem(t)
+	log := &events{}
+	children := make(chan *Ref[cmd], 2)
+	sup, err := Spawn(sys, "sup", func() Actor[string] {
+		return &supervisor{log: log, children: children}
+	}, WithSupervisor(OneForOne, 0, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	child := <-children
+	if child.Path() != "/sup/child" {
+		t.Errorf("子 actor 的 Path() = %q; 預期為 \"/sup/child\"", child.Path())
+	}
+
+	// sup 不允許任何重啟，因此子 actor 一失敗就往上回報：sup 被頂層重啟，並建立新的子 actor。
+	child.Tell(cmd{op: "fail"})
+	newChild := <-children
+	if got := get(t, newChild); got != 0 {
+		t.Errorf("新的子 actor = %d; 預期為 0", got)
+	}
+	<-child.Done()
+
+	// 停止時子 actor 先停止。
+	sup.Stop()
+	<-sup.Done()
+	want := []string{
+		"sup start", "child start",
+		"child stop", // 超過上限而停止
+		"sup pre-restart", "sup post-restart", "sup start", "child start",
+		"child stop", "sup stop",
+	}
+	if got := log.get(); !slices.Equal(got, want) {
+		t.Errorf("事件順序 = %q; 預期為 %q", got, want)
+	}
+	if err := newChild.Tell(cmd{op: "add"}); !errors.Is(err, ErrStopped) {
+		t.Errorf("父 actor 停止後子 actor 的 Tell 錯誤 = %v; 預期為 ErrStopped", err)
+	}
+}
+
+func TestStopCancelsContext(t *testing.T) {
+	sys := newTestSystem(t)
+	ref := spawn(t, sys, "busy", newCounter("busy", &events{}), WithMailbox(1))
+
+	ref.Tell(cmd{op: "block"})
+	// 等 actor 開始處理 block 之後，信箱只剩一個空位。
+	for ref.c.mailbox.push(cmd{op: "add"}) != nil {
+		time.Sleep(time.Millisecond)
+	}
+	if err := ref.Tell(cmd{op: "add"}); !errors.Is(err, ErrMailboxFull) {
+		t.Errorf("信箱已滿時 Tell 錯誤 = %v; 預期為 ErrMailboxFull", err)
+	}
+
+	// Stop 會取消正在執行的 Receive 的 Context。
+	ref.Stop()
+	select {
+	case <-ref.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Stop 沒有取消阻塞中的 Receive")
+	}
+}
@@ -0,0 +1,181 @@
+package actor
+
+import (
+	"context"
+	"sync"
+)
+
+// mailbox 是 actor 的信箱：一個 FIFO 佇列，加上有訊息時通知 actor 的 channel。
+type mailbox[M any] struct {
+	mu     sync.Mutex
+	queue  []M
+	limit  int // <= 0 代表不限制
+	closed bool
+	ready  chan struct{}
+}
+
+func newMailbox[M any](limit int) *mailbox[M] {
+	return &mailbox[M]{limit: limit, ready: make(chan struct{}, 1)}
+}
+
+func (m *mailbox[M]) push(msg M) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrStopped
+	}
+	if m.limit > 0 && len(m.queue) >= m.limit {
+		return ErrMailboxFull
+	}
+	m.queue = append(m.queue, msg)
+	m.notify()
+	return nil
+}
+
+func (m *mailbox[M]) pop() (M, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var zero M
+	if len(m.queue) == 0 {
+		return zero, false
+	}
+	msg := m.queue[0]
+	m.queue[0] = zero
+	m.queue = m.queue[1:]
+	if len(m.queue) > 0 {
+		m.notify()
+	}
+	return msg, true
+}
+
+// close 讓之後的 push 回傳 ErrStopped，並丟棄尚未處理的訊息。
+func (m *mailbox[M]) close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	m.queue = nil
+}
+
+// notify 通知 actor 有訊息。呼叫者必須持有 m.mu。
+func (m *mailbox[M]) notify() {
+	select {
+	case m.ready <- struct{}{}:
+	default:
+	}
+}
+
+// cell 是一個 actor 的執行環境：監督樹中的節點、信箱與目前的實例。
+type cell[M any] struct {
+	*node
+	newActor func() Actor[M]
+	mailbox  *mailbox[M]
+	ref      *Ref[M]
+
+	actor Actor[M]
+	ctx   *Context[M]
+}
+
+// run 是 actor 的 Goroutine：依序處理信箱中的訊息，並處理失敗與父 actor 的要求。
+func (c *cell[M]) run() {
+	defer c.exit()
+
+	err := c.start(nil)
+	for {
+		if err != nil {
+			if c.parent.supervise(c.node, err) == stop {
+				c.stop()
+				return
+			}
+			err = c.start(err)
+			continue
+		}
+
+		// 父 actor 的要求優先於信箱中的訊息。
+		switch ctl, reason := c.takeRequest(); ctl {
+		case ctlStop:
+			c.stop()
+			return
+		case ctlFail:
+			err = reason
+			continue
+		case ctlRestart:
+			err = c.start(reason)
+			continue
+		}
+
+		if msg, ok := c.mailbox.pop(); ok {
+			err = protect(func() error { return c.actor.Receive(c.ctx, msg) })
+			continue
+		}
+		// 沒有訊息也沒有要求時才阻塞；醒來後回到迴圈開頭，先檢查要求再取下一則訊息。
+		select {
+		case <-c.signal:
+		case <-c.mailbox.ready:
+		}
+	}
+}
+
+// start 建立新的實例並呼叫啟動 hook。reason 不為 nil 時代表重啟：
+// 先停止所有子 actor、對舊的實例呼叫 PreRestart (或 PostStop)，再對新的實例呼叫 PostRestart (或 PreStart)。
+func (c *cell[M]) start(reason error) error {
+	if c.actor != nil {
+		c.stopChildren(false)
+		old, oldCtx := c.actor, c.ctx
+		protect(func() error {
+			switch a := old.(type) {
+			case PreRestarter[M]:
+				a.PreRestart(oldCtx, reason)
+			case PostStopper[M]:
+				a.PostStop(oldCtx)
+			}
+			return nil
+		})
+	}
+
+	c.actor = c.newActor()
+	c.ctx = c.newContext()
+	return protect(func() error {
+		if a, ok := c.actor.(PostRestarter[M]); ok && reason != nil {
+			return a.PostRestart(c.ctx, reason)
+		}
+		if a, ok := c.actor.(PreStarter[M]); ok {
+			return a.PreStart(c.ctx)
+		}
+		return nil
+	})
+}
+
+// newContext 建立新實例的 Context；它會在下一次 request 時被取消。
+func (c *cell[M]) newContext() *Context[M] {
+	ctx, cancel := context.WithCancel(c.sys.ctx)
+	c.mu.Lock()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.cancel = cancel
+	c.mu.Unlock()
+	return &Context[M]{c: c, ctx: ctx}
+}
+
+// stop 關閉信箱、停止所有子 actor，再呼叫 PostStop。
+func (c *cell[M]) stop() {
+	c.mailbox.close()
+	c.stopChildren(true)
+	if a, ok := c.actor.(PostStopper[M]); ok {
+		protect(func() error {
+			a.PostStop(c.ctx)
+			return nil
+		})
+	}
+}
+
+// exit 取消 Context、把自己從父 actor 移除，並通知等待者。
+func (c *cell[M]) exit() {
+	c.mu.Lock()
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.mu.Unlock()
+	c.parent.removeChild(c.node)
+	close(c.done)
+}
@@ -0,0 +1,287 @@
+package actor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+)
+
+// Strategy 決定子 actor 失敗時要重啟哪些 actor。
+type Strategy int
+
+const (
+	// OneForOne 只重啟失敗的子 actor (預設)。適合彼此獨立的子 actor。
+	OneForOne Strategy = iota
+	// AllForOne 重啟所有子 actor。適合彼此依賴、必須一起重來的子 actor。
+	AllForOne
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case OneForOne:
+		return "OneForOne"
+	case AllForOne:
+		return "AllForOne"
+	default:
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+}
+
+// supervision 是一個 actor 監督子 actor 的方式。
+type supervision struct {
+	strategy    Strategy
+	maxRestarts int
+	within      time.Duration
+}
+
+// config 保存 Option 設定的值。
+type config struct {
+	supervision supervision
+	mailbox     int
+	clock       clock.Clock
+}
+
+// Option 用來調整 NewSystem 或 Spawn 的行為。
+type Option func(*config)
+
+// WithSupervisor 設定 actor (或 System 的頂層) 如何監督它的子 actor：
+// 在 within 時間內最多重啟 maxRestarts 次，超過時停止失敗的子 actor 並往上回報。
+// 預設為 OneForOne，1 分鐘內最多 3 次。
+func WithSupervisor(strategy Strategy, maxRestarts int, within time.Duration) Option {
+	return func(c *config) { c.supervision = supervision{strategy, maxRestarts, within} }
+}
+
+// WithMailbox 讓 actor 的信箱最多保存 n 則訊息，超過時 Tell 回傳 ErrMailboxFull。
+// 預設 (n <= 0) 不限制。只對 Spawn 有效。
+func WithMailbox(n int) Option {
+	return func(c *config) { c.mailbox = n }
+}
+
+// WithClock 指定 System 使用的時鐘 (Ask 的逾時與重啟次數的時間窗)，預設為 clock.Real()。
+// 只對 NewSystem 有效。
+func WithClock(c clock.Clock) Option {
+	return func(cfg *config) { cfg.clock = c }
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		supervision: supervision{strategy: OneForOne, maxRestarts: 3, within: time.Minute},
+		clock:       clock.Real(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// System 是監督樹的根。頂層 actor 的失敗超過重啟上限時，整個 System 會停止，
+// 並可以從 Err 取得原因。
+type System struct {
+	root  *node
+	clock clock.Clock
+	ctx   context.Context // 所有 actor 的 Context 都衍生自它
+
+	mu       sync.Mutex
+	err      error
+	stopOnce sync.Once
+}
+
+// NewSystem 建立一個 System。opts 中的 WithSupervisor 決定如何監督頂層 actor。
+func NewSystem(opts ...Option) *System {
+	cfg := newConfig(opts)
+	s := &System{clock: cfg.clock, ctx: context.Background()}
+	s.root = newNode(s, nil, "", cfg.supervision)
+	return s
+}
+
+func (s *System) parentNode() *node {
+	return s.root
+}
+
+// Shutdown 停止所有 actor (子 actor 先於父 actor 停止)，並等待它們結束。可以重複呼叫。
+func (s *System) Shutdown() {
+	s.stopOnce.Do(func() {
+		s.root.stopChildren(true)
+		close(s.root.done)
+	})
+	<-s.root.done
+}
+
+// Done 回傳一個在 System 停止後關閉的 channel。
+func (s *System) Done() <-chan struct{} {
+	return s.root.done
+}
+
+// Err 回傳讓 System 停止的失敗；正常 Shutdown 時為 nil。
+func (s *System) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// fail 記錄頂層的失敗並在背景停止 System (呼叫者可能是正要停止的 actor 本身)。
+func (s *System) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+	go s.Shutdown()
+}
+
+// control 是父 actor (或 Ref.Stop) 對 actor 的要求，數值越大優先權越高。
+type control int
+
+const (
+	ctlNone    control = iota
+	ctlRestart         // 兄弟失敗時 (AllForOne) 由父 actor 要求重啟
+	ctlFail            // 子 actor 重啟太多次，失敗被回報上來
+	ctlStop
+)
+
+// directive 是父 actor 對失敗的子 actor 做出的決定。
+type directive int
+
+const (
+	restart directive = iota
+	stop
+)
+
+// node 是 actor 在監督樹中與訊息型別無關的部分。
+type node struct {
+	sys    *System
+	parent *node
+	path   string
+	sup    supervision
+
+	signal chan struct{} // 有新的 control 時通知 actor 的 Goroutine
+	done   chan struct{}
+
+	mu       sync.Mutex
+	children []*node
+	stopping bool
+	pending  control
+	reason   error
+	cancel   context.CancelFunc // 取消目前實例的 Context
+	restarts []time.Time        // 子 actor 最近的重啟時間
+}
+
+func newNode(sys *System, parent *node, path string, sup supervision) *node {
+	return &node{
+		sys:    sys,
+		parent: parent,
+		path:   path,
+		sup:    sup,
+		signal: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+func (n *node) addChild(child *node) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.stopping {
+		return ErrStopped
+	}
+	for _, c := range n.children {
+		if c.path == child.path {
+			return fmt.Errorf("%w: %s", ErrDuplicateName, child.path)
+		}
+	}
+	n.children = append(n.children, child)
+	return nil
+}
+
+func (n *node) removeChild(child *node) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if i := slices.Index(n.children, child); i >= 0 {
+		n.children = slices.Delete(n.children, i, i+1)
+	}
+}
+
+// request 要求 actor 的 Goroutine 處理 ctl；優先權較高的要求會覆蓋較低的。
+// 目前實例的 Context 會被取消，讓正在執行的 Receive 有機會提早結束。
+func (n *node) request(ctl control, reason error) {
+	n.mu.Lock()
+	if ctl > n.pending {
+		n.pending, n.reason = ctl, reason
+	}
+	if n.cancel != nil {
+		n.cancel()
+	}
+	n.mu.Unlock()
+	select {
+	case n.signal <- struct{}{}:
+	default:
+	}
+}
+
+// takeRequest 取出待處理的 control。
+func (n *node) takeRequest() (control, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ctl, reason := n.pending, n.reason
+	n.pending, n.reason = ctlNone, nil
+	return ctl, reason
+}
+
+// supervise 在失敗的子 actor 的 Goroutine 中被呼叫，決定要重啟還是停止它。
+func (n *node) supervise(child *node, reason error) directive {
+	n.mu.Lock()
+	now := n.sys.clock.Now()
+	n.restarts = slices.DeleteFunc(n.restarts, func(t time.Time) bool {
+		return now.Sub(t) >= n.sup.within
+	})
+	if len(n.restarts) >= n.sup.maxRestarts {
+		n.restarts = nil
+		n.mu.Unlock()
+		n.escalate(fmt.Errorf("%w: %s: %w", ErrTooManyRestarts, child.path, reason))
+		return stop
+	}
+	n.restarts = append(n.restarts, now)
+
+	var siblings []*node
+	if n.sup.strategy == AllForOne {
+		for _, c := range n.children {
+			if c != child {
+				siblings = append(siblings, c)
+			}
+		}
+	}
+	n.mu.Unlock()
+
+	for _, s := range siblings {
+		s.request(ctlRestart, reason)
+	}
+	return restart
+}
+
+// escalate 把 n 自己視為失敗：交給 n 的父 actor 處理；n 是根時停止整個 System。
+func (n *node) escalate(err error) {
+	if n.parent == nil {
+		n.sys.fail(err)
+		return
+	}
+	n.request(ctlFail, err)
+}
+
+// stopChildren 依建立的相反順序停止所有子 actor，並等待它們結束。
+// final 為 true 時之後不再接受新的子 actor。
+func (n *node) stopChildren(final bool) {
+	n.mu.Lock()
+	if final {
+		n.stopping = true
+	}
+	children := slices.Clone(n.children)
+	n.mu.Unlock()
+
+	for _, c := range slices.Backward(children) {
+		c.request(ctlStop, nil)
+		<-c.done
+	}
+}
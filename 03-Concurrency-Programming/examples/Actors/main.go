@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Actors/actor"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutines/group"
+)
+
+// --- Bank Account Actor ---
+
+// errInsufficientFunds 是業務錯誤：透過 Reply 回覆給請求者，而不是讓 actor 失敗。
+var errInsufficientFunds = errors.New("insufficient funds")
+
+// accountMsg 是 account actor 接受的訊息。信箱是型別化的，其他型別的值無法送進來。
+type accountMsg interface {
+	accountMsg()
+}
+
+// deposit 存入 amount，不需要回覆。
+type deposit struct {
+	amount int
+}
+
+// withdraw 提領 amount，回覆提領後的餘額或 errInsufficientFunds。
+type withdraw struct {
+	amount int
+	reply  actor.Reply[int]
+}
+
+// balance 回覆目前的餘額。
+type balance struct {
+	reply actor.Reply[int]
+}
+
+// corrupt 模擬帳目損壞：account 會 panic，交由監督者重啟。
+type corrupt struct{}
+
+func (deposit) accountMsg()  {}
+func (withdraw) accountMsg() {}
+func (balance) accountMsg()  {}
+func (corrupt) accountMsg()  {}
+
+// account 與 SafeCounter 不同，它沒有 Mutex：balance 只會在 actor 自己的 Goroutine 中被存取。
+type account struct {
+	opening int
+	balance int
+}
+
+// newAccount 回傳建立帳戶的函式；重啟時帳戶會從開戶金額重新開始。
+func newAccount(opening int) func() actor.Actor[accountMsg] {
+	return func() actor.Actor[accountMsg] { return &account{opening: opening} }
+}
+
+func (a *account) PreStart(ctx *actor.Context[accountMsg]) error {
+	a.balance = a.opening
+	return nil
+}
+
+func (a *account) PostRestart(ctx *actor.Context[accountMsg], reason error) error {
+	// PanicError 的訊息包含完整堆疊，這裡只印出 panic 的值。
+	var pe *group.PanicError
+	if errors.As(reason, &pe) {
+		fmt.Printf("%s restarted after panic: %v\n", ctx.Path(), pe.Value)
+	} else {
+		fmt.Printf("%s restarted: %v\n", ctx.Path(), reason)
+	}
+	return a.PreStart(ctx)
+}
+
+func (a *account) Receive(ctx *actor.Context[accountMsg], msg accountMsg) error {
+	switch m := msg.(type) {
+	case deposit:
+		a.balance += m.amount
+	case withdraw:
+		if m.amount > a.balance {
+			m.reply.Fail(fmt.Errorf("%s: withdraw %d: %w", ctx.Path(), m.amount, errInsufficientFunds))
+			return nil
+		}
+		a.balance -= m.amount
+		m.reply.Send(a.balance)
+	case balance:
+		m.reply.Send(a.balance)
+	case corrupt:
+		panic("ledger corrupted")
+	}
+	return nil
+}
+
+// askTimeout 是 demo 中每一次 Ask 等待回覆的上限。
+const askTimeout = time.Second
+
+func getBalance(ref *actor.Ref[accountMsg]) (int, error) {
+	return actor.Ask(context.Background(), ref, askTimeout, func(r actor.Reply[int]) accountMsg {
+		return balance{reply: r}
+	})
+}
+
+func withdrawFrom(ref *actor.Ref[accountMsg], amount int) (int, error) {
+	return actor.Ask(context.Background(), ref, askTimeout, func(r actor.Reply[int]) accountMsg {
+		return withdraw{amount: amount, reply: r}
+	})
+}
+
+// concurrentDeposits 讓 n 個 Goroutine 同時對 ref 存入 1 元，回傳最後的餘額。
+// 與 SafeCounter 的 demo 相同的工作量，但完全不需要鎖。
+func concurrentDeposits(ref *actor.Ref[accountMsg], n int) (int, error) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ref.Tell(deposit{amount: 1})
+		}()
+	}
+	wg.Wait()
+	// 同一個 actor 依序處理訊息，因此 balance 一定在所有 deposit 之後才被處理。
+	return getBalance(ref)
+}
+
+// restartAfterCorruption 讓 ref 失敗一次，回傳監督者重啟它之後的餘額。
+func restartAfterCorruption(ref *actor.Ref[accountMsg]) (int, error) {
+	ref.Tell(corrupt{})
+	// 信箱在重啟後保留，因此這則 balance 會由新的實例處理。
+	return getBalance(ref)
+}
+
+func main() {
+	sys := actor.NewSystem(actor.WithSupervisor(actor.OneForOne, 3, time.Minute))
+	defer sys.Shutdown()
+
+	fmt.Println("--- Actor Example: Bank Account ---")
+	alice, err := actor.Spawn(sys, "alice", newAccount(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	total, err := concurrentDeposits(alice, 1000)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Balance after 1000 concurrent deposits:", total)
+
+	if left, err := withdrawFrom(alice, 300); err != nil {
+		log.Fatal(err)
+	} else {
+		fmt.Println("Withdrew 300, balance:", left)
+	}
+	if _, err := withdrawFrom(alice, 5000); errors.Is(err, errInsufficientFunds) {
+		fmt.Println("Overdraft rejected:", err)
+	}
+
+	fmt.Println("\n--- Actor Example: Supervision ---")
+	bob, err := actor.Spawn(sys, "bob", newAccount(100))
+	if err != nil {
+		log.Fatal(err)
+	}
+	bob.Tell(deposit{amount: 50})
+	after, err := restartAfterCorruption(bob)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// 重啟後的 actor 從全新的狀態開始；需要保留的狀態必須在 PostRestart 中重建。
+	fmt.Println("Balance after restart:", after)
+
+	left, _ := getBalance(alice)
+	fmt.Println("Alice is unaffected (OneForOne):", left)
+}
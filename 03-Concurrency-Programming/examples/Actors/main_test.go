@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Actors/actor"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+func newBank(t *testing.T) *actor.System {
+	t.Helper()
+	leakcheck.Check(t)
+	sys := actor.NewSystem()
+	t.Cleanup(sys.Shutdown)
+	return sys
+}
+
+func TestConcurrentDeposits(t *testing.T) {
+	sys := newBank(t)
+	ref, err := actor.Spawn(sys, "alice", newAccount(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := concurrentDeposits(ref, 1000); err != nil || got != 1010 {
+		t.Errorf("concurrentDeposits = (%d, %v); 預期為 1010", got, err)
+	}
+}
+
+func TestWithdraw(t *testing.T) {
+	sys := newBank(t)
+	ref, err := actor.Spawn(sys, "alice", newAccount(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		amount  int
+		want    int
+		wantErr error
+	}{
+		{30, 70, nil},
+		{70, 0, nil},
+		{1, 0, errInsufficientFunds},
+	}
+	for _, tc := range testCases {
+		got, err := withdrawFrom(ref, tc.amount)
+		if !errors.Is(err, tc.wantErr) || got != tc.want {
+			t.Errorf("withdrawFrom(%d) = (%d, %v); 預期為 (%d, %v)", tc.amount, got, err, tc.want, tc.wantErr)
+		}
+	}
+	// 業務錯誤不會讓 actor 失敗，餘額維持不變。
+	if got, err := getBalance(ref); err != nil || got != 0 {
+		t.Errorf("getBalance = (%d, %v); 預期為 0", got, err)
+	}
+}
+
+func TestRestartAfterCorruption(t *testing.T) {
+	sys := newBank(t)
+	alice, err := actor.Spawn(sys, "alice", newAccount(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := actor.Spawn(sys, "bob", newAccount(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice.Tell(deposit{amount: 5})
+	bob.Tell(deposit{amount: 50})
+
+	if got, err := restartAfterCorruption(bob); err != nil || got != 100 {
+		t.Errorf("restartAfterCorruption = (%d, %v); 預期重啟後回到開戶金額 100", got, err)
+	}
+	if got, err := getBalance(alice); err != nil || got != 5 {
+		t.Errorf("alice = (%d, %v); 預期不受 OneForOne 重啟影響，為 5", got, err)
+	}
+}
@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Channels/pipeline"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Channels/pubsub"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
 )
 
 // producer 函式會向 channel 中發送 5 個整數，然後關閉 channel
-func producer(ch chan int) {
+// clk 提供時間來源，測試時可以換成假時鐘。
+func producer(clk clock.Clock, ch chan int) {
 	fmt.Println("Producer: starting")
 	for i := 0; i < 5; i++ {
 		fmt.Printf("Producer: sending %d\n", i)
 		ch <- i
-		time.Sleep(100 * time.Millisecond)
+		clk.Sleep(100 * time.Millisecond)
 	}
 	// 當所有值都發送完畢後，關閉 channel
 	// 這會通知接收方不會再有新的值傳入
@@ -19,9 +25,8 @@ func producer(ch chan int) {
 	fmt.Println("Producer: channel closed")
 }
 
-func main() {
-	// --- Unbuffered Channel ---
-	fmt.Println("--- Unbuffered Channel Example ---")
+// unbuffered 示範無緩衝 channel：傳送與接收必須同時準備好。
+func unbuffered() {
 	// 建立一個無緩衝的 channel
 	messages := make(chan string)
 
@@ -33,9 +38,10 @@ func main() {
 	// 從 channel 接收一個值，這個操作會阻塞，直到有 goroutine 傳入值
 	msg := <-messages
 	fmt.Println("Received message:", msg)
+}
 
-	// --- Buffered Channel ---
-	fmt.Println("\n--- Buffered Channel Example ---")
+// buffered 示範有緩衝 channel：緩衝區未滿時傳送不會阻塞。
+func buffered() {
 	// 建立一個緩衝大小為 2 的 channel
 	bufferedChan := make(chan string, 2)
 
@@ -47,11 +53,12 @@ func main() {
 	// 取出值
 	fmt.Println("Received:", <-bufferedChan)
 	fmt.Println("Received:", <-bufferedChan)
+}
 
-	// --- Range and Close ---
-	fmt.Println("\n--- Range and Close Example ---")
+// rangeAndClose 用 for-range 接收 producer 送出的所有值。
+func rangeAndClose(clk clock.Clock) {
 	ch := make(chan int, 5)
-	go producer(ch)
+	go producer(clk, ch)
 
 	// for-range 會持續從 channel 中接收值，直到 channel 被關閉
 	fmt.Println("Consumer: waiting for values")
@@ -60,3 +67,61 @@ func main() {
 	}
 	fmt.Println("Consumer: finished receiving")
 }
+
+// pipelineDemo 用 pipeline 套件改寫 producer/consumer：
+// 產生 0~9，過濾出偶數、平方後每 2 個一組送出。
+func pipelineDemo() {
+	ctx, cancel := context.WithCancel(context.Background())
+	// 提早離開時取消 ctx，讓所有階段的 Goroutine 都能結束。
+	defer cancel()
+
+	nums := pipeline.Generate(ctx, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	even := pipeline.Filter(ctx, nums, func(n int) bool { return n%2 == 0 })
+	squares := pipeline.ParallelMap(ctx, even, 3, func(n int) int { return n * n })
+	for batch := range pipeline.Batch(ctx, squares, 2, 100*time.Millisecond) {
+		fmt.Println("Consumer: received batch", batch)
+	}
+}
+
+// pubsubDemo 示範一對多的發布/訂閱：同一則訊息依主題送給所有符合的訂閱者。
+func pubsubDemo() {
+	ctx := context.Background()
+	broker := pubsub.New[string](pubsub.WithReplay(10))
+	defer broker.Close()
+
+	// 在訂閱之前發布的訊息只有使用 Replay 的訂閱者收得到。
+	broker.Publish(ctx, "users.created", "alice")
+
+	direct, _ := broker.Subscribe("users.*")
+	all, _ := broker.Subscribe("users.>", pubsub.Replay(10))
+
+	broker.Publish(ctx, "users.deleted", "bob")
+	broker.Publish(ctx, "users.eu.created", "carol")
+
+	for _, sub := range []*pubsub.Subscription[string]{direct, all} {
+		sub.Unsubscribe()
+		// 取消訂閱後 C() 會被關閉，for-range 讀完緩衝區中的訊息就結束。
+		for msg := range sub.C() {
+			fmt.Printf("Subscriber %-8s received %s: %s\n", sub.Pattern(), msg.Topic, msg.Payload)
+		}
+	}
+}
+
+func main() {
+	clk := clock.Real()
+
+	fmt.Println("--- Unbuffered Channel Example ---")
+	unbuffered()
+
+	fmt.Println("\n--- Buffered Channel Example ---")
+	buffered()
+
+	fmt.Println("\n--- Range and Close Example ---")
+	rangeAndClose(clk)
+
+	fmt.Println("\n--- Pipeline Example ---")
+	pipelineDemo()
+
+	fmt.Println("\n--- Pub/Sub Example ---")
+	pubsubDemo()
+}
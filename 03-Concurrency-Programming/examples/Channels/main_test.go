@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+func TestExamplesNoLeak(t *testing.T) {
+	testCases := []struct {
+		name string
+		run  func(clk clock.Clock)
+	}{
+		{"unbuffered", func(clock.Clock) { unbuffered() }},
+		{"buffered", func(clock.Clock) { buffered() }},
+		{"range and close", rangeAndClose},
+		{"pipeline", func(clock.Clock) { pipelineDemo() }},
+		{"pubsub", func(clock.Clock) { pubsubDemo() }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			leakcheck.Check(t)
+			fake := clock.NewFake(time.Unix(0, 0))
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				tc.run(fake)
+			}()
+			// 快轉 producer 中的 Sleep。
+			fake.RunUntil(done)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// ParallelMap 以 workers 個 Goroutine 同時套用 fn，並保持輸出順序與輸入相同。
+// 較快完成的結果會等待前面的結果先送出，因此最多只會有 workers 個結果在等待中。
+func ParallelMap[In, Out any](ctx context.Context, in <-chan In, workers int, fn func(In) Out) <-chan Out {
+	if workers < 1 {
+		workers = 1
+	}
+	out := make(chan Out)
+	// pending 依輸入順序保存每個值的結果 channel；它的容量 (加上收集端正在等待的那一個)
+	// 同時限制了並行數量。
+	pending := make(chan chan Out, workers-1)
+
+	go func() {
+		defer close(pending)
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return
+			}
+			// 結果 channel 有一格緩衝，計算完成後就算沒人接收也不會阻塞。
+			result := make(chan Out, 1)
+			if !send(ctx, pending, result) {
+				return
+			}
+			go func() {
+				result <- fn(v)
+			}()
+		}
+	}()
+
+	go func() {
+		defer close(out)
+		for result := range pending {
+			v, ok := recv(ctx, result)
+			if !ok || !send(ctx, out, v) {
+				// 讓分派的 Goroutine 不會卡在 pending 上。
+				for range pending {
+				}
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// ParallelMapUnordered 以 workers 個 Goroutine 同時套用 fn，結果依完成的先後送出。
+func ParallelMapUnordered[In, Out any](ctx context.Context, in <-chan In, workers int, fn func(In) Out) <-chan Out {
+	if workers < 1 {
+		workers = 1
+	}
+	out := make(chan Out)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for {
+				v, ok := recv(ctx, in)
+				if !ok || !send(ctx, out, fn(v)) {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
@@ -0,0 +1,284 @@
+// Package pipeline 以泛型 (generics) 實作常見的 channel pipeline 階段。
+//
+// 每個階段都是一個函式：接收一個輸入 channel，啟動自己的 Goroutine，
+// 並回傳一個輸出 channel。輸入關閉時，輸出也會跟著關閉，因此可以像這樣串接：
+//
+//	nums := pipeline.Generate(ctx, 1, 2, 3, 4)
+//	even := pipeline.Filter(ctx, nums, func(n int) bool { return n%2 == 0 })
+//	strs := pipeline.Map(ctx, even, strconv.Itoa)
+//	for s := range strs { ... }
+//
+// 所有階段都會監聽 ctx：一旦 ctx 被取消，每個階段都會停止傳送、關閉輸出並結束 Goroutine，
+// 即使下游已經不再讀取也不會造成 Goroutine 洩漏。
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// send 嘗試把 v 送進 out，ctx 被取消時回傳 false。
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recv 從 in 接收一個值；in 關閉或 ctx 被取消時 ok 為 false。
+func recv[T any](ctx context.Context, in <-chan T) (v T, ok bool) {
+	select {
+	case v, ok = <-in:
+		return v, ok
+	case <-ctx.Done():
+		return v, false
+	}
+}
+
+// Generate 依序送出 values，送完後關閉輸出。
+func Generate[T any](ctx context.Context, values ...T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, v := range values {
+			if !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// GenerateFunc 重複呼叫 next 並送出它的結果，直到 next 回傳 false。
+func GenerateFunc[T any](ctx context.Context, next func() (T, bool)) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := next()
+			if !ok || !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Map 對每個輸入套用 fn。
+func Map[In, Out any](ctx context.Context, in <-chan In, fn func(In) Out) <-chan Out {
+	out := make(chan Out)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := recv(ctx, in)
+			if !ok || !send(ctx, out, fn(v)) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Filter 只保留 keep 回傳 true 的值。
+func Filter[T any](ctx context.Context, in <-chan T, keep func(T) bool) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return
+			}
+			if keep(v) && !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// FlatMap 對每個輸入套用 fn，並把回傳的切片逐一送出。
+func FlatMap[In, Out any](ctx context.Context, in <-chan In, fn func(In) []Out) <-chan Out {
+	out := make(chan Out)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return
+			}
+			for _, o := range fn(v) {
+				if !send(ctx, out, o) {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// Batch 把輸入收集成最多 size 個一組的切片。
+// 如果一組從收到第一個值開始超過 timeout 還沒湊滿，就先把目前收到的送出；timeout <= 0 代表只依大小分組。
+// 輸入關閉時，剩下不足一組的值也會被送出。
+func Batch[T any](ctx context.Context, in <-chan T, size int, timeout time.Duration) <-chan []T {
+	if size < 1 {
+		size = 1
+	}
+	out := make(chan []T)
+	go func() {
+		defer close(out)
+
+		var batch []T
+		var timer *time.Timer
+		var expired <-chan time.Time
+		stopTimer := func() {
+			if timer != nil {
+				timer.Stop()
+				timer, expired = nil, nil
+			}
+		}
+		defer stopTimer()
+
+		flush := func() bool {
+			stopTimer()
+			if len(batch) == 0 {
+				return true
+			}
+			b := batch
+			batch = nil
+			return send(ctx, out, b)
+		}
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, v)
+				if len(batch) == 1 && timeout > 0 {
+					timer = time.NewTimer(timeout)
+					expired = timer.C
+				}
+				if len(batch) >= size && !flush() {
+					return
+				}
+			case <-expired:
+				timer, expired = nil, nil
+				if !flush() {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// Merge 把多個輸入合併成一個輸出 (fan-in)，所有輸入都關閉後才關閉輸出。
+// 輸出的順序取決於各輸入到達的先後。
+func Merge[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		go func() {
+			defer wg.Done()
+			for {
+				v, ok := recv(ctx, in)
+				if !ok || !send(ctx, out, v) {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Tee 把每個輸入值複製到 n 個輸出。
+// 每個值會依序送給每個輸出，全部接收後才處理下一個值，因此每個輸出都應該由各自的 Goroutine 讀取，
+// 而且最慢的讀取者決定整體速度；若某個輸出不再被讀取，請取消 ctx 以釋放 Goroutine。
+func Tee[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return
+			}
+			for _, out := range outs {
+				if !send(ctx, out, v) {
+					return
+				}
+			}
+		}
+	}()
+	return result
+}
+
+// Partition 依 pred 把輸入分成兩個輸出：pred 為 true 的送到 matched，其餘送到 rest。
+// 兩個輸出都必須被讀取，否則分流會阻塞。
+func Partition[T any](ctx context.Context, in <-chan T, pred func(T) bool) (matched, rest <-chan T) {
+	yes, no := make(chan T), make(chan T)
+	go func() {
+		defer close(yes)
+		defer close(no)
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return
+			}
+			out := no
+			if pred(v) {
+				out = yes
+			}
+			if !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return yes, no
+}
+
+// Collect 讀取輸入直到關閉 (或 ctx 被取消)，並回傳收到的所有值。
+func Collect[T any](ctx context.Context, in <-chan T) []T {
+	var all []T
+	for {
+		v, ok := recv(ctx, in)
+		if !ok {
+			return all
+		}
+		all = append(all, v)
+	}
+}
+
+// Drain 丟棄輸入中剩下的值，直到輸入關閉或 ctx 被取消，回傳丟棄的數量。
+// 當下游提早停止讀取、又不想取消整條 pipeline 時，可以用它讓上游順利結束。
+func Drain[T any](ctx context.Context, in <-chan T) int {
+	n := 0
+	for {
+		if _, ok := recv(ctx, in); !ok {
+			return n
+		}
+		n++
+	}
+}
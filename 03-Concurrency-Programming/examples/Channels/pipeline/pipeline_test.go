@@ -0,0 +1,173 @@
+package pipeline
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+func TestStages(t *testing.T) {
+	leakcheck.Check(t)
+	ctx := context.Background()
+
+	nums := Generate(ctx, 1, 2, 3, 4, 5, 6)
+	even := Filter(ctx, nums, func(n int) bool { return n%2 == 0 })
+	pairs := FlatMap(ctx, even, func(n int) []int { return []int{n, n} })
+	strs := Map(ctx, pairs, strconv.Itoa)
+
+	got := Collect(ctx, strs)
+	want := []string{"2", "2", "4", "4", "6", "6"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v; 預期為 %v", got, want)
+	}
+}
+
+func TestGenerateFunc(t *testing.T) {
+	ctx := context.Background()
+	i := 0
+	got := Collect(ctx, GenerateFunc(ctx, func() (int, bool) {
+		i++
+		return i, i <= 3
+	}))
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("got %v; 預期為 [1 2 3]", got)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	leakcheck.Check(t)
+	ctx := context.Background()
+
+	t.Run("by size", func(t *testing.T) {
+		got := Collect(ctx, Batch(ctx, Generate(ctx, 1, 2, 3, 4, 5), 2, 0))
+		want := [][]int{{1, 2}, {3, 4}, {5}}
+		if !slices.EqualFunc(got, want, slices.Equal) {
+			t.Errorf("got %v; 預期為 %v", got, want)
+		}
+	})
+
+	t.Run("by timeout", func(t *testing.T) {
+		in := make(chan int)
+		batches := Batch(ctx, in, 10, 20*time.Millisecond)
+		in <- 1
+		in <- 2
+		// 沒有湊滿 10 個，但 timeout 到了就應該先送出。
+		if got := <-batches; !slices.Equal(got, []int{1, 2}) {
+			t.Errorf("got %v; 預期為 [1 2]", got)
+		}
+		close(in)
+		if _, ok := <-batches; ok {
+			t.Error("output should be closed after input closes")
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	leakcheck.Check(t)
+	ctx := context.Background()
+
+	got := Collect(ctx, Merge(ctx, Generate(ctx, 1, 2), Generate(ctx, 3), Generate(ctx, 4, 5)))
+	sort.Ints(got)
+	if !slices.Equal(got, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("got %v; 預期為 [1 2 3 4 5]", got)
+	}
+}
+
+func TestTee(t *testing.T) {
+	leakcheck.Check(t)
+	ctx := context.Background()
+
+	outs := Tee(ctx, Generate(ctx, 1, 2, 3), 2)
+	results := make([][]int, len(outs))
+	var wg sync.WaitGroup
+	for i, out := range outs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = Collect(ctx, out)
+		}()
+	}
+	wg.Wait()
+	for i, got := range results {
+		if !slices.Equal(got, []int{1, 2, 3}) {
+			t.Errorf("output %d got %v; 預期為 [1 2 3]", i, got)
+		}
+	}
+}
+
+func TestPartition(t *testing.T) {
+	leakcheck.Check(t)
+	ctx := context.Background()
+
+	odd, even := Partition(ctx, Generate(ctx, 1, 2, 3, 4, 5), func(n int) bool { return n%2 == 1 })
+	var odds, evens []int
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); odds = Collect(ctx, odd) }()
+	go func() { defer wg.Done(); evens = Collect(ctx, even) }()
+	wg.Wait()
+
+	if !slices.Equal(odds, []int{1, 3, 5}) || !slices.Equal(evens, []int{2, 4}) {
+		t.Errorf("got odds=%v evens=%v; 預期為 [1 3 5] 與 [2 4]", odds, evens)
+	}
+}
+
+func TestParallelMapKeepsOrder(t *testing.T) {
+	leakcheck.Check(t)
+	ctx := context.Background()
+
+	var active, peak atomic.Int32
+	square := func(n int) int {
+		cur := active.Add(1)
+		for old := peak.Load(); cur > old && !peak.CompareAndSwap(old, cur); old = peak.Load() {
+		}
+		// 讓前面的值比較慢完成，確認輸出仍然依照輸入順序。
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		active.Add(-1)
+		return n * n
+	}
+
+	got := Collect(ctx, ParallelMap(ctx, Generate(ctx, 1, 2, 3, 4, 5, 6, 7, 8, 9), 3, square))
+	want := []int{1, 4, 9, 16, 25, 36, 49, 64, 81}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v; 預期為 %v", got, want)
+	}
+	if p := peak.Load(); p > 3 {
+		t.Errorf("peak concurrency = %d; 預期不超過 3", p)
+	}
+}
+
+func TestParallelMapUnordered(t *testing.T) {
+	leakcheck.Check(t)
+	ctx := context.Background()
+
+	got := Collect(ctx, ParallelMapUnordered(ctx, Generate(ctx, 1, 2, 3, 4), 2, func(n int) int { return n * 10 }))
+	sort.Ints(got)
+	if !slices.Equal(got, []int{10, 20, 30, 40}) {
+		t.Errorf("got %v; 預期為 [10 20 30 40]", got)
+	}
+}
+
+// TestCancelStopsAllStages 在下游只讀一個值就取消，確認整條 pipeline 的 Goroutine 都會結束。
+func TestCancelStopsAllStages(t *testing.T) {
+	leakcheck.Check(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	n := 0
+	src := GenerateFunc(ctx, func() (int, bool) { n++; return n, true })
+	stages := ParallelMap(ctx, Map(ctx, src, func(n int) int { return n + 1 }), 4, func(n int) int { return n * 2 })
+	batched := Batch(ctx, stages, 3, time.Second)
+	outs := Tee(ctx, batched, 2)
+	merged := Merge(ctx, outs...)
+
+	<-merged
+	cancel()
+	Drain(context.Background(), merged)
+}
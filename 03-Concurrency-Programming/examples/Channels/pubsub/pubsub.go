@@ -0,0 +1,277 @@
+// Package pubsub 在 channel 之上實作一個行程內 (in-process) 的發布/訂閱 (publish/subscribe) broker。
+//
+// Channels 與 Select 範例中的 channel 都是點對點的：一個值只會被一個接收者拿到。
+// Broker 則讓一則訊息依主題 (topic) 分送給所有訂閱者：
+//
+//   - 主題以 "." 分隔成階層，例如 "users.created"、"users.eu.deleted"。
+//   - 訂閱可以使用萬用字元："*" 剛好比對一層 (users.* 比對 users.created)，
+//     ">" 放在最後比對剩下的一層或多層 (users.> 比對 users.created 與 users.eu.deleted)。
+//   - 每個訂閱者有自己的緩衝 channel，緩衝區滿時依 Policy 丟棄新訊息、阻塞發布者或只保留最新的訊息。
+//   - 訂閱者跟不上時會觸發 OnSlowConsumer 回呼，並累計被丟棄的訊息數。
+//   - WithReplay 讓 broker 保留最近的訊息，晚加入的訂閱者可以用 Replay 先收到它們。
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrClosed 表示 Broker 已經關閉。
+	ErrClosed = errors.New("pubsub: broker closed")
+	// ErrInvalidTopic 表示主題或訂閱樣式的格式不正確。
+	ErrInvalidTopic = errors.New("pubsub: invalid topic")
+)
+
+// Message 是一則已發布的訊息。Seq 是 broker 指派的遞增序號，從 1 開始。
+type Message[T any] struct {
+	Topic   string
+	Payload T
+	Seq     uint64
+}
+
+// SlowConsumer 描述一個跟不上發布速度的訂閱者，傳給 OnSlowConsumer 回呼。
+type SlowConsumer struct {
+	Pattern string // 訂閱樣式
+	Topic   string // 無法立即送達的訊息主題
+	Policy  Policy // 訂閱者的緩衝策略，決定這則訊息接下來的命運
+	Dropped uint64 // 到目前為止被丟棄的訊息數
+}
+
+// config 保存 Broker 的設定。
+type config struct {
+	replay int
+	onSlow func(SlowConsumer)
+}
+
+// Option 用來調整 Broker 的行為。
+type Option func(*config)
+
+// WithReplay 讓 Broker 保留最近 n 則訊息 (所有主題合計)，供使用 Replay 訂閱的訂閱者補收。
+func WithReplay(n int) Option {
+	return func(c *config) { c.replay = n }
+}
+
+// OnSlowConsumer 設定訂閱者緩衝區變滿時的回呼。
+// 回呼只在訂閱者「變慢」的那一刻呼叫一次，直到它再次跟上為止；
+// 它在發布者的 Goroutine 中同步執行，因此應該盡快返回。
+func OnSlowConsumer(fn func(SlowConsumer)) Option {
+	return func(c *config) { c.onSlow = fn }
+}
+
+// Broker 依主題把訊息分送給訂閱者，可以安全地被多個 Goroutine 同時使用。
+type Broker[T any] struct {
+	cfg config
+
+	mu       sync.RWMutex
+	root     *node[T]
+	seq      uint64
+	replay   []Message[T] // 環狀緩衝，replayAt 是最舊的位置
+	replayAt int
+	closed   bool
+}
+
+// New 建立一個 Broker。
+func New[T any](opts ...Option) *Broker[T] {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &Broker[T]{cfg: cfg, root: newNode[T]()}
+}
+
+// Publish 把 payload 發布到 topic (不能包含萬用字元)，並回傳成功送達的訂閱者數量。
+// ctx 只用於 Block 策略的訂閱者：ctx 結束時放棄對尚未送達的訂閱者傳送。
+func (b *Broker[T]) Publish(ctx context.Context, topic string, payload T) (int, error) {
+	tokens, err := split(topic, false)
+	if err != nil {
+		return 0, err
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return 0, ErrClosed
+	}
+	b.seq++
+	msg := Message[T]{Topic: topic, Payload: payload, Seq: b.seq}
+	b.retainLocked(msg)
+	var subs []*Subscription[T]
+	b.root.match(tokens, &subs)
+	b.mu.Unlock()
+
+	// 在鎖之外傳送，Block 策略的訂閱者才不會卡住其他發布者與訂閱操作。
+	delivered := 0
+	for _, s := range subs {
+		if s.deliver(ctx, msg) {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func (b *Broker[T]) retainLocked(msg Message[T]) {
+	if b.cfg.replay <= 0 {
+		return
+	}
+	if len(b.replay) < b.cfg.replay {
+		b.replay = append(b.replay, msg)
+		return
+	}
+	b.replay[b.replayAt] = msg
+	b.replayAt = (b.replayAt + 1) % len(b.replay)
+}
+
+// Subscribe 以 pattern 訂閱訊息。取消訂閱後 C() 會被關閉。
+func (b *Broker[T]) Subscribe(pattern string, opts ...SubscribeOption) (*Subscription[T], error) {
+	tokens, err := split(pattern, true)
+	if err != nil {
+		return nil, err
+	}
+	cfg := subscribeConfig{buffer: 16, policy: Drop}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	s := &Subscription[T]{
+		broker:  b,
+		pattern: pattern,
+		tokens:  tokens,
+		policy:  cfg.policy,
+		ch:      make(chan Message[T], max(cfg.buffer, 1)),
+		done:    make(chan struct{}),
+	}
+
+	// 補收與加入訂閱都在同一把寫鎖之內，因此不會漏掉或重複收到同時發布的訊息。
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if cfg.replay > 0 {
+		s.replayLocked(b, cfg.replay)
+	}
+	b.root.add(tokens, s)
+	return s, nil
+}
+
+// Close 關閉 Broker 並取消所有訂閱。之後的 Publish 與 Subscribe 會回傳 ErrClosed。
+func (b *Broker[T]) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	var subs []*Subscription[T]
+	b.root.all(&subs)
+	b.root = newNode[T]()
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		s.close()
+	}
+}
+
+func (b *Broker[T]) remove(s *Subscription[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.root.remove(s.tokens, s)
+}
+
+// split 驗證並拆解主題；allowWildcards 為 false 時不允許 "*" 與 ">"。
+func split(topic string, allowWildcards bool) ([]string, error) {
+	if topic == "" {
+		return nil, ErrInvalidTopic
+	}
+	tokens := strings.Split(topic, ".")
+	for i, tok := range tokens {
+		switch {
+		case tok == "":
+			return nil, ErrInvalidTopic
+		case (tok == "*" || tok == ">") && !allowWildcards:
+			return nil, ErrInvalidTopic
+		case tok == ">" && i != len(tokens)-1:
+			return nil, ErrInvalidTopic
+		}
+	}
+	return tokens, nil
+}
+
+// node 是主題樹的一個節點。萬用字元 "*" 與 ">" 也以一般的子節點保存。
+type node[T any] struct {
+	children map[string]*node[T]
+	subs     []*Subscription[T]
+}
+
+func newNode[T any]() *node[T] {
+	return &node[T]{children: make(map[string]*node[T])}
+}
+
+func (n *node[T]) add(tokens []string, s *Subscription[T]) {
+	for _, tok := range tokens {
+		child, ok := n.children[tok]
+		if !ok {
+			child = newNode[T]()
+			n.children[tok] = child
+		}
+		n = child
+	}
+	n.subs = append(n.subs, s)
+}
+
+// remove 移除 s，並順便刪除變成空的節點。回傳 n 是否已經變空。
+func (n *node[T]) remove(tokens []string, s *Subscription[T]) bool {
+	if len(tokens) == 0 {
+		for i, sub := range n.subs {
+			if sub == s {
+				n.subs = append(n.subs[:i], n.subs[i+1:]...)
+				break
+			}
+		}
+	} else if child, ok := n.children[tokens[0]]; ok && child.remove(tokens[1:], s) {
+		delete(n.children, tokens[0])
+	}
+	return len(n.subs) == 0 && len(n.children) == 0
+}
+
+// match 收集所有樣式與 tokens 相符的訂閱者。
+func (n *node[T]) match(tokens []string, out *[]*Subscription[T]) {
+	if len(tokens) == 0 {
+		*out = append(*out, n.subs...)
+		return
+	}
+	if child, ok := n.children[tokens[0]]; ok {
+		child.match(tokens[1:], out)
+	}
+	if child, ok := n.children["*"]; ok {
+		child.match(tokens[1:], out)
+	}
+	if child, ok := n.children[">"]; ok {
+		// ">" 至少比對一層，這裡 tokens 一定不是空的。
+		*out = append(*out, child.subs...)
+	}
+}
+
+// all 收集樹中所有的訂閱者。
+func (n *node[T]) all(out *[]*Subscription[T]) {
+	*out = append(*out, n.subs...)
+	for _, child := range n.children {
+		child.all(out)
+	}
+}
+
+// matches 回傳 topic 是否符合訂閱樣式 tokens，用於補收保留的訊息。
+func matches(pattern, topic []string) bool {
+	for i, p := range pattern {
+		if p == ">" {
+			return len(topic) > i
+		}
+		if i >= len(topic) || (p != "*" && p != topic[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(topic)
+}
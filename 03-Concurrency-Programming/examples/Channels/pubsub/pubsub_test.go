@@ -0,0 +1,245 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+// drain 取出 s 緩衝區中目前所有訊息的主題。
+func drain[T any](s *Subscription[T]) []string {
+	var topics []string
+	for {
+		select {
+		case msg, ok := <-s.C():
+			if !ok {
+				return topics
+			}
+			topics = append(topics, msg.Topic)
+		default:
+			return topics
+		}
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWildcardMatching(t *testing.T) {
+	b := New[int]()
+	defer b.Close()
+
+	patterns := []string{"users.created", "users.*", "users.>", "*.created", ">"}
+	subs := make(map[string]*Subscription[int])
+	for _, p := range patterns {
+		s, err := b.Subscribe(p)
+		if err != nil {
+			t.Fatalf("Subscribe(%q) 錯誤 = %v", p, err)
+		}
+		subs[p] = s
+	}
+
+	for _, topic := range []string{"users.created", "users.eu.deleted", "orders.created", "users"} {
+		if _, err := b.Publish(context.Background(), topic, 0); err != nil {
+			t.Fatalf("Publish(%q) 錯誤 = %v", topic, err)
+		}
+	}
+
+	testCases := []struct {
+		pattern string
+		want    []string
+	}{
+		{"users.created", []string{"users.created"}},
+		{"users.*", []string{"users.created"}},
+		{"users.>", []string{"users.created", "users.eu.deleted"}},
+		{"*.created", []string{"users.created", "orders.created"}},
+		{">", []string{"users.created", "users.eu.deleted", "orders.created", "users"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			if got := drain(subs[tc.pattern]); !equal(got, tc.want) {
+				t.Errorf("收到 %v; 預期為 %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInvalidTopics(t *testing.T) {
+	b := New[int]()
+	defer b.Close()
+
+	for _, pattern := range []string{"", "users.", "users..created", "users.>.created"} {
+		if _, err := b.Subscribe(pattern); !errors.Is(err, ErrInvalidTopic) {
+			t.Errorf("Subscribe(%q) 錯誤 = %v; 預期為 ErrInvalidTopic", pattern, err)
+		}
+	}
+	for _, topic := range []string{"users.*", "users.>", ""} {
+		if _, err := b.Publish(context.Background(), topic, 0); !errors.Is(err, ErrInvalidTopic) {
+			t.Errorf("Publish(%q) 錯誤 = %v; 預期為 ErrInvalidTopic", topic, err)
+		}
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      Policy
+		want        []int
+		wantDropped uint64
+	}{
+		{"Drop 保留最舊的", Drop, []int{1, 2}, 3},
+		{"Latest 保留最新的", Latest, []int{4, 5}, 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var slow []SlowConsumer
+			b := New[int](OnSlowConsumer(func(sc SlowConsumer) { slow = append(slow, sc) }))
+			defer b.Close()
+			s, _ := b.Subscribe("ticks", WithBuffer(2), WithPolicy(tc.policy))
+
+			for i := 1; i <= 5; i++ {
+				b.Publish(context.Background(), "ticks", i)
+			}
+
+			var got []int
+			for len(s.C()) > 0 {
+				got = append(got, (<-s.C()).Payload)
+			}
+			if len(got) != len(tc.want) || got[0] != tc.want[0] || got[1] != tc.want[1] {
+				t.Errorf("收到 %v; 預期為 %v", got, tc.want)
+			}
+			if st := s.Stats(); st.Dropped != tc.wantDropped || !st.Slow {
+				t.Errorf("Stats() = %+v; 預期 Dropped=%d 且 Slow=true", st, tc.wantDropped)
+			}
+			// 回呼只在變慢的那一刻呼叫一次。
+			if len(slow) != 1 || slow[0].Pattern != "ticks" || slow[0].Policy != tc.policy {
+				t.Errorf("OnSlowConsumer 呼叫 = %+v; 預期只呼叫一次", slow)
+			}
+		})
+	}
+}
+
+func TestBlockPolicy(t *testing.T) {
+	leakcheck.Check(t)
+	b := New[int]()
+	defer b.Close()
+	s, _ := b.Subscribe("jobs", WithBuffer(1), WithPolicy(Block))
+
+	b.Publish(context.Background(), "jobs", 1)
+
+	// 緩衝區已滿：發布者阻塞到 ctx 逾時。
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if n, _ := b.Publish(ctx, "jobs", 2); n != 0 {
+		t.Errorf("Publish(逾時) 送達 %d 個訂閱者; 預期為 0", n)
+	}
+
+	// 訂閱者讀取後，阻塞中的發布者可以完成。
+	published := make(chan int)
+	go func() {
+		n, _ := b.Publish(context.Background(), "jobs", 3)
+		published <- n
+	}()
+	if msg := <-s.C(); msg.Payload != 1 {
+		t.Errorf("第一則訊息 = %d; 預期為 1", msg.Payload)
+	}
+	if n := <-published; n != 1 {
+		t.Errorf("Publish 送達 %d 個訂閱者; 預期為 1", n)
+	}
+	if msg := <-s.C(); msg.Payload != 3 {
+		t.Errorf("第二則訊息 = %d; 預期為 3", msg.Payload)
+	}
+}
+
+func TestUnsubscribeReleasesBlockedPublisher(t *testing.T) {
+	leakcheck.Check(t)
+	b := New[int]()
+	defer b.Close()
+	s, _ := b.Subscribe("jobs", WithBuffer(1), WithPolicy(Block))
+	b.Publish(context.Background(), "jobs", 1)
+
+	published := make(chan int)
+	go func() {
+		n, _ := b.Publish(context.Background(), "jobs", 2)
+		published <- n
+	}()
+	// 等發布者真的阻塞後再取消訂閱。
+	for !s.Stats().Slow {
+		time.Sleep(time.Millisecond)
+	}
+	s.Unsubscribe()
+
+	if n := <-published; n != 0 {
+		t.Errorf("Publish 送達 %d 個訂閱者; 預期為 0", n)
+	}
+	// C() 已關閉，讀完剩下的訊息後得到零值。
+	drain(s)
+	if _, ok := <-s.C(); ok {
+		t.Error("Unsubscribe 後 C() 應該被關閉")
+	}
+	if n, _ := b.Publish(context.Background(), "jobs", 3); n != 0 {
+		t.Errorf("取消訂閱後 Publish 送達 %d 個訂閱者; 預期為 0", n)
+	}
+	s.Unsubscribe() // 可以重複呼叫
+}
+
+func TestReplay(t *testing.T) {
+	b := New[string](WithReplay(4))
+	defer b.Close()
+
+	for _, topic := range []string{"users.a", "orders.a", "users.b", "users.c", "users.d"} {
+		b.Publish(context.Background(), topic, topic)
+	}
+
+	testCases := []struct {
+		name string
+		opts []SubscribeOption
+		want []string
+	}{
+		// 只保留 4 則，users.a 已經被擠掉。
+		{"補收全部", []SubscribeOption{Replay(10)}, []string{"users.b", "users.c", "users.d"}},
+		{"只補收最近 2 則", []SubscribeOption{Replay(2)}, []string{"users.c", "users.d"}},
+		{"受緩衝區大小限制", []SubscribeOption{Replay(10), WithBuffer(1)}, []string{"users.d"}},
+		{"不補收", nil, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := b.Subscribe("users.*", tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Unsubscribe()
+			if got := drain(s); !equal(got, tc.want) {
+				t.Errorf("補收 %v; 預期為 %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClose(t *testing.T) {
+	b := New[int]()
+	s, _ := b.Subscribe("a.>")
+	b.Close()
+
+	if _, ok := <-s.C(); ok {
+		t.Error("Close 後 C() 應該被關閉")
+	}
+	if _, err := b.Publish(context.Background(), "a.b", 1); !errors.Is(err, ErrClosed) {
+		t.Errorf("Close 後 Publish 錯誤 = %v; 預期為 ErrClosed", err)
+	}
+	if _, err := b.Subscribe("a.>"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Close 後 Subscribe 錯誤 = %v; 預期為 ErrClosed", err)
+	}
+}
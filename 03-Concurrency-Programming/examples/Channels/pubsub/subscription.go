@@ -0,0 +1,212 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Policy 決定訂閱者的緩衝區滿時，新訊息要如何處理。
+type Policy int
+
+const (
+	// Drop 丟棄新訊息，已經在緩衝區中的訊息保持不變。
+	Drop Policy = iota
+	// Block 讓發布者等待緩衝區出現空位 (或 Publish 的 ctx 結束)。
+	// 一個慢的訂閱者會拖慢所有發布到它的主題的發布者。
+	Block
+	// Latest 丟棄緩衝區中最舊的訊息來放入新訊息，訂閱者總是看到最新的狀態。
+	Latest
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Drop:
+		return "Drop"
+	case Block:
+		return "Block"
+	case Latest:
+		return "Latest"
+	default:
+		return fmt.Sprintf("Policy(%d)", int(p))
+	}
+}
+
+type subscribeConfig struct {
+	buffer int
+	policy Policy
+	replay int
+}
+
+// SubscribeOption 用來調整單一訂閱的行為。
+type SubscribeOption func(*subscribeConfig)
+
+// WithBuffer 設定訂閱者緩衝 channel 的大小 (預設 16，最小 1)。
+func WithBuffer(n int) SubscribeOption {
+	return func(c *subscribeConfig) { c.buffer = n }
+}
+
+// WithPolicy 設定緩衝區滿時的處理方式 (預設 Drop)。
+func WithPolicy(p Policy) SubscribeOption {
+	return func(c *subscribeConfig) { c.policy = p }
+}
+
+// Replay 讓訂閱者先收到 Broker 保留的訊息中，最近 n 則符合樣式的訊息。
+// 補收的數量不會超過緩衝區大小。Broker 必須以 WithReplay 建立才會保留訊息。
+func Replay(n int) SubscribeOption {
+	return func(c *subscribeConfig) { c.replay = n }
+}
+
+// Stats 是訂閱者的統計資料。
+type Stats struct {
+	Delivered uint64 // 成功放入緩衝區的訊息數
+	Dropped   uint64 // 因緩衝區已滿而被丟棄的訊息數
+	Pending   int    // 目前在緩衝區中等待讀取的訊息數
+	Slow      bool   // 最近一次傳送時緩衝區是否已滿
+}
+
+// Subscription 是一個訂閱。從 C() 讀取訊息，不再需要時呼叫 Unsubscribe。
+type Subscription[T any] struct {
+	broker  *Broker[T]
+	pattern string
+	tokens  []string
+	policy  Policy
+
+	// mu 保護 ch 的關閉：傳送時持有讀鎖，關閉時持有寫鎖。
+	mu     sync.RWMutex
+	ch     chan Message[T]
+	closed bool
+	done   chan struct{} // 先於 ch 關閉，讓阻塞中的傳送放棄並釋放讀鎖
+	once   sync.Once
+
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+	slow      atomic.Bool
+}
+
+// C 回傳接收訊息的 channel，取消訂閱或 Broker 關閉後會被關閉。
+func (s *Subscription[T]) C() <-chan Message[T] {
+	return s.ch
+}
+
+// Pattern 回傳訂閱樣式。
+func (s *Subscription[T]) Pattern() string {
+	return s.pattern
+}
+
+// Unsubscribe 取消訂閱並關閉 C()。可以重複呼叫。
+func (s *Subscription[T]) Unsubscribe() {
+	s.broker.remove(s)
+	s.close()
+}
+
+// Stats 回傳訂閱者目前的統計資料。
+func (s *Subscription[T]) Stats() Stats {
+	return Stats{
+		Delivered: s.delivered.Load(),
+		Dropped:   s.dropped.Load(),
+		Pending:   len(s.ch),
+		Slow:      s.slow.Load(),
+	}
+}
+
+func (s *Subscription[T]) close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.closed = true
+		close(s.ch)
+	})
+}
+
+// deliver 依 policy 把 msg 送進緩衝區，並回傳是否成功。
+func (s *Subscription[T]) deliver(ctx context.Context, msg Message[T]) bool {
+	if ok, closed := s.trySend(msg); ok || closed {
+		return ok
+	}
+
+	// 緩衝區已滿：第一次變慢時通知 (在鎖之外呼叫，回呼中可以安全地 Unsubscribe)。
+	if !s.slow.Swap(true) {
+		if onSlow := s.broker.cfg.onSlow; onSlow != nil {
+			onSlow(SlowConsumer{Pattern: s.pattern, Topic: msg.Topic, Policy: s.policy, Dropped: s.dropped.Load()})
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false
+	}
+	switch s.policy {
+	case Block:
+		select {
+		case s.ch <- msg:
+			s.delivered.Add(1)
+			return true
+		case <-s.done:
+			return false
+		case <-ctx.Done():
+			s.dropped.Add(1)
+			return false
+		}
+
+	case Latest:
+		for {
+			select {
+			case s.ch <- msg:
+				s.delivered.Add(1)
+				return true
+			default:
+			}
+			// 丟掉最舊的一則再試一次；若訂閱者剛好讀走了，下一輪就能直接放入。
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+
+	default: // Drop
+		s.dropped.Add(1)
+		return false
+	}
+}
+
+// trySend 嘗試不阻塞地傳送 msg。
+func (s *Subscription[T]) trySend(msg Message[T]) (ok, closed bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false, true
+	}
+	select {
+	case s.ch <- msg:
+		s.delivered.Add(1)
+		s.slow.Store(false)
+		return true, false
+	default:
+		return false, false
+	}
+}
+
+// replayLocked 把 b 保留的訊息中符合樣式的最近 n 則放入緩衝區。呼叫者必須持有 b.mu。
+func (s *Subscription[T]) replayLocked(b *Broker[T], n int) {
+	n = min(n, cap(s.ch))
+	var matched []Message[T]
+	for i := range b.replay {
+		msg := b.replay[(b.replayAt+i)%len(b.replay)]
+		if matches(s.tokens, strings.Split(msg.Topic, ".")) {
+			matched = append(matched, msg)
+		}
+	}
+	if len(matched) > n {
+		matched = matched[len(matched)-n:]
+	}
+	for _, msg := range matched {
+		s.ch <- msg
+		s.delivered.Add(1)
+	}
+}
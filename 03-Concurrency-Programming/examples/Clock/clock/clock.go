@@ -0,0 +1,90 @@
+// Package clock 把「時間」抽象成介面，讓依賴時間的程式碼可以在測試中注入假的時鐘。
+//
+// 正式程式使用 Real()，它直接轉呼叫 time 套件；
+// 測試則使用 NewFake()，時間只有在呼叫 Advance 時才會前進，因此可以精準、快速地驗證逾時行為。
+package clock
+
+import "time"
+
+// Clock 是程式碼取得時間與建立計時器的入口，方法與 time 套件中的同名函式一一對應。
+type Clock interface {
+	// Now 回傳目前時間。
+	Now() time.Time
+	// Sleep 暫停目前的 Goroutine 至少 d。
+	Sleep(d time.Duration)
+	// After 回傳一個在 d 之後收到時間的 channel。
+	After(d time.Duration) <-chan time.Time
+	// NewTimer 建立一個在 d 之後觸發的 Timer。
+	NewTimer(d time.Duration) Timer
+	// NewTicker 建立一個每隔 d 觸發一次的 Ticker。
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc 在 d 之後於另一個 Goroutine 執行 f；回傳的 Timer 可用來取消，其 C() 為 nil。
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer 對應 *time.Timer，但把 channel 改成方法以便假時鐘實作。
+type Timer interface {
+	// C 回傳計時器觸發時會收到時間的 channel。
+	C() <-chan time.Time
+	// Stop 停止計時器，若計時器在觸發前被停止則回傳 true。
+	Stop() bool
+	// Reset 讓計時器在 d 之後重新觸發，若計時器原本仍在等待中則回傳 true。
+	Reset(d time.Duration) bool
+}
+
+// Ticker 對應 *time.Ticker。
+type Ticker interface {
+	// C 回傳每次觸發時會收到時間的 channel。
+	C() <-chan time.Time
+	// Stop 停止 Ticker，之後不會再觸發。
+	Stop()
+	// Reset 停止 Ticker 並把週期改為 d。
+	Reset(d time.Duration)
+}
+
+// Real 回傳使用系統時間的 Clock。
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
@@ -0,0 +1,313 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake 是一個手動控制的時鐘。時間只有在呼叫 Advance 或 Set 時才會前進，
+// 到期的計時器、Ticker 與 AfterFunc 會依照到期時間的先後觸發。
+// 為了讓測試結果可預期，AfterFunc 的函式會在呼叫 Advance 的 Goroutine 中同步執行。
+// 與 time 套件相同，d <= 0 的計時器在建立 (或 Reset) 時就立即觸發。
+// Fake 可以安全地被多個 Goroutine 同時使用。
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	changed chan struct{}
+}
+
+// NewFake 建立一個從 start 開始的假時鐘。
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start, changed: make(chan struct{})}
+}
+
+// Now 回傳假時鐘目前的時間。
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Sleep 阻塞直到假時間經過 d。
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.NewTimer(d).C()
+}
+
+// After 回傳一個在假時間經過 d 之後收到時間的 channel。
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer 建立一個在假時間經過 d 之後觸發的 Timer。
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return f.add(&fakeTimer{clock: f, c: make(chan time.Time, 1)}, d)
+}
+
+// NewTicker 建立一個每隔假時間 d 觸發一次的 Ticker。
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return fakeTicker{f.add(&fakeTimer{clock: f, c: make(chan time.Time, 1), period: d}, d)}
+}
+
+// AfterFunc 在假時間經過 d 之後執行 f。
+// d <= 0 時與 time.AfterFunc 相同，立即在新的 Goroutine 中執行 f。
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	return f.add(&fakeTimer{clock: f, fn: fn}, d)
+}
+
+func (f *Fake) add(t *fakeTimer, d time.Duration) *fakeTimer {
+	f.mu.Lock()
+	fire := f.schedule(t, d)
+	f.mu.Unlock()
+	if fire != nil {
+		fire()
+	}
+	return t
+}
+
+// Advance 讓時間前進 d，並依序觸發期間內到期的計時器。
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set 把時間設定為 t (不能往回調)，並依序觸發 t 之前到期的計時器。
+// 每個計時器觸發時，Now 會等於它的到期時間。
+func (f *Fake) Set(t time.Time) {
+	for {
+		f.mu.Lock()
+		if len(f.timers) == 0 || f.timers[0].when.After(t) {
+			if t.After(f.now) {
+				f.now = t
+			}
+			f.mu.Unlock()
+			return
+		}
+		next := f.timers[0]
+		f.timers = f.timers[1:]
+		if next.when.After(f.now) {
+			f.now = next.when
+		}
+		fire := next.fire(f.now)
+		if next.period > 0 {
+			// Ticker 以原本的到期時間為基準排入下一次，不會因為 Advance 的步伐而漂移。
+			f.reschedule(next, next.when.Add(next.period))
+		}
+		f.notify()
+		f.mu.Unlock()
+
+		// 在鎖外執行，讓回呼函式可以再次使用時鐘。
+		fire()
+	}
+}
+
+// AdvanceNext 把時間直接推進到下一個計時器的到期時間並觸發它。
+// 沒有等待中的計時器時回傳 false。
+func (f *Fake) AdvanceNext() bool {
+	f.mu.Lock()
+	if len(f.timers) == 0 {
+		f.mu.Unlock()
+		return false
+	}
+	when := f.timers[0].when
+	f.mu.Unlock()
+	f.Set(when)
+	return true
+}
+
+// settleTime 是 RunUntil 在前進之前，計時器必須保持不變的實際時間。
+// 被喚醒的 Goroutine 通常在這段時間內就會再次呼叫 Sleep 或在 channel 上阻塞。
+const settleTime = 10 * time.Millisecond
+
+// RunUntil 不斷把時間推進到下一個計時器並觸發它，直到 done 被關閉。
+// 適合用來快速跑完一段會反覆 Sleep 的程式：
+//
+//	done := make(chan struct{})
+//	go func() { defer close(done); runWorkers(fake, 3) }()
+//	fake.RunUntil(done)
+//
+// 每次前進之前，RunUntil 會等到計時器連續 settleTime 沒有變化，
+// 讓上一次被喚醒的 Goroutine 有機會註冊新的計時器；
+// 否則時間可能直接跳過這些比較早到期、但稍晚才註冊的計時器。
+// 因此被喚醒後不再使用時鐘、卻需要超過 settleTime 才會阻塞的程式不適合使用 RunUntil，
+// 請改用 BlockUntil 搭配 Advance。
+func (f *Fake) RunUntil(done <-chan struct{}) {
+	for {
+		if f.settle(done) {
+			return
+		}
+		f.mu.Lock()
+		if len(f.timers) == 0 {
+			changed := f.changed
+			f.mu.Unlock()
+			select {
+			case <-done:
+				return
+			case <-changed:
+			}
+			continue
+		}
+		when := f.timers[0].when
+		f.mu.Unlock()
+		f.Set(when)
+	}
+}
+
+// settle 等到計時器連續 settleTime 沒有變化；done 先被關閉時回傳 true。
+func (f *Fake) settle(done <-chan struct{}) bool {
+	idle := time.NewTimer(settleTime)
+	defer idle.Stop()
+	for {
+		f.mu.Lock()
+		changed := f.changed
+		f.mu.Unlock()
+
+		select {
+		case <-done:
+			return true
+		case <-changed:
+			idle.Reset(settleTime)
+		case <-idle.C:
+			return false
+		}
+	}
+}
+
+// Pending 回傳尚未觸發的計時器數量。
+func (f *Fake) Pending() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.timers)
+}
+
+// BlockUntil 阻塞直到至少有 n 個計時器在等待中。
+// 測試中常用它確認被測的 Goroutine 已經開始等待，再呼叫 Advance。
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		if len(f.timers) >= n {
+			f.mu.Unlock()
+			return
+		}
+		changed := f.changed
+		f.mu.Unlock()
+		<-changed
+	}
+}
+
+// schedule 在持有鎖時把 t 排入 d 之後觸發。d <= 0 時與 time.Timer 相同，不等待 Advance 就立即觸發：
+// 回傳要在鎖外執行的動作 (AfterFunc 的函式在新的 Goroutine 中執行，因為呼叫者可能持有它需要的鎖)；
+// 否則回傳 nil。
+func (f *Fake) schedule(t *fakeTimer, d time.Duration) (fire func()) {
+	if d > 0 {
+		f.reschedule(t, f.now.Add(d))
+		return nil
+	}
+	t.active = false
+	if fn := t.fn; fn != nil {
+		return func() { go fn() }
+	}
+	return t.fire(f.now)
+}
+
+// reschedule 在持有鎖時把 t 排入 when 觸發。
+func (f *Fake) reschedule(t *fakeTimer, when time.Time) {
+	t.when = when
+	t.active = true
+	f.timers = append(f.timers, t)
+	// 相同到期時間的計時器保持建立的先後順序。
+	sort.SliceStable(f.timers, func(i, j int) bool {
+		return f.timers[i].when.Before(f.timers[j].when)
+	})
+	f.notify()
+}
+
+// remove 在持有鎖時取消 t，回傳 t 原本是否在等待中。
+func (f *Fake) remove(t *fakeTimer) bool {
+	if !t.active {
+		return false
+	}
+	t.active = false
+	for i, other := range f.timers {
+		if other == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			break
+		}
+	}
+	f.notify()
+	return true
+}
+
+// notify 喚醒所有在 BlockUntil 中等待的 Goroutine。
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// fakeTimer 同時實作 Timer 與 Ticker：period > 0 時為 Ticker，fn 不為 nil 時為 AfterFunc。
+type fakeTimer struct {
+	clock  *Fake
+	c      chan time.Time
+	fn     func()
+	period time.Duration
+	when   time.Time
+	active bool
+}
+
+// fire 在持有鎖時把計時器標記為已觸發，並回傳要在鎖外執行的動作。
+func (t *fakeTimer) fire(now time.Time) func() {
+	t.active = false
+	if t.fn != nil {
+		return t.fn
+	}
+	return func() {
+		// 與 time.Timer 相同：沒有人接收時丟棄這次的值，而不是阻塞。
+		select {
+		case t.c <- now:
+		default:
+		}
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	wasActive := t.clock.remove(t)
+	if t.period > 0 {
+		t.period = d
+	}
+	fire := t.clock.schedule(t, d)
+	t.clock.mu.Unlock()
+	if fire != nil {
+		fire()
+	}
+	return wasActive
+}
+
+// fakeTicker 把 fakeTimer 轉成 Ticker 介面 (Stop 與 Reset 沒有回傳值)。
+type fakeTicker struct {
+	*fakeTimer
+}
+
+func (t fakeTicker) Stop() {
+	t.fakeTimer.Stop()
+}
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	t.fakeTimer.Reset(d)
+}
@@ -0,0 +1,211 @@
+package clock
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFakeTimersFireInOrder(t *testing.T) {
+	start := time.Unix(0, 0)
+	fake := NewFake(start)
+
+	late := fake.NewTimer(3 * time.Second)
+	early := fake.NewTimer(time.Second)
+
+	fake.Advance(2 * time.Second)
+	select {
+	case at := <-early.C():
+		if want := start.Add(time.Second); !at.Equal(want) {
+			t.Errorf("early fired at %v; 預期為 %v", at, want)
+		}
+	default:
+		t.Fatal("early timer should have fired")
+	}
+	select {
+	case <-late.C():
+		t.Fatal("late timer fired too soon")
+	default:
+	}
+
+	fake.Advance(time.Second)
+	if at := <-late.C(); !at.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("late fired at %v; 預期為 %v", at, start.Add(3*time.Second))
+	}
+	if got := fake.Now(); !got.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("Now() = %v; 預期為 %v", got, start.Add(3*time.Second))
+	}
+}
+
+func TestFakeTimerStopAndReset(t *testing.T) {
+	fake := NewFake(time.Unix(0, 0))
+	timer := fake.NewTimer(time.Second)
+
+	if !timer.Stop() {
+		t.Error("Stop() on pending timer should return true")
+	}
+	if timer.Stop() {
+		t.Error("second Stop() should return false")
+	}
+	fake.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("stopped timer should not fire")
+	default:
+	}
+
+	if timer.Reset(time.Second) {
+		t.Error("Reset() on stopped timer should return false")
+	}
+	fake.Advance(time.Second)
+	select {
+	case <-timer.C():
+	default:
+		t.Fatal("reset timer should fire")
+	}
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	fake := NewFake(time.Unix(0, 0))
+	done := make(chan struct{})
+	go func() {
+		timer := fake.NewTimer(time.Minute)
+		<-timer.C()
+		close(done)
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	<-done
+}
+
+func TestFakeTicker(t *testing.T) {
+	start := time.Unix(0, 0)
+	fake := NewFake(start)
+	ticker := fake.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for i := 1; i <= 3; i++ {
+		fake.Advance(time.Second)
+		if at := <-ticker.C(); !at.Equal(start.Add(time.Duration(i) * time.Second)) {
+			t.Errorf("tick %d at %v; 預期為 %v", i, at, start.Add(time.Duration(i)*time.Second))
+		}
+	}
+
+	ticker.Reset(5 * time.Second)
+	fake.Advance(4 * time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker fired before the new period")
+	default:
+	}
+	fake.Advance(time.Second)
+	<-ticker.C()
+}
+
+func TestFakeAfterFuncAndSleep(t *testing.T) {
+	fake := NewFake(time.Unix(0, 0))
+
+	var order []string
+	fake.AfterFunc(2*time.Second, func() { order = append(order, "second") })
+	fake.AfterFunc(time.Second, func() { order = append(order, "first") })
+	canceled := fake.AfterFunc(time.Second, func() { order = append(order, "canceled") })
+	canceled.Stop()
+
+	fake.Advance(3 * time.Second)
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("order = %v; 預期為 [first second]", order)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 5 {
+			fake.Sleep(time.Minute)
+		}
+	}()
+	fake.RunUntil(done)
+	if got := fake.Now(); !got.Equal(time.Unix(0, 0).Add(3*time.Second + 5*time.Minute)) {
+		t.Errorf("Now() = %v; 預期經過 5 分鐘", got)
+	}
+}
+
+func TestFakeRunUntilWaitsForNewTimers(t *testing.T) {
+	start := time.Unix(0, 0)
+	fake := NewFake(start)
+	// 一個很晚才到期的計時器：若 RunUntil 在 Goroutine 註冊下一次 Sleep 之前就前進，時間會直接跳到這裡。
+	fake.NewTimer(time.Hour)
+
+	var woke []time.Duration
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 3 {
+			fake.Sleep(time.Second)
+			woke = append(woke, fake.Now().Sub(start))
+		}
+	}()
+	fake.RunUntil(done)
+
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+	if !slices.Equal(woke, want) {
+		t.Errorf("woke at %v; 預期為 %v", woke, want)
+	}
+	if got := fake.Now().Sub(start); got != 3*time.Second {
+		t.Errorf("elapsed = %v; 預期為 3s", got)
+	}
+	if got := fake.Pending(); got != 1 {
+		t.Errorf("Pending() = %d; 預期為 1 (一小時後的計時器)", got)
+	}
+}
+
+func TestFakeNonPositiveDurationFiresImmediately(t *testing.T) {
+	start := time.Unix(0, 0)
+	fake := NewFake(start)
+
+	// 與 time.NewTimer 相同：不需要 Advance 就已經觸發。
+	for _, d := range []time.Duration{0, -time.Second} {
+		select {
+		case at := <-fake.After(d):
+			if !at.Equal(start) {
+				t.Errorf("After(%v) fired at %v; 預期為 %v", d, at, start)
+			}
+		default:
+			t.Errorf("After(%v) did not fire immediately", d)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fake.Sleep(0)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Sleep(0) blocked")
+	}
+
+	ran := make(chan struct{})
+	if fake.AfterFunc(-time.Second, func() { close(ran) }).Stop() {
+		t.Error("Stop() on a fired AfterFunc should return false")
+	}
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("AfterFunc(-1s) did not run")
+	}
+
+	timer := fake.NewTimer(time.Hour)
+	if !timer.Reset(0) {
+		t.Error("Reset() on pending timer should return true")
+	}
+	select {
+	case <-timer.C():
+	default:
+		t.Error("Reset(0) did not fire immediately")
+	}
+	if fake.Pending() != 0 || !fake.Now().Equal(start) {
+		t.Errorf("Pending() = %d, Now() = %v; 預期為 0, %v", fake.Pending(), fake.Now(), start)
+	}
+}
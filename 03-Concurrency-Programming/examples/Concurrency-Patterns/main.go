@@ -1,24 +1,31 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Concurrency-Patterns/ratelimit"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Concurrency-Patterns/scheduler"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Concurrency-Patterns/workerpool"
 )
 
 // --- Fan-Out, Fan-In Pattern ---
 
 // worker 函式從 jobs channel 接收任務，並將結果發送到 results channel。
-func worker(id int, jobs <-chan int, results chan<- string) {
+// clk 提供時間來源，測試時可以換成假時鐘。
+func worker(clk clock.Clock, id int, jobs <-chan int, results chan<- string) {
 	for j := range jobs {
 		fmt.Printf("Worker %d started job %d\n", id, j)
 		// 模擬耗時的計算
-		time.Sleep(time.Second)
+		clk.Sleep(time.Second)
 		resultStr := fmt.Sprintf("Worker %d finished job %d", id, j)
 		results <- resultStr
 	}
 }
 
-func fanOutFanIn() {
+func fanOutFanIn(clk clock.Clock) {
 	fmt.Println("--- Fan-Out, Fan-In Example ---")
 	numJobs := 10
 	jobs := make(chan int, numJobs)
@@ -27,7 +34,7 @@ func fanOutFanIn() {
 	// Fan-Out: 啟動 3 個 worker goroutines 來並行處理任務
 	numWorkers := 3
 	for w := 1; w <= numWorkers; w++ {
-		go worker(w, jobs, results)
+		go worker(clk, w, jobs, results)
 	}
 
 	// 將任務發送到 jobs channel
@@ -43,14 +50,48 @@ func fanOutFanIn() {
 	fmt.Println("All jobs finished.")
 }
 
+// --- Worker Pool Pattern ---
+
+// workerPool 用 workerpool 套件完成與 fanOutFanIn 相同的工作：
+// 提交任務取得 Future，依提交順序收集結果，最後優雅地關閉並印出統計。
+func workerPool(clk clock.Clock) {
+	fmt.Println("\n--- Worker Pool Example ---")
+	pool := workerpool.New[string](3, workerpool.WithQueueSize(5), workerpool.WithPolicy(workerpool.Block))
+
+	var futures []*workerpool.Future[string]
+	for j := 1; j <= 10; j++ {
+		// 佇列只有 5 個位置，提交速度超過處理速度時 Submit 會阻塞 (背壓)。
+		f, err := pool.Submit(context.Background(), func(ctx context.Context) (string, error) {
+			clk.Sleep(time.Second)
+			return fmt.Sprintf("job %d done", j), nil
+		})
+		if err != nil {
+			fmt.Println("Submit failed:", err)
+			continue
+		}
+		futures = append(futures, f)
+	}
+
+	for _, f := range futures {
+		result, err := f.Get(context.Background())
+		fmt.Println("Result:", result, err)
+	}
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		fmt.Println("Shutdown failed:", err)
+	}
+	m := pool.Metrics()
+	fmt.Printf("Completed=%d Failed=%d AvgLatency=%v\n", m.Completed, m.Failed, m.AvgLatency())
+}
+
 // --- Rate Limiting Pattern ---
 
-func rateLimiting() {
+func rateLimiting(clk clock.Clock) {
 	fmt.Println("\n--- Rate Limiting Example ---")
 
 	// 建立一個 Ticker，它會以固定的時間間隔向其 channel 發送事件
 	// 這裡我們設定為每 500 毫秒一次
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := clk.NewTicker(500 * time.Millisecond)
 	// 確保在函式結束時停止 ticker，以釋放資源
 	defer ticker.Stop()
 
@@ -65,13 +106,66 @@ func rateLimiting() {
 	for req := range requests {
 		// 等待 ticker 觸發。這會阻塞當前 goroutine，
 		// 從而將請求處理的速率限制在每 500 毫秒一次。
-		<-ticker.C
-		fmt.Printf("Processing request %d at %v\n", req, time.Now().Format("15:04:05.000"))
+		<-ticker.C()
+		fmt.Printf("Processing request %d at %v\n", req, clk.Now().Format("15:04:05.000"))
 	}
 	fmt.Println("All requests processed.")
 }
 
+// burstyRateLimiting 與 rateLimiting 有相同的平均速率，但使用令牌桶允許一開始的 3 個請求立即通過。
+func burstyRateLimiting(clk clock.Clock) {
+	fmt.Println("\n--- Bursty Rate Limiting Example ---")
+	limiter := ratelimit.NewTokenBucket(500*time.Millisecond, 3, ratelimit.WithClock(clk))
+
+	start := clk.Now()
+	for req := 1; req <= 5; req++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			fmt.Println("Wait failed:", err)
+			return
+		}
+		fmt.Printf("Processing request %d at +%v\n", req, clk.Now().Sub(start))
+	}
+	fmt.Println("All requests processed.")
+}
+
+// --- Scheduling Pattern ---
+
+// scheduling 用 scheduler 同時管理一個週期性工作與一個延遲執行一次的工作，
+// 取代手動管理多個 Ticker / Timer。
+func scheduling(clk clock.Clock) {
+	fmt.Println("\n--- Scheduling Example ---")
+	s := scheduler.New(scheduler.WithClock(clk))
+	start := clk.Now()
+
+	// 每 500ms 一次的心跳；NoOverlap 讓上一次還沒結束時跳過這一次。
+	s.Add("heartbeat", scheduler.Every(500*time.Millisecond), func(ctx context.Context) error {
+		fmt.Printf("heartbeat at +%v\n", clk.Now().Sub(start))
+		return nil
+	}, scheduler.NoOverlap())
+
+	// 1.2 秒後產生一次報表，完成後結束範例。
+	reported := make(chan struct{})
+	s.Add("report", s.After(1200*time.Millisecond), func(ctx context.Context) error {
+		fmt.Printf("report at +%v\n", clk.Now().Sub(start))
+		close(reported)
+		return nil
+	})
+
+	s.Start()
+	<-reported
+	if err := s.Stop(context.Background()); err != nil {
+		fmt.Println("Stop failed:", err)
+	}
+	for _, job := range s.Jobs() {
+		fmt.Printf("%-9s %-16s runs=%d\n", job.Name, job.Schedule, job.Runs)
+	}
+}
+
 func main() {
-	fanOutFanIn()
-	rateLimiting()
+	clk := clock.Real()
+	fanOutFanIn(clk)
+	workerPool(clk)
+	rateLimiting(clk)
+	burstyRateLimiting(clk)
+	scheduling(clk)
 }
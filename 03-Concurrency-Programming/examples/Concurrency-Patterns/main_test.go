@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+func TestFanOutFanIn(t *testing.T) {
+	leakcheck.Check(t)
+	start := time.Unix(0, 0)
+	fake := clock.NewFake(start)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fanOutFanIn(fake)
+	}()
+
+	// 10 個任務、3 個 worker、每個任務 1 秒：前 3 輪各有 3 個 worker 在 Sleep，最後一輪只剩 1 個。
+	for _, sleeping := range []int{3, 3, 3, 1} {
+		fake.BlockUntil(sleeping)
+		fake.Advance(time.Second)
+	}
+	<-done
+	if elapsed := fake.Now().Sub(start); elapsed != 4*time.Second {
+		t.Errorf("fanOutFanIn took %v of fake time; 預期為 4s", elapsed)
+	}
+}
+
+func TestWorkerPool(t *testing.T) {
+	leakcheck.Check(t)
+	start := time.Unix(0, 0)
+	fake := clock.NewFake(start)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		workerPool(fake)
+	}()
+
+	// 與 fanOutFanIn 相同：3 個 worker 處理 10 個各需 1 秒的任務。
+	for _, sleeping := range []int{3, 3, 3, 1} {
+		fake.BlockUntil(sleeping)
+		fake.Advance(time.Second)
+	}
+	<-done
+	if elapsed := fake.Now().Sub(start); elapsed != 4*time.Second {
+		t.Errorf("workerPool took %v of fake time; 預期為 4s", elapsed)
+	}
+}
+
+func TestRateLimiting(t *testing.T) {
+	leakcheck.Check(t)
+	start := time.Unix(0, 0)
+	fake := clock.NewFake(start)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rateLimiting(fake)
+	}()
+	fake.RunUntil(done)
+
+	// 5 個請求，每個都等待下一次的 Ticker，第 5 個在 2.5 秒時處理。
+	if elapsed := fake.Now().Sub(start); elapsed != 2500*time.Millisecond {
+		t.Errorf("rateLimiting took %v of fake time; 預期為 2.5s", elapsed)
+	}
+}
+
+func TestBurstyRateLimiting(t *testing.T) {
+	leakcheck.Check(t)
+	start := time.Unix(0, 0)
+	fake := clock.NewFake(start)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		burstyRateLimiting(fake)
+	}()
+	fake.RunUntil(done)
+
+	// 前 3 個請求使用累積的令牌立即通過，剩下 2 個各等 500ms。
+	if elapsed := fake.Now().Sub(start); elapsed != time.Second {
+		t.Errorf("burstyRateLimiting took %v of fake time; 預期為 1s", elapsed)
+	}
+}
+
+func TestScheduling(t *testing.T) {
+	leakcheck.Check(t)
+	start := time.Unix(0, 0)
+	fake := clock.NewFake(start)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scheduling(fake)
+	}()
+	fake.RunUntil(done)
+
+	// 報表排在 1.2 秒後，範例收到通知後立即停止排程器。
+	if elapsed := fake.Now().Sub(start); elapsed != 1200*time.Millisecond {
+		t.Errorf("scheduling took %v of fake time; 預期為 1.2s", elapsed)
+	}
+}
@@ -0,0 +1,238 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// mustBePositive 在時間參數 <= 0 時 panic。這是呼叫者的程式錯誤，
+// 不檢查的話會在之後的某次請求中以除以零的方式失敗，很難追查。
+func mustBePositive(fn, name string, d time.Duration) {
+	if d <= 0 {
+		panic(fmt.Sprintf("ratelimit: %s: %s must be positive, got %v", fn, name, d))
+	}
+}
+
+// --- Token Bucket ---
+
+// tokenBucket 每隔 every 補充一個令牌，最多累積 burst 個；每個請求消耗一個令牌。
+// 令牌可以暫時變成負數，代表已經被 Reserve 預約的未來令牌。
+type tokenBucket struct {
+	every  time.Duration
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket 建立一個令牌桶 Limiter：平均每 every 放行一個請求，
+// 閒置時最多累積 burst 個令牌，可以一次放行 burst 個請求。every <= 0 時 panic。
+func NewTokenBucket(every time.Duration, burst int, opts ...Option) *Limiter {
+	mustBePositive("NewTokenBucket", "every", every)
+	return newLimiter(&tokenBucket{every: every, burst: max(burst, 1)}, opts)
+}
+
+// advance 依照經過的時間補充令牌。
+func (b *tokenBucket) advance(now time.Time) {
+	if b.last.IsZero() {
+		b.tokens, b.last = float64(b.burst), now
+		return
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(b.burst), b.tokens+float64(elapsed)/float64(b.every))
+		b.last = now
+	}
+}
+
+// durationFor 回傳補充 tokens 個令牌需要的時間 (無條件進位，避免提早放行)。
+func (b *tokenBucket) durationFor(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens * float64(b.every)))
+}
+
+func (b *tokenBucket) delay(now time.Time) time.Duration {
+	b.advance(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return b.durationFor(1 - b.tokens)
+}
+
+func (b *tokenBucket) reserve(now time.Time, maxWait time.Duration) (time.Time, bool) {
+	d := b.delay(now)
+	if d > maxWait {
+		return time.Time{}, false
+	}
+	b.tokens--
+	return now.Add(d), true
+}
+
+func (b *tokenBucket) status(now time.Time) Status {
+	b.advance(now)
+	return Status{
+		Limit:     b.burst,
+		Remaining: max(0, int(b.tokens)),
+		Reset:     b.durationFor(float64(b.burst) - b.tokens),
+	}
+}
+
+// --- Leaky Bucket ---
+
+// leakyBucket 把請求排進一個以固定間隔漏出的佇列。next 是下一個請求可以被放行的時間，
+// 桶中 (正在等待) 的請求數約為 (next - now) / every。
+type leakyBucket struct {
+	every    time.Duration
+	capacity int
+	next     time.Time
+}
+
+// NewLeakyBucket 建立一個漏桶 Limiter：請求之間至少相隔 every，
+// 桶中最多容納 capacity 個請求 (包含可以立即放行的那一個)，桶滿時拒絕。
+// capacity 為 1 時不排隊，只有在距離上一個請求超過 every 時才放行。every <= 0 時 panic。
+func NewLeakyBucket(every time.Duration, capacity int, opts ...Option) *Limiter {
+	mustBePositive("NewLeakyBucket", "every", every)
+	return newLimiter(&leakyBucket{every: every, capacity: max(capacity, 1)}, opts)
+}
+
+// wait 回傳在 now 到達的請求需要排隊多久。
+func (b *leakyBucket) wait(now time.Time) time.Duration {
+	return max(0, b.next.Sub(now))
+}
+
+// maxQueue 是請求最多可以排隊等待的時間，超過代表桶已滿。
+func (b *leakyBucket) maxQueue() time.Duration {
+	return time.Duration(b.capacity-1) * b.every
+}
+
+func (b *leakyBucket) delay(now time.Time) time.Duration {
+	w := b.wait(now)
+	if over := w - b.maxQueue(); over > 0 {
+		return over
+	}
+	return w
+}
+
+func (b *leakyBucket) reserve(now time.Time, maxWait time.Duration) (time.Time, bool) {
+	w := b.wait(now)
+	if w > b.maxQueue() || w > maxWait {
+		return time.Time{}, false
+	}
+	at := now.Add(w)
+	b.next = at.Add(b.every)
+	return at, true
+}
+
+func (b *leakyBucket) status(now time.Time) Status {
+	w := b.wait(now)
+	queued := int((w + b.every - 1) / b.every)
+	return Status{Limit: b.capacity, Remaining: max(0, b.capacity-queued), Reset: w}
+}
+
+// --- Fixed Window ---
+
+// fixedWindow 把時間切成長度為 window 的視窗，每個視窗最多放行 limit 個請求。
+// count 可以超過 limit，超出的部分代表已經被預約到之後視窗的請求。
+type fixedWindow struct {
+	limit  int
+	window time.Duration
+	start  time.Time
+	count  int
+}
+
+// NewFixedWindow 建立一個固定視窗 Limiter：每個長度為 window 的視窗最多放行 limit 個請求。
+// 視窗與時鐘對齊 (例如 window 為一分鐘時，從每分鐘的第 0 秒開始)。window <= 0 時 panic。
+func NewFixedWindow(limit int, window time.Duration, opts ...Option) *Limiter {
+	mustBePositive("NewFixedWindow", "window", window)
+	return newLimiter(&fixedWindow{limit: max(limit, 1), window: window}, opts)
+}
+
+// roll 把視窗推進到包含 now 的那一個，並扣掉已經過去的視窗所用掉的配額。
+func (w *fixedWindow) roll(now time.Time) {
+	if w.start.IsZero() {
+		w.start = now.Truncate(w.window)
+		return
+	}
+	if elapsed := now.Sub(w.start); elapsed >= w.window {
+		k := elapsed / w.window
+		w.start = w.start.Add(k * w.window)
+		w.count = max(0, w.count-int(k)*w.limit)
+	}
+}
+
+func (w *fixedWindow) delay(now time.Time) time.Duration {
+	w.roll(now)
+	at := w.start.Add(time.Duration(w.count/w.limit) * w.window)
+	return max(0, at.Sub(now))
+}
+
+func (w *fixedWindow) reserve(now time.Time, maxWait time.Duration) (time.Time, bool) {
+	d := w.delay(now)
+	if d > maxWait {
+		return time.Time{}, false
+	}
+	w.count++
+	return now.Add(d), true
+}
+
+func (w *fixedWindow) status(now time.Time) Status {
+	w.roll(now)
+	return Status{
+		Limit:     w.limit,
+		Remaining: max(0, w.limit-w.count),
+		Reset:     w.start.Add(w.window).Sub(now),
+	}
+}
+
+// --- Sliding Window Log ---
+
+// slidingLog 記錄每個被放行 (或預約) 的請求時間，
+// 任何長度為 window 的區間內最多只有 limit 筆記錄。
+type slidingLog struct {
+	limit  int
+	window time.Duration
+	log    []time.Time // 依時間排序，可能包含已預約的未來時間
+}
+
+// NewSlidingLog 建立一個滑動視窗記錄 Limiter：任何長度為 window 的區間內最多放行 limit 個請求。
+// 它比固定視窗精確，但需要為每個請求保留一筆時間記錄。window <= 0 時 panic。
+func NewSlidingLog(limit int, window time.Duration, opts ...Option) *Limiter {
+	mustBePositive("NewSlidingLog", "window", window)
+	return newLimiter(&slidingLog{limit: max(limit, 1), window: window}, opts)
+}
+
+// prune 移除已經滑出視窗的記錄。
+func (s *slidingLog) prune(now time.Time) {
+	cutoff := now.Add(-s.window)
+	i := 0
+	for i < len(s.log) && !s.log[i].After(cutoff) {
+		i++
+	}
+	s.log = s.log[i:]
+}
+
+func (s *slidingLog) delay(now time.Time) time.Duration {
+	s.prune(now)
+	if len(s.log) < s.limit {
+		return 0
+	}
+	// 必須等到倒數第 limit 筆記錄滑出視窗，新的請求才不會讓區間內超過 limit 筆。
+	return s.log[len(s.log)-s.limit].Add(s.window).Sub(now)
+}
+
+func (s *slidingLog) reserve(now time.Time, maxWait time.Duration) (time.Time, bool) {
+	d := s.delay(now)
+	if d > maxWait {
+		return time.Time{}, false
+	}
+	at := now.Add(d)
+	s.log = append(s.log, at)
+	return at, true
+}
+
+func (s *slidingLog) status(now time.Time) Status {
+	s.prune(now)
+	st := Status{Limit: s.limit, Remaining: max(0, s.limit-len(s.log))}
+	if n := len(s.log); n > 0 {
+		st.Reset = s.log[n-1].Add(s.window).Sub(now)
+	}
+	return st
+}
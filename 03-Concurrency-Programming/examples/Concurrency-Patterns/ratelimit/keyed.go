@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+)
+
+// keyedEntry 是 Keyed 中一個 key 的 Limiter 與最後使用時間。
+type keyedEntry struct {
+	limiter  *Limiter
+	lastUsed time.Time
+}
+
+// Keyed 為每個 key 維護各自的 Limiter，例如「每個使用者每秒 10 個請求」。
+// 超過 idle 沒有使用的 key 會被清除，避免 map 無限制地成長；
+// 清除是在 Get 時順便進行的，因此不需要額外的背景 Goroutine。
+type Keyed[K comparable] struct {
+	newLimiter func() *Limiter
+	idle       time.Duration
+	clock      clock.Clock
+
+	mu        sync.Mutex
+	entries   map[K]*keyedEntry
+	lastSweep time.Time
+}
+
+// NewKeyed 建立一個 Keyed；newLimiter 在第一次看到某個 key 時被呼叫。
+// idle <= 0 代表永不清除。opts 中的時鐘用來計算閒置時間，
+// newLimiter 建立的 Limiter 應該使用相同的時鐘。
+func NewKeyed[K comparable](newLimiter func() *Limiter, idle time.Duration, opts ...Option) *Keyed[K] {
+	return &Keyed[K]{
+		newLimiter: newLimiter,
+		idle:       idle,
+		clock:      newOptions(opts).clock,
+		entries:    make(map[K]*keyedEntry),
+	}
+}
+
+// Get 回傳 key 的 Limiter，不存在時建立一個新的。
+func (k *Keyed[K]) Get(key K) *Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.clock.Now()
+	k.sweepLocked(now)
+
+	e, ok := k.entries[key]
+	if !ok {
+		e = &keyedEntry{limiter: k.newLimiter()}
+		k.entries[key] = e
+	}
+	e.lastUsed = now
+	return e.limiter
+}
+
+// Allow 是 Get(key).Allow() 的簡寫。
+func (k *Keyed[K]) Allow(key K) bool {
+	return k.Get(key).Allow()
+}
+
+// Len 回傳目前保存的 key 數量。
+func (k *Keyed[K]) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.entries)
+}
+
+// Sweep 立即清除所有閒置超過 idle 的 key，並回傳清除的數量。
+func (k *Keyed[K]) Sweep() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.clock.Now()
+	k.lastSweep = time.Time{}
+	return k.sweepLocked(now)
+}
+
+// sweepLocked 最多每 idle 掃描一次整個 map，讓 Get 的平均成本維持在 O(1)。
+func (k *Keyed[K]) sweepLocked(now time.Time) int {
+	if k.idle <= 0 || now.Sub(k.lastSweep) < k.idle {
+		return 0
+	}
+	k.lastSweep = now
+	n := 0
+	for key, e := range k.entries {
+		if now.Sub(e.lastUsed) >= k.idle {
+			delete(k.entries, key)
+			n++
+		}
+	}
+	return n
+}
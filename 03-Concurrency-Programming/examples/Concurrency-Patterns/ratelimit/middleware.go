@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// KeyFunc 從請求中取出限流用的 key，例如使用者 ID 或用戶端 IP。
+type KeyFunc func(r *http.Request) string
+
+// ByIP 以 r.RemoteAddr 中的 IP 作為 key。
+// 位於反向代理之後時，應該改用可信任的 X-Forwarded-For 等標頭。
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Middleware 回傳一個 net/http 中介軟體，依 key 對請求限流。
+//
+// 每個回應都會帶有 RateLimit-Limit、RateLimit-Remaining 與 RateLimit-Reset 標頭
+// (秒數，依 IETF draft-ietf-httpapi-ratelimit-headers)；
+// 超過限制的請求會得到 429 Too Many Requests 與 Retry-After 標頭，不會交給 next 處理。
+func Middleware(limiters *Keyed[string], key KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, st, retryAfter := limiters.Get(key(r)).allow()
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(st.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(st.Remaining))
+			h.Set("RateLimit-Reset", seconds(st.Reset))
+			if !ok {
+				h.Set("Retry-After", seconds(retryAfter))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// seconds 把 d 無條件進位成整數秒，讓用戶端不會太早重試。
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
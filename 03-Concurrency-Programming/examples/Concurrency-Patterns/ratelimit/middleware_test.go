@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+)
+
+func TestMiddleware(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	keyed := NewKeyed[string](func() *Limiter {
+		return NewTokenBucket(2*time.Second, 2, WithClock(fake))
+	}, time.Minute, WithClock(fake))
+
+	handler := Middleware(keyed, ByIP)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	testCases := []struct {
+		name          string
+		remoteAddr    string
+		wantStatus    int
+		wantRemaining string
+		wantRetry     string
+	}{
+		{"第一個請求", "10.0.0.1:1234", http.StatusOK, "1", ""},
+		{"同一個 IP 不同埠", "10.0.0.1:5678", http.StatusOK, "0", ""},
+		{"超過限制", "10.0.0.1:1234", http.StatusTooManyRequests, "0", "2"},
+		{"其他 IP 不受影響", "10.0.0.2:1234", http.StatusOK, "1", ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := request(tc.remoteAddr)
+			if rec.Code != tc.wantStatus {
+				t.Errorf("狀態碼 = %d; 預期為 %d", rec.Code, tc.wantStatus)
+			}
+			if got := rec.Header().Get("RateLimit-Limit"); got != "2" {
+				t.Errorf("RateLimit-Limit = %q; 預期為 \"2\"", got)
+			}
+			if got := rec.Header().Get("RateLimit-Remaining"); got != tc.wantRemaining {
+				t.Errorf("RateLimit-Remaining = %q; 預期為 %q", got, tc.wantRemaining)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tc.wantRetry {
+				t.Errorf("Retry-After = %q; 預期為 %q", got, tc.wantRetry)
+			}
+		})
+	}
+
+	// 兩秒後補充一個令牌，請求再次被放行。
+	fake.Advance(2 * time.Second)
+	if rec := request("10.0.0.1:1234"); rec.Code != http.StatusOK {
+		t.Errorf("補充令牌後狀態碼 = %d; 預期為 200", rec.Code)
+	}
+}
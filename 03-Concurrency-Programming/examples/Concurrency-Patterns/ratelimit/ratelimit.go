@@ -0,0 +1,154 @@
+// Package ratelimit 把 rateLimiting 範例中「每 500ms 一個 tick」的做法，
+// 擴充成幾種常見的限流演算法，並提供相同的 Allow / Reserve / Wait 介面：
+//
+//   - NewTokenBucket：令牌桶，平均速率固定，但允許累積的令牌造成短暫的突發 (burst)。
+//   - NewLeakyBucket：漏桶 (作為佇列)，請求以固定間隔放行，完全沒有突發，佇列滿時拒絕。
+//   - NewFixedWindow：固定視窗計數，實作最簡單，但在視窗交界處可能放行兩倍的請求。
+//   - NewSlidingLog：滑動視窗記錄，記下每個請求的時間，任何長度為 window 的區間都不會超過上限。
+//
+// 另外 Keyed 為每個 key (例如使用者或 IP) 維護各自的 Limiter 並清除閒置的項目，
+// Middleware 則把它包裝成 net/http 的中介軟體。
+// 時間來源可以透過 WithClock 換成假時鐘以便測試。
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+)
+
+// ErrLimited 表示請求無法在期限內被放行 (例如 ctx 的截止時間太早，或漏桶的佇列已滿)。
+var ErrLimited = errors.New("ratelimit: request cannot be admitted in time")
+
+// Option 用來調整時間來源等設定。
+type Option func(*options)
+
+type options struct {
+	clock clock.Clock
+}
+
+// WithClock 指定 Limiter (或 Keyed) 使用的時鐘，預設為 clock.Real()。
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{clock: clock.Real()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// algorithm 是各種限流演算法共同的核心，所有方法都在 Limiter 的鎖之內呼叫。
+type algorithm interface {
+	// reserve 為一個在 now 到達的請求安排放行時間。
+	// 若需要等待超過 maxWait (或永遠無法放行) 則回傳 false，且不改變任何狀態。
+	reserve(now time.Time, maxWait time.Duration) (at time.Time, ok bool)
+	// delay 回傳在 now 到達的請求最少要等多久才能被放行 (不改變狀態)。
+	delay(now time.Time) time.Duration
+	// status 回傳 now 時的配額狀態。
+	status(now time.Time) Status
+}
+
+// Status 描述 Limiter 在某個瞬間的配額，對應 RateLimit-* 標頭。
+type Status struct {
+	Limit     int           // 配額上限
+	Remaining int           // 目前還能立即放行的請求數
+	Reset     time.Duration // 配額完全恢復 (或下一個視窗開始) 前的時間
+}
+
+// Reservation 是 Reserve 的結果。OK 為 false 代表請求永遠不會被放行 (例如佇列已滿)；
+// 否則呼叫者應該等待 Delay 之後再執行請求。
+type Reservation struct {
+	OK    bool
+	Delay time.Duration
+}
+
+// Limiter 以某種演算法限制請求速率，可以安全地被多個 Goroutine 同時使用。
+type Limiter struct {
+	clock clock.Clock
+
+	mu   sync.Mutex
+	algo algorithm
+}
+
+func newLimiter(algo algorithm, opts []Option) *Limiter {
+	return &Limiter{clock: newOptions(opts).clock, algo: algo}
+}
+
+// Allow 回傳現在是否可以立即放行一個請求；放行時會消耗一份配額。
+func (l *Limiter) Allow() bool {
+	_, ok := l.reserve(0)
+	return ok
+}
+
+// Reserve 預約一份配額並回傳需要等待的時間。預約無法取消。
+func (l *Limiter) Reserve() Reservation {
+	delay, ok := l.reserve(math.MaxInt64)
+	return Reservation{OK: ok, Delay: delay}
+}
+
+// Wait 阻塞直到可以放行一個請求，或 ctx 結束。
+// 若 ctx 的截止時間早於可放行的時間，Wait 會立即回傳 ErrLimited 而不消耗配額。
+func (l *Limiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	maxWait := time.Duration(math.MaxInt64)
+	if deadline, ok := ctx.Deadline(); ok {
+		// 截止時間是實際時間，因此以 time.Until 計算；不能與 Limiter 的時鐘 (可能是假時鐘) 相減。
+		maxWait = time.Until(deadline)
+	}
+	delay, ok := l.reserve(maxWait)
+	if !ok {
+		return ErrLimited
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	t := l.clock.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status 回傳目前的配額狀態。
+func (l *Limiter) Status() Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.algo.status(l.clock.Now())
+}
+
+// allow 與 Allow 相同，但同時回傳決策後的狀態，以及被拒絕時建議的重試等待時間，
+// 供 Middleware 設定標頭。
+func (l *Limiter) allow() (ok bool, st Status, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	_, ok = l.algo.reserve(now, 0)
+	if !ok {
+		retryAfter = l.algo.delay(now)
+	}
+	return ok, l.algo.status(now), retryAfter
+}
+
+func (l *Limiter) reserve(maxWait time.Duration) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	at, ok := l.algo.reserve(now, maxWait)
+	if !ok {
+		return 0, false
+	}
+	return at.Sub(now), true
+}
@@ -0,0 +1,279 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+// step 是在某個假時間點呼叫一次 Allow 的預期結果。
+type step struct {
+	at   time.Duration // 相對於起始時間
+	want bool
+}
+
+func TestAllow(t *testing.T) {
+	testCases := []struct {
+		name  string
+		new   func(clk clock.Clock) *Limiter
+		steps []step
+	}{
+		{
+			name: "TokenBucket 允許突發後以固定速率補充",
+			new: func(clk clock.Clock) *Limiter {
+				return NewTokenBucket(100*time.Millisecond, 3, WithClock(clk))
+			},
+			steps: []step{
+				{0, true}, {0, true}, {0, true}, {0, false}, // 突發 3 個
+				{50 * time.Millisecond, false},
+				{100 * time.Millisecond, true}, // 補充 1 個
+				{100 * time.Millisecond, false},
+				{time.Second, true}, {time.Second, true}, {time.Second, true}, {time.Second, false}, // 最多累積 3 個
+			},
+		},
+		{
+			name: "LeakyBucket 沒有突發",
+			new: func(clk clock.Clock) *Limiter {
+				return NewLeakyBucket(100*time.Millisecond, 3, WithClock(clk))
+			},
+			steps: []step{
+				{0, true}, {0, false},
+				{99 * time.Millisecond, false},
+				{100 * time.Millisecond, true},
+				{time.Second, true}, {time.Second, false},
+			},
+		},
+		{
+			name: "FixedWindow 在視窗交界處重置",
+			new: func(clk clock.Clock) *Limiter {
+				return NewFixedWindow(2, time.Second, WithClock(clk))
+			},
+			steps: []step{
+				{900 * time.Millisecond, true}, {900 * time.Millisecond, true}, {900 * time.Millisecond, false},
+				// 新視窗開始，0.2 秒內放行了 4 個請求：固定視窗的已知缺點。
+				{1100 * time.Millisecond, true}, {1100 * time.Millisecond, true}, {1100 * time.Millisecond, false},
+			},
+		},
+		{
+			name: "SlidingLog 任何區間都不超過上限",
+			new: func(clk clock.Clock) *Limiter {
+				return NewSlidingLog(2, time.Second, WithClock(clk))
+			},
+			steps: []step{
+				{900 * time.Millisecond, true}, {900 * time.Millisecond, true},
+				{1100 * time.Millisecond, false}, // 與固定視窗不同，仍在同一個滑動區間內
+				{1900 * time.Millisecond, true},  // 第一筆記錄滑出視窗
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start := time.Unix(0, 0)
+			fake := clock.NewFake(start)
+			l := tc.new(fake)
+			for i, s := range tc.steps {
+				fake.Set(start.Add(s.at))
+				if got := l.Allow(); got != s.want {
+					t.Errorf("步驟 %d (t=%v): Allow() = %v; 預期為 %v", i, s.at, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReserve(t *testing.T) {
+	testCases := []struct {
+		name   string
+		new    func(clk clock.Clock) *Limiter
+		delays []time.Duration // 連續 Reserve 的預期等待時間，-1 代表 OK 為 false
+	}{
+		{
+			name: "TokenBucket",
+			new: func(clk clock.Clock) *Limiter {
+				return NewTokenBucket(100*time.Millisecond, 2, WithClock(clk))
+			},
+			delays: []time.Duration{0, 0, 100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			name: "LeakyBucket 佇列滿時拒絕",
+			new: func(clk clock.Clock) *Limiter {
+				return NewLeakyBucket(100*time.Millisecond, 3, WithClock(clk))
+			},
+			delays: []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond, -1},
+		},
+		{
+			name: "FixedWindow 預約到之後的視窗",
+			new: func(clk clock.Clock) *Limiter {
+				return NewFixedWindow(2, time.Second, WithClock(clk))
+			},
+			delays: []time.Duration{0, 0, time.Second, time.Second, 2 * time.Second},
+		},
+		{
+			name: "SlidingLog",
+			new: func(clk clock.Clock) *Limiter {
+				return NewSlidingLog(2, time.Second, WithClock(clk))
+			},
+			delays: []time.Duration{0, 0, time.Second, time.Second, 2 * time.Second},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := tc.new(clock.NewFake(time.Unix(0, 0)))
+			for i, want := range tc.delays {
+				r := l.Reserve()
+				if want < 0 {
+					if r.OK {
+						t.Errorf("第 %d 次 Reserve() = %+v; 預期 OK 為 false", i, r)
+					}
+					continue
+				}
+				if !r.OK || r.Delay != want {
+					t.Errorf("第 %d 次 Reserve() = %+v; 預期為 {OK:true Delay:%v}", i, r, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWait(t *testing.T) {
+	leakcheck.Check(t)
+	start := time.Unix(0, 0)
+	fake := clock.NewFake(start)
+	l := NewTokenBucket(500*time.Millisecond, 1, WithClock(fake))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			if err := l.Wait(context.Background()); err != nil {
+				t.Errorf("Wait() 錯誤 = %v", err)
+			}
+		}
+	}()
+	fake.RunUntil(done)
+
+	// 第一個請求立即放行，之後每 500ms 一個。
+	if elapsed := fake.Now().Sub(start); elapsed != 2*time.Second {
+		t.Errorf("5 次 Wait 花了 %v 的假時間; 預期為 2s", elapsed)
+	}
+}
+
+func TestWaitRespectsDeadline(t *testing.T) {
+	leakcheck.Check(t)
+	// ctx 的截止時間是實際時間，與假時鐘無關：不論假時鐘落後還是領先實際時間，
+	// 剩下的等待時間都必須以實際時間計算。
+	starts := map[string]time.Time{
+		"假時鐘落後": time.Unix(0, 0),
+		"假時鐘領先": time.Now().AddDate(100, 0, 0),
+	}
+	for name, start := range starts {
+		fake := clock.NewFake(start)
+		l := NewTokenBucket(2*time.Second, 1, WithClock(fake))
+		l.Allow()
+		fake.Advance(time.Second)
+
+		// 下一個令牌在 (假時間) 1 秒後才有，截止時間在 500ms 後，不可能放行，因此立即失敗且不消耗配額。
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		errc := make(chan error, 1)
+		go func() { errc <- l.Wait(ctx) }()
+		select {
+		case err := <-errc:
+			if !errors.Is(err, ErrLimited) {
+				t.Errorf("%s: Wait() 錯誤 = %v; 預期為 ErrLimited", name, err)
+			}
+		case <-time.After(5 * time.Second):
+			cancel()
+			t.Errorf("%s: Wait() 沒有立即回傳 (錯誤 = %v); 預期為 ErrLimited", name, <-errc)
+		}
+		cancel()
+		if r := l.Reserve(); r.Delay != time.Second {
+			t.Errorf("%s: Reserve().Delay = %v; 預期為 1s，失敗的 Wait 不應該消耗配額", name, r.Delay)
+		}
+
+		// 截止時間還有一小時：Wait 等待假時鐘的計時器，而不是立即失敗。
+		ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+		go func() { errc <- l.Wait(ctx) }()
+		waiting := make(chan struct{})
+		go func() {
+			fake.BlockUntil(1)
+			close(waiting)
+		}()
+		select {
+		case <-waiting:
+			// 上面的 Reserve 已經預約了下一個令牌，這次要再等一個週期。
+			fake.Advance(3 * time.Second)
+			select {
+			case err := <-errc:
+				if err != nil {
+					t.Errorf("%s: Wait() 錯誤 = %v; 預期為 nil", name, err)
+				}
+			case <-time.After(5 * time.Second):
+				cancel()
+				t.Errorf("%s: Wait() 在令牌到期後沒有回傳 (錯誤 = %v)", name, <-errc)
+			}
+		case err := <-errc:
+			t.Errorf("%s: Wait() 沒有等待就回傳 %v; 預期等到下一個令牌", name, err)
+		}
+		cancel()
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	l := NewTokenBucket(time.Second, 1)
+	if err := l.Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait(已取消) 錯誤 = %v; 預期為 context.Canceled", err)
+	}
+}
+
+func TestNonPositiveDurationPanics(t *testing.T) {
+	constructors := map[string]func(d time.Duration){
+		"NewTokenBucket": func(d time.Duration) { NewTokenBucket(d, 1) },
+		"NewLeakyBucket": func(d time.Duration) { NewLeakyBucket(d, 1) },
+		"NewFixedWindow": func(d time.Duration) { NewFixedWindow(1, d) },
+		"NewSlidingLog":  func(d time.Duration) { NewSlidingLog(1, d) },
+	}
+	for name, newLimiter := range constructors {
+		for _, d := range []time.Duration{0, -time.Second} {
+			func() {
+				defer func() {
+					msg, _ := recover().(string)
+					if !strings.Contains(msg, name) || !strings.Contains(msg, "must be positive") {
+						t.Errorf("%s(%v) panic = %q; 預期為說明參數必須為正數的 panic", name, d, msg)
+					}
+				}()
+				newLimiter(d)
+			}()
+		}
+	}
+}
+
+func TestKeyedEvictsIdleKeys(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	keyed := NewKeyed[string](func() *Limiter {
+		return NewFixedWindow(1, time.Minute, WithClock(fake))
+	}, 10*time.Second, WithClock(fake))
+
+	if !keyed.Allow("alice") || keyed.Allow("alice") {
+		t.Error("alice: 預期第一次放行、第二次拒絕")
+	}
+	if !keyed.Allow("bob") {
+		t.Error("bob: 不同的 key 應該有各自的配額")
+	}
+
+	fake.Advance(5 * time.Second)
+	keyed.Get("bob") // bob 仍然活躍
+	fake.Advance(6 * time.Second)
+	if n := keyed.Sweep(); n != 1 || keyed.Len() != 1 {
+		t.Errorf("Sweep() = %d, Len() = %d; 預期清除閒置的 alice，留下 bob", n, keyed.Len())
+	}
+
+	// alice 被清除後重新建立，配額也重新開始。
+	if !keyed.Allow("alice") {
+		t.Error("alice 被清除後應該得到新的 Limiter")
+	}
+}
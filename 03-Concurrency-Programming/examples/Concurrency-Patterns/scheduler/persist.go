@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// jobState 是一個工作在 JSON 檔中的樣子。函式無法序列化，載入時依 Name 重新對應。
+type jobState struct {
+	Name      string    `json:"name"`
+	Schedule  string    `json:"schedule"`
+	NoOverlap bool      `json:"no_overlap,omitempty"`
+	Next      time.Time `json:"next,omitzero"`
+	Runs      uint64    `json:"runs,omitempty"`
+	LastRun   time.Time `json:"last_run,omitzero"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// SaveFile 把所有工作的排程與上次執行結果寫入 path (JSON)。
+// 先寫入暫存檔再改名，因此程式中途結束也不會留下寫到一半的檔案。
+func (s *Scheduler) SaveFile(path string) error {
+	var states []jobState
+	for _, info := range s.Jobs() {
+		st := jobState{
+			Name:      info.Name,
+			Schedule:  info.Schedule,
+			NoOverlap: info.NoOverlap,
+			Next:      info.Next,
+			Runs:      info.Runs,
+			LastRun:   info.LastRun,
+		}
+		if info.LastErr != nil {
+			st.LastError = info.LastErr.Error()
+		}
+		states = append(states, st)
+	}
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // 改名成功後這裡只會得到「檔案不存在」
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadFile 從 path 讀回 SaveFile 保存的工作，並以 funcs 中同名的函式重新加入。
+//
+// 保存的下次執行時間會被沿用，因此 At 排程不會因為重新啟動而重算；
+// 若該時間在程式停止期間已經過去，工作會在 Start 後立即執行一次。
+// 沒有下次執行時間的工作 (例如手寫的檔案省略了 next) 從現在起依排程重新計算，
+// 已經執行過的一次性工作則維持結束的狀態。
+// 找不到對應函式或無法解析的工作會被略過，並合併成一個錯誤回傳。
+func (s *Scheduler) LoadFile(path string, funcs map[string]JobFunc) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var states []jobState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return fmt.Errorf("scheduler: decode %s: %w", path, err)
+	}
+
+	var errs []error
+	for _, st := range states {
+		fn, ok := funcs[st.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("scheduler: no function for job %q", st.Name))
+			continue
+		}
+		schedule, err := ParseSchedule(st.Schedule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("job %q: %w", st.Name, err))
+			continue
+		}
+		next := st.Next
+		if next.IsZero() {
+			// 零值代表「不會再執行」；否則重複的排程會永遠被 runDue 略過。
+			next = schedule.Next(s.clock.Now())
+		}
+		j := &job{
+			name:      st.Name,
+			schedule:  schedule,
+			fn:        fn,
+			noOverlap: st.NoOverlap,
+			next:      next,
+			runs:      st.Runs,
+			lastRun:   st.LastRun,
+		}
+		if st.LastError != "" {
+			j.lastErr = errors.New(st.LastError)
+		}
+		if err := s.add(j); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
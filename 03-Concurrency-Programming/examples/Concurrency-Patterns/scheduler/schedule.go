@@ -0,0 +1,293 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 決定工作的執行時間。
+type Schedule interface {
+	// Next 回傳嚴格晚於 after 的下一次執行時間；沒有下一次時回傳零值。
+	Next(after time.Time) time.Time
+	// String 回傳可以被 ParseSchedule 解析回來的表示法，用於持久化。
+	String() string
+}
+
+// ParseSchedule 解析 Schedule.String() 產生的表示法：
+//
+//	@at 2025-01-02T15:04:05Z   在指定時間執行一次 (RFC 3339)
+//	@every 1m30s               每隔固定時間執行
+//	*/5 9-17 * * MON-FRI       5 欄位的 cron 表示式 (見 ParseCron)
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	switch {
+	case strings.HasPrefix(spec, "@at "):
+		t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(spec[len("@at "):]))
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: invalid @at time: %w", err)
+		}
+		return At(t), nil
+	case strings.HasPrefix(spec, "@every "):
+		d, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: invalid @every duration: %w", err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("scheduler: @every duration must be positive, got %v", d)
+		}
+		return Every(d), nil
+	default:
+		return ParseCron(spec)
+	}
+}
+
+// --- At ---
+
+type atSchedule time.Time
+
+// At 回傳只在 t 執行一次的 Schedule。
+func At(t time.Time) Schedule {
+	return atSchedule(t)
+}
+
+func (s atSchedule) Next(after time.Time) time.Time {
+	if t := time.Time(s); t.After(after) {
+		return t
+	}
+	return time.Time{}
+}
+
+func (s atSchedule) String() string {
+	return "@at " + time.Time(s).Format(time.RFC3339Nano)
+}
+
+// --- Every ---
+
+type everySchedule time.Duration
+
+// Every 回傳每隔 d 執行一次的 Schedule。d 必須大於 0。
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		panic("scheduler: non-positive interval for Every")
+	}
+	return everySchedule(d)
+}
+
+func (s everySchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+func (s everySchedule) String() string {
+	return "@every " + time.Duration(s).String()
+}
+
+// --- Cron ---
+
+// CronSchedule 是解析後的 5 欄位 cron 表示式。
+type CronSchedule struct {
+	spec                         string
+	minute, hour, dom, month     uint64 // 每個位元代表一個允許的值
+	dow                          uint64
+	domRestricted, dowRestricted bool
+	loc                          *time.Location
+}
+
+// cronField 描述一個欄位的範圍與可用的名稱。
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	// 星期欄位允許 0-7，0 與 7 都代表星期日。
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// descriptors 是常用表示式的別名。
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron 解析標準的 5 欄位 cron 表示式「分 時 日 月 星期」。
+//
+// 每個欄位支援 *、數值、範圍 (1-5)、清單 (1,3,5)、間隔 (*/15、0-30/10)，
+// 月份與星期也可以使用英文縮寫 (JAN、MON)。也支援 @hourly、@daily 等別名。
+// 與傳統 cron 相同，日與星期都有限制時，只要符合其中之一就會執行。
+//
+// 表示式前面可以加上 "CRON_TZ=Asia/Taipei " 指定時區，否則使用 time.Local。
+func ParseCron(spec string) (*CronSchedule, error) {
+	original := spec
+	loc := time.Local
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		tz, rest, _ := strings.Cut(spec, " ")
+		_, name, _ := strings.Cut(tz, "=")
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			return nil, fmt.Errorf("scheduler: invalid time zone in %q: %w", original, err)
+		}
+		spec = strings.TrimSpace(rest)
+	}
+	if expanded, ok := descriptors[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("scheduler: cron expression %q must have 5 fields, got %d", original, len(fields))
+	}
+	c := &CronSchedule{spec: original, loc: loc}
+	var err error
+	if c.minute, _, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if c.hour, _, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if c.dom, c.domRestricted, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if c.month, _, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if c.dow, c.dowRestricted, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 << 0 // 7 也是星期日
+	}
+	return c, nil
+}
+
+// parseField 把一個欄位轉成位元集合；restricted 表示欄位不是單純的 "*"。
+func parseField(expr string, f cronField) (set uint64, restricted bool, err error) {
+	for _, part := range strings.Split(expr, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("scheduler: invalid step %q in %s field", stepStr, f.name)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = f.min, f.max
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			if lo, err = f.value(a); err != nil {
+				return 0, false, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, false, err
+			}
+		default:
+			if lo, err = f.value(rng); err != nil {
+				return 0, false, err
+			}
+			hi = lo
+			if hasStep {
+				hi = f.max // "5/15" 代表從 5 開始每 15 一次
+			}
+		}
+		if lo > hi {
+			return 0, false, fmt.Errorf("scheduler: invalid range %q in %s field", rng, f.name)
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+		if rng != "*" || hasStep {
+			restricted = true
+		}
+	}
+	return set, restricted, nil
+}
+
+// value 解析單一數值或名稱並檢查範圍。
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("scheduler: value %q out of range %d-%d in %s field", s, f.min, f.max, f.name)
+	}
+	return v, nil
+}
+
+// Location 回傳表示式使用的時區。
+func (c *CronSchedule) Location() *time.Location {
+	return c.loc
+}
+
+func (c *CronSchedule) String() string {
+	return c.spec
+}
+
+// Next 回傳嚴格晚於 after 的下一個符合表示式的時間 (以 c 的時區計算)。
+// 五年內找不到符合的時間 (例如 2 月 30 日) 時回傳零值。
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	// 以時區內的日期欄位運算 (而不是 Truncate)，非整點時差的時區才會正確。
+	a := after.In(c.loc)
+	t := time.Date(a.Year(), a.Month(), a.Day(), a.Hour(), a.Minute()+1, 0, 0, c.loc)
+	limit := t.AddDate(5, 0, 0)
+
+	// 由大到小逐欄位檢查，不符合就跳到該欄位的下一個值並從較小的欄位重新開始。
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = forward(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc))
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc))
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc))
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// forward 回傳 next。若 next 落在夏令時間的缺口 (例如 02:30 不存在)，time.Date 會把它
+// 往回正規化成不晚於 t 的時間；此時改為前進到 t 的下一個整點，確保搜尋一定會往前走。
+func forward(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Hour - time.Duration(t.Minute())*time.Minute)
+}
+
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domRestricted && c.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// 2025-01-01 是星期三。
+	from := time.Date(2025, 1, 1, 10, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name string
+		spec string
+		want []time.Time // 從 from 開始連續呼叫 Next 的結果
+	}{
+		{
+			name: "每 15 分鐘",
+			spec: "CRON_TZ=UTC */15 * * * *",
+			want: []time.Time{
+				time.Date(2025, 1, 1, 10, 45, 0, 0, time.UTC),
+				time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "平日上班時間整點",
+			spec: "CRON_TZ=UTC 0 9-17 * * MON-FRI",
+			want: []time.Time{
+				time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "跨過週末",
+			spec: "CRON_TZ=UTC 0 9 * * FRI",
+			want: []time.Time{
+				time.Date(2025, 1, 3, 9, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "日與星期都有限制時符合其一即可",
+			spec: "CRON_TZ=UTC 0 0 15 * SUN",
+			want: []time.Time{
+				time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),  // 星期日
+				time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC), // 星期日
+				time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), // 15 號
+			},
+		},
+		{
+			name: "星期 7 也是星期日",
+			spec: "CRON_TZ=UTC 0 0 * * 7",
+			want: []time.Time{time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "月份名稱與清單",
+			spec: "CRON_TZ=UTC 0 0 1 FEB,jun *",
+			want: []time.Time{
+				time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "2 月 29 日只在閏年",
+			spec: "CRON_TZ=UTC 0 0 29 2 *",
+			want: []time.Time{time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "別名 @daily",
+			spec: "CRON_TZ=UTC @daily",
+			want: []time.Time{time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "台北時間早上 9 點 (UTC+8)",
+			spec: "CRON_TZ=Asia/Taipei 0 9 * * *",
+			want: []time.Time{time.Date(2025, 1, 2, 1, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "不存在的日期",
+			spec: "CRON_TZ=UTC 0 0 30 2 *",
+			want: []time.Time{{}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := ParseCron(tc.spec)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) 錯誤 = %v", tc.spec, err)
+			}
+			at := from
+			for i, want := range tc.want {
+				got := c.Next(at)
+				if !got.Equal(want) {
+					t.Fatalf("第 %d 次 Next = %v; 預期為 %v", i, got, want)
+				}
+				at = got
+			}
+		})
+	}
+}
+
+func TestCronNextAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("系統沒有時區資料:", err)
+	}
+	c, _ := ParseCron("CRON_TZ=America/New_York 30 2 * * *")
+
+	// 2025-03-09 02:30 因夏令時間開始而不存在，下一次應該是隔天的 02:30。
+	got := c.Next(time.Date(2025, 3, 8, 12, 0, 0, 0, loc))
+	if want := time.Date(2025, 3, 10, 2, 30, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("Next = %v; 預期為 %v", got, want)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{
+		"* * * *",     // 欄位數量不對
+		"60 * * * *",  // 超出範圍
+		"* * 0 * *",   // 日從 1 開始
+		"*/0 * * * *", // 間隔為 0
+		"5-1 * * * *", // 範圍顛倒
+		"* * * FOO *", // 未知名稱
+		"CRON_TZ=Mars/Base * * * * *",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) 預期回傳錯誤", spec)
+		}
+	}
+}
+
+func TestParseScheduleRoundTrip(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	cron, _ := ParseCron("CRON_TZ=UTC 0 9 * * MON-FRI")
+	for _, s := range []Schedule{At(at), Every(90 * time.Second), cron} {
+		parsed, err := ParseSchedule(s.String())
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) 錯誤 = %v", s.String(), err)
+			continue
+		}
+		if parsed.String() != s.String() || !parsed.Next(at.Add(-time.Hour)).Equal(s.Next(at.Add(-time.Hour))) {
+			t.Errorf("ParseSchedule(%q) = %q; 預期解析回相同的排程", s.String(), parsed.String())
+		}
+	}
+}
@@ -0,0 +1,338 @@
+// Package scheduler 在 Ticker 之上實作一個工作排程器 (job scheduler)。
+//
+// rateLimiting 範例只用 Ticker 以固定間隔做事；Scheduler 則可以管理多個具名的工作，
+// 每個工作有自己的 Schedule：
+//
+//   - At / After：在指定時間 (或一段延遲後) 執行一次。
+//   - Every：每隔固定時間執行。
+//   - ParseCron：標準 5 欄位 cron 表示式，可以用 CRON_TZ= 指定時區。
+//
+// 工作可以設定 NoOverlap，上一次還沒跑完時跳過這一次；
+// Jobs 回傳每個工作的下次執行時間與上次的結果；SaveFile / LoadFile 把排程保存成 JSON 檔。
+// 所有時間都來自注入的 clock.Clock，測試時可以用假時鐘快轉。
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+)
+
+var (
+	// ErrDuplicateJob 表示已經有同名的工作。
+	ErrDuplicateJob = errors.New("scheduler: duplicate job name")
+	// ErrStopped 表示 Scheduler 已經停止。
+	ErrStopped = errors.New("scheduler: stopped")
+)
+
+// JobFunc 是工作要執行的函式。ctx 會在 Stop 的等待期限到期時被取消。
+type JobFunc func(ctx context.Context) error
+
+// JobOption 用來調整單一工作的行為。
+type JobOption func(*job)
+
+// NoOverlap 讓工作在上一次執行尚未結束時跳過這一次 (記錄在 JobInfo.Skipped)。
+func NoOverlap() JobOption {
+	return func(j *job) { j.noOverlap = true }
+}
+
+// Option 用來調整 Scheduler 的行為。
+type Option func(*Scheduler)
+
+// WithClock 指定 Scheduler 使用的時鐘，預設為 clock.Real()。
+func WithClock(c clock.Clock) Option {
+	return func(s *Scheduler) { s.clock = c }
+}
+
+// JobInfo 是工作在某個瞬間的狀態。
+type JobInfo struct {
+	Name      string
+	Schedule  string // Schedule.String()
+	NoOverlap bool
+	Next      time.Time // 下次執行時間；零值代表不會再執行
+	Running   int       // 正在執行中的次數
+
+	Runs         uint64        // 已完成的執行次數
+	Skipped      uint64        // 因 NoOverlap 被跳過的次數
+	LastRun      time.Time     // 上次開始執行的時間
+	LastDuration time.Duration // 上次執行花費的時間
+	LastErr      error         // 上次執行的錯誤
+}
+
+// job 是 Scheduler 內部保存的工作。
+type job struct {
+	name      string
+	schedule  Schedule
+	fn        JobFunc
+	noOverlap bool
+
+	next         time.Time
+	running      int
+	runs         uint64
+	skipped      uint64
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastErr      error
+}
+
+func (j *job) info() JobInfo {
+	return JobInfo{
+		Name:         j.name,
+		Schedule:     j.schedule.String(),
+		NoOverlap:    j.noOverlap,
+		Next:         j.next,
+		Running:      j.running,
+		Runs:         j.runs,
+		Skipped:      j.skipped,
+		LastRun:      j.lastRun,
+		LastDuration: j.lastDuration,
+		LastErr:      j.lastErr,
+	}
+}
+
+// Scheduler 依照各自的 Schedule 執行工作。一個 Goroutine 負責等待最早到期的工作，
+// 每次執行則在新的 Goroutine 中進行，因此慢的工作不會延誤其他工作。
+type Scheduler struct {
+	clock clock.Clock
+
+	ctx    context.Context // 傳給所有工作，Stop 逾時時取消
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	started bool
+	stopped bool
+	running sync.WaitGroup
+
+	wake     chan struct{} // 工作變動時喚醒排程迴圈
+	stop     chan struct{}
+	loopDone chan struct{}
+}
+
+// New 建立一個 Scheduler。呼叫 Start 之後才會開始執行工作。
+func New(opts ...Option) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		clock:    clock.Real(),
+		ctx:      ctx,
+		cancel:   cancel,
+		jobs:     make(map[string]*job),
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		loopDone: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// After 回傳在 Scheduler 的時鐘經過 d 之後執行一次的 Schedule。
+func (s *Scheduler) After(d time.Duration) Schedule {
+	return At(s.clock.Now().Add(d))
+}
+
+// Add 新增一個名為 name 的工作。名稱必須唯一，也是持久化時對應函式的依據。
+func (s *Scheduler) Add(name string, schedule Schedule, fn JobFunc, opts ...JobOption) error {
+	j := &job{name: name, schedule: schedule, fn: fn}
+	for _, opt := range opts {
+		opt(j)
+	}
+	j.next = schedule.Next(s.clock.Now())
+	return s.add(j)
+}
+
+func (s *Scheduler) add(j *job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrStopped
+	}
+	if _, ok := s.jobs[j.name]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateJob, j.name)
+	}
+	s.jobs[j.name] = j
+	s.notify()
+	return nil
+}
+
+// Remove 移除名為 name 的工作，並回傳它是否存在。正在執行中的那一次不受影響。
+func (s *Scheduler) Remove(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.jobs[name]
+	delete(s.jobs, name)
+	s.notify()
+	return ok
+}
+
+// Job 回傳名為 name 的工作狀態。
+func (s *Scheduler) Job(name string) (JobInfo, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return JobInfo{}, false
+	}
+	return j.info(), true
+}
+
+// Jobs 回傳所有工作的狀態，依下次執行時間排序 (不會再執行的排在最後)。
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		infos = append(infos, j.info())
+	}
+	s.mu.Unlock()
+
+	sort.Slice(infos, func(a, b int) bool {
+		na, nb := infos[a].Next, infos[b].Next
+		if na.IsZero() != nb.IsZero() {
+			return nb.IsZero()
+		}
+		if !na.Equal(nb) {
+			return na.Before(nb)
+		}
+		return infos[a].Name < infos[b].Name
+	})
+	return infos
+}
+
+// notify 喚醒排程迴圈重新計算下一個到期時間。呼叫者必須持有 s.mu。
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start 啟動排程迴圈。重複呼叫沒有效果。
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+	go s.loop()
+}
+
+// Stop 停止排程 (不再開始新的執行)，並等待執行中的工作結束。
+// 若 ctx 先結束，Stop 會取消傳給工作的 ctx，等它們返回後回傳 ctx.Err()。
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stop)
+		if !s.started {
+			close(s.loopDone)
+		}
+	}
+	s.mu.Unlock()
+	<-s.loopDone
+
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.loopDone)
+	for {
+		next := s.runDue()
+
+		var timer clock.Timer
+		var fire <-chan time.Time
+		if !next.IsZero() {
+			timer = s.clock.NewTimer(next.Sub(s.clock.Now()))
+			fire = timer.C()
+		}
+		select {
+		case <-fire:
+		case <-s.wake:
+		case <-s.stop:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+	}
+}
+
+// runDue 啟動所有已到期的工作、計算它們的下次執行時間，並回傳最早的下次執行時間。
+func (s *Scheduler) runDue() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+
+	var earliest time.Time
+	for _, j := range s.jobs {
+		if j.next.IsZero() {
+			continue
+		}
+		if !j.next.After(now) {
+			if j.noOverlap && j.running > 0 {
+				j.skipped++
+			} else {
+				j.running++
+				s.running.Add(1)
+				go s.run(j)
+			}
+			// 從預定時間計算下一次以避免漂移；落後太多時 (例如系統休眠) 則從現在開始，不補跑。
+			next := j.schedule.Next(j.next)
+			if !next.IsZero() && !next.After(now) {
+				next = j.schedule.Next(now)
+			}
+			j.next = next
+		}
+		if !j.next.IsZero() && (earliest.IsZero() || j.next.Before(earliest)) {
+			earliest = j.next
+		}
+	}
+	return earliest
+}
+
+// run 執行一次 j，並記錄結果。
+func (s *Scheduler) run(j *job) {
+	defer s.running.Done()
+	start := s.clock.Now()
+	err := call(s.ctx, j.fn)
+	elapsed := s.clock.Now().Sub(start)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.running--
+	j.runs++
+	j.lastRun, j.lastDuration, j.lastErr = start, elapsed, err
+}
+
+// call 執行 fn，並把 panic 轉成錯誤，避免一個工作讓整個程式崩潰。
+func call(ctx context.Context, fn JobFunc) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("scheduler: job panicked: %v", v)
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,243 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestScheduler 建立一個使用假時鐘並已啟動的 Scheduler，測試結束時停止它。
+func newTestScheduler(t *testing.T) (*Scheduler, *clock.Fake) {
+	t.Helper()
+	leakcheck.Check(t)
+	fake := clock.NewFake(start)
+	s := New(WithClock(fake))
+	s.Start()
+	t.Cleanup(func() {
+		if err := s.Stop(context.Background()); err != nil {
+			t.Errorf("Stop() 錯誤 = %v", err)
+		}
+	})
+	return s, fake
+}
+
+// advance 讓排程迴圈進入等待後再快轉 d。
+func advance(fake *clock.Fake, d time.Duration) {
+	fake.BlockUntil(1)
+	fake.Advance(d)
+}
+
+func TestEveryAndAfter(t *testing.T) {
+	s, fake := newTestScheduler(t)
+
+	ticks := make(chan time.Time, 10)
+	s.Add("tick", Every(time.Minute), func(ctx context.Context) error {
+		ticks <- fake.Now()
+		return nil
+	})
+	once := make(chan time.Time, 1)
+	s.Add("once", s.After(90*time.Second), func(ctx context.Context) error {
+		once <- fake.Now()
+		return nil
+	})
+
+	advance(fake, time.Minute)
+	if got := <-ticks; !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("第一次 tick 在 %v; 預期為 %v", got, start.Add(time.Minute))
+	}
+	advance(fake, 30*time.Second)
+	if got := <-once; !got.Equal(start.Add(90 * time.Second)) {
+		t.Errorf("once 在 %v; 預期為 %v", got, start.Add(90*time.Second))
+	}
+	advance(fake, 30*time.Second)
+	if got := <-ticks; !got.Equal(start.Add(2 * time.Minute)) {
+		t.Errorf("第二次 tick 在 %v; 預期為 %v", got, start.Add(2*time.Minute))
+	}
+
+	// 一次性的工作執行後不會再有下次執行時間。
+	waitRuns(t, s, "once", 1)
+	info, _ := s.Job("once")
+	if !info.Next.IsZero() || info.Runs != 1 {
+		t.Errorf("once: Next = %v, Runs = %d; 預期為零值與 1", info.Next, info.Runs)
+	}
+	if info, _ := s.Job("tick"); !info.Next.Equal(start.Add(3 * time.Minute)) {
+		t.Errorf("tick: Next = %v; 預期為 %v", info.Next, start.Add(3*time.Minute))
+	}
+}
+
+// waitRuns 等到工作完成 n 次 (工作函式返回後才會記錄結果)。
+func waitRuns(t *testing.T, s *Scheduler, name string, n uint64) JobInfo {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		info, _ := s.Job(name)
+		if info.Runs >= n {
+			return info
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("工作 %q 只完成 %d 次; 預期 %d 次", name, info.Runs, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLastResult(t *testing.T) {
+	s, fake := newTestScheduler(t)
+	errBoom := errors.New("boom")
+	s.Add("fail", Every(time.Minute), func(ctx context.Context) error { return errBoom })
+	s.Add("panic", Every(time.Minute), func(ctx context.Context) error { panic("oops") })
+
+	advance(fake, time.Minute)
+	if info := waitRuns(t, s, "fail", 1); !errors.Is(info.LastErr, errBoom) || !info.LastRun.Equal(start.Add(time.Minute)) {
+		t.Errorf("fail: LastErr = %v, LastRun = %v; 預期為 %v 與 %v", info.LastErr, info.LastRun, errBoom, start.Add(time.Minute))
+	}
+	if info := waitRuns(t, s, "panic", 1); info.LastErr == nil {
+		t.Error("panic: 預期 panic 被轉成錯誤")
+	}
+}
+
+func TestNoOverlap(t *testing.T) {
+	s, fake := newTestScheduler(t)
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	s.Add("slow", Every(time.Minute), func(ctx context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, NoOverlap())
+
+	advance(fake, time.Minute)
+	<-started
+	// 第一次還沒結束，接下來兩次都會被跳過。
+	advance(fake, time.Minute)
+	advance(fake, time.Minute)
+	fake.BlockUntil(1)
+
+	info, _ := s.Job("slow")
+	if info.Running != 1 || info.Skipped != 2 {
+		t.Errorf("Running = %d, Skipped = %d; 預期為 1 與 2", info.Running, info.Skipped)
+	}
+	close(release)
+	waitRuns(t, s, "slow", 1)
+}
+
+func TestRemoveAndDuplicate(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	noop := func(ctx context.Context) error { return nil }
+
+	if err := s.Add("a", Every(time.Minute), noop); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("a", Every(time.Minute), noop); !errors.Is(err, ErrDuplicateJob) {
+		t.Errorf("重複的 Add 錯誤 = %v; 預期為 ErrDuplicateJob", err)
+	}
+	s.Add("b", Every(30*time.Second), noop)
+
+	jobs := s.Jobs()
+	if len(jobs) != 2 || jobs[0].Name != "b" || jobs[1].Name != "a" {
+		t.Errorf("Jobs() 順序 = %v; 預期依下次執行時間排序為 [b a]", jobs)
+	}
+	if !s.Remove("a") || s.Remove("a") {
+		t.Error("Remove(\"a\") 預期第一次回傳 true、第二次回傳 false")
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	leakcheck.Check(t)
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	fake := clock.NewFake(start)
+	noop := func(ctx context.Context) error { return nil }
+
+	s := New(WithClock(fake))
+	s.Add("report", s.After(time.Hour), noop)
+	cron, _ := ParseCron("CRON_TZ=UTC 0 9 * * *")
+	s.Add("daily", cron, noop, NoOverlap())
+	if err := s.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() 錯誤 = %v", err)
+	}
+	s.Stop(context.Background())
+
+	// 重新啟動：時間已經過了 10 分鐘，但一次性工作仍然保留原本的執行時間。
+	fake.Advance(10 * time.Minute)
+	loaded := New(WithClock(fake))
+	defer loaded.Stop(context.Background())
+	err := loaded.LoadFile(path, map[string]JobFunc{"report": noop})
+	if err == nil {
+		t.Error("LoadFile() 預期回報找不到 daily 的函式")
+	}
+
+	info, ok := loaded.Job("report")
+	if !ok || !info.Next.Equal(start.Add(time.Hour)) {
+		t.Errorf("report: Next = %v; 預期為 %v", info.Next, start.Add(time.Hour))
+	}
+	if _, ok := loaded.Job("daily"); ok {
+		t.Error("沒有函式的 daily 不應該被載入")
+	}
+
+	// 提供所有函式時可以完整載入。
+	full := New(WithClock(fake))
+	defer full.Stop(context.Background())
+	if err := full.LoadFile(path, map[string]JobFunc{"report": noop, "daily": noop}); err != nil {
+		t.Fatalf("LoadFile() 錯誤 = %v", err)
+	}
+	if info, _ := full.Job("daily"); !info.NoOverlap || !info.Next.Equal(start.Add(9*time.Hour)) {
+		t.Errorf("daily = %+v; 預期 NoOverlap 且 Next 為 %v", info, start.Add(9*time.Hour))
+	}
+}
+
+func TestLoadWithoutNext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	state := `[
+		{"name": "tick", "schedule": "@every 1m"},
+		{"name": "daily", "schedule": "CRON_TZ=UTC 0 9 * * *"},
+		{"name": "done", "schedule": "@at 2024-12-31T00:00:00Z", "runs": 1},
+		{"name": "later", "schedule": "@at 2025-01-01T02:00:00Z"}
+	]`
+	if err := os.WriteFile(path, []byte(state), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, fake := newTestScheduler(t)
+	ran := make(chan struct{}, 1)
+	noop := func(ctx context.Context) error { return nil }
+	funcs := map[string]JobFunc{
+		"tick":  func(ctx context.Context) error { ran <- struct{}{}; return nil },
+		"daily": noop,
+		"done":  noop,
+		"later": noop,
+	}
+	if err := s.LoadFile(path, funcs); err != nil {
+		t.Fatalf("LoadFile() 錯誤 = %v", err)
+	}
+
+	want := map[string]time.Time{
+		"tick":  start.Add(time.Minute),
+		"daily": start.Add(9 * time.Hour),
+		"done":  {},
+		"later": start.Add(2 * time.Hour),
+	}
+	for name, next := range want {
+		if info, _ := s.Job(name); !info.Next.Equal(next) {
+			t.Errorf("%s: Next = %v; 預期為 %v", name, info.Next, next)
+		}
+	}
+
+	if t.Failed() {
+		return // 沒有排入計時器時下面的 BlockUntil 會永遠等待
+	}
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("沒有 next 的 @every 工作沒有執行")
+	}
+}
@@ -0,0 +1,491 @@
+// Package workerpool 把 Fan-Out/Fan-In 範例中固定 3 個 worker 的寫法，
+// 擴充成可以在正式環境使用的工作池 (worker pool)。
+//
+// 與範例相比，Pool 多了以下能力：
+//   - Submit 回傳 Future，呼叫者可以等待單一任務的結果。
+//   - 有界佇列 (bounded queue)，佇列滿時依 Policy 阻塞、拒絕、丟棄最舊任務或由呼叫者自己執行。
+//   - Resize 可以在執行期間調整 worker 數量。
+//   - 每個任務可以設定逾時，任務中的 panic 會被轉成 *group.PanicError。
+//   - Shutdown 會等待佇列中的任務做完；ShutdownNow 則丟棄佇列並取消執行中的任務。
+//   - WithResults 可以把所有結果送到一個 channel，並可選擇依提交順序輸出。
+//   - Metrics 回傳佇列長度、執行中、完成、失敗與延遲等統計。
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutines/group"
+)
+
+var (
+	// ErrQueueFull 表示佇列已滿且 Policy 為 Reject。
+	ErrQueueFull = errors.New("workerpool: queue is full")
+	// ErrClosed 表示 Pool 已經呼叫過 Shutdown 或 ShutdownNow。
+	ErrClosed = errors.New("workerpool: pool is shut down")
+	// ErrDiscarded 是因 DiscardOldest 被擠出佇列的任務所得到的錯誤。
+	ErrDiscarded = errors.New("workerpool: task discarded from full queue")
+	// ErrDropped 是因 ShutdownNow 而沒有執行的任務所得到的錯誤。
+	ErrDropped = errors.New("workerpool: task dropped by ShutdownNow")
+)
+
+// Policy 決定佇列已滿時 Submit 的行為。
+type Policy int
+
+const (
+	// Block 讓 Submit 等待佇列出現空位 (或 ctx 結束)，對提交者形成背壓 (backpressure)。
+	Block Policy = iota
+	// Reject 讓 Submit 立即回傳 ErrQueueFull。
+	Reject
+	// DiscardOldest 丟棄佇列中最舊的任務 (它的 Future 得到 ErrDiscarded)，再放入新任務。
+	DiscardOldest
+	// CallerRuns 讓呼叫 Submit 的 Goroutine 自己執行任務，自然地減緩提交速度。
+	CallerRuns
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Block:
+		return "Block"
+	case Reject:
+		return "Reject"
+	case DiscardOldest:
+		return "DiscardOldest"
+	case CallerRuns:
+		return "CallerRuns"
+	default:
+		return fmt.Sprintf("Policy(%d)", int(p))
+	}
+}
+
+// Task 是交給 Pool 執行的工作。ctx 會在任務逾時或 ShutdownNow 時被取消。
+type Task[T any] func(ctx context.Context) (T, error)
+
+// config 保存 Option 設定的值。
+type config struct {
+	queueSize   int
+	policy      Policy
+	taskTimeout time.Duration
+	results     bool
+	ordered     bool
+}
+
+// Option 用來調整 Pool 的行為。
+type Option func(*config)
+
+// WithQueueSize 設定佇列最多能放幾個等待中的任務 (預設 64)；n <= 0 代表不限制。
+func WithQueueSize(n int) Option {
+	return func(c *config) { c.queueSize = n }
+}
+
+// WithPolicy 設定佇列已滿時的處理方式 (預設 Block)。
+func WithPolicy(p Policy) Option {
+	return func(c *config) { c.policy = p }
+}
+
+// WithTaskTimeout 讓每個任務的 ctx 在 d 之後逾時。
+// 逾時是協作式的：任務必須觀察 ctx 才會提早結束。
+func WithTaskTimeout(d time.Duration) Option {
+	return func(c *config) { c.taskTimeout = d }
+}
+
+// WithResults 讓 Pool 把每個任務的結果送到 Results() 回傳的 channel。
+// ordered 為 true 時，結果會依提交順序輸出 (先完成的任務會等待前面的任務)。
+// 使用此選項時必須持續讀取 Results，否則 worker 會被阻塞。
+func WithResults(ordered bool) Option {
+	return func(c *config) { c.results, c.ordered = true, ordered }
+}
+
+// Result 是經由 Results channel 輸出的任務結果。Seq 是任務的提交序號，從 0 開始。
+type Result[T any] struct {
+	Seq   uint64
+	Value T
+	Err   error
+}
+
+// Future 代表一個尚未完成的任務結果。
+type Future[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// Done 回傳一個在任務完成時關閉的 channel。
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Get 等待任務完成並回傳結果；若 ctx 先結束則回傳 ctx.Err()，任務本身不受影響。
+func (f *Future[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+func (f *Future[T]) complete(val T, err error) {
+	f.val, f.err = val, err
+	close(f.done)
+}
+
+// job 是佇列中的一個任務。
+type job[T any] struct {
+	seq       uint64
+	task      Task[T]
+	future    *Future[T]
+	submitted time.Time
+}
+
+// Pool 以固定數量 (可用 Resize 調整) 的 worker 執行任務。
+type Pool[T any] struct {
+	cfg    config
+	ctx    context.Context // 所有任務 ctx 的來源，ShutdownNow 時取消
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond // 佇列有任務、Pool 關閉或需要縮減 worker
+	notFull  *sync.Cond // 佇列有空位或 Pool 關閉
+	queue    []*job[T]
+	nextSeq  uint64
+	target   int // 期望的 worker 數量
+	workers  int // 目前存活的 worker 數量
+	closed   bool
+	stats    Metrics
+
+	workerWG sync.WaitGroup
+	inflight sync.WaitGroup // 已接受但尚未完成的任務
+	stopped  chan struct{}
+
+	results  chan Result[T]
+	resMu    sync.Mutex
+	pending  map[uint64]Result[T] // ordered 模式下等待前面任務的結果
+	nextEmit uint64
+}
+
+// New 建立一個有 workers 個 worker 的 Pool。workers < 1 時使用 1。
+func New[T any](workers int, opts ...Option) *Pool[T] {
+	cfg := config{queueSize: 64}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool[T]{cfg: cfg, ctx: ctx, cancel: cancel, stopped: make(chan struct{})}
+	p.notEmpty = sync.NewCond(&p.mu)
+	p.notFull = sync.NewCond(&p.mu)
+	if cfg.results {
+		p.results = make(chan Result[T], workers)
+		if cfg.ordered {
+			p.pending = make(map[uint64]Result[T])
+		}
+	}
+
+	p.mu.Lock()
+	p.resizeLocked(workers)
+	p.mu.Unlock()
+	return p
+}
+
+// Results 回傳輸出所有任務結果的 channel；沒有使用 WithResults 時回傳 nil。
+// channel 會在 Pool 關閉且所有任務結束後關閉。
+func (p *Pool[T]) Results() <-chan Result[T] {
+	return p.results
+}
+
+// Submit 把 task 放入佇列並回傳它的 Future。
+// 佇列已滿時的行為由 Policy 決定；ctx 只用於 Block 策略下的等待，不會傳給 task。
+func (p *Pool[T]) Submit(ctx context.Context, task Task[T]) (*Future[T], error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrClosed
+	}
+
+	if p.fullLocked() {
+		switch p.cfg.policy {
+		case Reject:
+			p.stats.Rejected++
+			p.mu.Unlock()
+			return nil, ErrQueueFull
+
+		case DiscardOldest:
+			oldest := p.queue[0]
+			p.queue[0] = nil
+			p.queue = p.queue[1:]
+			p.stats.Discarded++
+			j := p.enqueueLocked(task)
+			p.mu.Unlock()
+			p.finish(oldest, *new(T), ErrDiscarded)
+			return j.future, nil
+
+		case CallerRuns:
+			j := p.acceptLocked(task)
+			p.stats.Active++
+			p.mu.Unlock()
+			p.execute(j)
+			return j.future, nil
+
+		default: // Block
+			// sync.Cond 無法直接等待 ctx，因此在 ctx 結束時喚醒所有等待者，讓它們重新檢查。
+			stop := context.AfterFunc(ctx, func() {
+				p.mu.Lock()
+				defer p.mu.Unlock()
+				p.notFull.Broadcast()
+			})
+			for p.fullLocked() && !p.closed && ctx.Err() == nil {
+				p.notFull.Wait()
+			}
+			stop()
+			if p.closed {
+				p.mu.Unlock()
+				return nil, ErrClosed
+			}
+			if err := ctx.Err(); err != nil {
+				p.mu.Unlock()
+				return nil, err
+			}
+		}
+	}
+
+	j := p.enqueueLocked(task)
+	p.mu.Unlock()
+	return j.future, nil
+}
+
+func (p *Pool[T]) fullLocked() bool {
+	return p.cfg.queueSize > 0 && len(p.queue) >= p.cfg.queueSize
+}
+
+// acceptLocked 為 task 建立 job 並分配提交序號。
+func (p *Pool[T]) acceptLocked(task Task[T]) *job[T] {
+	j := &job[T]{
+		seq:       p.nextSeq,
+		task:      task,
+		future:    &Future[T]{done: make(chan struct{})},
+		submitted: time.Now(),
+	}
+	p.nextSeq++
+	p.stats.Submitted++
+	p.inflight.Add(1)
+	return j
+}
+
+func (p *Pool[T]) enqueueLocked(task Task[T]) *job[T] {
+	j := p.acceptLocked(task)
+	p.queue = append(p.queue, j)
+	p.notEmpty.Signal()
+	return j
+}
+
+// Resize 把 worker 數量調整為 n (n < 0 視為 0)。
+// 縮減時，多出來的 worker 會在完成手上的任務後結束；n 為 0 時佇列會暫停處理。
+// Pool 關閉後呼叫 Resize 沒有效果。
+func (p *Pool[T]) Resize(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.resizeLocked(max(n, 0))
+}
+
+func (p *Pool[T]) resizeLocked(n int) {
+	p.target = n
+	for p.workers < p.target {
+		p.workers++
+		p.workerWG.Add(1)
+		go p.worker()
+	}
+	p.notEmpty.Broadcast()
+}
+
+func (p *Pool[T]) worker() {
+	defer p.workerWG.Done()
+	for {
+		p.mu.Lock()
+		for len(p.queue) == 0 && !p.closed && p.workers <= p.target {
+			p.notEmpty.Wait()
+		}
+		if p.workers > p.target || len(p.queue) == 0 {
+			// 需要縮減，或 Pool 已關閉且佇列已清空。
+			p.workers--
+			p.mu.Unlock()
+			return
+		}
+		j := p.queue[0]
+		p.queue[0] = nil
+		p.queue = p.queue[1:]
+		p.stats.Active++
+		p.notFull.Signal()
+		p.mu.Unlock()
+
+		p.execute(j)
+	}
+}
+
+// execute 執行 j 並記錄結果。呼叫前必須已經把 Active 加一。
+func (p *Pool[T]) execute(j *job[T]) {
+	ctx := p.ctx
+	if p.cfg.taskTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.taskTimeout)
+		defer cancel()
+	}
+	val, err := run(ctx, j.task)
+
+	latency := time.Since(j.submitted)
+	p.mu.Lock()
+	p.stats.Active--
+	if err != nil {
+		p.stats.Failed++
+	} else {
+		p.stats.Completed++
+	}
+	p.stats.TotalLatency += latency
+	p.stats.MaxLatency = max(p.stats.MaxLatency, latency)
+	p.mu.Unlock()
+
+	p.finish(j, val, err)
+}
+
+// run 執行 task，並把 panic 轉換成 *group.PanicError。
+func run[T any](ctx context.Context, task Task[T]) (val T, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &group.PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return task(ctx)
+}
+
+// finish 完成 j 的 Future，並在需要時輸出到 Results。
+func (p *Pool[T]) finish(j *job[T], val T, err error) {
+	j.future.complete(val, err)
+	p.emit(Result[T]{Seq: j.seq, Value: val, Err: err})
+	p.inflight.Done()
+}
+
+func (p *Pool[T]) emit(r Result[T]) {
+	if p.results == nil {
+		return
+	}
+	if p.pending == nil {
+		p.results <- r
+		return
+	}
+
+	p.resMu.Lock()
+	defer p.resMu.Unlock()
+	p.pending[r.Seq] = r
+	for {
+		next, ok := p.pending[p.nextEmit]
+		if !ok {
+			return
+		}
+		delete(p.pending, p.nextEmit)
+		p.nextEmit++
+		p.results <- next
+	}
+}
+
+// Shutdown 停止接受新任務，等待佇列中與執行中的任務全部完成。
+// 若 ctx 先結束則回傳 ctx.Err()，任務仍會在背景繼續完成。可以重複呼叫。
+func (p *Pool[T]) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	p.closeLocked()
+	p.mu.Unlock()
+
+	select {
+	case <-p.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ShutdownNow 停止接受新任務、丟棄佇列中尚未開始的任務 (它們的 Future 得到 ErrDropped)，
+// 並取消執行中任務的 ctx。回傳被丟棄的任務數量。
+// ShutdownNow 不會等待執行中的任務結束，需要時請接著呼叫 Shutdown。
+func (p *Pool[T]) ShutdownNow() int {
+	p.mu.Lock()
+	p.closeLocked()
+	dropped := p.queue
+	p.queue = nil
+	p.stats.Discarded += uint64(len(dropped))
+	p.mu.Unlock()
+
+	p.cancel()
+	for _, j := range dropped {
+		p.finish(j, *new(T), ErrDropped)
+	}
+	return len(dropped)
+}
+
+func (p *Pool[T]) closeLocked() {
+	if p.closed {
+		return
+	}
+	p.closed = true
+	// 沒有 worker 時 (例如 Resize(0))，補一個 worker 把佇列做完。
+	if p.target == 0 && len(p.queue) > 0 {
+		p.resizeLocked(1)
+	}
+	p.notEmpty.Broadcast()
+	p.notFull.Broadcast()
+
+	go func() {
+		p.workerWG.Wait()
+		p.inflight.Wait()
+		if p.results != nil {
+			close(p.results)
+		}
+		p.cancel()
+		close(p.stopped)
+	}()
+}
+
+// Metrics 是 Pool 在某個瞬間的統計資料。
+type Metrics struct {
+	Workers int // 存活的 worker 數量
+	Queued  int // 佇列中等待的任務數
+	Active  int // 正在執行的任務數
+
+	Submitted uint64 // 被接受的任務總數
+	Completed uint64 // 成功完成的任務數
+	Failed    uint64 // 回傳錯誤 (含逾時與 panic) 的任務數
+	Rejected  uint64 // 因佇列已滿被拒絕的提交數
+	Discarded uint64 // 被 DiscardOldest 或 ShutdownNow 丟棄的任務數
+
+	// TotalLatency 與 MaxLatency 量測從提交到執行完成的時間 (包含排隊時間)。
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+// AvgLatency 回傳已執行任務的平均延遲。
+func (m Metrics) AvgLatency() time.Duration {
+	n := m.Completed + m.Failed
+	if n == 0 {
+		return 0
+	}
+	return m.TotalLatency / time.Duration(n)
+}
+
+// Metrics 回傳目前的統計資料。
+func (p *Pool[T]) Metrics() Metrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m := p.stats
+	m.Workers = p.workers
+	m.Queued = len(p.queue)
+	return m
+}
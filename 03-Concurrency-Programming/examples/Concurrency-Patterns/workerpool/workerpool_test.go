@@ -0,0 +1,331 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutines/group"
+)
+
+// value 回傳一個直接回傳 v 的任務。
+func value(v int) Task[int] {
+	return func(context.Context) (int, error) { return v, nil }
+}
+
+// gated 回傳一個等到 gate 關閉才完成的任務，以及一個在任務開始時關閉的 channel。
+func gated(v int, gate <-chan struct{}) (Task[int], <-chan struct{}) {
+	started := make(chan struct{})
+	return func(context.Context) (int, error) {
+		close(started)
+		<-gate
+		return v, nil
+	}, started
+}
+
+func shutdown[T any](t *testing.T, p *Pool[T]) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() 錯誤 = %v; 預期為 nil", err)
+	}
+}
+
+func TestSubmitAndGet(t *testing.T) {
+	leakcheck.Check(t)
+	p := New[int](3)
+	defer shutdown(t, p)
+
+	var futures []*Future[int]
+	for i := 0; i < 20; i++ {
+		f, err := p.Submit(context.Background(), func(context.Context) (int, error) { return i * i, nil })
+		if err != nil {
+			t.Fatalf("Submit(%d) 錯誤 = %v", i, err)
+		}
+		futures = append(futures, f)
+	}
+	for i, f := range futures {
+		if got, err := f.Get(context.Background()); got != i*i || err != nil {
+			t.Errorf("future %d = (%d, %v); 預期為 (%d, nil)", i, got, err, i*i)
+		}
+	}
+}
+
+func TestFullQueuePolicies(t *testing.T) {
+	testCases := []struct {
+		name      string
+		policy    Policy
+		wantErr   error // 第三個 Submit 的錯誤
+		wantQueue error // 原本在佇列中的任務的結果錯誤
+	}{
+		{"Reject", Reject, ErrQueueFull, nil},
+		{"DiscardOldest", DiscardOldest, nil, ErrDiscarded},
+		{"CallerRuns", CallerRuns, nil, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			leakcheck.Check(t)
+			p := New[int](1, WithQueueSize(1), WithPolicy(tc.policy))
+			defer shutdown(t, p)
+
+			// 唯一的 worker 被第一個任務佔住，第二個任務把佇列填滿。
+			gate := make(chan struct{})
+			busy, started := gated(1, gate)
+			if _, err := p.Submit(context.Background(), busy); err != nil {
+				t.Fatal(err)
+			}
+			<-started
+			queued, err := p.Submit(context.Background(), value(2))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			third, err := p.Submit(context.Background(), value(3))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("第三個 Submit 錯誤 = %v; 預期為 %v", err, tc.wantErr)
+			}
+			if tc.policy == CallerRuns {
+				// 呼叫者自己執行，Submit 返回時任務已經完成。
+				select {
+				case <-third.Done():
+				default:
+					t.Error("CallerRuns: Submit 返回時任務尚未完成")
+				}
+			}
+			close(gate)
+
+			if _, err := queued.Get(context.Background()); !errors.Is(err, tc.wantQueue) {
+				t.Errorf("佇列中任務的錯誤 = %v; 預期為 %v", err, tc.wantQueue)
+			}
+			if third != nil {
+				if got, err := third.Get(context.Background()); got != 3 || err != nil {
+					t.Errorf("第三個任務 = (%d, %v); 預期為 (3, nil)", got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestBlockPolicyWaitsForSpace(t *testing.T) {
+	leakcheck.Check(t)
+	p := New[int](1, WithQueueSize(1), WithPolicy(Block))
+	defer shutdown(t, p)
+
+	gate := make(chan struct{})
+	busy, started := gated(1, gate)
+	p.Submit(context.Background(), busy)
+	<-started
+	p.Submit(context.Background(), value(2))
+
+	// 佇列已滿：帶逾時的 ctx 應該放棄等待。
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.Submit(ctx, value(3)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Submit(已滿) 錯誤 = %v; 預期為 context.DeadlineExceeded", err)
+	}
+
+	// 釋放 worker 之後，阻塞中的 Submit 應該成功。
+	submitted := make(chan error)
+	go func() {
+		_, err := p.Submit(context.Background(), value(4))
+		submitted <- err
+	}()
+	close(gate)
+	if err := <-submitted; err != nil {
+		t.Errorf("Submit(等待空位) 錯誤 = %v; 預期為 nil", err)
+	}
+}
+
+func TestTaskTimeoutAndPanic(t *testing.T) {
+	leakcheck.Check(t)
+	p := New[int](2, WithTaskTimeout(10*time.Millisecond))
+	defer shutdown(t, p)
+
+	slow, _ := p.Submit(context.Background(), func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	boom, _ := p.Submit(context.Background(), func(context.Context) (int, error) {
+		panic("boom")
+	})
+
+	if _, err := slow.Get(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("逾時任務錯誤 = %v; 預期為 context.DeadlineExceeded", err)
+	}
+	var pe *group.PanicError
+	if _, err := boom.Get(context.Background()); !errors.As(err, &pe) || pe.Value != "boom" {
+		t.Errorf("panic 任務錯誤 = %v; 預期為 *group.PanicError{Value: \"boom\"}", err)
+	}
+
+	// panic 之後 worker 仍然可用。
+	f, _ := p.Submit(context.Background(), value(5))
+	if got, err := f.Get(context.Background()); got != 5 || err != nil {
+		t.Errorf("panic 之後的任務 = (%d, %v); 預期為 (5, nil)", got, err)
+	}
+	if m := p.Metrics(); m.Failed != 2 || m.Completed != 1 {
+		t.Errorf("Metrics Failed=%d Completed=%d; 預期為 2, 1", m.Failed, m.Completed)
+	}
+}
+
+func TestResize(t *testing.T) {
+	leakcheck.Check(t)
+	p := New[int](1)
+	defer shutdown(t, p)
+
+	// 擴充到 4 個 worker 後，4 個任務可以同時執行。
+	p.Resize(4)
+	gate := make(chan struct{})
+	var started []<-chan struct{}
+	for i := 0; i < 4; i++ {
+		task, s := gated(i, gate)
+		p.Submit(context.Background(), task)
+		started = append(started, s)
+	}
+	for _, s := range started {
+		<-s
+	}
+	if m := p.Metrics(); m.Workers != 4 || m.Active != 4 {
+		t.Errorf("Metrics Workers=%d Active=%d; 預期為 4, 4", m.Workers, m.Active)
+	}
+
+	// 縮減到 1：多出來的 worker 在做完手上的任務後結束。
+	p.Resize(1)
+	close(gate)
+	deadline := time.Now().Add(time.Second)
+	for p.Metrics().Workers != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Workers = %d; 預期縮減為 1", p.Metrics().Workers)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestShutdownDrainsQueue(t *testing.T) {
+	leakcheck.Check(t)
+	p := New[int](2)
+	var futures []*Future[int]
+	for i := 0; i < 10; i++ {
+		f, _ := p.Submit(context.Background(), func(context.Context) (int, error) {
+			time.Sleep(time.Millisecond)
+			return i, nil
+		})
+		futures = append(futures, f)
+	}
+	shutdown(t, p)
+
+	for i, f := range futures {
+		select {
+		case <-f.Done():
+		default:
+			t.Errorf("Shutdown 返回時任務 %d 尚未完成", i)
+		}
+	}
+	if _, err := p.Submit(context.Background(), value(0)); !errors.Is(err, ErrClosed) {
+		t.Errorf("Shutdown 後 Submit 錯誤 = %v; 預期為 ErrClosed", err)
+	}
+}
+
+func TestShutdownNow(t *testing.T) {
+	leakcheck.Check(t)
+	p := New[int](1)
+
+	running, started := submitCanceled(t, p)
+	<-started
+	var queued []*Future[int]
+	for i := 0; i < 3; i++ {
+		f, _ := p.Submit(context.Background(), value(i))
+		queued = append(queued, f)
+	}
+
+	if n := p.ShutdownNow(); n != 3 {
+		t.Errorf("ShutdownNow() = %d; 預期丟棄 3 個任務", n)
+	}
+	shutdown(t, p)
+
+	if _, err := running.Get(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Errorf("執行中任務錯誤 = %v; 預期為 context.Canceled", err)
+	}
+	for i, f := range queued {
+		if _, err := f.Get(context.Background()); !errors.Is(err, ErrDropped) {
+			t.Errorf("佇列中任務 %d 錯誤 = %v; 預期為 ErrDropped", i, err)
+		}
+	}
+	if m := p.Metrics(); m.Discarded != 3 {
+		t.Errorf("Metrics Discarded = %d; 預期為 3", m.Discarded)
+	}
+}
+
+// submitCanceled 提交一個等到 ctx 被取消才結束的任務。
+func submitCanceled[T any](t *testing.T, p *Pool[T]) (*Future[T], <-chan struct{}) {
+	t.Helper()
+	started := make(chan struct{})
+	f, err := p.Submit(context.Background(), func(ctx context.Context) (T, error) {
+		close(started)
+		<-ctx.Done()
+		return *new(T), ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, started
+}
+
+func TestOrderedResults(t *testing.T) {
+	leakcheck.Check(t)
+	p := New[int](4, WithResults(true))
+
+	var wg sync.WaitGroup
+	var got []Result[int]
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for r := range p.Results() {
+			got = append(got, r)
+		}
+	}()
+
+	const n = 20
+	for i := 0; i < n; i++ {
+		// 越早提交的任務越慢完成，確保完成順序與提交順序相反。
+		p.Submit(context.Background(), func(context.Context) (int, error) {
+			time.Sleep(time.Duration(n-i) * time.Millisecond / 2)
+			return i, nil
+		})
+	}
+	shutdown(t, p)
+	wg.Wait()
+
+	if len(got) != n {
+		t.Fatalf("收到 %d 個結果; 預期為 %d", len(got), n)
+	}
+	for i, r := range got {
+		if r.Seq != uint64(i) || r.Value != i {
+			t.Errorf("第 %d 個結果 = {Seq:%d Value:%d}; 預期為 {Seq:%d Value:%d}", i, r.Seq, r.Value, i, i)
+		}
+	}
+}
+
+func TestMetricsLatency(t *testing.T) {
+	leakcheck.Check(t)
+	p := New[int](1)
+	for i := 0; i < 3; i++ {
+		p.Submit(context.Background(), func(context.Context) (int, error) {
+			time.Sleep(5 * time.Millisecond)
+			return 0, nil
+		})
+	}
+	shutdown(t, p)
+
+	m := p.Metrics()
+	if m.Submitted != 3 || m.Completed != 3 || m.Queued != 0 || m.Active != 0 || m.Workers != 0 {
+		t.Errorf("Metrics = %+v; 預期 3 個任務全部完成且沒有存活的 worker", m)
+	}
+	// 單一 worker 依序執行，最後一個任務至少排隊了前兩個任務的時間。
+	if m.MaxLatency < 15*time.Millisecond || m.AvgLatency() < 5*time.Millisecond {
+		t.Errorf("MaxLatency = %v, AvgLatency = %v; 預期至少 15ms 與 5ms", m.MaxLatency, m.AvgLatency())
+	}
+}
@@ -0,0 +1,202 @@
+// Package leakcheck 在測試中偵測 Goroutine 洩漏 (goroutine leak)。
+//
+// 一個 Goroutine 如果在測試結束後仍然存活 (例如永遠卡在 channel 上)，
+// 它所引用的記憶體就永遠不會被回收。這個套件在測試開始與結束時各拍一張
+// Goroutine 快照 (runtime.Stack)，排除 runtime 與 testing 自己的 Goroutine，
+// 並在短暫重試後仍然多出來的 Goroutine 時讓測試失敗，同時印出它們的堆疊。
+//
+// 使用 t.Parallel 時，其他同時執行的測試與它們建立的 Goroutine 也會出現在快照中。
+// 這些 Goroutine 沿著建立者 (堆疊最後的 "created by ... in goroutine N") 往上可以找到
+// 另一個正在執行的測試，因此不算在這個測試的洩漏中。建立者已經結束的 Goroutine
+// 無法判斷屬於哪個測試，仍然會被回報；例如已經結束的平行測試真正洩漏的 Goroutine。
+//
+// 最常見的用法是在測試的第一行呼叫 Check：
+//
+//	func TestWorkers(t *testing.T) {
+//		leakcheck.Check(t)
+//		runWorkers(3)
+//	}
+package leakcheck
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 預設的重試設定：最多等待 1 秒讓 Goroutine 自行結束。
+const (
+	defaultTimeout = time.Second
+	initialBackoff = time.Millisecond
+	maxBackoff     = 50 * time.Millisecond
+)
+
+// Option 用來調整洩漏檢查的行為。
+type Option func(*config)
+
+type config struct {
+	timeout   time.Duration
+	ignoreTop []string
+	ignoreIDs map[int]bool
+}
+
+// Timeout 設定等待 Goroutine 結束的最長時間，預設為 1 秒。
+func Timeout(d time.Duration) Option {
+	return func(c *config) { c.timeout = d }
+}
+
+// IgnoreTopFunction 忽略目前正在執行 fn 的 Goroutine，例如某個預期常駐的背景工作。
+func IgnoreTopFunction(fn string) Option {
+	return func(c *config) { c.ignoreTop = append(c.ignoreTop, fn) }
+}
+
+// IgnoreCurrent 忽略呼叫當下已經存在的所有 Goroutine。
+func IgnoreCurrent() Option {
+	ids := map[int]bool{}
+	for _, g := range Snapshot() {
+		ids[g.ID] = true
+	}
+	return func(c *config) {
+		for id := range ids {
+			c.ignoreIDs[id] = true
+		}
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{timeout: defaultTimeout, ignoreIDs: map[int]bool{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Check 記錄目前存活的 Goroutine，並在測試結束時 (t.Cleanup) 確認沒有新的 Goroutine 留下來。
+func Check(t testing.TB, opts ...Option) {
+	t.Helper()
+	opts = append([]Option{IgnoreCurrent()}, opts...)
+	t.Cleanup(func() {
+		t.Helper()
+		VerifyNone(t, opts...)
+	})
+}
+
+// VerifyNone 立刻檢查是否有洩漏的 Goroutine，找到時呼叫 t.Error 並附上堆疊。
+// 與 Check 不同，它不會忽略測試開始前就存在的 Goroutine，除非傳入 IgnoreCurrent。
+func VerifyNone(t testing.TB, opts ...Option) {
+	t.Helper()
+	if err := Find(opts...); err != nil {
+		t.Error(err)
+	}
+}
+
+// Find 在重試期限內尋找洩漏的 Goroutine，沒有洩漏時回傳 nil。
+func Find(opts ...Option) error {
+	c := newConfig(opts)
+	self := currentID()
+
+	deadline := time.Now().Add(c.timeout)
+	backoff := initialBackoff
+	for {
+		leaked := c.filter(Snapshot(), self)
+		if len(leaked) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return &LeakError{Goroutines: leaked}
+		}
+		time.Sleep(backoff)
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+func (c *config) filter(gs []Goroutine, self int) []Goroutine {
+	byID := make(map[int]Goroutine, len(gs))
+	for _, g := range gs {
+		byID[g.ID] = g
+	}
+	var leaked []Goroutine
+	for _, g := range gs {
+		if g.ID == self || c.ignoreIDs[g.ID] || isSystem(g) || c.ignoredTop(g) {
+			continue
+		}
+		if owner := testOwner(g, byID); owner != 0 && owner != self {
+			continue // 屬於另一個同時執行的測試 (t.Parallel)
+		}
+		leaked = append(leaked, g)
+	}
+	return leaked
+}
+
+// testOwner 沿著 CreatorID 往上找，回傳 g 本身或它的祖先中第一個正在執行測試的 Goroutine 編號；
+// 途中遇到已經結束的 Goroutine 時回傳 0。
+func testOwner(g Goroutine, byID map[int]Goroutine) int {
+	for range len(byID) {
+		if isTestRunner(g) {
+			return g.ID
+		}
+		parent, ok := byID[g.CreatorID]
+		if !ok {
+			return 0
+		}
+		g = parent
+	}
+	return 0
+}
+
+// isTestRunner 回報 g 是否正在執行測試函式，也就是堆疊的最底層是 testing.tRunner。
+func isTestRunner(g Goroutine) bool {
+	return strings.Contains(g.Stack, "\ntesting.tRunner(")
+}
+
+func (c *config) ignoredTop(g Goroutine) bool {
+	for _, fn := range c.ignoreTop {
+		if g.TopFunction == fn {
+			return true
+		}
+	}
+	return false
+}
+
+// systemTopFunctions 是 runtime、testing 與標準函式庫常駐的 Goroutine。
+var systemTopFunctions = []string{
+	"testing.RunTests",
+	"testing.(*T).Run",
+	"testing.(*T).Parallel",
+	"testing.tRunner",
+	"testing.runFuzzing",
+	"testing.runFuzzTests",
+	"testing.(*F).Fuzz",
+	"os/signal.signal_recv",
+	"os/signal.loop",
+	"runtime.ReadTrace",
+	"runtime.goexit",
+	"runtime.ensureSigM",
+}
+
+func isSystem(g Goroutine) bool {
+	for _, fn := range systemTopFunctions {
+		if g.TopFunction == fn {
+			return true
+		}
+	}
+	// runtime/trace 在追蹤期間會啟動自己的讀取 Goroutine。
+	return strings.HasPrefix(g.CreatedBy, "runtime/trace.Start") || strings.HasPrefix(g.CreatedBy, "runtime.StartTrace")
+}
+
+// LeakError 列出所有洩漏的 Goroutine。
+type LeakError struct {
+	Goroutines []Goroutine
+}
+
+func (e *LeakError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "found %d leaked goroutine(s):\n", len(e.Goroutines))
+	for _, g := range e.Goroutines {
+		b.WriteString("\n")
+		b.WriteString(g.Stack)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,165 @@
+package leakcheck
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockForever 會一直阻塞到 stop 被關閉，用來製造一個「洩漏」的 Goroutine。
+func blockForever(stop <-chan struct{}) {
+	<-stop
+}
+
+func TestFindDetectsLeak(t *testing.T) {
+	ignore := IgnoreCurrent()
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go blockForever(stop)
+
+	err := Find(ignore, Timeout(20*time.Millisecond))
+	var leak *LeakError
+	if !errors.As(err, &leak) {
+		t.Fatalf("Find() = %v; 預期為 *LeakError", err)
+	}
+	if len(leak.Goroutines) != 1 {
+		t.Fatalf("leaked = %d; 預期為 1\n%v", len(leak.Goroutines), err)
+	}
+	g := leak.Goroutines[0]
+	if !strings.HasSuffix(g.TopFunction, ".blockForever") {
+		t.Errorf("TopFunction = %q; 預期以 .blockForever 結尾", g.TopFunction)
+	}
+	if g.State != "chan receive" {
+		t.Errorf("State = %q; 預期為 %q", g.State, "chan receive")
+	}
+	if !strings.Contains(err.Error(), "blockForever") {
+		t.Errorf("error should include the leaked stack, got:\n%v", err)
+	}
+}
+
+func TestFindWaitsForShutdown(t *testing.T) {
+	ignore := IgnoreCurrent()
+	stop := make(chan struct{})
+	go blockForever(stop)
+
+	// Goroutine 會在重試期間內結束，因此不算洩漏。
+	time.AfterFunc(10*time.Millisecond, func() { close(stop) })
+	if err := Find(ignore); err != nil {
+		t.Errorf("Find() = %v; 預期為 nil", err)
+	}
+}
+
+func TestIgnoreTopFunction(t *testing.T) {
+	ignore := IgnoreCurrent()
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go blockForever(stop)
+
+	// Goroutine 可能還沒開始執行，先等它真的阻塞在 blockForever 裡。
+	var top string
+	for top == "" {
+		for _, g := range Snapshot() {
+			if strings.HasSuffix(g.TopFunction, ".blockForever") {
+				top = g.TopFunction
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := Find(ignore, IgnoreTopFunction(top), Timeout(10*time.Millisecond)); err != nil {
+		t.Errorf("Find() = %v; 預期為 nil", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	Check(t)
+	done := make(chan struct{})
+	go func() { close(done) }()
+	<-done
+}
+
+// TestFilterParallelTests 以假的堆疊檢查：同時執行的另一個測試 (t.Parallel) 與它建立的 Goroutine
+// 不算洩漏，這個測試建立的以及找不到所屬測試的 Goroutine 仍然算。
+func TestFilterParallelTests(t *testing.T) {
+	dump := `goroutine 10 [running]:
+example.TestA(0xc000001)
+	/src/a_test.go:10 +0x19
+testing.tRunner(0xc000001, 0x1)
+	/go/src/testing/testing.go:1 +0x1
+created by testing.(*T).Run in goroutine 6
+	/go/src/testing/testing.go:2 +0x1
+
+goroutine 11 [chan receive]:
+example.TestB(0xc000002)
+	/src/b_test.go:10 +0x19
+testing.tRunner(0xc000002, 0x1)
+	/go/src/testing/testing.go:1 +0x1
+created by testing.(*T).Run in goroutine 6
+	/go/src/testing/testing.go:2 +0x1
+
+goroutine 12 [chan receive]:
+example.serve()
+	/src/b_test.go:20 +0x19
+created by example.TestB in goroutine 11
+	/src/b_test.go:12 +0x1
+
+goroutine 13 [select]:
+example.handle()
+	/src/b_test.go:30 +0x19
+created by example.serve in goroutine 12
+	/src/b_test.go:22 +0x1
+
+goroutine 14 [chan receive]:
+example.worker()
+	/src/a_test.go:20 +0x19
+created by example.TestA in goroutine 10
+	/src/a_test.go:12 +0x1
+
+goroutine 15 [chan send]:
+example.orphan()
+	/src/c_test.go:20 +0x19
+created by example.start in goroutine 99
+	/src/c_test.go:12 +0x1
+`
+	leaked := newConfig(nil).filter(parseStacks([]byte(dump)), 10)
+	var ids []int
+	for _, g := range leaked {
+		ids = append(ids, g.ID)
+	}
+	if want := []int{14, 15}; !slices.Equal(ids, want) {
+		t.Errorf("filter() = %v; 預期為 %v", ids, want)
+	}
+}
+
+func TestParseGoroutine(t *testing.T) {
+	block := `goroutine 42 [chan receive, 3 minutes]:
+main.worker(0x1, 0xc000012345)
+	/src/main.go:10 +0x19
+created by main.main in goroutine 1
+	/src/main.go:20 +0x76`
+
+	g, ok := parseGoroutine(block)
+	if !ok {
+		t.Fatal("parseGoroutine() failed")
+	}
+	testCases := []struct {
+		name      string
+		got, want any
+	}{
+		{"ID", g.ID, 42},
+		{"State", g.State, "chan receive"},
+		{"TopFunction", g.TopFunction, "main.worker"},
+		{"CreatedBy", g.CreatedBy, "main.main"},
+		{"CreatorID", g.CreatorID, 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.got != tc.want {
+				t.Errorf("got %v; 預期為 %v", tc.got, tc.want)
+			}
+		})
+	}
+}
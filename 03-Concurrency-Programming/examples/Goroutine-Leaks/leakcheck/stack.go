@@ -0,0 +1,118 @@
+package leakcheck
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Goroutine 是從 runtime.Stack 解析出來的一個 Goroutine 快照。
+type Goroutine struct {
+	// ID 是 runtime 分配的 Goroutine 編號。
+	ID int
+	// State 是方括號中的狀態，例如 "chan receive" 或 "sleep"。
+	State string
+	// TopFunction 是目前正在執行的函式，例如 "main.worker"。
+	TopFunction string
+	// CreatedBy 是建立這個 Goroutine 的函式；主 Goroutine 則為空字串。
+	CreatedBy string
+	// CreatorID 是建立這個 Goroutine 的 Goroutine 編號；主 Goroutine 則為 0。
+	CreatorID int
+	// Stack 是完整的堆疊文字，用於回報洩漏時印出。
+	Stack string
+}
+
+// String 回傳完整的堆疊文字。
+func (g Goroutine) String() string {
+	return g.Stack
+}
+
+// Snapshot 回傳目前所有存活的 Goroutine，包含呼叫者本身。
+func Snapshot() []Goroutine {
+	return parseStacks(allStacks())
+}
+
+// currentID 回傳呼叫者所在 Goroutine 的編號。
+func currentID() int {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	g, ok := parseGoroutine(string(buf))
+	if !ok {
+		return 0
+	}
+	return g.ID
+}
+
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func parseStacks(dump []byte) []Goroutine {
+	var gs []Goroutine
+	for _, block := range bytes.Split(dump, []byte("\n\n")) {
+		if g, ok := parseGoroutine(string(block)); ok {
+			gs = append(gs, g)
+		}
+	}
+	return gs
+}
+
+// parseGoroutine 解析如下格式的區塊：
+//
+//	goroutine 7 [chan receive]:
+//	main.main.func1()
+//		/tmp/main.go:3 +0x19
+//	created by main.main in goroutine 1
+//		/tmp/main.go:3 +0x76
+func parseGoroutine(block string) (Goroutine, bool) {
+	block = strings.TrimSpace(block)
+	header, rest, _ := strings.Cut(block, "\n")
+	if !strings.HasPrefix(header, "goroutine ") {
+		return Goroutine{}, false
+	}
+
+	idStr, state, ok := strings.Cut(strings.TrimPrefix(header, "goroutine "), " ")
+	if !ok {
+		return Goroutine{}, false
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return Goroutine{}, false
+	}
+	state = strings.TrimSuffix(strings.TrimPrefix(state, "["), "]:")
+	// 狀態可能帶有等待時間，例如 "chan receive, 2 minutes"。
+	state, _, _ = strings.Cut(state, ",")
+
+	g := Goroutine{ID: id, State: state, Stack: block}
+	for _, line := range strings.Split(rest, "\n") {
+		if strings.HasPrefix(line, "\t") || line == "" {
+			continue
+		}
+		if after, found := strings.CutPrefix(line, "created by "); found {
+			var creator string
+			g.CreatedBy, creator, _ = strings.Cut(after, " in goroutine ")
+			g.CreatorID, _ = strconv.Atoi(creator)
+			continue
+		}
+		if g.TopFunction == "" {
+			g.TopFunction = funcName(line)
+		}
+	}
+	return g, true
+}
+
+// funcName 從 "main.worker(0x1, 0xc000012345)" 取出 "main.worker"。
+func funcName(line string) string {
+	if i := strings.LastIndex(line, "("); i > 0 {
+		return line[:i]
+	}
+	return line
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+// search 模擬一個耗時不一的查詢。
+func search(query string, delay time.Duration) string {
+	time.Sleep(delay)
+	return "result for " + query
+}
+
+// firstLeaky 同時向多個來源查詢，只取最快的結果。
+// 因為 results 是無緩衝 channel，較慢的 Goroutine 在傳送時會永遠阻塞 —— 這就是 Goroutine 洩漏。
+func firstLeaky(query string) string {
+	results := make(chan string)
+	for _, d := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond} {
+		go func() {
+			results <- search(query, d)
+		}()
+	}
+	return <-results
+}
+
+// firstFixed 與 firstLeaky 相同，但 channel 的緩衝大小等於 Goroutine 數量，
+// 每個 Goroutine 都能完成傳送並結束。
+func firstFixed(query string) string {
+	delays := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
+	results := make(chan string, len(delays))
+	for _, d := range delays {
+		go func() {
+			results <- search(query, d)
+		}()
+	}
+	return <-results
+}
+
+func main() {
+	fmt.Println("--- Leaky Version ---")
+	ignore := leakcheck.IgnoreCurrent()
+	fmt.Println(firstLeaky("golang"))
+	if err := leakcheck.Find(ignore, leakcheck.Timeout(100*time.Millisecond)); err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println("--- Fixed Version ---")
+	ignore = leakcheck.IgnoreCurrent()
+	fmt.Println(firstFixed("golang"))
+	if err := leakcheck.Find(ignore); err != nil {
+		fmt.Println(err)
+	} else {
+		fmt.Println("No leaked goroutines.")
+	}
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"testing"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+func TestFirstFixedNoLeak(t *testing.T) {
+	leakcheck.Check(t)
+	if got := firstFixed("go"); got != "result for go" {
+		t.Errorf("firstFixed() = %q; 預期為 %q", got, "result for go")
+	}
+}
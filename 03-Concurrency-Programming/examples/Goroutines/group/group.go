@@ -0,0 +1,171 @@
+// Package group 提供一個類似 errgroup 的任務群組：
+// 一次啟動多個 Goroutine，等待它們全部完成，並把錯誤、取消與 panic 集中處理。
+//
+// 與單純使用 sync.WaitGroup 相比，Group 多了以下能力：
+//   - 任務回傳 error，Wait 會把錯誤交回給呼叫者。
+//   - 任一任務失敗時，其他任務會透過 context 收到取消通知。
+//   - SetLimit 可以限制同時執行的任務數量。
+//   - 任務中的 panic 會被捕捉並轉成帶有堆疊的 *PanicError。
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Option 用來調整 Group 的行為。
+type Option func(*Group)
+
+// JoinErrors 讓 Wait 回傳所有任務錯誤 (以 errors.Join 合併)，而不只是第一個錯誤。
+func JoinErrors() Option {
+	return func(g *Group) { g.joinErrors = true }
+}
+
+// Group 是一組共享同一個 context 的任務。
+// 零值的 Group 可以直接使用，但它不會在失敗時取消其他任務。
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	wg  sync.WaitGroup
+	sem chan struct{}
+
+	joinErrors bool
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// New 建立一個從 ctx 衍生的 Group。
+// 當第一個任務回傳錯誤 (或 Wait 返回) 時，傳給任務的 context 會被取消，
+// context.Cause 會回傳造成取消的錯誤。
+func New(ctx context.Context, opts ...Option) *Group {
+	ctx, cancel := context.WithCancelCause(ctx)
+	g := &Group{ctx: ctx, cancel: cancel}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Context 回傳傳給每個任務的 context。
+func (g *Group) Context() context.Context {
+	if g.ctx == nil {
+		return context.Background()
+	}
+	return g.ctx
+}
+
+// SetLimit 限制同時執行的任務數量最多為 n；n 為負數代表不限制。
+// 只能在沒有任務執行時呼叫，否則會 panic。
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic(fmt.Errorf("group: modify limit while %v goroutines in the group are still active", len(g.sem)))
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go 在新的 Goroutine 中執行 fn。
+// 若已經達到 SetLimit 設定的上限，Go 會阻塞直到有任務完成。
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.start(fn)
+}
+
+// TryGo 只有在未達到上限時才啟動 fn，並回傳是否成功啟動。
+func (g *Group) TryGo(fn func(ctx context.Context) error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(fn)
+	return true
+}
+
+func (g *Group) start(fn func(ctx context.Context) error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		if err := g.run(fn); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+// run 執行 fn，並把 panic 轉換成 *PanicError。
+func (g *Group) run(fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return fn(g.Context())
+}
+
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+func (g *Group) fail(err error) {
+	g.mu.Lock()
+	g.errs = append(g.errs, err)
+	first := len(g.errs) == 1
+	g.mu.Unlock()
+
+	if first && g.cancel != nil {
+		g.cancel(err)
+	}
+}
+
+// Wait 等待所有任務結束，並回傳第一個錯誤；
+// 若使用 JoinErrors 建立，則回傳所有錯誤合併後的結果。
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel(nil)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.errs) == 0 {
+		return nil
+	}
+	if g.joinErrors {
+		return errors.Join(g.errs...)
+	}
+	return g.errs[0]
+}
+
+// PanicError 包裝被捕捉的 panic，並保留發生當下的堆疊。
+// 除了 Group 的任務之外，workerpool、singleflight 與 actor 捕捉到 panic 時也回傳這個型別。
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n\n%s", e.Value, e.Stack)
+}
+
+// Unwrap 在 panic 的值本身是 error 時回傳它，讓 errors.Is/As 可以穿透。
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+func TestWaitReturnsFirstError(t *testing.T) {
+	leakcheck.Check(t)
+	errBoom := errors.New("boom")
+
+	g := New(context.Background())
+	g.Go(func(ctx context.Context) error { return errBoom })
+	g.Go(func(ctx context.Context) error {
+		// 兄弟任務失敗後，這裡會收到取消通知而不是等滿一分鐘。
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Minute):
+			return nil
+		}
+	})
+
+	if err := g.Wait(); !errors.Is(err, errBoom) {
+		t.Errorf("Wait() = %v; 預期為 %v", err, errBoom)
+	}
+	if cause := context.Cause(g.Context()); !errors.Is(cause, errBoom) {
+		t.Errorf("context.Cause() = %v; 預期為 %v", cause, errBoom)
+	}
+}
+
+func TestJoinErrors(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+
+	g := New(context.Background(), JoinErrors())
+	g.Go(func(ctx context.Context) error { return errA })
+	g.Go(func(ctx context.Context) error { return errB })
+
+	err := g.Wait()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Wait() = %v; 預期同時包含 %v 與 %v", err, errA, errB)
+	}
+}
+
+func TestWaitWithoutErrors(t *testing.T) {
+	var g Group
+	var n atomic.Int32
+	for i := 0; i < 10; i++ {
+		g.Go(func(ctx context.Context) error {
+			n.Add(1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Errorf("Wait() = %v; 預期為 nil", err)
+	}
+	if n.Load() != 10 {
+		t.Errorf("ran %d tasks; 預期為 10", n.Load())
+	}
+}
+
+func TestSetLimit(t *testing.T) {
+	const limit = 3
+	g := New(context.Background())
+	g.SetLimit(limit)
+
+	var active, peak atomic.Int32
+	for i := 0; i < 20; i++ {
+		g.Go(func(ctx context.Context) error {
+			cur := active.Add(1)
+			for {
+				old := peak.Load()
+				if cur <= old || peak.CompareAndSwap(old, cur) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			active.Add(-1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if peak.Load() > limit {
+		t.Errorf("peak concurrency = %d; 預期不超過 %d", peak.Load(), limit)
+	}
+}
+
+func TestTryGo(t *testing.T) {
+	g := New(context.Background())
+	g.SetLimit(1)
+
+	release := make(chan struct{})
+	if !g.TryGo(func(ctx context.Context) error { <-release; return nil }) {
+		t.Fatal("first TryGo should succeed")
+	}
+	if g.TryGo(func(ctx context.Context) error { return nil }) {
+		t.Error("second TryGo should fail while the limit is reached")
+	}
+	close(release)
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPanicBecomesError(t *testing.T) {
+	errInner := errors.New("inner")
+
+	g := New(context.Background())
+	g.Go(func(ctx context.Context) error { panic(errInner) })
+
+	err := g.Wait()
+	var pe *PanicError
+	if !errors.As(err, &pe) {
+		t.Fatalf("Wait() = %v; 預期為 *PanicError", err)
+	}
+	if !errors.Is(err, errInner) {
+		t.Errorf("PanicError should unwrap to the panic value")
+	}
+	if !strings.Contains(string(pe.Stack), "group_test.go") {
+		t.Errorf("PanicError.Stack should point at the panicking task, got:\n%s", pe.Stack)
+	}
+}
@@ -1,54 +1,109 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutines/group"
 )
 
 // worker 函式模擬一個需要一些時間來完成的工作。
-// 它接收一個指向 sync.WaitGroup 的指標，以便在完成時通知主程式。
-func worker(id int, wg *sync.WaitGroup) {
+// 它接收一個指向 sync.WaitGroup 的指標，以便在完成時通知主程式；
+// clk 則提供時間來源，測試時可以換成假時鐘。
+func worker(clk clock.Clock, id int, wg *sync.WaitGroup) {
 	// defer 確保在函式返回前，一定會呼叫 wg.Done()
 	defer wg.Done()
 
 	fmt.Printf("Worker %d starting\n", id)
 
 	// 模擬耗時的工作
-	time.Sleep(time.Second)
+	clk.Sleep(time.Second)
 
 	fmt.Printf("Worker %d done\n", id)
 }
 
-func main() {
+// runWorkers 啟動 n 個 worker goroutines，並等待它們全部完成。
+func runWorkers(clk clock.Clock, n int) {
 	// WaitGroup 用於等待一組 Goroutine 完成。
 	var wg sync.WaitGroup
 
-	fmt.Println("--- WaitGroup Example ---")
-
-	// 啟動 3 個 worker goroutines。
-	for i := 1; i <= 3; i++ {
+	for i := 1; i <= n; i++ {
 		// 每啟動一個 Goroutine，計數器就加 1。
 		wg.Add(1)
 
 		// 使用 go 關鍵字啟動一個新的 Goroutine。
-		go worker(i, &wg)
+		go worker(clk, i, &wg)
 	}
 
 	// Wait() 會阻塞，直到 WaitGroup 的計數器變為 0。
 	fmt.Println("Waiting for workers to finish...")
 	wg.Wait()
 	fmt.Println("All workers done.")
+}
 
-	fmt.Println("\n--- Anonymous Goroutine Example ---")
+// runAnonymous 以匿名函式啟動一個 Goroutine，並等待它完成。
+func runAnonymous(clk clock.Clock) {
+	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		fmt.Println("I am an anonymous goroutine!")
-		time.Sleep(500 * time.Millisecond)
+		clk.Sleep(500 * time.Millisecond)
 	}()
 
 	fmt.Println("Waiting for the anonymous goroutine...")
 	wg.Wait()
 	fmt.Println("Anonymous goroutine finished.")
 }
+
+// ctxWorker 與 worker 相同，但可以回傳錯誤，並在 ctx 被取消時提早結束。
+func ctxWorker(ctx context.Context, clk clock.Clock, id int) error {
+	fmt.Printf("Worker %d starting\n", id)
+
+	if id == 2 {
+		return errors.New("worker 2 failed")
+	}
+
+	select {
+	case <-clk.After(time.Second):
+		fmt.Printf("Worker %d done\n", id)
+		return nil
+	case <-ctx.Done():
+		fmt.Printf("Worker %d canceled: %v\n", id, context.Cause(ctx))
+		return ctx.Err()
+	}
+}
+
+// runGroup 使用 group.Group 取代 WaitGroup：一個 worker 失敗時，其餘 worker 會被取消。
+func runGroup(clk clock.Clock, n int) error {
+	g := group.New(context.Background())
+	// 最多同時執行 2 個 worker。
+	g.SetLimit(2)
+
+	for i := 1; i <= n; i++ {
+		g.Go(func(ctx context.Context) error {
+			return ctxWorker(ctx, clk, i)
+		})
+	}
+	return g.Wait()
+}
+
+func main() {
+	clk := clock.Real()
+
+	fmt.Println("--- WaitGroup Example ---")
+	// 啟動 3 個 worker goroutines。
+	runWorkers(clk, 3)
+
+	fmt.Println("\n--- Anonymous Goroutine Example ---")
+	runAnonymous(clk)
+
+	fmt.Println("\n--- Group Example ---")
+	if err := runGroup(clk, 3); err != nil {
+		fmt.Println("Group finished with error:", err)
+	}
+}
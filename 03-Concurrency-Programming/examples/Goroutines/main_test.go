@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+func TestRunWorkersNoLeak(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(time.Unix(0, 0))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runWorkers(fake, 3)
+	}()
+
+	// 三個 worker 並行執行，所以只要前進一秒就能全部完成。
+	fake.BlockUntil(3)
+	fake.Advance(time.Second)
+	<-done
+}
+
+func TestRunAnonymousNoLeak(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(time.Unix(0, 0))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runAnonymous(fake)
+	}()
+	fake.RunUntil(done)
+}
+
+func TestRunGroupCancelsSiblings(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(time.Unix(0, 0))
+
+	errc := make(chan error, 1)
+	go func() { errc <- runGroup(fake, 3) }()
+
+	// 假時鐘完全不前進：worker 2 失敗後，worker 1 必須因為取消而結束，
+	// worker 3 則一開始就看到已取消的 ctx。
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("runGroup() = nil; 預期回傳 worker 2 的錯誤")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runGroup() did not return after a worker failed")
+	}
+}
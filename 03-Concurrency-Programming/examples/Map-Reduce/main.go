@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Map-Reduce/mapreduce"
+)
+
+var (
+	workersFlag = flag.String("workers", "1,2,4,8", "以逗號分隔的 worker 數量，每個數量各執行一次以比較吞吐量")
+	topFlag     = flag.Int("top", 10, "顯示出現次數最多的前幾個詞")
+	chunkFlag   = flag.Int("chunk", 256, "每個區塊的大小 (KiB)")
+	sampleFlag  = flag.Int("sample", 8, "沒有指定檔案時，產生幾 MiB 的範例文字")
+)
+
+// sampleText 是沒有指定檔案時使用的範例文字，混合了英文與中文。
+const sampleText = `Go is an open source programming language that makes it simple to build
+secure, scalable systems. Concurrency is not parallelism: concurrency is about
+dealing with lots of things at once, parallelism is about doing lots of things at once.
+Go 語言的並發模型讓我們可以輕鬆地把工作分給多個 Goroutine 同時處理，
+再透過 Channel 把結果合併起來，這正是 Map-Reduce 的精神。
+Don't communicate by sharing memory; share memory by communicating.
+`
+
+// sample 產生大約 mib MiB 的範例輸入。
+func sample(mib int) []mapreduce.Input {
+	n := max(mib<<20/len(sampleText), 1)
+	return []mapreduce.Input{mapreduce.Text("sample", strings.Repeat(sampleText, n))}
+}
+
+// parseWorkers 解析 "1,2,4,8" 這樣的 worker 數量清單。
+func parseWorkers(s string) ([]int, error) {
+	var counts []int
+	for _, field := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid worker count %q", field)
+		}
+		counts = append(counts, n)
+	}
+	return counts, nil
+}
+
+// run 以每一種 worker 數量統計一次 inputs，印出前 top 名的詞，以及吞吐量與加速比的比較表。
+func run(ctx context.Context, out io.Writer, inputs []mapreduce.Input, workers []int, top, chunkSize int) error {
+	var results []*mapreduce.Result
+	for _, n := range workers {
+		res, err := mapreduce.Run(ctx, inputs,
+			mapreduce.WithWorkers(n),
+			mapreduce.WithTopN(top),
+			mapreduce.WithChunkSize(chunkSize),
+		)
+		if err != nil {
+			return err
+		}
+		results = append(results, res)
+	}
+
+	// 不論幾個 worker，統計結果都一樣，因此只印出第一次的結果。
+	first := results[0]
+	fmt.Fprintf(out, "%d words, %d unique, %.1f MiB in %d chunks\n\n",
+		first.Words, first.Unique, float64(first.Bytes)/(1<<20), first.Chunks)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "rank\tword\tcount\t")
+	for i, wc := range first.Top {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t\n", i+1, wc.Word, wc.Count)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(out)
+	tw = tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "workers\telapsed\tMiB/s\tspeedup\t")
+	for _, res := range results {
+		fmt.Fprintf(tw, "%d\t%v\t%.1f\t%.2fx\t\n", res.Workers, res.Elapsed.Round(10*time.Microsecond),
+			res.Throughput(), first.Elapsed.Seconds()/res.Elapsed.Seconds())
+	}
+	return tw.Flush()
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file or directory ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	workers, err := parseWorkers(*workersFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	inputs := sample(*sampleFlag)
+	if flag.NArg() > 0 {
+		if inputs, err = mapreduce.Files(flag.Args()...); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fmt.Println("--- Map-Reduce Word Count ---")
+	if err := run(context.Background(), os.Stdout, inputs, workers, *topFlag, *chunkFlag<<10); err != nil {
+		log.Fatal(err)
+	}
+}
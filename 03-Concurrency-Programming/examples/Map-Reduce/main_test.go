@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Map-Reduce/mapreduce"
+)
+
+func TestParseWorkers(t *testing.T) {
+	testCases := []struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{"1,2,4,8", []int{1, 2, 4, 8}, false},
+		{" 3 , 6 ", []int{3, 6}, false},
+		{"4", []int{4}, false},
+		{"0", nil, true},
+		{"1,,2", nil, true},
+		{"two", nil, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := parseWorkers(tc.input)
+			if (err != nil) != tc.wantErr || !slices.Equal(got, tc.want) {
+				t.Errorf("parseWorkers(%q) = (%v, %v); 預期為 %v (錯誤: %v)", tc.input, got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	leakcheck.Check(t)
+	var out bytes.Buffer
+	inputs := []mapreduce.Input{mapreduce.Text("a", "go gopher go 語言 go 語")}
+	if err := run(context.Background(), &out, inputs, []int{1, 2}, 3, 8); err != nil {
+		t.Fatalf("run() 錯誤 = %v", err)
+	}
+
+	// 把每一列的欄位以單一空白連接，忽略表格對齊用的空白。
+	var lines []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		lines = append(lines, strings.Join(strings.Fields(line), " "))
+	}
+	for _, want := range []string{"1 go 3", "2 語 2", "3 gopher 1"} {
+		if !slices.Contains(lines, want) {
+			t.Errorf("輸出缺少 %q:\n%s", want, out.String())
+		}
+	}
+	if !strings.HasPrefix(lines[0], "7 words, 4 unique") {
+		t.Errorf("摘要 = %q; 預期以 \"7 words, 4 unique\" 開頭", lines[0])
+	}
+	if n := strings.Count(out.String(), "x\n"); n != 2 {
+		t.Errorf("吞吐量表有 %d 列; 預期為 2 列:\n%s", n, out.String())
+	}
+}
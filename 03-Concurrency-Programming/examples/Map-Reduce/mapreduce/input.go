@@ -0,0 +1,125 @@
+package mapreduce
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Input 是一個要統計的文字來源。Open 在處理時才被呼叫，因此大量檔案不會同時開啟。
+type Input struct {
+	Name string
+	Open func() (io.ReadCloser, error)
+}
+
+// Text 回傳內容為 s 的 Input，方便測試與範例使用。
+func Text(name, s string) Input {
+	return Input{Name: name, Open: func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(s)), nil
+	}}
+}
+
+// Files 把檔案與目錄路徑展開成 Input；目錄會被遞迴走訪，並略過以 "." 開頭的檔案與目錄。
+func Files(paths ...string) ([]Input, error) {
+	var inputs []Input
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() {
+				inputs = append(inputs, fileInput(path))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+func fileInput(path string) Input {
+	return Input{Name: path, Open: func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}}
+}
+
+// split 依序讀取每個 Input，切成大約 size bytes 的區塊並交給 emit。
+// 區塊只會在空白或中日文字元之後切開，因此不會把一個詞切成兩半 (比區塊還長的詞除外)，
+// 也永遠不會把一個 UTF-8 字元切成兩半。
+func split(ctx context.Context, inputs []Input, size int, emit func([]byte) error) error {
+	for _, in := range inputs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rc, err := in.Open()
+		if err != nil {
+			return err
+		}
+		err = splitReader(rc, size, emit)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("mapreduce: %s: %w", in.Name, err)
+		}
+	}
+	return nil
+}
+
+func splitReader(r io.Reader, size int, emit func([]byte) error) error {
+	buf := make([]byte, 0, size)
+	for {
+		n, err := io.ReadFull(r, buf[len(buf):size])
+		buf = buf[:len(buf)+n]
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			if len(buf) == 0 {
+				return nil
+			}
+			return emit(buf)
+		}
+		if err != nil {
+			return err
+		}
+
+		// 區塊會交給其他 Goroutine，因此剩下的部分要搬到新的緩衝區。
+		cut := lastBoundary(buf)
+		next := make([]byte, len(buf)-cut, size)
+		copy(next, buf[cut:])
+		if err := emit(buf[:cut]); err != nil {
+			return err
+		}
+		buf = next
+	}
+}
+
+// lastBoundary 回傳 buf 中最後一個可以安全切開的位置。找不到時 (一個比區塊還長的詞) 只好切開這個詞，
+// 但仍然退回最後一個完整的 UTF-8 字元之後，不把一個字元切成兩半。
+func lastBoundary(buf []byte) int {
+	for end := len(buf); end > 0; {
+		r, size := utf8.DecodeLastRune(buf[:end])
+		if r != utf8.RuneError && isBoundary(r) {
+			return end
+		}
+		end -= size
+	}
+	start := len(buf) - 1
+	for start > 0 && !utf8.RuneStart(buf[start]) {
+		start--
+	}
+	if start <= 0 || utf8.FullRune(buf[start:]) {
+		return len(buf)
+	}
+	return start
+}
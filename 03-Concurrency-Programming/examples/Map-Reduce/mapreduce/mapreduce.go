@@ -0,0 +1,257 @@
+// Package mapreduce 用 Map-Reduce 的方式平行統計大量文字的詞頻。
+//
+// Fan-Out/Fan-In 範例只處理假的整數任務；這裡把同樣的模式套用在真實的資料上：
+//
+//  1. Split：把檔案 (或整個目錄) 切成大約固定大小的區塊，切點不會落在詞的中間。
+//  2. Map：區塊交給 workerpool 平行切詞並計數。每個區塊先在本地合併 (combiner)，
+//     再依詞的雜湊值分成 R 份，因此同一個詞一定會送到同一個 reducer。
+//  3. Shuffle / Reduce：R 個 reducer 各自合併自己那一份的部分結果，並找出自己的前 N 名。
+//  4. 最後合併 R 份前 N 名得到全域的前 N 名；因為每個詞只屬於一個 reducer，結果是精確的。
+//
+// 切詞支援 Unicode：拉丁字母以外的文字也能正確處理，中文與日文則以字元切分 (見 Tokenize)。
+package mapreduce
+
+import (
+	"cmp"
+	"container/heap"
+	"context"
+	"hash/maphash"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Concurrency-Patterns/workerpool"
+)
+
+// WordCount 是一個詞與它出現的次數。
+type WordCount struct {
+	Word  string
+	Count int
+}
+
+// Result 是一次統計的結果與效能數據。
+type Result struct {
+	Top     []WordCount // 出現次數最多的詞，次數相同時依字典順序排列
+	Words   int         // 詞的總數
+	Unique  int         // 不同詞的數量
+	Bytes   int64       // 處理的位元組數
+	Chunks  int         // 區塊數量
+	Workers int         // map worker 的數量
+	Elapsed time.Duration
+}
+
+// Throughput 回傳每秒處理的 MB 數。
+func (r *Result) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) / (1 << 20) / r.Elapsed.Seconds()
+}
+
+// config 保存 Option 設定的值。
+type config struct {
+	workers   int
+	reducers  int
+	chunkSize int
+	topN      int
+}
+
+// Option 用來調整 Run 的行為。
+type Option func(*config)
+
+// WithWorkers 設定 map worker 的數量，預設為 runtime.GOMAXPROCS(0)。
+func WithWorkers(n int) Option {
+	return func(c *config) { c.workers = n }
+}
+
+// WithReducers 設定 reducer 的數量，預設與 worker 數量相同。
+func WithReducers(n int) Option {
+	return func(c *config) { c.reducers = n }
+}
+
+// WithChunkSize 設定每個區塊的大約大小 (bytes)，預設為 1 MiB。
+func WithChunkSize(n int) Option {
+	return func(c *config) { c.chunkSize = n }
+}
+
+// WithTopN 設定要回傳前幾名，預設為 10；n <= 0 代表回傳全部的詞。
+func WithTopN(n int) Option {
+	return func(c *config) { c.topN = n }
+}
+
+// partial 是一個區塊的部分結果，依 reducer 分成多份。
+type partial []map[string]int
+
+// Run 統計所有 inputs 的詞頻。任何一個 Input 讀取失敗或 ctx 被取消時，
+// 會停止所有工作並回傳錯誤。
+func Run(ctx context.Context, inputs []Input, opts ...Option) (*Result, error) {
+	cfg := config{workers: runtime.GOMAXPROCS(0), chunkSize: 1 << 20, topN: 10}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cfg.workers = max(cfg.workers, 1)
+	if cfg.reducers <= 0 {
+		cfg.reducers = cfg.workers
+	}
+	cfg.chunkSize = max(cfg.chunkSize, utf8.UTFMax) // 至少要能容納一個完整的字元
+
+	start := time.Now()
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// Reduce：每個 reducer 只處理屬於自己的詞，不需要任何鎖。
+	shards := make([]chan map[string]int, cfg.reducers)
+	reduced := make([]reduceResult, cfg.reducers)
+	var reducers sync.WaitGroup
+	for i := range shards {
+		shards[i] = make(chan map[string]int, cfg.workers)
+		reducers.Add(1)
+		go func() {
+			defer reducers.Done()
+			reduced[i] = reduce(shards[i], cfg.topN)
+		}()
+	}
+
+	// Split + Map：佇列長度等於 worker 數量，讀檔的速度會被 map 的速度限制 (backpressure)。
+	pool := workerpool.New[partial](cfg.workers,
+		workerpool.WithQueueSize(cfg.workers),
+		workerpool.WithResults(false),
+	)
+	seed := maphash.MakeSeed()
+	res := &Result{Workers: cfg.workers}
+	produced := make(chan struct{})
+	go func() {
+		defer close(produced)
+		err := split(ctx, inputs, cfg.chunkSize, func(chunk []byte) error {
+			res.Bytes += int64(len(chunk))
+			res.Chunks++
+			_, err := pool.Submit(ctx, func(ctx context.Context) (partial, error) {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				return mapChunk(chunk, seed, cfg.reducers), nil
+			})
+			return err
+		})
+		if err != nil {
+			cancel(err)
+			pool.ShutdownNow()
+		}
+		pool.Shutdown(context.Background())
+	}()
+
+	// Shuffle：把每個部分結果分送給對應的 reducer。
+	for r := range pool.Results() {
+		if r.Err != nil {
+			cancel(r.Err)
+			pool.ShutdownNow()
+			continue
+		}
+		if ctx.Err() != nil {
+			continue
+		}
+		for i, counts := range r.Value {
+			if len(counts) > 0 {
+				shards[i] <- counts
+			}
+		}
+	}
+	<-produced
+	for _, ch := range shards {
+		close(ch)
+	}
+	reducers.Wait()
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+
+	// 每個詞只屬於一個 reducer，因此合併各自的前 N 名就是全域的前 N 名。
+	for _, rr := range reduced {
+		res.Top = append(res.Top, rr.top...)
+		res.Words += rr.words
+		res.Unique += rr.unique
+	}
+	slices.SortFunc(res.Top, compareCounts)
+	if cfg.topN > 0 && len(res.Top) > cfg.topN {
+		res.Top = res.Top[:cfg.topN]
+	}
+	res.Elapsed = time.Since(start)
+	return res, nil
+}
+
+// mapChunk 是 map 階段：切詞、在本地計數，再依雜湊值分成 n 份。
+func mapChunk(chunk []byte, seed maphash.Seed, n int) partial {
+	parts := make(partial, n)
+	for i := range parts {
+		parts[i] = make(map[string]int)
+	}
+	Tokenize(chunk, func(word []byte) {
+		i := maphash.Bytes(seed, word) % uint64(n)
+		parts[i][string(word)]++
+	})
+	return parts
+}
+
+// reduceResult 是一個 reducer 的結果。
+type reduceResult struct {
+	top    []WordCount
+	words  int
+	unique int
+}
+
+// reduce 合併送進 in 的所有部分結果，並回傳其中的前 n 名。
+func reduce(in <-chan map[string]int, n int) reduceResult {
+	counts := make(map[string]int)
+	var words int
+	for part := range in {
+		for w, c := range part {
+			counts[w] += c
+			words += c
+		}
+	}
+	return reduceResult{top: topN(counts, n), words: words, unique: len(counts)}
+}
+
+// compareCounts 依次數由多到少排序，次數相同時依字典順序。
+func compareCounts(a, b WordCount) int {
+	if c := cmp.Compare(b.Count, a.Count); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Word, b.Word)
+}
+
+// topN 用大小為 n 的最小堆積找出前 n 名，只需要 O(U log n) 的時間。
+func topN(counts map[string]int, n int) []WordCount {
+	if n <= 0 || n > len(counts) {
+		n = len(counts)
+	}
+	h := make(minHeap, 0, n)
+	for w, c := range counts {
+		wc := WordCount{Word: w, Count: c}
+		switch {
+		case len(h) < n:
+			heap.Push(&h, wc)
+		case compareCounts(wc, h[0]) < 0:
+			h[0] = wc
+			heap.Fix(&h, 0)
+		}
+	}
+	slices.SortFunc(h, compareCounts)
+	return h
+}
+
+// minHeap 的頂端是目前前 n 名中排名最後的詞。
+type minHeap []WordCount
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return compareCounts(h[i], h[j]) > 0 }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(WordCount)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
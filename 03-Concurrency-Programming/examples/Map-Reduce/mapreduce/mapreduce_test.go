@@ -0,0 +1,228 @@
+package mapreduce
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+// words 收集 Tokenize 的結果。
+func words(text string) []string {
+	var out []string
+	Tokenize([]byte(text), func(w []byte) { out = append(out, string(w)) })
+	return out
+}
+
+func TestTokenize(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"英文與標點", "Hello, World! hello-world", []string{"hello", "world", "hello", "world"}},
+		{"撇號", "Don't stop 'til it’s done'", []string{"don't", "stop", "til", "it's", "done"}},
+		{"數字", "Go 1.24 has 3 new features", []string{"go", "1", "24", "has", "3", "new", "features"}},
+		{"重音符號", "Café CAFÉ naïve", []string{"café", "café", "naïve"}},
+		{"中文以字元切分", "你好，世界", []string{"你", "好", "世", "界"}},
+		{"中英混合", "學習Go語言", []string{"學", "習", "go", "語", "言"}},
+		{"日文假名", "ひらがなカタカナ", []string{"ひ", "ら", "が", "な", "カ", "タ", "カ", "ナ"}},
+		{"韓文以空白分詞", "안녕하세요 세계", []string{"안녕하세요", "세계"}},
+		{"空字串", "", nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := words(tc.text); !slices.Equal(got, tc.want) {
+				t.Errorf("Tokenize(%q) = %q; 預期為 %q", tc.text, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplitNeverCutsWords(t *testing.T) {
+	text := strings.Repeat("The quick brown fox 跳過了 lazy dogs; café naïve don't. ", 50)
+	want := words(text)
+
+	// 只要區塊比最長的詞 (naïve，6 bytes) 大，不論怎麼切都不會切斷詞。
+	for _, size := range []int{7, 8, 13, 100, 1 << 20} {
+		var chunks [][]byte
+		err := splitReader(strings.NewReader(text), size, func(chunk []byte) error {
+			chunks = append(chunks, chunk)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("size %d: splitReader() 錯誤 = %v", size, err)
+		}
+
+		var got []string
+		for _, c := range chunks {
+			got = append(got, words(string(c))...)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("size %d: 分塊後的詞與原文不同 (%d 個詞; 預期 %d 個)", size, len(got), len(want))
+		}
+		if joined := bytes.Join(chunks, nil); string(joined) != text {
+			t.Errorf("size %d: 區塊接起來與原文不同", size)
+		}
+	}
+}
+
+func TestSplitLongMultibyteWord(t *testing.T) {
+	// 韓文以空白分詞，每個字元 3 bytes，一個詞比區塊還長時只能把詞切開，但不能切斷字元。
+	text := strings.Repeat("안녕하세요반갑습니다 ", 20)
+	want := strings.Join(words(text), "")
+
+	for _, size := range []int{4, 7, 8, 16} {
+		var chunks [][]byte
+		err := splitReader(strings.NewReader(text), size, func(chunk []byte) error {
+			chunks = append(chunks, chunk)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("size %d: splitReader() 錯誤 = %v", size, err)
+		}
+
+		var got []string
+		for i, c := range chunks {
+			if !utf8.Valid(c) {
+				t.Fatalf("size %d: 第 %d 個區塊 %q 不是合法的 UTF-8", size, i, c)
+			}
+			got = append(got, words(string(c))...)
+		}
+		if joined := strings.Join(got, ""); joined != want {
+			t.Errorf("size %d: 分塊後的字元與原文不同", size)
+		}
+		if joined := bytes.Join(chunks, nil); string(joined) != text {
+			t.Errorf("size %d: 區塊接起來與原文不同", size)
+		}
+	}
+}
+
+// sequential 是不分塊、不平行的參考實作。
+func sequential(texts ...string) map[string]int {
+	counts := make(map[string]int)
+	for _, text := range texts {
+		for _, w := range words(text) {
+			counts[w]++
+		}
+	}
+	return counts
+}
+
+func TestRunMatchesSequential(t *testing.T) {
+	leakcheck.Check(t)
+	texts := []string{
+		strings.Repeat("to be or not to be, that is the question. ", 200),
+		strings.Repeat("學而時習之，不亦說乎？有朋自遠方來，不亦樂乎？", 100),
+		"Whether 'tis nobler in the mind to suffer",
+	}
+	inputs := []Input{Text("hamlet", texts[0]), Text("論語", texts[1]), Text("more", texts[2])}
+	want := sequential(texts...)
+	wantTop := topN(want, 5)
+
+	for _, workers := range []int{1, 2, 8} {
+		res, err := Run(context.Background(), inputs,
+			WithWorkers(workers), WithChunkSize(64), WithTopN(5))
+		if err != nil {
+			t.Fatalf("workers=%d: Run() 錯誤 = %v", workers, err)
+		}
+		if !slices.Equal(res.Top, wantTop) {
+			t.Errorf("workers=%d: Top = %v; 預期為 %v", workers, res.Top, wantTop)
+		}
+		if res.Unique != len(want) {
+			t.Errorf("workers=%d: Unique = %d; 預期為 %d", workers, res.Unique, len(want))
+		}
+		total := 0
+		for _, c := range want {
+			total += c
+		}
+		if res.Words != total || res.Bytes != int64(len(texts[0])+len(texts[1])+len(texts[2])) {
+			t.Errorf("workers=%d: Words = %d, Bytes = %d; 預期為 %d, %d", workers, res.Words, res.Bytes, total, len(texts[0])+len(texts[1])+len(texts[2]))
+		}
+	}
+}
+
+func TestTopNTies(t *testing.T) {
+	counts := map[string]int{"b": 2, "a": 2, "c": 3, "d": 1}
+	got := topN(counts, 3)
+	want := []WordCount{{"c", 3}, {"a", 2}, {"b", 2}}
+	if !slices.Equal(got, want) {
+		t.Errorf("topN = %v; 預期為 %v", got, want)
+	}
+	if all := topN(counts, 0); len(all) != 4 {
+		t.Errorf("topN(0) 回傳 %d 個; 預期全部 4 個", len(all))
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	leakcheck.Check(t)
+	errOpen := errors.New("disk on fire")
+	broken := Input{Name: "broken", Open: func() (io.ReadCloser, error) { return nil, errOpen }}
+	big := Text("big", strings.Repeat("word ", 10000))
+
+	if _, err := Run(context.Background(), []Input{big, broken}, WithChunkSize(16)); !errors.Is(err, errOpen) {
+		t.Errorf("開檔失敗時 Run() 錯誤 = %v; 預期為 %v", err, errOpen)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, []Input{big}); !errors.Is(err, context.Canceled) {
+		t.Errorf("ctx 取消時 Run() 錯誤 = %v; 預期為 context.Canceled", err)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.txt", "go go go")
+	write("sub/b.txt", "gopher")
+	write(".hidden", "secret")
+	write(".git/config", "secret")
+
+	inputs, err := Files(dir)
+	if err != nil {
+		t.Fatalf("Files() 錯誤 = %v", err)
+	}
+	res, err := Run(context.Background(), inputs)
+	if err != nil {
+		t.Fatalf("Run() 錯誤 = %v", err)
+	}
+	want := []WordCount{{"go", 3}, {"gopher", 1}}
+	if len(inputs) != 2 || !slices.Equal(res.Top, want) {
+		t.Errorf("%d 個檔案, Top = %v; 預期為 2 個檔案與 %v", len(inputs), res.Top, want)
+	}
+
+	if _, err := Files(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Files() 對不存在的路徑預期回傳錯誤")
+	}
+}
+
+func BenchmarkRun(b *testing.B) {
+	input := Text("bench", strings.Repeat("the quick brown fox jumps over the lazy dog 敏捷的棕色狐狸 ", 20000))
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for b.Loop() {
+				res, err := Run(context.Background(), []Input{input}, WithWorkers(workers), WithChunkSize(64<<10))
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.SetBytes(res.Bytes)
+			}
+		})
+	}
+}
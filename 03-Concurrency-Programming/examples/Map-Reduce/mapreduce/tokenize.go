@@ -0,0 +1,74 @@
+package mapreduce
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenize 把 text 切成詞，並對每個詞呼叫 emit。
+//
+// 切詞規則：
+//   - 連續的字母、數字與組合記號 (例如重音符號) 組成一個詞，並轉成小寫。
+//   - 夾在字母之間的撇號 (don't、it’s) 屬於同一個詞。
+//   - 中文、日文的漢字與假名沒有空白分隔，每一個字元各自是一個詞 (以字元切分)。
+//   - 其他字元 (空白、標點、符號) 都是分隔。
+//
+// 為了避免配置記憶體，傳給 emit 的 word 只在呼叫期間有效，需要保存時請複製 (例如 string(word))。
+func Tokenize(text []byte, emit func(word []byte)) {
+	var word []byte
+	flush := func() {
+		if len(word) > 0 {
+			emit(word)
+			word = word[:0]
+		}
+	}
+
+	for i := 0; i < len(text); {
+		r, size := rune(text[i]), 1
+		if r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRune(text[i:])
+		}
+		i += size
+
+		switch {
+		case isCJK(r):
+			flush()
+			word = utf8.AppendRune(word, r)
+			flush()
+		case isWordRune(r):
+			word = utf8.AppendRune(word, unicode.ToLower(r))
+		case isApostrophe(r) && len(word) > 0:
+			if next, _ := utf8.DecodeRune(text[i:]); unicode.IsLetter(next) && !isCJK(next) {
+				word = utf8.AppendRune(word, '\'')
+				continue
+			}
+			flush()
+		default:
+			flush()
+		}
+	}
+	flush()
+}
+
+// isCJK 回報 r 是否為以字元切分的漢字或假名。
+// 韓文 (Hangul) 以空白分詞，因此和拉丁字母一樣處理。
+func isCJK(r rune) bool {
+	// 0x2E80 之前沒有漢字與假名，先排除可以省下大部分的表格查詢。
+	return r >= 0x2E80 && unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+func isWordRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9'
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’'
+}
+
+// isBoundary 回報在 r 之後切開是否一定不會把一個詞切成兩半。
+func isBoundary(r rune) bool {
+	return unicode.IsSpace(r) || isCJK(r)
+}
@@ -1,22 +1,37 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Select/race"
 )
 
-func main() {
-	// --- Basic Select ---
-	fmt.Println("---"Basic Select Example---")
+// slowQuery 模擬一個需要 d 才能完成、並且會尊重 ctx 取消的查詢。
+func slowQuery(clk clock.Clock, name string, d time.Duration) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		select {
+		case <-clk.After(d):
+			return name, nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// basicSelect 同時等待兩個 channel，哪個先準備好就先處理哪個。
+func basicSelect(clk clock.Clock) {
 	c1 := make(chan string)
 	c2 := make(chan string)
 
 	go func() {
-		time.Sleep(1 * time.Second)
+		clk.Sleep(1 * time.Second)
 		c1 <- "one"
 	}()
 	go func() {
-		time.Sleep(2 * time.Second)
+		clk.Sleep(2 * time.Second)
 		c2 <- "two"
 	}()
 
@@ -29,26 +44,28 @@ func main() {
 			fmt.Println("Received from c2:", msg2)
 		}
 	}
+}
 
-	// --- Select with Timeout ---
-	fmt.Println("\n---"Select with Timeout Example---")
+// selectWithTimeout 只等待結果 1 秒，超過就放棄。
+func selectWithTimeout(clk clock.Clock) {
 	cr := make(chan string, 1)
 	go func() {
 		// 這個 Goroutine 需要 2 秒才能完成
-		time.Sleep(2 * time.Second)
+		clk.Sleep(2 * time.Second)
 		cr <- "result"
 	}()
 
 	select {
 	case res := <-cr:
 		fmt.Println(res)
-	case <-time.After(1 * time.Second):
+	case <-clk.After(1 * time.Second):
 		// 但我們只等待 1 秒
 		fmt.Println("Timeout waiting for result")
 	}
+}
 
-	// --- Select with Default (Non-blocking) ---
-	fmt.Println("\n---"Select with Default (Non-blocking) Example---")
+// selectWithDefault 示範用 default 做非阻塞的收發。
+func selectWithDefault() {
 	messages := make(chan string)
 
 	// 嘗試接收 messages，但沒有 Goroutine 在發送，所以會立即執行 default
@@ -66,4 +83,45 @@ func main() {
 	default:
 		fmt.Println("No message sent.")
 	}
+
+	// 與 select + default 相同的非阻塞收發。
+	fmt.Println("TrySend:", race.TrySend(messages, "hi"))
+}
+
+// racingHelpers 用 race 套件改寫上面的逾時與競速模式。
+func racingHelpers(clk clock.Clock) {
+	ctx := context.Background()
+
+	// 與上面的 time.After 逾時相同，但逾時後會取消 slowQuery，不會留下 Goroutine。
+	if _, err := race.WithTimeout(ctx, time.Second, slowQuery(clk, "slow", 2*time.Second), race.WithClock(clk)); err != nil {
+		fmt.Println("WithTimeout:", err)
+	}
+
+	// 同時查詢兩個來源，採用先回來的結果。
+	winner, _ := race.FirstOf(ctx, slowQuery(clk, "replica-a", 300*time.Millisecond), slowQuery(clk, "replica-b", 100*time.Millisecond))
+	fmt.Println("FirstOf winner:", winner)
+
+	// 主要呼叫超過 200ms 還沒回來，就再送出一個備援呼叫。
+	hedged, _ := race.Hedge(ctx, 200*time.Millisecond, slowQuery(clk, "hedged", 100*time.Millisecond), race.WithClock(clk))
+	fmt.Println("Hedge result:", hedged)
+}
+
+func main() {
+	clk := clock.Real()
+
+	// --- Basic Select ---
+	fmt.Println("--- Basic Select Example ---")
+	basicSelect(clk)
+
+	// --- Select with Timeout ---
+	fmt.Println("\n--- Select with Timeout Example ---")
+	selectWithTimeout(clk)
+
+	// --- Select with Default (Non-blocking) ---
+	fmt.Println("\n--- Select with Default (Non-blocking) Example ---")
+	selectWithDefault()
+
+	// --- Racing Helpers ---
+	fmt.Println("\n--- Racing Helpers Example ---")
+	racingHelpers(clk)
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+// start 在背景執行 fn，回傳它結束時會被關閉的 channel。
+func start(fn func()) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	return done
+}
+
+func TestBasicSelect(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(time.Unix(0, 0))
+	done := start(func() { basicSelect(fake) })
+
+	fake.BlockUntil(2)
+	fake.Advance(time.Second)
+	fake.Advance(time.Second)
+	<-done
+}
+
+func TestSelectWithTimeout(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(time.Unix(0, 0))
+	done := start(func() { selectWithTimeout(fake) })
+
+	// 一個是 2 秒的 Sleep，一個是 1 秒的 After。
+	fake.BlockUntil(2)
+	fake.Advance(time.Second)
+	<-done
+
+	// 逾時後背景 Goroutine 仍在 Sleep；讓它完成，否則就是一個洩漏。
+	fake.Advance(time.Second)
+}
+
+func TestSelectWithDefault(t *testing.T) {
+	leakcheck.Check(t)
+	selectWithDefault()
+}
+
+func TestRacingHelpers(t *testing.T) {
+	leakcheck.Check(t)
+	begin := time.Unix(0, 0)
+	fake := clock.NewFake(begin)
+	fake.RunUntil(start(func() { racingHelpers(fake) }))
+
+	// WithTimeout 在 1 秒時逾時，FirstOf 等 replica-b 的 100ms，Hedge 的主要呼叫在 100ms 內成功。
+	// 若 FirstOf 選了較慢的 replica-a，總時間會是 1.4 秒。
+	if elapsed := fake.Now().Sub(begin); elapsed != 1200*time.Millisecond {
+		t.Errorf("racingHelpers took %v of fake time; 預期為 1.2s", elapsed)
+	}
+}
@@ -0,0 +1,191 @@
+// Package race 把 Select 範例中臨時寫出來的模式 (time.After 逾時、default 非阻塞收發)
+// 整理成可重複使用的工具：
+//
+//   - WithTimeout：在期限內執行函式，逾時就取消它。
+//   - FirstOf：同時執行多個函式，採用第一個成功的結果並取消其他的。
+//   - Hedge：呼叫太慢時，延遲一段時間後再發出一個備援呼叫。
+//   - TrySend / TryRecv：不會阻塞的 channel 傳送與接收。
+//
+// 所有計時器都會在函式返回前停止，被取消的函式也只會寫入有緩衝的 channel，
+// 因此不會留下計時器或 Goroutine。時間來源可以透過 WithClock 換成假時鐘以便測試。
+package race
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+)
+
+// ErrTimeout 表示函式沒有在期限內完成。它包裝了 context.DeadlineExceeded，
+// 因此 errors.Is(err, context.DeadlineExceeded) 也會成立。
+var ErrTimeout = fmt.Errorf("race: timed out: %w", context.DeadlineExceeded)
+
+// ErrNoFuncs 表示 FirstOf 沒有收到任何函式。
+var ErrNoFuncs = errors.New("race: no functions to run")
+
+// Option 用來調整時間來源等設定。
+type Option func(*options)
+
+type options struct {
+	clock clock.Clock
+}
+
+// WithClock 指定計時使用的時鐘，預設為 clock.Real()。
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{clock: clock.Real()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// result 是單一次呼叫的結果。
+type result[T any] struct {
+	val   T
+	err   error
+	index int
+}
+
+// WithTimeout 執行 fn，若 d 之內沒有完成就取消傳給 fn 的 ctx 並回傳 ErrTimeout。
+// 外層 ctx 被取消時則回傳 ctx.Err()。
+func WithTimeout[T any](ctx context.Context, d time.Duration, fn func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	o := newOptions(opts)
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	timer := o.clock.NewTimer(d)
+	defer timer.Stop()
+
+	// 有一格緩衝，逾時後 fn 才完成也不會阻塞它的 Goroutine。
+	done := make(chan result[T], 1)
+	go func() {
+		v, err := fn(ctx)
+		done <- result[T]{val: v, err: err}
+	}()
+
+	var zero T
+	select {
+	case r := <-done:
+		return r.val, r.err
+	case <-timer.C():
+		cancel(ErrTimeout)
+		return zero, ErrTimeout
+	case <-ctx.Done():
+		return zero, context.Cause(ctx)
+	}
+}
+
+// FirstOf 同時執行所有 fns，回傳第一個成功 (err == nil) 的結果，並取消其餘仍在執行的函式。
+// 如果全部失敗，回傳以 errors.Join 合併的所有錯誤。
+func FirstOf[T any](ctx context.Context, fns ...func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	if len(fns) == 0 {
+		return zero, ErrNoFuncs
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result[T], len(fns))
+	for i, fn := range fns {
+		go func() {
+			v, err := fn(ctx)
+			results <- result[T]{val: v, err: err, index: i}
+		}()
+	}
+
+	errs := make([]error, len(fns))
+	for range fns {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				return r.val, nil
+			}
+			errs[r.index] = r.err
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+	return zero, errors.Join(errs...)
+}
+
+// Hedge 呼叫 fn；若 delay 之後還沒有結果，就再發出一次備援呼叫，採用先成功的那一個並取消另一個。
+// 若第一次呼叫在 delay 之前就失敗，備援呼叫會立刻發出。兩次都失敗時回傳合併的錯誤。
+func Hedge[T any](ctx context.Context, delay time.Duration, fn func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	o := newOptions(opts)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan result[T], 2)
+	launch := func(i int) {
+		go func() {
+			v, err := fn(ctx)
+			results <- result[T]{val: v, err: err, index: i}
+		}()
+	}
+
+	timer := o.clock.NewTimer(delay)
+	defer timer.Stop()
+	hedge := timer.C()
+
+	launch(0)
+	launched, finished := 1, 0
+	var errs []error
+	var zero T
+	for {
+		select {
+		case r := <-results:
+			if r.err == nil {
+				return r.val, nil
+			}
+			errs = append(errs, r.err)
+			finished++
+			if launched == 1 {
+				// 第一次呼叫提早失敗，不必等到 delay。
+				timer.Stop()
+				hedge = nil
+				launch(1)
+				launched++
+			} else if finished == launched {
+				return zero, errors.Join(errs...)
+			}
+		case <-hedge:
+			hedge = nil
+			launch(1)
+			launched++
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// TrySend 嘗試不阻塞地把 v 送進 ch，成功時回傳 true。
+func TrySend[T any](ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	default:
+		return false
+	}
+}
+
+// TryRecv 嘗試不阻塞地從 ch 接收一個值。
+// received 表示是否收到值；closed 表示 ch 已經關閉 (此時 received 為 false)。
+func TryRecv[T any](ch <-chan T) (v T, received, closed bool) {
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			return v, false, true
+		}
+		return v, true, false
+	default:
+		return v, false, false
+	}
+}
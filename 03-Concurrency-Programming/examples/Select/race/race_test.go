@@ -0,0 +1,217 @@
+package race
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+// waitCtx 模擬一個只有在 ctx 被取消時才會結束的慢速呼叫。
+func waitCtx(ctx context.Context) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestWithTimeout(t *testing.T) {
+	leakcheck.Check(t)
+
+	t.Run("finishes in time", func(t *testing.T) {
+		fake := clock.NewFake(time.Unix(0, 0))
+		got, err := WithTimeout(context.Background(), time.Second, func(ctx context.Context) (string, error) {
+			return "ok", nil
+		}, WithClock(fake))
+		if got != "ok" || err != nil {
+			t.Errorf("WithTimeout() = %q, %v; 預期為 \"ok\", nil", got, err)
+		}
+		if fake.Pending() != 0 {
+			t.Errorf("timer should be stopped, %d still pending", fake.Pending())
+		}
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		fake := clock.NewFake(time.Unix(0, 0))
+		errc := make(chan error, 1)
+		go func() {
+			_, err := WithTimeout(context.Background(), time.Second, waitCtx, WithClock(fake))
+			errc <- err
+		}()
+
+		fake.BlockUntil(1)
+		fake.Advance(999 * time.Millisecond)
+		select {
+		case err := <-errc:
+			t.Fatalf("WithTimeout() returned early: %v", err)
+		default:
+		}
+
+		fake.Advance(time.Millisecond)
+		err := <-errc
+		if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("WithTimeout() error = %v; 預期為 ErrTimeout", err)
+		}
+	})
+
+	t.Run("parent canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := WithTimeout(ctx, time.Hour, waitCtx)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("WithTimeout() error = %v; 預期為 context.Canceled", err)
+		}
+	})
+}
+
+func TestFirstOf(t *testing.T) {
+	leakcheck.Check(t)
+	errSlow := errors.New("slow failed")
+
+	t.Run("first success wins and losers are canceled", func(t *testing.T) {
+		// 輸家會一直等到被取消；leakcheck 確認它們真的有結束。
+		loser := waitCtx
+		winner := func(ctx context.Context) (string, error) { return "fast", nil }
+
+		got, err := FirstOf(context.Background(), loser, winner, loser)
+		if got != "fast" || err != nil {
+			t.Errorf("FirstOf() = %q, %v; 預期為 \"fast\", nil", got, err)
+		}
+	})
+
+	t.Run("earliest wins on a fake clock", func(t *testing.T) {
+		start := time.Unix(0, 0)
+		fake := clock.NewFake(start)
+		after := func(name string, d time.Duration) func(ctx context.Context) (string, error) {
+			return func(ctx context.Context) (string, error) {
+				timer := fake.NewTimer(d)
+				defer timer.Stop()
+				select {
+				case <-timer.C():
+					return name, nil
+				case <-ctx.Done():
+					return "", ctx.Err()
+				}
+			}
+		}
+
+		var got string
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			got, _ = FirstOf(context.Background(), after("slow", 300*time.Millisecond), after("fast", 100*time.Millisecond))
+		}()
+		fake.RunUntil(done)
+		if got != "fast" {
+			t.Errorf("FirstOf() = %q; 預期為 \"fast\"", got)
+		}
+		if elapsed := fake.Now().Sub(start); elapsed != 100*time.Millisecond {
+			t.Errorf("FirstOf() took %v of fake time; 預期為 100ms", elapsed)
+		}
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		errFast := errors.New("fast failed")
+		_, err := FirstOf(context.Background(),
+			func(ctx context.Context) (int, error) { return 0, errFast },
+			func(ctx context.Context) (int, error) { return 0, errSlow },
+		)
+		if !errors.Is(err, errFast) || !errors.Is(err, errSlow) {
+			t.Errorf("FirstOf() error = %v; 預期包含兩個錯誤", err)
+		}
+	})
+
+	t.Run("no functions", func(t *testing.T) {
+		if _, err := FirstOf[int](context.Background()); !errors.Is(err, ErrNoFuncs) {
+			t.Errorf("FirstOf() error = %v; 預期為 ErrNoFuncs", err)
+		}
+	})
+}
+
+func TestHedge(t *testing.T) {
+	leakcheck.Check(t)
+
+	t.Run("backup launched after delay", func(t *testing.T) {
+		fake := clock.NewFake(time.Unix(0, 0))
+		var calls atomic.Int32
+		fn := func(ctx context.Context) (int, error) {
+			// 第一次呼叫會卡住，第二次 (備援) 立刻成功。
+			if calls.Add(1) == 1 {
+				<-ctx.Done()
+				return 0, ctx.Err()
+			}
+			return 42, nil
+		}
+
+		type res struct {
+			v   int
+			err error
+		}
+		done := make(chan res, 1)
+		go func() {
+			v, err := Hedge(context.Background(), 50*time.Millisecond, fn, WithClock(fake))
+			done <- res{v, err}
+		}()
+
+		fake.BlockUntil(1)
+		fake.Advance(50 * time.Millisecond)
+		r := <-done
+		if r.v != 42 || r.err != nil {
+			t.Errorf("Hedge() = %d, %v; 預期為 42, nil", r.v, r.err)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("calls = %d; 預期為 2", calls.Load())
+		}
+	})
+
+	t.Run("fast primary needs no backup", func(t *testing.T) {
+		fake := clock.NewFake(time.Unix(0, 0))
+		var calls atomic.Int32
+		v, err := Hedge(context.Background(), time.Second, func(ctx context.Context) (int, error) {
+			calls.Add(1)
+			return 7, nil
+		}, WithClock(fake))
+		if v != 7 || err != nil || calls.Load() != 1 {
+			t.Errorf("Hedge() = %d, %v with %d calls; 預期為 7, nil with 1 call", v, err, calls.Load())
+		}
+	})
+
+	t.Run("primary fails early", func(t *testing.T) {
+		fake := clock.NewFake(time.Unix(0, 0))
+		var calls atomic.Int32
+		errFirst := errors.New("first failed")
+		v, err := Hedge(context.Background(), time.Hour, func(ctx context.Context) (int, error) {
+			if calls.Add(1) == 1 {
+				return 0, errFirst
+			}
+			return 1, nil
+		}, WithClock(fake))
+		// 不需要 Advance：第一次失敗後備援呼叫會立刻發出。
+		if v != 1 || err != nil {
+			t.Errorf("Hedge() = %d, %v; 預期為 1, nil", v, err)
+		}
+	})
+}
+
+func TestTrySendTryRecv(t *testing.T) {
+	ch := make(chan int, 1)
+
+	if _, received, closed := TryRecv(ch); received || closed {
+		t.Errorf("TryRecv on empty channel: received=%v closed=%v; 預期皆為 false", received, closed)
+	}
+	if !TrySend(ch, 1) {
+		t.Error("TrySend into empty buffer should succeed")
+	}
+	if TrySend(ch, 2) {
+		t.Error("TrySend into full buffer should fail")
+	}
+	if v, received, _ := TryRecv(ch); !received || v != 1 {
+		t.Errorf("TryRecv() = %d, %v; 預期為 1, true", v, received)
+	}
+	close(ch)
+	if _, received, closed := TryRecv(ch); received || !closed {
+		t.Errorf("TryRecv on closed channel: received=%v closed=%v; 預期為 false, true", received, closed)
+	}
+}
@@ -0,0 +1,89 @@
+package counters
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// procsLevels 是要比較的 GOMAXPROCS 值，也可以改用 `go test -bench . -cpu 1,2,4,8`。
+func procsLevels() []int {
+	levels := []int{1, 4}
+	if n := runtime.NumCPU(); n > 4 {
+		levels = append(levels, n)
+	}
+	return levels
+}
+
+// withProcs 在 GOMAXPROCS=procs 的情況下執行 sub-benchmark，結束後還原設定。
+func withProcs(b *testing.B, procs int, fn func(b *testing.B)) {
+	b.Run(fmt.Sprintf("procs=%d", procs), func(b *testing.B) {
+		old := runtime.GOMAXPROCS(procs)
+		defer runtime.GOMAXPROCS(old)
+		fn(b)
+	})
+}
+
+// BenchmarkCounters 比較不同寫入比例下各計數器的表現。
+// writePct=100 代表純寫入 (最高競爭)，writePct=10 代表讀多寫少。
+func BenchmarkCounters(b *testing.B) {
+	for _, impl := range implementations {
+		b.Run(impl.name, func(b *testing.B) {
+			for _, writePct := range []int{100, 50, 10} {
+				b.Run("write="+strconv.Itoa(writePct)+"%", func(b *testing.B) {
+					for _, procs := range procsLevels() {
+						withProcs(b, procs, func(b *testing.B) {
+							c := impl.new()
+							b.RunParallel(func(pb *testing.PB) {
+								i := 0
+								for pb.Next() {
+									if i%100 < writePct {
+										c.Inc()
+									} else {
+										_ = c.Value()
+									}
+									i++
+								}
+							})
+						})
+					}
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkKeyedCounter 比較「所有 Goroutine 搶同一個 key」與「key 分散」兩種競爭程度，
+// 以及分片數量帶來的差異。
+func BenchmarkKeyedCounter(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+
+	for _, shards := range []int{1, 32} {
+		for _, spread := range []struct {
+			name string
+			keys []string
+		}{
+			{"hot-key", keys[:1]},
+			{"spread", keys},
+		} {
+			b.Run(fmt.Sprintf("shards=%d/%s", shards, spread.name), func(b *testing.B) {
+				for _, procs := range procsLevels() {
+					withProcs(b, procs, func(b *testing.B) {
+						c := NewKeyed(shards)
+						b.RunParallel(func(pb *testing.PB) {
+							i := 0
+							for pb.Next() {
+								c.Inc(spread.keys[i%len(spread.keys)])
+								i++
+							}
+						})
+					})
+				}
+			})
+		}
+	}
+}
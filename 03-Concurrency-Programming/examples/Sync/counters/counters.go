@@ -0,0 +1,152 @@
+// Package counters 收集幾種執行緒安全的計數器實作，用來比較它們在高併發下的表現。
+//
+// SafeCounter 範例用一把 sync.Mutex 保護每一次 Inc，所有 Goroutine 都必須排隊。
+// 這個套件提供同一個 Counter 介面的不同實作：
+//
+//   - MutexCounter：與 SafeCounter 相同的做法，作為比較基準。
+//   - RWMutexCounter：讀取使用讀鎖，適合讀多寫少。
+//   - AtomicCounter：使用 sync/atomic，不需要鎖。
+//   - ShardedCounter：把計數分散到多個對齊快取行 (cache line) 的分片，
+//     寫入幾乎不會互相競爭，代價是讀取時要加總所有分片。
+//
+// 另外 KeyedCounter 則以分片的 map 提供「每個 key 一個計數」的功能。
+// 效能比較請執行 `go test -bench . -cpu 1,4,8`。
+package counters
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter 是所有計數器共同的介面。
+type Counter interface {
+	// Inc 將計數加一。
+	Inc()
+	// Add 將計數加上 n。
+	Add(n int64)
+	// Value 回傳目前的計數。
+	Value() int64
+}
+
+// MutexCounter 以 sync.Mutex 保護計數，讀寫都需要取得同一把鎖。
+type MutexCounter struct {
+	mu sync.Mutex
+	n  int64
+}
+
+func (c *MutexCounter) Inc() { c.Add(1) }
+
+func (c *MutexCounter) Add(n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n += n
+}
+
+func (c *MutexCounter) Value() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+// RWMutexCounter 以 sync.RWMutex 保護計數，多個讀取者可以同時讀取。
+type RWMutexCounter struct {
+	mu sync.RWMutex
+	n  int64
+}
+
+func (c *RWMutexCounter) Inc() { c.Add(1) }
+
+func (c *RWMutexCounter) Add(n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n += n
+}
+
+func (c *RWMutexCounter) Value() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.n
+}
+
+// AtomicCounter 以 atomic.Int64 實作，不需要任何鎖。
+type AtomicCounter struct {
+	n atomic.Int64
+}
+
+func (c *AtomicCounter) Inc() { c.n.Add(1) }
+
+func (c *AtomicCounter) Add(n int64) { c.n.Add(n) }
+
+func (c *AtomicCounter) Value() int64 { return c.n.Load() }
+
+// cacheLineSize 是常見 CPU (x86-64 與大多數 arm64) 的快取行大小。
+const cacheLineSize = 64
+
+// paddedInt64 佔滿一整條快取行，避免相鄰分片之間的偽共享 (false sharing)。
+type paddedInt64 struct {
+	n atomic.Int64
+	_ [cacheLineSize - 8]byte
+}
+
+// ShardedCounter 把計數分散到多個分片，每次寫入隨機挑一個分片。
+// 寫入很快且幾乎不競爭；Value 需要加總所有分片，因此讀取較慢，
+// 而且在持續寫入時讀到的只是某個瞬間的近似值。
+//
+// 與其他計數器一樣零值即可使用，第一次寫入時才建立 GOMAXPROCS 個分片；
+// 需要指定分片數量時請使用 NewSharded。
+type ShardedCounter struct {
+	shards atomic.Pointer[[]paddedInt64]
+}
+
+// NewSharded 建立一個有 shards 個分片的計數器；shards <= 0 時使用 GOMAXPROCS。
+func NewSharded(shards int) *ShardedCounter {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	s := make([]paddedInt64, shards)
+	c := &ShardedCounter{}
+	c.shards.Store(&s)
+	return c
+}
+
+// load 回傳分片，還沒有分片時建立；多個 Goroutine 同時建立時只有一個會被採用。
+func (c *ShardedCounter) load() []paddedInt64 {
+	if p := c.shards.Load(); p != nil {
+		return *p
+	}
+	s := make([]paddedInt64, runtime.GOMAXPROCS(0))
+	if !c.shards.CompareAndSwap(nil, &s) {
+		return *c.shards.Load()
+	}
+	return s
+}
+
+func (c *ShardedCounter) Inc() { c.Add(1) }
+
+func (c *ShardedCounter) Add(n int64) {
+	shards := c.load()
+	// math/rand/v2 的全域亂數來源在每個執行緒上各自運作，不會成為新的競爭點。
+	shards[rand.IntN(len(shards))].n.Add(n)
+}
+
+func (c *ShardedCounter) Value() int64 {
+	p := c.shards.Load()
+	if p == nil {
+		return 0
+	}
+	var sum int64
+	for i := range *p {
+		sum += (*p)[i].n.Load()
+	}
+	return sum
+}
+
+// 確認所有實作都滿足 Counter 介面。
+var (
+	_ Counter = (*MutexCounter)(nil)
+	_ Counter = (*RWMutexCounter)(nil)
+	_ Counter = (*AtomicCounter)(nil)
+	_ Counter = (*ShardedCounter)(nil)
+)
@@ -0,0 +1,109 @@
+package counters
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// implementations 列出所有要測試與比較的計數器。
+var implementations = []struct {
+	name string
+	new  func() Counter
+}{
+	{"Mutex", func() Counter { return &MutexCounter{} }},
+	{"RWMutex", func() Counter { return &RWMutexCounter{} }},
+	{"Atomic", func() Counter { return &AtomicCounter{} }},
+	{"Sharded", func() Counter { return NewSharded(0) }},
+}
+
+func TestCountersAreExact(t *testing.T) {
+	const goroutines, perGoroutine = 50, 1000
+
+	for _, impl := range implementations {
+		t.Run(impl.name, func(t *testing.T) {
+			c := impl.new()
+			var wg sync.WaitGroup
+			for i := 0; i < goroutines; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < perGoroutine; j++ {
+						c.Inc()
+					}
+					c.Add(10)
+				}()
+			}
+			wg.Wait()
+
+			want := int64(goroutines * (perGoroutine + 10))
+			if got := c.Value(); got != want {
+				t.Errorf("Value() = %d; 預期為 %d", got, want)
+			}
+		})
+	}
+}
+
+// TestShardedCounterZeroValue 檢查零值的 ShardedCounter 可以直接使用，
+// 同時第一次寫入的 Goroutine 只會建立一組分片 (以 -race 執行)。
+func TestShardedCounterZeroValue(t *testing.T) {
+	var c ShardedCounter
+	if got := c.Value(); got != 0 {
+		t.Errorf("零值的 Value() = %d; 預期為 0", got)
+	}
+
+	const goroutines = 50
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Inc()
+		}()
+	}
+	wg.Wait()
+	if got := c.Value(); got != goroutines {
+		t.Errorf("Value() = %d; 預期為 %d", got, goroutines)
+	}
+}
+
+func TestKeyedCounter(t *testing.T) {
+	c := NewKeyed(4)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Inc(fmt.Sprintf("key-%d", i%10))
+			c.Inc("hot")
+		}()
+	}
+	wg.Wait()
+
+	testCases := []struct {
+		key  string
+		want int64
+	}{
+		{"hot", 100},
+		{"key-0", 10},
+		{"key-9", 10},
+		{"missing", 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.key, func(t *testing.T) {
+			if got := c.Get(tc.key); got != tc.want {
+				t.Errorf("Get(%q) = %d; 預期為 %d", tc.key, got, tc.want)
+			}
+		})
+	}
+
+	if got := c.Len(); got != 11 {
+		t.Errorf("Len() = %d; 預期為 11", got)
+	}
+	if snap := c.Snapshot(); snap["hot"] != 100 || len(snap) != 11 {
+		t.Errorf("Snapshot() = %v; 預期包含 11 個 key 且 hot=100", snap)
+	}
+	if got := c.Delete("hot"); got != 100 || c.Get("hot") != 0 {
+		t.Errorf("Delete(\"hot\") = %d; 預期為 100 並移除該 key", got)
+	}
+}
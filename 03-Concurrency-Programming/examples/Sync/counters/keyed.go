@@ -0,0 +1,95 @@
+package counters
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// keyedShard 是 KeyedCounter 的一個分片，擁有自己的鎖與 map。
+type keyedShard struct {
+	mu     sync.RWMutex
+	counts map[string]int64
+	_      [cacheLineSize]byte
+}
+
+// KeyedCounter 為每個 key 維護一個計數，例如統計每個 URL 的請求數。
+// key 依雜湊值分配到不同分片，不同分片的 key 可以同時更新而不互相阻塞。
+// 零值不可使用，請透過 NewKeyed 建立。
+type KeyedCounter struct {
+	seed   maphash.Seed
+	shards []keyedShard
+}
+
+// NewKeyed 建立一個有 shards 個分片的 KeyedCounter；shards <= 0 時使用 32。
+func NewKeyed(shards int) *KeyedCounter {
+	if shards <= 0 {
+		shards = 32
+	}
+	c := &KeyedCounter{seed: maphash.MakeSeed(), shards: make([]keyedShard, shards)}
+	for i := range c.shards {
+		c.shards[i].counts = make(map[string]int64)
+	}
+	return c
+}
+
+func (c *KeyedCounter) shard(key string) *keyedShard {
+	return &c.shards[maphash.String(c.seed, key)%uint64(len(c.shards))]
+}
+
+// Inc 將 key 的計數加一。
+func (c *KeyedCounter) Inc(key string) {
+	c.Add(key, 1)
+}
+
+// Add 將 key 的計數加上 n，並回傳更新後的值。
+func (c *KeyedCounter) Add(key string, n int64) int64 {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[key] += n
+	return s.counts[key]
+}
+
+// Get 回傳 key 目前的計數，不存在時為 0。
+func (c *KeyedCounter) Get(key string) int64 {
+	s := c.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.counts[key]
+}
+
+// Delete 移除 key 並回傳它最後的計數。
+func (c *KeyedCounter) Delete(key string) int64 {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.counts[key]
+	delete(s.counts, key)
+	return n
+}
+
+// Snapshot 回傳所有 key 計數的副本。每個分片各自一致，但分片之間不是同一瞬間。
+func (c *KeyedCounter) Snapshot() map[string]int64 {
+	out := make(map[string]int64)
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.RLock()
+		for k, v := range s.counts {
+			out[k] = v
+		}
+		s.mu.RUnlock()
+	}
+	return out
+}
+
+// Len 回傳目前有計數的 key 數量。
+func (c *KeyedCounter) Len() int {
+	n := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.RLock()
+		n += len(s.counts)
+		s.mu.RUnlock()
+	}
+	return n
+}
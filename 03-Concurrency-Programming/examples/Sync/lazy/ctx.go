@@ -0,0 +1,90 @@
+package lazy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// call 代表一次正在進行中的初始化。
+type call[T any] struct {
+	done chan struct{}
+	gen  uint64 // 開始時的 CtxValue.gen；不同代表期間被 Reset 過，結果不能快取
+	val  T
+	err  error
+}
+
+// CtxValue 是接受 context 的 Value。
+//
+// 初始化在獨立的 Goroutine 中執行，並使用不會被呼叫者取消的 context
+// (只受 timeout 限制)，因此一個呼叫者放棄等待不會害其他正在等待的呼叫者失敗。
+// 同一時間只會有一次初始化在進行 (除非期間呼叫了 Reset)，失敗的結果不會被快取。
+type CtxValue[T any] struct {
+	init    func(ctx context.Context) (T, error)
+	timeout time.Duration
+
+	mu       sync.Mutex
+	inflight *call[T]
+	gen      uint64 // 每次 Reset 加一
+	ready    bool
+	val      T
+}
+
+// NewCtxValue 建立一個 CtxValue；timeout > 0 時，每次初始化最多執行 timeout。
+func NewCtxValue[T any](timeout time.Duration, init func(ctx context.Context) (T, error)) *CtxValue[T] {
+	return &CtxValue[T]{init: init, timeout: timeout}
+}
+
+// Get 回傳初始化後的值。若初始化正在進行，Get 會等待它完成或 ctx 結束。
+func (v *CtxValue[T]) Get(ctx context.Context) (T, error) {
+	v.mu.Lock()
+	if v.ready {
+		v.mu.Unlock()
+		return v.val, nil
+	}
+	c := v.inflight
+	if c == nil {
+		c = &call[T]{done: make(chan struct{}), gen: v.gen}
+		v.inflight = c
+		go v.run(context.WithoutCancel(ctx), c)
+	}
+	v.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+func (v *CtxValue[T]) run(ctx context.Context, c *call[T]) {
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+	c.val, c.err = v.init(ctx)
+
+	v.mu.Lock()
+	if v.inflight == c {
+		v.inflight = nil
+	}
+	if c.err == nil && c.gen == v.gen {
+		v.val, v.ready = c.val, true
+	}
+	v.mu.Unlock()
+	close(c.done)
+}
+
+// Reset 丟棄快取的值，下一次 Get 會重新初始化。
+// 進行中的初始化仍會把結果交給已經在等待的呼叫者，但不會被快取；Reset 之後的 Get 不會再等待它。
+func (v *CtxValue[T]) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var zero T
+	v.val, v.ready = zero, false
+	v.inflight = nil
+	v.gen++
+}
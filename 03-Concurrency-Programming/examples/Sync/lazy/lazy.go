@@ -0,0 +1,94 @@
+// Package lazy 提供比 sync.Once 更有彈性的延遲初始化 (lazy initialization) 工具。
+//
+// sync.Once 只能執行一個沒有回傳值、也不能失敗的函式；一旦執行過，就算失敗也不會再重試。
+// 這個套件補上幾個常見的需求：
+//
+//   - Value / OnceValue：初始化可以回傳值與錯誤，失敗不會被快取，下一次呼叫會重試。
+//   - CtxValue：初始化接受 context 並可設定逾時，等待中的呼叫者可以各自放棄等待。
+//   - Once：可以 Reset 的 Once，方便在測試之間重新初始化。
+//   - Registry：依型別保存延遲初始化的單例 (singleton)。
+package lazy
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Value 延遲計算一個 T，只有成功的結果會被快取。
+// 零值不可使用，請透過 NewValue 建立。
+type Value[T any] struct {
+	init func() (T, error)
+
+	// cached 指向快取的值，nil 代表尚未初始化 (或已經 Reset)。
+	// 值與「是否完成」放在同一個指標中，Get 的快速路徑讀到的值才不會與 Reset 競爭。
+	cached atomic.Pointer[T]
+	mu     sync.Mutex
+}
+
+// NewValue 建立一個第一次呼叫 Get 時才執行 init 的 Value。
+func NewValue[T any](init func() (T, error)) *Value[T] {
+	return &Value[T]{init: init}
+}
+
+// Get 回傳初始化後的值。第一次成功之前，每次呼叫都會 (序列化地) 重新執行 init；
+// 成功之後就直接回傳快取的值，不再取得鎖。
+func (v *Value[T]) Get() (T, error) {
+	if p := v.cached.Load(); p != nil {
+		return *p, nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if p := v.cached.Load(); p != nil {
+		return *p, nil
+	}
+	val, err := v.init()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	v.cached.Store(&val)
+	return val, nil
+}
+
+// Reset 丟棄快取的值，下一次 Get 會重新初始化。
+// 與 Get 同時呼叫是安全的：進行中的 Get 回傳舊值或新值，不會讀到一半被清除的值。
+func (v *Value[T]) Reset() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.cached.Store(nil)
+}
+
+// OnceValue 回傳一個函式，它在第一次成功後就一直回傳同一個值；失敗時下一次呼叫會重試。
+// 與 sync.OnceValues 不同，sync.OnceValues 會連錯誤一起快取。
+func OnceValue[T any](init func() (T, error)) func() (T, error) {
+	return NewValue(init).Get
+}
+
+// Once 與 sync.Once 相同，但可以透過 Reset 讓下一次 Do 再執行一次。
+type Once struct {
+	done atomic.Bool
+	mu   sync.Mutex
+}
+
+// Do 在 Once 尚未執行 (或被 Reset 後) 時呼叫 f。f 執行期間其他呼叫者會等待。
+func (o *Once) Do(f func()) {
+	if o.done.Load() {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.done.Load() {
+		return
+	}
+	// 與 sync.Once 相同：即使 f panic 也視為已經執行過。
+	defer o.done.Store(true)
+	f()
+}
+
+// Reset 讓下一次 Do 重新執行。通常只在測試中使用。
+func (o *Once) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.done.Store(false)
+}
@@ -0,0 +1,289 @@
+package lazy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+var errBoom = errors.New("boom")
+
+func TestValueCachesSuccessOnly(t *testing.T) {
+	var calls int
+	v := NewValue(func() (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errBoom
+		}
+		return 42, nil
+	})
+
+	testCases := []struct {
+		name    string
+		want    int
+		wantErr error
+	}{
+		{"第一次失敗", 0, errBoom},
+		{"第二次重試仍失敗", 0, errBoom},
+		{"第三次成功", 42, nil},
+		{"之後使用快取", 42, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := v.Get()
+			if got != tc.want || !errors.Is(err, tc.wantErr) {
+				t.Errorf("Get() = (%d, %v); 預期為 (%d, %v)", got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+	if calls != 3 {
+		t.Errorf("init 被呼叫 %d 次; 預期為 3", calls)
+	}
+
+	v.Reset()
+	if _, err := v.Get(); err != nil || calls != 4 {
+		t.Errorf("Reset 後 Get() 錯誤 = %v, 呼叫次數 = %d; 預期為 nil, 4", err, calls)
+	}
+}
+
+func TestOnceValueConcurrent(t *testing.T) {
+	var calls atomic.Int32
+	get := OnceValue(func() (string, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return "ready", nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := get(); v != "ready" || err != nil {
+				t.Errorf("get() = (%q, %v); 預期為 (\"ready\", nil)", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Errorf("init 被呼叫 %d 次; 預期為 1", n)
+	}
+}
+
+// TestValueConcurrentGetReset 在 -race 下檢查 Get 的快速路徑與 Reset 沒有資料競爭，
+// 而且每次 Get 都回傳某一次完整初始化的結果。
+func TestValueConcurrentGetReset(t *testing.T) {
+	var calls atomic.Int32
+	v := NewValue(func() ([2]int, error) {
+		n := int(calls.Add(1))
+		return [2]int{n, n}, nil
+	})
+
+	// Reset 一直執行，直到所有的 Get 完成。
+	stop := make(chan struct{})
+	resetDone := make(chan struct{})
+	go func() {
+		defer close(resetDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				v.Reset()
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if got, err := v.Get(); err != nil || got[0] != got[1] || got[0] == 0 {
+					t.Errorf("Get() = (%v, %v); 預期為某一次初始化的 [n n]", got, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-resetDone
+	if calls.Load() < 2 {
+		t.Errorf("init 被呼叫 %d 次; 預期 Reset 之後會重新初始化", calls.Load())
+	}
+}
+
+func TestOnceReset(t *testing.T) {
+	var o Once
+	var n int
+	for i := 0; i < 3; i++ {
+		o.Do(func() { n++ })
+	}
+	if n != 1 {
+		t.Fatalf("Do 執行了 %d 次; 預期為 1", n)
+	}
+
+	o.Reset()
+	o.Do(func() { n++ })
+	if n != 2 {
+		t.Errorf("Reset 後 Do 執行了 %d 次; 預期為 2", n)
+	}
+}
+
+func TestOncePanicCountsAsDone(t *testing.T) {
+	var o Once
+	func() {
+		defer func() { _ = recover() }()
+		o.Do(func() { panic("init failed") })
+	}()
+
+	ran := false
+	o.Do(func() { ran = true })
+	if ran {
+		t.Error("panic 之後 Do 又執行了一次; 預期與 sync.Once 相同視為已執行")
+	}
+}
+
+func TestCtxValueCallerCancelDoesNotAbortInit(t *testing.T) {
+	leakcheck.Check(t)
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	v := NewCtxValue(0, func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		select {
+		case <-release:
+			return 7, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	})
+
+	// 第一個呼叫者很快就放棄等待。
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := v.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Get(短逾時) 錯誤 = %v; 預期為 context.DeadlineExceeded", err)
+	}
+
+	// 第二個呼叫者等待同一次初始化完成。
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if got, err := v.Get(context.Background()); got != 7 || err != nil {
+			t.Errorf("Get() = (%d, %v); 預期為 (7, nil)", got, err)
+		}
+	}()
+	close(release)
+	<-done
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("init 被呼叫 %d 次; 預期為 1", n)
+	}
+}
+
+func TestCtxValueTimeoutIsRetried(t *testing.T) {
+	leakcheck.Check(t)
+
+	var calls atomic.Int32
+	v := NewCtxValue(20*time.Millisecond, func(ctx context.Context) (string, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done() // 第一次卡住直到逾時
+			return "", ctx.Err()
+		}
+		return "ok", nil
+	})
+
+	if _, err := v.Get(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("第一次 Get() 錯誤 = %v; 預期為 context.DeadlineExceeded", err)
+	}
+	if got, err := v.Get(context.Background()); got != "ok" || err != nil {
+		t.Errorf("第二次 Get() = (%q, %v); 預期為 (\"ok\", nil)", got, err)
+	}
+}
+
+func TestCtxValueResetDuringInit(t *testing.T) {
+	leakcheck.Check(t)
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	v := NewCtxValue(0, func(ctx context.Context) (int32, error) {
+		n := calls.Add(1)
+		if n == 1 {
+			<-release
+		}
+		return n, nil
+	})
+
+	first := make(chan int32)
+	go func() {
+		got, _ := v.Get(context.Background())
+		first <- got
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	v.Reset()
+
+	// Reset 之後的 Get 重新初始化，不等待 Reset 之前開始的那一次。
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if got, err := v.Get(ctx); got != 2 || err != nil {
+		t.Errorf("Reset 後 Get() = (%d, %v); 預期為 (2, nil)", got, err)
+	}
+	close(release)
+	if got := <-first; got != 1 {
+		t.Errorf("等待中的 Get() = %d; 預期為 1", got)
+	}
+	// Reset 之前開始的初始化結束時不會蓋掉快取。
+	if got, _ := v.Get(context.Background()); got != 2 || calls.Load() != 2 {
+		t.Errorf("Get() = %d, init 被呼叫 %d 次; 預期使用快取的 2", got, calls.Load())
+	}
+}
+
+type config struct{ name string }
+
+type database struct{ cfg *config }
+
+func TestRegistry(t *testing.T) {
+	var r Registry
+	var builds int
+	Register(&r, func() (*config, error) {
+		builds++
+		return &config{name: "prod"}, nil
+	})
+	Register(&r, func() (*database, error) {
+		cfg, err := Resolve[*config](&r)
+		if err != nil {
+			return nil, err
+		}
+		return &database{cfg: cfg}, nil
+	})
+
+	db := MustResolve[*database](&r)
+	cfg := MustResolve[*config](&r)
+	if db.cfg != cfg {
+		t.Error("database 使用的 config 與 Resolve 取得的不是同一個實例")
+	}
+	if builds != 1 {
+		t.Errorf("config 被建立 %d 次; 預期為 1", builds)
+	}
+
+	r.Reset()
+	if again := MustResolve[*config](&r); again == cfg || builds != 2 {
+		t.Errorf("Reset 後應重新建立 config (建立次數 = %d)", builds)
+	}
+
+	_, err := Resolve[int](&r)
+	if err == nil || !strings.Contains(err.Error(), "int") {
+		t.Errorf("Resolve[int] 錯誤 = %v; 預期為未登記的錯誤", err)
+	}
+}
@@ -0,0 +1,62 @@
+package lazy
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Registry 依型別保存延遲初始化的單例。每個型別先以 Register 登記建構函式，
+// 第一次 Resolve 時才真正建立，之後都回傳同一個實例。
+//
+//	var services lazy.Registry
+//	lazy.Register(&services, func() (*DB, error) { return openDB() })
+//	db, err := lazy.Resolve[*DB](&services)
+//
+// 零值的 Registry 可以直接使用，並且可以安全地被多個 Goroutine 同時使用。
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[reflect.Type]any // 值為 *Value[T]
+}
+
+// Register 為型別 T 登記建構函式。重複登記會取代先前的建構函式與已建立的實例。
+func Register[T any](r *Registry, init func() (T, error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.entries == nil {
+		r.entries = make(map[reflect.Type]any)
+	}
+	r.entries[reflect.TypeFor[T]()] = NewValue(init)
+}
+
+// Resolve 回傳型別 T 的單例，第一次呼叫時才會執行建構函式。
+// 建構失敗不會被快取，下一次 Resolve 會重試。
+func Resolve[T any](r *Registry) (T, error) {
+	typ := reflect.TypeFor[T]()
+	r.mu.RLock()
+	entry, ok := r.entries[typ]
+	r.mu.RUnlock()
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("lazy: no constructor registered for %v", typ)
+	}
+	return entry.(*Value[T]).Get()
+}
+
+// MustResolve 與 Resolve 相同，但失敗時 panic。適合在程式啟動階段使用。
+func MustResolve[T any](r *Registry) T {
+	v, err := Resolve[T](r)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Reset 丟棄所有已建立的實例 (保留登記的建構函式)，通常用於測試之間。
+func (r *Registry) Reset() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, entry := range r.entries {
+		entry.(interface{ Reset() }).Reset()
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !lockdebug
+
+package lockdebug
+
+// Enabled 表示是否以 lockdebug build tag 編譯。為 false 時所有檢查都會被編譯器移除。
+const Enabled = false
+
+func acquire(l any, read bool, try func() bool, lock func()) { lock() }
+
+func acquired(l any, read bool) {}
+
+func release(l any, read bool) {}
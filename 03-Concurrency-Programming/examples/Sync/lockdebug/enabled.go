@@ -0,0 +1,209 @@
+//go:build lockdebug
+
+package lockdebug
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Enabled 表示是否以 lockdebug build tag 編譯。
+const Enabled = true
+
+// holding 是一個 Goroutine 持有的一把鎖。
+type holding struct {
+	lock  any
+	gid   int64
+	read  bool
+	since time.Time
+	stack []byte // 取得鎖時的堆疊
+}
+
+// order 記錄第一次觀察到「持有 from 時取得 to」的 Goroutine 與它取得 to 時的堆疊。
+type order struct {
+	gid   int64
+	stack []byte
+}
+
+type pair struct{ a, b any }
+
+var (
+	mu       sync.Mutex
+	held     = map[int64][]*holding{}  // Goroutine → 依取得順序排列的持有中的鎖
+	owners   = map[any][]*holding{}    // 鎖 → 目前的持有者 (讀鎖可能有多個)
+	edges    = map[any]map[any]order{} // from → to：曾經在持有 from 時取得 to
+	reported = map[pair]bool{}         // 每一組順序相反的鎖只報告一次
+)
+
+// acquire 檢查取得順序後以 lock 取得鎖；等待超過門檻時報告 WaitTooLong。
+// 順序檢查在阻塞之前進行，因此就算這次真的死結，報告也已經送出。
+func acquire(l any, read bool, try func() bool, lock func()) {
+	gid, stack := goroutine()
+	check(l, gid, stack)
+	if !try() {
+		start := time.Now()
+		var timer *time.Timer
+		if d := time.Duration(threshold.Load()); d > 0 {
+			timer = time.AfterFunc(d, func() { waiting(l, gid, stack, start) })
+		}
+		lock()
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+	record(l, gid, read, stack)
+}
+
+// acquired 記錄 TryLock 成功取得的鎖。TryLock 不會阻塞，因此不參與順序檢查。
+func acquired(l any, read bool) {
+	gid, stack := goroutine()
+	record(l, gid, read, stack)
+}
+
+// release 移除持有記錄；持有超過門檻時報告 HeldTooLong。
+func release(l any, read bool) {
+	gid, stack := goroutine()
+	mu.Lock()
+	hs := owners[l]
+	i := slices.IndexFunc(hs, func(h *holding) bool { return h.gid == gid && h.read == read })
+	if i < 0 {
+		// Mutex 可以由取得它以外的 Goroutine 釋放。
+		i = slices.IndexFunc(hs, func(h *holding) bool { return h.read == read })
+	}
+	if i < 0 {
+		// 釋放沒有被持有的鎖：交給 sync 回報錯誤。
+		mu.Unlock()
+		return
+	}
+	h := hs[i]
+	if hs = slices.Delete(hs, i, i+1); len(hs) == 0 {
+		delete(owners, l)
+	} else {
+		owners[l] = hs
+	}
+	if list := slices.DeleteFunc(held[h.gid], func(x *holding) bool { return x == h }); len(list) == 0 {
+		delete(held, h.gid)
+	} else {
+		held[h.gid] = list
+	}
+	mu.Unlock()
+
+	d := time.Duration(threshold.Load())
+	if elapsed := time.Since(h.since); d > 0 && elapsed > d {
+		report(Report{
+			Kind: HeldTooLong, Lock: name(l), Other: name(l),
+			Goroutine: gid, Stack: stack,
+			OtherGoroutine: h.gid, OtherStack: h.stack,
+			Duration: elapsed,
+		})
+	}
+}
+
+// check 比對 Goroutine 目前持有的每一把鎖與 l 的順序，並記錄新的順序。
+func check(l any, gid int64, stack []byte) {
+	var reports []Report
+	mu.Lock()
+	for _, h := range held[gid] {
+		if h.lock == l {
+			reports = append(reports, Report{
+				Kind: Relock, Lock: name(l), Other: name(l),
+				Goroutine: gid, Stack: stack,
+				OtherGoroutine: gid, OtherStack: h.stack,
+			})
+			continue
+		}
+		// 如果從 l 出發可以走到 h.lock，代表曾經 (直接或間接) 在持有 l 時取得 h.lock。
+		if o, ok := path(l, h.lock); ok && !reported[pair{h.lock, l}] {
+			reported[pair{h.lock, l}] = true
+			reported[pair{l, h.lock}] = true
+			reports = append(reports, Report{
+				Kind: Inversion, Lock: name(l), Other: name(h.lock),
+				Goroutine: gid, Stack: stack,
+				OtherGoroutine: o.gid, OtherStack: o.stack,
+			})
+		}
+		if edges[h.lock] == nil {
+			edges[h.lock] = map[any]order{}
+		}
+		if _, ok := edges[h.lock][l]; !ok {
+			edges[h.lock][l] = order{gid: gid, stack: stack}
+		}
+	}
+	mu.Unlock()
+
+	for _, r := range reports {
+		report(r)
+	}
+}
+
+// path 以廣度優先搜尋找出 from 到 to 的順序，並回傳抵達 to 的那一次取得。呼叫者必須持有 mu。
+func path(from, to any) (order, bool) {
+	visited := map[any]bool{from: true}
+	queue := []any{from}
+	for len(queue) > 0 {
+		l := queue[0]
+		queue = queue[1:]
+		for next, o := range edges[l] {
+			if next == to {
+				return o, true
+			}
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return order{}, false
+}
+
+func record(l any, gid int64, read bool, stack []byte) {
+	h := &holding{lock: l, gid: gid, read: read, since: time.Now(), stack: stack}
+	mu.Lock()
+	defer mu.Unlock()
+	held[gid] = append(held[gid], h)
+	owners[l] = append(owners[l], h)
+}
+
+// waiting 在等待超過門檻時報告目前的持有者。
+func waiting(l any, gid int64, stack []byte, start time.Time) {
+	mu.Lock()
+	hs := owners[l]
+	if len(hs) == 0 {
+		// 持有者剛好釋放了鎖。
+		mu.Unlock()
+		return
+	}
+	h := hs[0]
+	mu.Unlock()
+	report(Report{
+		Kind: WaitTooLong, Lock: name(l), Other: name(l),
+		Goroutine: gid, Stack: stack,
+		OtherGoroutine: h.gid, OtherStack: h.stack,
+		Duration: time.Since(start),
+	})
+}
+
+func name(l any) string {
+	return l.(fmt.Stringer).String()
+}
+
+// goroutine 回傳目前 Goroutine 的 ID 與堆疊。堆疊的第一行格式為 "goroutine 18 [running]:"。
+func goroutine() (int64, []byte) {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	line, _, _ := bytes.Cut(buf, []byte(" ["))
+	gid, _ := strconv.ParseInt(string(bytes.TrimPrefix(line, []byte("goroutine "))), 10, 64)
+	return gid, buf
+}
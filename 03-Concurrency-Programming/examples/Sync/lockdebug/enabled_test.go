@@ -0,0 +1,132 @@
+//go:build lockdebug
+
+package lockdebug
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collect 把報告收集到 channel 中，並在測試結束時恢復原本的設定。
+func collect(t *testing.T, d time.Duration) <-chan Report {
+	t.Helper()
+	reports := make(chan Report, 16)
+	old := SetReporter(func(r Report) { reports <- r })
+	SetThreshold(d)
+	t.Cleanup(func() {
+		SetReporter(old)
+		SetThreshold(time.Second)
+	})
+	return reports
+}
+
+// inGoroutine 在新的 Goroutine 中執行 f 並等待它結束。
+func inGoroutine(f func()) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		f()
+	}()
+	wg.Wait()
+}
+
+func lockBoth(first, second sync.Locker) func() {
+	return func() {
+		first.Lock()
+		second.Lock()
+		second.Unlock()
+		first.Unlock()
+	}
+}
+
+func TestInversion(t *testing.T) {
+	reports := collect(t, 0)
+	a, b, c := &Mutex{Name: "a"}, &RWMutex{Name: "b"}, &Mutex{Name: "c"}
+
+	// 相同的順序不論幾次都不會報告。
+	inGoroutine(lockBoth(a, b))
+	inGoroutine(lockBoth(a, b))
+	inGoroutine(lockBoth(b.RLocker(), c))
+
+	// 兩個 Goroutine 先後執行，這次沒有死結，但順序 c → a 與已知的 a → b → c 形成環。
+	inGoroutine(lockBoth(c, a))
+	select {
+	case r := <-reports:
+		if r.Kind != Inversion || r.Lock != "a" || r.Other != "c" {
+			t.Errorf("報告 = (%v, %s, %s); 預期為 (Inversion, a, c)", r.Kind, r.Lock, r.Other)
+		}
+		if r.Goroutine == r.OtherGoroutine || r.OtherGoroutine == 0 {
+			t.Errorf("Goroutine = %d, OtherGoroutine = %d; 預期為兩個不同的 Goroutine", r.Goroutine, r.OtherGoroutine)
+		}
+		if !bytes.Contains(r.Stack, []byte("lockBoth")) || !bytes.Contains(r.OtherStack, []byte("lockBoth")) {
+			t.Errorf("報告缺少兩個 Goroutine 的堆疊:\n%v", r)
+		}
+	default:
+		t.Fatal("沒有報告順序相反的鎖")
+	}
+
+	// 同一組鎖只報告一次。
+	inGoroutine(lockBoth(c, a))
+	select {
+	case r := <-reports:
+		t.Errorf("非預期的報告: %v", r)
+	default:
+	}
+}
+
+func TestWaitAndHeldTooLong(t *testing.T) {
+	reports := collect(t, 20*time.Millisecond)
+	m := &Mutex{Name: "slow"}
+
+	locked := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.Lock()
+		close(locked)
+		time.Sleep(100 * time.Millisecond)
+		m.Unlock()
+	}()
+	<-locked
+	m.Lock()
+	m.Unlock()
+	<-done
+
+	for _, want := range []Kind{WaitTooLong, HeldTooLong} {
+		r := <-reports
+		if r.Kind != want || r.Lock != "slow" {
+			t.Errorf("報告 = (%v, %s); 預期為 (%v, slow)", r.Kind, r.Lock, want)
+		}
+		if r.Duration < 20*time.Millisecond {
+			t.Errorf("%v 的 Duration = %v; 預期超過門檻 20ms", r.Kind, r.Duration)
+		}
+		if r.OtherGoroutine == 0 || !bytes.Contains(r.OtherStack, []byte("TestWaitAndHeldTooLong")) {
+			t.Errorf("%v 缺少持有者的堆疊:\n%v", r.Kind, r)
+		}
+	}
+}
+
+func TestRelock(t *testing.T) {
+	reports := collect(t, 0)
+	rw := &RWMutex{Name: "rw"}
+	rw.RLock()
+	rw.RLock()
+	rw.RUnlock()
+	rw.RUnlock()
+
+	select {
+	case r := <-reports:
+		if r.Kind != Relock || r.Goroutine != r.OtherGoroutine {
+			t.Errorf("報告 = (%v, %d, %d); 預期為同一個 Goroutine 的 Relock", r.Kind, r.Goroutine, r.OtherGoroutine)
+		}
+	default:
+		t.Fatal("沒有報告重複取得的讀鎖")
+	}
+	if !rw.TryLock() {
+		t.Fatal("釋放後 TryLock() = false; 持有記錄沒有被清除")
+	}
+	rw.Unlock()
+}
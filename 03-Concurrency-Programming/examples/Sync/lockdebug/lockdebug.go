@@ -0,0 +1,250 @@
+// Package lockdebug 提供可以直接取代 sync.Mutex 與 sync.RWMutex 的 Mutex 與 RWMutex，
+// 用來在開發時找出潛在的死結 (deadlock)。
+//
+// SafeCounter 只用一把鎖，Lock/Unlock 成對出現就不會出錯；但當程式需要同時持有多把鎖時，
+// 只要兩個 Goroutine 以相反的順序取得它們，就可能互相等待而永遠卡住。
+// 這種錯誤通常只在特定的時序下才會發生，很難靠測試重現。
+//
+// 一般編譯時，Mutex 與 RWMutex 只是 sync 版本的薄包裝，沒有額外成本。
+// 以 `-tags lockdebug` 編譯 (例如 `go test -tags lockdebug ./...`) 時，它們會記錄每個
+// Goroutine 取得鎖的順序與堆疊，並在下列情況呼叫 SetReporter 設定的函式 (預設印到 os.Stderr)：
+//
+//   - Inversion：曾經在持有 A 時取得 B，現在卻在持有 B 時取得 A。即使這次沒有卡住，
+//     只要兩者同時發生就會死結。同時印出兩個 Goroutine 取得鎖時的堆疊。
+//   - WaitTooLong：等待一把鎖超過 SetThreshold 設定的時間，通常代表已經死結；
+//     同時印出等待者與持有者的堆疊。
+//   - HeldTooLong：一把鎖被持有超過門檻才釋放，會拖慢所有等待它的 Goroutine。
+//   - Relock：同一個 Goroutine 重複取得它已經持有的鎖。
+//
+// 讀鎖與寫鎖一樣參與順序檢查：在有寫入者等待時，讀鎖也會造成死結。
+package lockdebug
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Mutex 是可以記錄取得順序的互斥鎖，零值可以直接使用。不可以在使用後複製。
+type Mutex struct {
+	// Name 是報告中顯示的名稱，空字串時以位址代替。
+	Name string
+
+	mu sync.Mutex
+}
+
+// Lock 取得鎖。
+func (m *Mutex) Lock() {
+	if Enabled {
+		acquire(m, false, m.mu.TryLock, m.mu.Lock)
+		return
+	}
+	m.mu.Lock()
+}
+
+// TryLock 嘗試取得鎖，不會阻塞。
+func (m *Mutex) TryLock() bool {
+	if !m.mu.TryLock() {
+		return false
+	}
+	if Enabled {
+		acquired(m, false)
+	}
+	return true
+}
+
+// Unlock 釋放鎖。與 sync.Mutex 相同，可以由其他 Goroutine 釋放。
+func (m *Mutex) Unlock() {
+	if Enabled {
+		release(m, false)
+	}
+	m.mu.Unlock()
+}
+
+func (m *Mutex) String() string {
+	return lockName(m.Name, m)
+}
+
+// RWMutex 是可以記錄取得順序的讀寫鎖，零值可以直接使用。不可以在使用後複製。
+type RWMutex struct {
+	// Name 是報告中顯示的名稱，空字串時以位址代替。
+	Name string
+
+	mu sync.RWMutex
+}
+
+// Lock 取得寫鎖。
+func (m *RWMutex) Lock() {
+	if Enabled {
+		acquire(m, false, m.mu.TryLock, m.mu.Lock)
+		return
+	}
+	m.mu.Lock()
+}
+
+// TryLock 嘗試取得寫鎖，不會阻塞。
+func (m *RWMutex) TryLock() bool {
+	if !m.mu.TryLock() {
+		return false
+	}
+	if Enabled {
+		acquired(m, false)
+	}
+	return true
+}
+
+// Unlock 釋放寫鎖。
+func (m *RWMutex) Unlock() {
+	if Enabled {
+		release(m, false)
+	}
+	m.mu.Unlock()
+}
+
+// RLock 取得讀鎖。
+func (m *RWMutex) RLock() {
+	if Enabled {
+		acquire(m, true, m.mu.TryRLock, m.mu.RLock)
+		return
+	}
+	m.mu.RLock()
+}
+
+// TryRLock 嘗試取得讀鎖，不會阻塞。
+func (m *RWMutex) TryRLock() bool {
+	if !m.mu.TryRLock() {
+		return false
+	}
+	if Enabled {
+		acquired(m, true)
+	}
+	return true
+}
+
+// RUnlock 釋放讀鎖。
+func (m *RWMutex) RUnlock() {
+	if Enabled {
+		release(m, true)
+	}
+	m.mu.RUnlock()
+}
+
+// RLocker 回傳以 RLock/RUnlock 實作的 sync.Locker。
+func (m *RWMutex) RLocker() sync.Locker {
+	return rlocker{m}
+}
+
+func (m *RWMutex) String() string {
+	return lockName(m.Name, m)
+}
+
+type rlocker struct{ m *RWMutex }
+
+func (r rlocker) Lock()   { r.m.RLock() }
+func (r rlocker) Unlock() { r.m.RUnlock() }
+
+func lockName(name string, l any) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("%T(%p)", l, l)
+}
+
+// Kind 是報告的種類。
+type Kind int
+
+const (
+	// Inversion 表示兩把鎖曾經以相反的順序被取得。
+	Inversion Kind = iota
+	// WaitTooLong 表示等待一把鎖超過門檻，很可能已經死結。
+	WaitTooLong
+	// HeldTooLong 表示一把鎖被持有超過門檻才釋放。
+	HeldTooLong
+	// Relock 表示 Goroutine 重複取得它已經持有的鎖。
+	Relock
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Inversion:
+		return "lock order inversion"
+	case WaitTooLong:
+		return "waiting too long (potential deadlock)"
+	case HeldTooLong:
+		return "lock held too long"
+	case Relock:
+		return "recursive locking"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// Report 描述一個偵測到的問題。
+type Report struct {
+	Kind Kind
+	// Lock 是目前 Goroutine 正在取得 (或釋放) 的鎖。
+	Lock string
+	// Other 是另一把相關的鎖：Inversion 時為目前已持有、順序相反的那把鎖；其他種類時與 Lock 相同。
+	Other string
+	// Goroutine 與 Stack 是目前的 Goroutine 與它的堆疊。
+	Goroutine int64
+	Stack     []byte
+	// OtherGoroutine 與 OtherStack 是另一方：Inversion 時為以相反順序取得鎖的 Goroutine，
+	// WaitTooLong 與 HeldTooLong 時為持有者與它取得鎖時的堆疊。Relock 時為第一次取得時的堆疊。
+	OtherGoroutine int64
+	OtherStack     []byte
+	// Duration 是 WaitTooLong 的等待時間或 HeldTooLong 的持有時間。
+	Duration time.Duration
+}
+
+func (r Report) String() string {
+	var head string
+	switch r.Kind {
+	case Inversion:
+		head = fmt.Sprintf("lockdebug: %v: goroutine %d acquires %s while holding %s, but goroutine %d acquired them in the opposite order",
+			r.Kind, r.Goroutine, r.Lock, r.Other, r.OtherGoroutine)
+	case WaitTooLong:
+		head = fmt.Sprintf("lockdebug: %v: goroutine %d has waited %v for %s held by goroutine %d",
+			r.Kind, r.Goroutine, r.Duration, r.Lock, r.OtherGoroutine)
+	case HeldTooLong:
+		head = fmt.Sprintf("lockdebug: %v: %s was held by goroutine %d for %v",
+			r.Kind, r.Lock, r.OtherGoroutine, r.Duration)
+	default:
+		head = fmt.Sprintf("lockdebug: %v: goroutine %d acquires %s again", r.Kind, r.Goroutine, r.Lock)
+	}
+	return fmt.Sprintf("%s\n\n%s\n%s", head, r.Stack, r.OtherStack)
+}
+
+var (
+	threshold atomic.Int64
+	reporter  atomic.Pointer[func(Report)]
+)
+
+func init() {
+	threshold.Store(int64(time.Second))
+	SetReporter(nil)
+}
+
+// SetThreshold 設定 WaitTooLong 與 HeldTooLong 的門檻，預設為 1 秒。d <= 0 時停用這兩種檢查。
+func SetThreshold(d time.Duration) {
+	threshold.Store(int64(d))
+}
+
+// SetReporter 設定收到報告時呼叫的函式，並回傳原本的函式。
+// f 為 nil 時恢復預設行為：把報告印到 os.Stderr。f 可能同時在多個 Goroutine 中被呼叫。
+func SetReporter(f func(Report)) func(Report) {
+	if f == nil {
+		f = func(r Report) { fmt.Fprintln(os.Stderr, r) }
+	}
+	old := reporter.Swap(&f)
+	if old == nil {
+		return nil
+	}
+	return *old
+}
+
+func report(r Report) {
+	(*reporter.Load())(r)
+}
@@ -0,0 +1,90 @@
+package lockdebug
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 不論是否以 lockdebug 編譯，Mutex 與 RWMutex 的行為都必須與 sync 版本相同。
+func TestMutualExclusion(t *testing.T) {
+	old := SetReporter(func(r Report) { t.Errorf("非預期的報告: %v", r) })
+	defer SetReporter(old)
+
+	var m Mutex
+	var rw RWMutex
+	n := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			m.Lock()
+			defer m.Unlock()
+			n++
+		}()
+		go func() {
+			defer wg.Done()
+			rw.RLock()
+			defer rw.RUnlock()
+		}()
+	}
+	wg.Wait()
+	if n != 50 {
+		t.Errorf("n = %d; 預期為 50", n)
+	}
+
+	if !m.TryLock() {
+		t.Fatal("TryLock() = false; 預期可以取得沒有被持有的鎖")
+	}
+	if m.TryLock() {
+		t.Error("TryLock() = true; 預期無法取得已被持有的鎖")
+	}
+	m.Unlock()
+
+	rw.RLock()
+	if rw.TryLock() {
+		t.Error("持有讀鎖時 TryLock() = true; 預期為 false")
+	}
+	if !rw.TryRLock() {
+		t.Error("持有讀鎖時 TryRLock() = false; 預期可以再取得讀鎖")
+	} else {
+		rw.RUnlock()
+	}
+	rw.RUnlock()
+
+	l := rw.RLocker()
+	l.Lock()
+	if rw.TryLock() {
+		t.Error("RLocker 持有讀鎖時 TryLock() = true; 預期為 false")
+	}
+	l.Unlock()
+}
+
+func TestReportString(t *testing.T) {
+	testCases := []struct {
+		report Report
+		want   string
+	}{
+		{Report{Kind: Inversion, Lock: "b", Other: "a", Goroutine: 1, OtherGoroutine: 2},
+			"lock order inversion: goroutine 1 acquires b while holding a, but goroutine 2"},
+		{Report{Kind: WaitTooLong, Lock: "a", Goroutine: 1, OtherGoroutine: 2, Duration: time.Second},
+			"goroutine 1 has waited 1s for a held by goroutine 2"},
+		{Report{Kind: HeldTooLong, Lock: "a", OtherGoroutine: 2, Duration: time.Second},
+			"a was held by goroutine 2 for 1s"},
+		{Report{Kind: Relock, Lock: "a", Goroutine: 1},
+			"recursive locking: goroutine 1 acquires a again"},
+	}
+	for _, tc := range testCases {
+		if got := tc.report.String(); !strings.Contains(got, tc.want) {
+			t.Errorf("String() = %q; 預期包含 %q", got, tc.want)
+		}
+	}
+	if got := (&Mutex{Name: "accounts"}).String(); got != "accounts" {
+		t.Errorf("String() = %q; 預期為 \"accounts\"", got)
+	}
+	if got := (&RWMutex{}).String(); !strings.HasPrefix(got, "*lockdebug.RWMutex(0x") {
+		t.Errorf("String() = %q; 預期以位址表示", got)
+	}
+}
@@ -1,8 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Sync/counters"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Sync/lazy"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Sync/lockdebug"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Sync/semaphore"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Sync/singleflight"
 )
 
 // --- sync.Mutex Example ---
@@ -29,6 +39,25 @@ func (c *SafeCounter) Value() int {
 	return c.counter
 }
 
+// --- Counter Family Example ---
+
+// countWith 讓 n 個 goroutine 並發地對 c 遞增，回傳最終的值與花費的時間。
+func countWith(c counters.Counter, n int) (int64, time.Duration) {
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+	return c.Value(), time.Since(start)
+}
+
 // --- sync.Once Example ---
 
 var once sync.Once
@@ -37,9 +66,60 @@ func initialize() {
 	fmt.Println("This will be printed only once.")
 }
 
+// --- Lazy Initialization Example ---
+
+// flakyConnect 模擬一個前兩次會失敗的連線動作。
+func flakyConnect() func() (string, error) {
+	attempts := 0
+	return func() (string, error) {
+		attempts++
+		if attempts < 3 {
+			return "", errors.New("connection refused")
+		}
+		return fmt.Sprintf("connected after %d attempts", attempts), nil
+	}
+}
+
+// --- Semaphore Example ---
+
+// weightedFile 是一個需要 weight 單位資源才能處理的檔案。
+type weightedFile struct {
+	name   string
+	weight int64
+}
+
+// --- Singleflight Example ---
+
+// slowLookup 模擬一個很慢的資料庫查詢，並記錄實際查詢的次數。
+func slowLookup(queries *atomic.Int32) func(ctx context.Context, id int) (string, error) {
+	return func(ctx context.Context, id int) (string, error) {
+		queries.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return fmt.Sprintf("user-%d", id), nil
+	}
+}
+
+// --- Lock Ordering Diagnostics Example ---
+
+// ledger 是一個以 lockdebug.Mutex 保護的帳戶。
+type ledger struct {
+	mu      lockdebug.Mutex
+	balance int
+}
+
+// transfer 先鎖住 from 再鎖住 to。transfer(a, b) 與 transfer(b, a) 同時執行時可能互相等待而死結。
+func transfer(from, to *ledger, amount int) {
+	from.mu.Lock()
+	defer from.mu.Unlock()
+	to.mu.Lock()
+	defer to.mu.Unlock()
+	from.balance -= amount
+	to.balance += amount
+}
+
 func main() {
 	// --- Mutex Demo ---
-	fmt.Println("---\ sync.Mutex Example ---")
+	fmt.Println("--- sync.Mutex Example ---")
 	sc := SafeCounter{counter: 0}
 	var wg sync.WaitGroup
 
@@ -55,6 +135,22 @@ func main() {
 	wg.Wait() // 等待所有 goroutine 完成
 	fmt.Println("Final counter value:", sc.Value()) // 如果沒有 Mutex 保護，結果將不確定
 
+	// --- Counter Family Demo ---
+	fmt.Println("\n--- Counter Family Example ---")
+	// 同樣的工作量交給不同的實作，比較它們的耗時 (詳細比較請執行 counters 套件的 benchmark)。
+	for _, c := range []struct {
+		name    string
+		counter counters.Counter
+	}{
+		{"Mutex", &counters.MutexCounter{}},
+		{"RWMutex", &counters.RWMutexCounter{}},
+		{"Atomic", &counters.AtomicCounter{}},
+		{"Sharded", counters.NewSharded(0)},
+	} {
+		value, elapsed := countWith(c.counter, 100)
+		fmt.Printf("%-8s value=%d elapsed=%v\n", c.name, value, elapsed)
+	}
+
 	// --- Once Demo ---
 	fmt.Println("\n--- sync.Once Example ---")
 	var onceWg sync.WaitGroup
@@ -72,4 +168,59 @@ func main() {
 
 	onceWg.Wait()
 	fmt.Println("Done.")
+
+	// --- Lazy Demo ---
+	fmt.Println("\n--- Lazy Initialization Example ---")
+	// sync.Once 失敗後就不會再執行；lazy.OnceValue 只快取成功的結果，失敗時下一次呼叫會重試。
+	conn := lazy.OnceValue(flakyConnect())
+	for i := 1; i <= 4; i++ {
+		v, err := conn()
+		fmt.Printf("call %d: value=%q err=%v\n", i, v, err)
+	}
+
+	// --- Semaphore Demo ---
+	fmt.Println("\n--- Semaphore Example ---")
+	// 總共 4 個單位的「資源」，大檔案需要 2 個單位，小檔案 1 個；同時使用量不會超過 4。
+	files := []weightedFile{
+		{"a.log", 1}, {"big.iso", 2}, {"b.log", 1}, {"huge.tar", 2}, {"c.log", 1},
+	}
+	sem := semaphore.NewWeighted(4)
+	err := semaphore.ParallelForEach(context.Background(), files, 0, func(ctx context.Context, f weightedFile) error {
+		if err := sem.Acquire(ctx, f.weight); err != nil {
+			return err
+		}
+		defer sem.Release(f.weight)
+		fmt.Printf("Processing %s (weight %d)\n", f.name, f.weight)
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	fmt.Println("All files processed, err =", err)
+
+	// --- Singleflight Demo ---
+	fmt.Println("\n--- Singleflight Example ---")
+	// 20 個同時的請求與之後的重複請求都查詢同一個使用者，但資料庫只會被查詢一次：
+	// 同時的請求由 singleflight 合併，之後的請求命中 TTL 快取。
+	var queries atomic.Int32
+	users := singleflight.NewMemo(time.Minute, slowLookup(&queries))
+	var sfWg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		sfWg.Add(1)
+		go func() {
+			defer sfWg.Done()
+			users.Get(context.Background(), 1)
+		}()
+	}
+	sfWg.Wait()
+	name, _ := users.Get(context.Background(), 1)
+	fmt.Printf("21 requests for %s, %d database query\n", name, queries.Load())
+
+	// --- Lock Ordering Diagnostics Demo ---
+	fmt.Println("\n--- Lock Ordering Diagnostics Example ---")
+	// 兩次轉帳依序執行，這次不會死結；以 `go run -tags lockdebug .` 執行時，
+	// lockdebug 會在第二次轉帳時報告順序相反的兩把鎖，並印出兩次取得鎖的堆疊。
+	alice := &ledger{mu: lockdebug.Mutex{Name: "alice"}, balance: 100}
+	bob := &ledger{mu: lockdebug.Mutex{Name: "bob"}, balance: 100}
+	transfer(alice, bob, 30)
+	transfer(bob, alice, 10)
+	fmt.Printf("alice=%d bob=%d (lockdebug enabled: %v)\n", alice.balance, bob.balance, lockdebug.Enabled)
 }
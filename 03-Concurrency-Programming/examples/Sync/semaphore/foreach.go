@@ -0,0 +1,56 @@
+package semaphore
+
+import (
+	"context"
+	"sync"
+)
+
+// ParallelForEach 對 items 中的每個元素呼叫 fn，最多同時執行 limit 個 (limit <= 0 代表不限制)。
+//
+// 第一個回傳錯誤的呼叫會取消傳給其他呼叫的 ctx，尚未開始的元素不會再被處理，
+// ParallelForEach 等待所有已開始的呼叫結束後回傳該錯誤。
+// 外層 ctx 被取消而有元素沒有被處理時回傳 ctx.Err()；所有元素都已經成功處理時，
+// 即使外層 ctx 在等待期間被取消也回傳 nil。
+func ParallelForEach[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) error) error {
+	if limit <= 0 || limit > len(items) {
+		limit = len(items)
+	}
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := NewWeighted(int64(limit))
+	var wg sync.WaitGroup
+	skipped := false
+	for _, item := range items {
+		// ctx 結束 (任一呼叫失敗或外層取消) 時 Acquire 會失敗，停止分派新的元素。
+		if err := sem.Acquire(ctx, 1); err != nil {
+			skipped = true
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer sem.Release(1)
+			if err := fn(ctx, item); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if skipped {
+		return parent.Err()
+	}
+	return nil
+}
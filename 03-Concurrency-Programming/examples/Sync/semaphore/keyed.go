@@ -0,0 +1,77 @@
+package semaphore
+
+import "sync"
+
+// keyedLock 是 KeyedMutex 中一個 key 的鎖，refs 記錄持有或等待它的 Goroutine 數量。
+type keyedLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// KeyedMutex 為每個字串 key 提供一把獨立的互斥鎖，例如「同一個使用者的請求依序處理，
+// 不同使用者之間可以平行」。沒有人使用的 key 會被移除，因此 map 不會無限制地成長。
+// 零值的 KeyedMutex 可以直接使用。
+type KeyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// acquire 取得 key 的鎖物件並增加參考計數。
+func (m *KeyedMutex) acquire(key string) *keyedLock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.locks == nil {
+		m.locks = make(map[string]*keyedLock)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		m.locks[key] = l
+	}
+	l.refs++
+	return l
+}
+
+// release 減少參考計數，沒有人使用時從 map 移除。
+func (m *KeyedMutex) release(key string, l *keyedLock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l.refs--
+	if l.refs == 0 {
+		delete(m.locks, key)
+	}
+}
+
+// Lock 鎖住 key，必要時等待其他持有者解鎖。
+func (m *KeyedMutex) Lock(key string) {
+	m.acquire(key).mu.Lock()
+}
+
+// TryLock 嘗試在不阻塞的情況下鎖住 key，並回傳是否成功。
+func (m *KeyedMutex) TryLock(key string) bool {
+	l := m.acquire(key)
+	if l.mu.TryLock() {
+		return true
+	}
+	m.release(key, l)
+	return false
+}
+
+// Unlock 解鎖 key。解鎖一個沒有被鎖住的 key 會 panic。
+func (m *KeyedMutex) Unlock(key string) {
+	m.mu.Lock()
+	l, ok := m.locks[key]
+	m.mu.Unlock()
+	if !ok {
+		panic("semaphore: unlock of unlocked key " + key)
+	}
+	l.mu.Unlock()
+	m.release(key, l)
+}
+
+// Len 回傳目前被持有或等待中的 key 數量。
+func (m *KeyedMutex) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.locks)
+}
@@ -0,0 +1,287 @@
+package semaphore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+func TestWeightedLimitsConcurrency(t *testing.T) {
+	leakcheck.Check(t)
+	const size = 5
+	sem := NewWeighted(size)
+
+	var cur, peak atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		n := int64(i%3 + 1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sem.Acquire(context.Background(), n); err != nil {
+				t.Errorf("Acquire(%d) 錯誤 = %v", n, err)
+				return
+			}
+			defer sem.Release(n)
+			v := cur.Add(n)
+			for {
+				p := peak.Load()
+				if v <= p || peak.CompareAndSwap(p, v) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			cur.Add(-n)
+		}()
+	}
+	wg.Wait()
+	if p := peak.Load(); p > size {
+		t.Errorf("同時持有 %d 個單位; 預期最多 %d", p, size)
+	}
+}
+
+func TestWeightedFIFO(t *testing.T) {
+	leakcheck.Check(t)
+	sem := NewWeighted(3)
+	sem.Acquire(context.Background(), 3)
+
+	// 依序排隊：大請求 (3) 在前，小請求 (1) 在後。
+	var order []int64
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, n := range []int64{3, 1} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem.Acquire(context.Background(), n)
+			mu.Lock()
+			order = append(order, n)
+			mu.Unlock()
+			sem.Release(n)
+		}()
+		// 確保等待者依序進入佇列。
+		waitFor(t, func() bool { return waiters(sem) == i+1 })
+	}
+
+	// 有等待者時 TryAcquire 一律失敗，即使資源足夠也不能插隊。
+	sem.Release(1)
+	if sem.TryAcquire(1) {
+		t.Error("TryAcquire(1) 插隊成功; 預期在有等待者時失敗")
+	}
+	sem.Release(2)
+	wg.Wait()
+
+	if len(order) != 2 || order[0] != 3 || order[1] != 1 {
+		t.Errorf("取得順序 = %v; 預期為 [3 1]", order)
+	}
+}
+
+func TestWeightedAcquireCanceled(t *testing.T) {
+	leakcheck.Check(t)
+	sem := NewWeighted(2)
+	sem.Acquire(context.Background(), 1)
+
+	// 排在最前面的大請求逾時離開後，後面的小請求應該立即被喚醒。
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	acquired := make(chan struct{})
+	go func() {
+		waitFor(t, func() bool { return waiters(sem) == 1 })
+		sem.Acquire(context.Background(), 1)
+		close(acquired)
+	}()
+	if err := sem.Acquire(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire(2) 錯誤 = %v; 預期為 context.DeadlineExceeded", err)
+	}
+	<-acquired
+
+	if sem.TryAcquire(1) {
+		t.Error("資源已經用完，TryAcquire(1) 不應該成功")
+	}
+	sem.Release(2)
+	if !sem.TryAcquire(2) {
+		t.Error("逾時的 Acquire 不應該持有任何資源")
+	}
+}
+
+func TestWeightedReleaseTooMuchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Release 超過持有量時預期會 panic")
+		}
+	}()
+	NewWeighted(1).Release(1)
+}
+
+// waiters 回傳 sem 目前的等待者數量。
+func waiters(sem *Weighted) int {
+	sem.mu.Lock()
+	defer sem.mu.Unlock()
+	return sem.waiters.Len()
+}
+
+// waitFor 輪詢直到 cond 成立，最多等一秒。
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待條件成立逾時")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestKeyedMutex(t *testing.T) {
+	leakcheck.Check(t)
+	var km KeyedMutex
+	counts := map[string]int{}
+	var countsMu sync.Mutex
+	inside := map[string]*atomic.Int32{"a": {}, "b": {}, "c": {}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 90; i++ {
+		key := []string{"a", "b", "c"}[i%3]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			km.Lock(key)
+			defer km.Unlock(key)
+			// 同一個 key 同時只能有一個持有者。
+			if n := inside[key].Add(1); n != 1 {
+				t.Errorf("key %q 同時有 %d 個持有者", key, n)
+			}
+			countsMu.Lock()
+			counts[key]++
+			countsMu.Unlock()
+			inside[key].Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	for _, key := range []string{"a", "b", "c"} {
+		if counts[key] != 30 {
+			t.Errorf("counts[%q] = %d; 預期為 30", key, counts[key])
+		}
+	}
+	if n := km.Len(); n != 0 {
+		t.Errorf("全部解鎖後 Len() = %d; 預期為 0", n)
+	}
+}
+
+func TestKeyedMutexTryLock(t *testing.T) {
+	var km KeyedMutex
+	km.Lock("a")
+	if km.TryLock("a") {
+		t.Error("TryLock(\"a\") 在已上鎖時成功")
+	}
+	if !km.TryLock("b") {
+		t.Error("TryLock(\"b\") 應該不受 \"a\" 影響")
+	}
+	km.Unlock("a")
+	km.Unlock("b")
+	if n := km.Len(); n != 0 {
+		t.Errorf("Len() = %d; 預期為 0", n)
+	}
+}
+
+func TestParallelForEach(t *testing.T) {
+	leakcheck.Check(t)
+	items := make([]int, 100)
+	for i := range items {
+		items[i] = i
+	}
+
+	var cur, peak atomic.Int32
+	var sum atomic.Int64
+	err := ParallelForEach(context.Background(), items, 4, func(ctx context.Context, n int) error {
+		v := cur.Add(1)
+		defer cur.Add(-1)
+		for {
+			p := peak.Load()
+			if v <= p || peak.CompareAndSwap(p, v) {
+				break
+			}
+		}
+		time.Sleep(100 * time.Microsecond)
+		sum.Add(int64(n))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ParallelForEach() 錯誤 = %v", err)
+	}
+	if got := sum.Load(); got != 4950 {
+		t.Errorf("總和 = %d; 預期為 4950", got)
+	}
+	if p := peak.Load(); p > 4 {
+		t.Errorf("同時執行 %d 個; 預期最多 4 個", p)
+	}
+}
+
+func TestParallelForEachStopsOnError(t *testing.T) {
+	leakcheck.Check(t)
+	errBad := errors.New("bad item")
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i
+	}
+
+	var calls atomic.Int32
+	err := ParallelForEach(context.Background(), items, 2, func(ctx context.Context, n int) error {
+		calls.Add(1)
+		if n == 10 {
+			return errBad
+		}
+		return nil
+	})
+	if !errors.Is(err, errBad) {
+		t.Errorf("ParallelForEach() 錯誤 = %v; 預期為 %v", err, errBad)
+	}
+	if n := calls.Load(); n >= 1000 {
+		t.Errorf("呼叫了 %d 次; 預期在錯誤後停止分派", n)
+	}
+}
+
+func TestParallelForEachOuterCancel(t *testing.T) {
+	leakcheck.Check(t)
+	errStop := errors.New("stop")
+	noop := func(ctx context.Context, n int) error { return nil }
+
+	// 有元素沒有被處理時回傳外層的 ctx.Err()，而不是取消的原因。
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errStop)
+	if err := ParallelForEach(ctx, make([]int, 3), 2, noop); err != context.Canceled {
+		t.Errorf("外層已取消: ParallelForEach() 錯誤 = %v; 預期為 context.Canceled", err)
+	}
+
+	// 所有元素都已經開始而且成功時，等待期間的取消不影響結果。
+	ctx, cancel = context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- ParallelForEach(ctx, make([]int, 2), 2, func(ctx context.Context, n int) error {
+			started <- struct{}{}
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	<-started
+	cancel(errStop)
+	close(release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("等待期間取消: ParallelForEach() 錯誤 = %v; 預期為 nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ParallelForEach 沒有返回")
+	}
+}
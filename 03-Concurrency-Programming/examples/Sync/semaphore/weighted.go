@@ -0,0 +1,123 @@
+// Package semaphore 提供限制「同時使用多少資源」的工具。
+//
+// 章節中的範例只能透過固定數量的 worker 來限制併發度，這個套件補上更細緻的做法：
+//
+//   - Weighted：加權號誌 (weighted semaphore)，每次可以取得多個單位，等待者依 FIFO 順序被喚醒。
+//   - KeyedMutex：依字串 key 分別上鎖，不同 key 之間互不阻塞。
+//   - ParallelForEach：以最多 limit 個 Goroutine 平行處理一個 slice。
+package semaphore
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// waiter 是一個正在等待 n 個單位的呼叫者；取得時 ready 會被關閉。
+type waiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// Weighted 是一個總容量為 size 的加權號誌。
+//
+// 等待者嚴格依照到達順序取得資源：即使後面的小請求現在就能滿足，
+// 也要等前面的大請求先取得，避免大請求被不斷插隊而餓死 (starvation)。
+type Weighted struct {
+	size    int64
+	mu      sync.Mutex
+	cur     int64
+	waiters list.List // *waiter
+}
+
+// NewWeighted 建立一個總容量為 n 的 Weighted。
+func NewWeighted(n int64) *Weighted {
+	return &Weighted{size: n}
+}
+
+// Acquire 取得 n 個單位，必要時阻塞直到資源足夠或 ctx 結束。
+// 成功時回傳 nil；ctx 結束時回傳 ctx.Err() 且不持有任何資源。
+// n 大於總容量時永遠無法滿足，會一直等到 ctx 結束。
+func (s *Weighted) Acquire(ctx context.Context, n int64) error {
+	done := ctx.Done()
+
+	s.mu.Lock()
+	select {
+	case <-done:
+		// ctx 已經結束時，即使資源足夠也不取得，行為比較容易預測。
+		s.mu.Unlock()
+		return ctx.Err()
+	default:
+	}
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+
+	w := waiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-done:
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// ctx 結束的同時剛好取得了資源：把它還回去，讓語意保持「失敗就不持有」。
+			s.cur -= n
+			s.notifyWaiters()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// 排在最前面的等待者離開後，後面較小的請求可能已經可以滿足。
+			if isFront && s.size > s.cur {
+				s.notifyWaiters()
+			}
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire 在不阻塞的情況下嘗試取得 n 個單位，並回傳是否成功。
+// 有其他等待者時一律失敗，以維持 FIFO 順序。
+func (s *Weighted) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// Release 歸還 n 個單位。歸還超過持有的數量會 panic。
+func (s *Weighted) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		panic("semaphore: released more than held")
+	}
+	s.notifyWaiters()
+}
+
+// notifyWaiters 依序喚醒目前資源足以滿足的等待者，遇到無法滿足的就停止。呼叫者必須持有 s.mu。
+func (s *Weighted) notifyWaiters() {
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			return
+		}
+		w := next.Value.(waiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(next)
+		close(w.ready)
+	}
+}
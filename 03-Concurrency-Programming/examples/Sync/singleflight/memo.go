@@ -0,0 +1,140 @@
+package singleflight
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+)
+
+// Option 用來調整 Memo 的行為。
+type Option func(*options)
+
+type options struct {
+	clock clock.Clock
+}
+
+// WithClock 指定 Memo 計算到期時間使用的時鐘，預設為 clock.Real()。
+func WithClock(c clock.Clock) Option {
+	return func(o *options) { o.clock = c }
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{clock: clock.Real()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// memoEntry 是快取中的一個值與它的到期時間。
+type memoEntry[V any] struct {
+	val     V
+	expires time.Time
+}
+
+// Memo 把 fn 的結果依 key 快取 ttl 的時間，並用 Group 合併同時發生的計算：
+// 同一個 key 無論是同時還是重複的呼叫，在 ttl 內都只會執行一次 fn。
+//
+// 只有成功的結果會被快取，失敗的計算在下一次呼叫時會重試。
+// 到期的項目在 Get 時順便清除，因此不需要額外的背景 Goroutine。
+type Memo[K comparable, V any] struct {
+	fn    func(ctx context.Context, key K) (V, error)
+	ttl   time.Duration
+	clock clock.Clock
+	group Group[K, V]
+
+	mu        sync.Mutex
+	entries   map[K]memoEntry[V]
+	gens      map[K]uint64 // 每個 key 被 Invalidate 的次數；計算開始與結束時不同就不寫回快取
+	lastSweep time.Time
+}
+
+// NewMemo 建立一個 Memo。ttl <= 0 代表不快取，只合併同時發生的呼叫。
+func NewMemo[K comparable, V any](ttl time.Duration, fn func(ctx context.Context, key K) (V, error), opts ...Option) *Memo[K, V] {
+	return &Memo[K, V]{
+		fn:      fn,
+		ttl:     ttl,
+		clock:   newOptions(opts).clock,
+		entries: make(map[K]memoEntry[V]),
+		gens:    make(map[K]uint64),
+	}
+}
+
+// Get 回傳 key 的值：快取中有未到期的值就直接回傳，否則執行 (或加入正在進行的) 計算。
+//
+// 計算使用第一個呼叫者的 ctx 中的值，但不會因為它被取消而中止，
+// 因為其他呼叫者可能還在等待同一個結果；每個呼叫者可以在自己的 ctx 結束時各自放棄等待。
+func (m *Memo[K, V]) Get(ctx context.Context, key K) (V, error) {
+	m.mu.Lock()
+	now := m.clock.Now()
+	m.sweepLocked(now)
+	if e, ok := m.entries[key]; ok && now.Before(e.expires) {
+		m.mu.Unlock()
+		return e.val, nil
+	}
+	// 在 DoChan 之前取得世代：Invalidate 可能發生在計算登記之後、callback 開始執行之前。
+	gen := m.gens[key]
+	m.mu.Unlock()
+
+	ch := m.group.DoChan(key, func() (V, error) {
+		val, err := m.fn(context.WithoutCancel(ctx), key)
+		if err == nil && m.ttl > 0 {
+			m.mu.Lock()
+			if m.gens[key] == gen {
+				m.entries[key] = memoEntry[V]{val: val, expires: m.clock.Now().Add(m.ttl)}
+			}
+			m.mu.Unlock()
+		}
+		return val, err
+	})
+	select {
+	case r := <-ch:
+		return r.Val, r.Err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// Invalidate 丟棄 key 的快取值，之後的 Get 會重新計算。
+// 正在進行中的計算仍會把結果交給等待者，但不會寫回快取。
+func (m *Memo[K, V]) Invalidate(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	m.gens[key]++
+	m.group.Forget(key)
+}
+
+// Len 回傳快取中的項目數量 (包含已到期但尚未清除的項目)。
+func (m *Memo[K, V]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+// Sweep 立即清除所有已到期的項目，並回傳清除的數量。
+func (m *Memo[K, V]) Sweep() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastSweep = time.Time{}
+	return m.sweepLocked(m.clock.Now())
+}
+
+// sweepLocked 最多每 ttl 掃描一次整個 map，讓 Get 的平均成本維持在 O(1)。
+func (m *Memo[K, V]) sweepLocked(now time.Time) int {
+	if m.ttl <= 0 || now.Sub(m.lastSweep) < m.ttl {
+		return 0
+	}
+	m.lastSweep = now
+	n := 0
+	for key, e := range m.entries {
+		if !now.Before(e.expires) {
+			delete(m.entries, key)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,131 @@
+// Package singleflight 合併同時發生的相同呼叫 (request coalescing)。
+//
+// 當許多 Goroutine 同時查詢同一個 key (例如快取失效瞬間湧入的請求)，
+// Group 只讓第一個呼叫真的執行，其他呼叫等待並共用它的結果，避免重複打到後端。
+// Memo 進一步把成功的結果保存一段時間 (TTL)，讓之後的呼叫直接命中快取。
+//
+// 這是 golang.org/x/sync/singleflight 的泛型版本，並把 panic 轉成 *group.PanicError
+// 回傳給所有等待者，而不是讓它們永遠等下去。
+package singleflight
+
+import (
+	"errors"
+	"runtime/debug"
+	"sync"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutines/group"
+)
+
+// ErrGoexit 表示函式呼叫了 runtime.Goexit (例如在測試中呼叫 t.FailNow)，沒有產生結果。
+var ErrGoexit = errors.New("singleflight: function called runtime.Goexit")
+
+// Result 是 DoChan 送出的結果。Shared 表示這個結果是否也交給了其他呼叫者。
+type Result[V any] struct {
+	Val    V
+	Err    error
+	Shared bool
+}
+
+// call 是一次正在進行 (或已完成) 的呼叫。
+type call[V any] struct {
+	wg    sync.WaitGroup
+	val   V
+	err   error
+	dups  int
+	chans []chan<- Result[V]
+}
+
+// Group 管理以 K 為 key 的呼叫。零值即可使用，不可複製。
+type Group[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+// Do 執行 fn 並回傳結果；若相同 key 的呼叫正在進行，就等待它並共用結果。
+// shared 表示結果是否也交給了其他呼叫者。
+func (g *Group[K, V]) Do(key K, fn func() (V, error)) (v V, err error, shared bool) {
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := g.start(key)
+	g.mu.Unlock()
+
+	g.doCall(c, key, fn)
+	return c.val, c.err, c.dups > 0
+}
+
+// DoChan 與 Do 相同，但不阻塞，而是回傳一個會收到結果的 channel。
+// 呼叫者可以搭配 select 在自己的 ctx 結束時放棄等待，fn 仍會繼續執行完。
+func (g *Group[K, V]) DoChan(key K, fn func() (V, error)) <-chan Result[V] {
+	ch := make(chan Result[V], 1)
+	g.mu.Lock()
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		c.chans = append(c.chans, ch)
+		g.mu.Unlock()
+		return ch
+	}
+	c := g.start(key)
+	c.chans = append(c.chans, ch)
+	g.mu.Unlock()
+
+	go g.doCall(c, key, fn)
+	return ch
+}
+
+// Forget 讓之後對 key 的呼叫不再等待目前正在進行的那一次，而是重新執行。
+// 已經在等待的呼叫者仍會拿到原本的結果。
+func (g *Group[K, V]) Forget(key K) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.calls, key)
+}
+
+// start 登記一個新的呼叫。呼叫者必須持有 g.mu。
+func (g *Group[K, V]) start(key K) *call[V] {
+	if g.calls == nil {
+		g.calls = make(map[K]*call[V])
+	}
+	c := new(call[V])
+	c.wg.Add(1)
+	g.calls[key] = c
+	return c
+}
+
+// doCall 執行 fn 並把結果交給所有等待者。
+// fn 發生 panic 時回傳 *group.PanicError；呼叫 runtime.Goexit 時回傳 ErrGoexit。
+func (g *Group[K, V]) doCall(c *call[V], key K, fn func() (V, error)) {
+	normalReturn := false
+	defer func() {
+		if !normalReturn && c.err == nil {
+			c.err = ErrGoexit
+		}
+
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		c.wg.Done()
+		// 若已經被 Forget 且有新的呼叫登記在同一個 key，不能刪掉新的那個。
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		for _, ch := range c.chans {
+			ch <- Result[V]{Val: c.val, Err: c.err, Shared: c.dups > 0}
+		}
+	}()
+
+	func() {
+		defer func() {
+			if !normalReturn {
+				if v := recover(); v != nil {
+					c.err = &group.PanicError{Value: v, Stack: debug.Stack()}
+				}
+			}
+		}()
+		c.val, c.err = fn()
+		normalReturn = true
+	}()
+}
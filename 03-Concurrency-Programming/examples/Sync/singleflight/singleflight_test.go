@@ -0,0 +1,309 @@
+package singleflight
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Clock/clock"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutines/group"
+)
+
+var errBoom = errors.New("boom")
+
+// waitDups 等到 key 的呼叫已經開始，且有 n 個等待者加入。
+func waitDups[K comparable, V any](t *testing.T, g *Group[K, V], key K, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		g.mu.Lock()
+		c, ok := g.calls[key]
+		dups := -1
+		if ok {
+			dups = c.dups
+		}
+		g.mu.Unlock()
+		if dups >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("只有 %d 個等待者; 預期 %d 個", dups, n)
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestDoCoalesces(t *testing.T) {
+	leakcheck.Check(t)
+	var g Group[string, int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func() (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	var shared atomic.Int32
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err, s := g.Do("key", fn)
+			if v != 42 || err != nil {
+				t.Errorf("Do() = (%d, %v); 預期為 (42, nil)", v, err)
+			}
+			if s {
+				shared.Add(1)
+			}
+		}()
+	}
+	waitDups(t, &g, "key", n-1)
+	close(release)
+	wg.Wait()
+
+	if c := calls.Load(); c != 1 {
+		t.Errorf("fn 被呼叫 %d 次; 預期為 1", c)
+	}
+	if s := shared.Load(); s != n {
+		t.Errorf("shared 為 true 的呼叫有 %d 個; 預期為 %d", s, n)
+	}
+
+	// 完成後的呼叫會重新執行，且不是共用的結果。
+	if _, _, s := g.Do("key", fn); s || calls.Load() != 2 {
+		t.Errorf("完成後的 Do(): shared = %v, 呼叫次數 = %d; 預期為 false, 2", s, calls.Load())
+	}
+}
+
+func TestDoChan(t *testing.T) {
+	leakcheck.Check(t)
+	var g Group[int, string]
+	release := make(chan struct{})
+	first := g.DoChan(1, func() (string, error) {
+		<-release
+		return "", errBoom
+	})
+	second := g.DoChan(1, func() (string, error) {
+		t.Error("第二個 fn 不應該被執行")
+		return "", nil
+	})
+	close(release)
+
+	for _, ch := range []<-chan Result[string]{first, second} {
+		r := <-ch
+		if !errors.Is(r.Err, errBoom) || !r.Shared {
+			t.Errorf("結果 = %+v; 預期錯誤為 %v 且 Shared", r, errBoom)
+		}
+	}
+}
+
+func TestForget(t *testing.T) {
+	leakcheck.Check(t)
+	var g Group[string, int]
+	release := make(chan struct{})
+	old := g.DoChan("key", func() (int, error) {
+		<-release
+		return 1, nil
+	})
+
+	// Forget 之後的呼叫不再等待舊的那一次。
+	g.Forget("key")
+	if v, _, shared := g.Do("key", func() (int, error) { return 2, nil }); v != 2 || shared {
+		t.Errorf("Forget 後 Do() = (%d, shared=%v); 預期為 (2, false)", v, shared)
+	}
+
+	// 舊的呼叫仍然把結果交給原本的等待者。
+	close(release)
+	if r := <-old; r.Val != 1 {
+		t.Errorf("舊的呼叫結果 = %d; 預期為 1", r.Val)
+	}
+}
+
+func TestPanicAndGoexit(t *testing.T) {
+	leakcheck.Check(t)
+	var g Group[string, int]
+
+	_, err, _ := g.Do("panic", func() (int, error) { panic(errBoom) })
+	var pe *group.PanicError
+	if !errors.As(err, &pe) || !errors.Is(err, errBoom) {
+		t.Errorf("panic 的錯誤 = %v; 預期為包裝 %v 的 *group.PanicError", err, errBoom)
+	}
+
+	r := <-g.DoChan("goexit", func() (int, error) {
+		runtime.Goexit()
+		return 0, nil
+	})
+	if !errors.Is(r.Err, ErrGoexit) {
+		t.Errorf("Goexit 的錯誤 = %v; 預期為 ErrGoexit", r.Err)
+	}
+
+	// 出錯之後 key 會被清除，下一次呼叫可以正常執行。
+	if v, err, _ := g.Do("panic", func() (int, error) { return 1, nil }); v != 1 || err != nil {
+		t.Errorf("panic 之後 Do() = (%d, %v); 預期為 (1, nil)", v, err)
+	}
+}
+
+func TestMemoTTL(t *testing.T) {
+	leakcheck.Check(t)
+	fake := clock.NewFake(time.Unix(0, 0))
+	var calls atomic.Int32
+	fail := true
+	m := NewMemo(time.Minute, func(ctx context.Context, id int) (string, error) {
+		calls.Add(1)
+		if fail {
+			return "", errBoom
+		}
+		return "user", nil
+	}, WithClock(fake))
+	ctx := context.Background()
+
+	testCases := []struct {
+		name      string
+		advance   time.Duration
+		fail      bool
+		wantErr   error
+		wantCalls int32
+	}{
+		{"失敗不會被快取", 0, true, errBoom, 1},
+		{"下一次重試並成功", 0, false, nil, 2},
+		{"TTL 內命中快取", 59 * time.Second, false, nil, 2},
+		{"TTL 到期後重新計算", time.Second, false, nil, 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake.Advance(tc.advance)
+			fail = tc.fail
+			if _, err := m.Get(ctx, 1); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Get() 錯誤 = %v; 預期為 %v", err, tc.wantErr)
+			}
+			if c := calls.Load(); c != tc.wantCalls {
+				t.Errorf("fn 被呼叫 %d 次; 預期為 %d", c, tc.wantCalls)
+			}
+		})
+	}
+
+	m.Invalidate(1)
+	m.Get(ctx, 1)
+	if c := calls.Load(); c != 4 {
+		t.Errorf("Invalidate 後 fn 被呼叫 %d 次; 預期為 4", c)
+	}
+
+	m.Get(ctx, 2)
+	fake.Advance(time.Minute)
+	if n := m.Sweep(); n != 2 || m.Len() != 0 {
+		t.Errorf("Sweep() = %d, Len() = %d; 預期為 2, 0", n, m.Len())
+	}
+}
+
+func TestMemoConcurrentAndCancel(t *testing.T) {
+	leakcheck.Check(t)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	m := NewMemo(time.Minute, func(ctx context.Context, key string) (int, error) {
+		calls.Add(1)
+		<-release
+		return len(key), ctx.Err() // 計算不受第一個呼叫者取消的影響
+	})
+
+	// 第一個呼叫者放棄等待。
+	ctx, cancel := context.WithCancel(context.Background())
+	firstDone := make(chan error, 1)
+	go func() {
+		_, err := m.Get(ctx, "alice")
+		firstDone <- err
+	}()
+	waitDups(t, &m.group, "alice", 0)
+	cancel()
+	if err := <-firstDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("取消後 Get() 錯誤 = %v; 預期為 context.Canceled", err)
+	}
+
+	// 其他呼叫者仍然共用同一個計算。
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := m.Get(context.Background(), "alice"); v != 5 || err != nil {
+				t.Errorf("Get() = (%d, %v); 預期為 (5, nil)", v, err)
+			}
+		}()
+	}
+	waitDups(t, &m.group, "alice", 5)
+	close(release)
+	wg.Wait()
+
+	if c := calls.Load(); c != 1 {
+		t.Errorf("fn 被呼叫 %d 次; 預期為 1", c)
+	}
+	if v, _ := m.Get(context.Background(), "alice"); v != 5 || calls.Load() != 1 {
+		t.Errorf("之後的 Get() = %d, 呼叫次數 = %d; 預期使用快取", v, calls.Load())
+	}
+}
+
+func TestMemoInvalidateDuringCall(t *testing.T) {
+	leakcheck.Check(t)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	m := NewMemo(time.Minute, func(ctx context.Context, key string) (int32, error) {
+		n := calls.Add(1)
+		if n == 1 {
+			<-release
+		}
+		return n, nil
+	})
+
+	done := make(chan int32)
+	go func() {
+		v, _ := m.Get(context.Background(), "k")
+		done <- v
+	}()
+	waitDups(t, &m.group, "k", 0)
+	m.Invalidate("k")
+	close(release)
+	if v := <-done; v != 1 {
+		t.Errorf("進行中的呼叫結果 = %d; 預期為 1", v)
+	}
+
+	// 在 Invalidate 之前開始的計算不會寫回快取。
+	if v, _ := m.Get(context.Background(), "k"); v != 2 {
+		t.Errorf("Invalidate 後 Get() = %d; 預期重新計算得到 2", v)
+	}
+}
+
+func TestMemoInvalidateOtherKey(t *testing.T) {
+	leakcheck.Check(t)
+	var calls atomic.Int32
+	release := make(chan struct{})
+	m := NewMemo(time.Minute, func(ctx context.Context, key string) (int32, error) {
+		n := calls.Add(1)
+		if key == "b" {
+			<-release
+		}
+		return n, nil
+	})
+
+	done := make(chan int32)
+	go func() {
+		v, _ := m.Get(context.Background(), "b")
+		done <- v
+	}()
+	waitDups(t, &m.group, "b", 0)
+	m.Invalidate("a")
+	close(release)
+	if v := <-done; v != 1 {
+		t.Errorf("進行中的呼叫結果 = %d; 預期為 1", v)
+	}
+
+	// 使 "a" 失效不影響 "b" 進行中的計算寫回快取。
+	if v, _ := m.Get(context.Background(), "b"); v != 1 || calls.Load() != 1 {
+		t.Errorf("Get(\"b\") = %d, 呼叫次數 = %d; 預期使用快取的 1", v, calls.Load())
+	}
+}
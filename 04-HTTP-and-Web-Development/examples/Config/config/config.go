@@ -0,0 +1,545 @@
+// Package config 把多個來源的設定載入到一個有型別的 struct，並驗證結果。
+//
+//	type Config struct {
+//		HTTP struct {
+//			Addr        string        `json:"addr" default:":8080" usage:"HTTP 監聽位址"`
+//			ReadTimeout time.Duration `json:"read_timeout" default:"15s"`
+//		} `json:"http"`
+//		Log struct {
+//			Level string `json:"level" default:"info" validate:"oneof=debug info warn error"`
+//		} `json:"log"`
+//		DB struct {
+//			Password config.Secret `json:"password"`
+//		} `json:"db"`
+//	}
+//
+//	var cfg Config
+//	err := config.Load(&cfg,
+//		config.WithOptionalFile("config.yaml"),
+//		config.WithEnv("APP"),
+//		config.WithArgs(os.Args[1:]),
+//	)
+//
+// 每個欄位的鍵是以點連接的 json 名稱 (沒有 json tag 時為欄位名稱)，例如 "http.read_timeout"。
+// 來源的優先順序由低到高：
+//
+//  1. default tag
+//  2. 設定檔，依 WithFile 的順序，後面的覆蓋前面的；格式由副檔名決定 (.yaml、.yml、.toml、.json)
+//  3. 環境變數：前綴加上大寫的鍵，點換成底線，例如 APP_HTTP_READ_TIMEOUT
+//  4. 命令列旗標：旗標名稱就是鍵，例如 -http.read_timeout=30s
+//
+// 沒有被任何來源設定、也沒有 default tag 的欄位保留 dst 原本的值。
+// 設定檔中不認得的鍵是錯誤，可以及早發現打錯字的鍵。
+//
+// 任何鍵加上 _file 後綴 (環境變數是 _FILE) 表示從檔案讀取值，例如
+// APP_DB_PASSWORD_FILE=/run/secrets/db_password；這是 Docker 與 Kubernetes 提供 secret 的慣例，
+// 比把密碼直接放在環境變數或旗標 (會出現在 ps 的輸出中) 安全。
+// 密碼這類欄位請使用 Secret 型別，Print 與 fmt 都不會印出它的內容。
+//
+// 全部來源套用之後，以 validate tag (見 validate 套件) 驗證；
+// 若 struct 實作了 Validate() error，也會呼叫它做跨欄位的檢查。
+//
+// 支援的欄位型別：string、bool、整數、浮點數、time.Duration、實作 encoding.TextUnmarshaler 的型別、
+// 它們的 slice (環境變數與旗標以逗號分隔)，以及巢狀的 struct。
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/validate"
+)
+
+// Option 用來設定 Load 與 Watch 的來源。
+type Option func(*settings)
+
+type settings struct {
+	files        []file
+	env          bool
+	envPrefix    string
+	args         []string
+	flags        bool
+	pollInterval time.Duration
+	signals      []os.Signal
+}
+
+type file struct {
+	path     string
+	optional bool
+}
+
+// WithFile 從 path 載入設定；檔案不存在是錯誤。可以指定多次，後面的檔案覆蓋前面的。
+func WithFile(path string) Option {
+	return func(s *settings) { s.files = append(s.files, file{path: path}) }
+}
+
+// WithOptionalFile 與 WithFile 相同，但檔案不存在時略過。
+func WithOptionalFile(path string) Option {
+	return func(s *settings) { s.files = append(s.files, file{path: path, optional: true}) }
+}
+
+// WithEnv 從環境變數載入設定，變數名稱為 prefix 加上底線與大寫的鍵；prefix 為空字串時沒有前綴。
+func WithEnv(prefix string) Option {
+	return func(s *settings) {
+		s.env = true
+		s.envPrefix = prefix
+	}
+}
+
+// WithArgs 從命令列旗標載入設定，args 通常是 os.Args[1:]。
+// 每個鍵都是一個旗標，-h 會印出所有的旗標與預設值，這時 Load 回傳包裝 flag.ErrHelp 的錯誤。
+// 旗標之後的其他參數被忽略。
+func WithArgs(args []string) Option {
+	return func(s *settings) {
+		s.flags = true
+		s.args = args
+	}
+}
+
+func newSettings(opts []Option) settings {
+	s := settings{pollInterval: defaultPollInterval, signals: defaultSignals}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// leaf 是 struct 中一個可以設定的欄位。
+type leaf struct {
+	key    string // 以點連接的鍵，例如 "http.read_timeout"
+	index  []int
+	typ    reflect.Type
+	def    string
+	hasDef bool
+	usage  string
+}
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// leavesOf 依宣告的順序列出 t 的所有欄位；巢狀的 struct 會展開，內嵌且沒有 json 名稱的 struct 欄位會提升到外層。
+func leavesOf(t reflect.Type, prefix string, index []int) ([]leaf, error) {
+	var leaves []leaf
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		idx := append(slices.Clip(index), i)
+		key := join(prefix, name)
+		if name == "" {
+			key = join(prefix, sf.Name)
+		}
+		if sf.Type.Kind() == reflect.Struct && !isScalar(sf.Type) {
+			if name == "" && sf.Anonymous {
+				key = prefix
+			}
+			nested, err := leavesOf(sf.Type, key, idx)
+			if err != nil {
+				return nil, err
+			}
+			leaves = append(leaves, nested...)
+			continue
+		}
+		if !supported(sf.Type) {
+			return nil, fmt.Errorf("config: %s: unsupported type %s", key, sf.Type)
+		}
+		def, hasDef := sf.Tag.Lookup("default")
+		leaves = append(leaves, leaf{key: key, index: idx, typ: sf.Type, def: def, hasDef: hasDef, usage: sf.Tag.Get("usage")})
+	}
+	return leaves, nil
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// isScalar 回傳 t 是否從一個字串解析，例如 time.Duration 或實作 encoding.TextUnmarshaler 的型別。
+func isScalar(t reflect.Type) bool {
+	if t == durationType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func supported(t reflect.Type) bool {
+	return isScalar(t) || t.Kind() == reflect.Slice && isScalar(t.Elem())
+}
+
+// Validator 由需要跨欄位檢查的設定 struct 實作，在 validate tag 的檢查之後呼叫。
+type Validator interface {
+	Validate() error
+}
+
+// Load 依序套用所有的來源並驗證，然後才寫入 dst (指向 struct 的指標)；發生錯誤時 dst 不會被修改。
+func Load(dst any, opts ...Option) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: Load(%T): want a non-nil pointer to a struct", dst)
+	}
+	s := newSettings(opts)
+	leaves, err := leavesOf(rv.Elem().Type(), "", nil)
+	if err != nil {
+		return err
+	}
+
+	// 在副本上載入，全部成功之後才寫回 dst。
+	tmp := reflect.New(rv.Elem().Type())
+	tmp.Elem().Set(rv.Elem())
+	ld := &loader{dst: tmp.Elem(), leaves: leaves, byKey: make(map[string]*leaf, len(leaves))}
+	for i := range leaves {
+		ld.byKey[strings.ToLower(leaves[i].key)] = &leaves[i]
+	}
+
+	for _, l := range leaves {
+		if l.hasDef {
+			if err := setValue(ld.dst.FieldByIndex(l.index), l.def); err != nil {
+				return fmt.Errorf("config: default %s: %w", l.key, err)
+			}
+		}
+	}
+	for _, f := range s.files {
+		if err := ld.loadFile(f); err != nil {
+			return err
+		}
+	}
+	if s.env {
+		if err := ld.loadEnv(s.envPrefix); err != nil {
+			return err
+		}
+	}
+	if s.flags {
+		if err := ld.loadFlags(s.args); err != nil {
+			return err
+		}
+	}
+
+	if err := validate.Struct(tmp.Interface()); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	if v, ok := tmp.Interface().(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("config: invalid: %w", err)
+		}
+	}
+	rv.Elem().Set(tmp.Elem())
+	return nil
+}
+
+// loader 把各個來源的值寫入 dst。
+type loader struct {
+	dst    reflect.Value
+	leaves []leaf
+	byKey  map[string]*leaf // 小寫的鍵
+}
+
+// entry 是來源中的一個值：string 或 []string。
+type entry struct {
+	key string
+	raw any
+}
+
+// apply 把同一個來源的值寫入 dst；source 用在錯誤訊息中。
+// 同一個來源同時設定了 key 與 key_file 是錯誤。
+func (ld *loader) apply(source string, entries []entry) error {
+	seen := make(map[*leaf]string)
+	for _, e := range entries {
+		key := strings.ToLower(e.key)
+		l, ok := ld.byKey[key]
+		fromFile := false
+		if !ok {
+			if base, found := strings.CutSuffix(key, "_file"); found {
+				l, ok = ld.byKey[base]
+				fromFile = true
+			}
+		}
+		if !ok {
+			return fmt.Errorf("config: %s: unknown key %q", source, e.key)
+		}
+		if prev, dup := seen[l]; dup {
+			return fmt.Errorf("config: %s: both %q and %q set %s", source, prev, e.key, l.key)
+		}
+		seen[l] = e.key
+
+		raw := e.raw
+		if fromFile {
+			path, ok := raw.(string)
+			if !ok {
+				return fmt.Errorf("config: %s: %s: expected a file path", source, e.key)
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("config: %s: %s: %w", source, e.key, err)
+			}
+			// 檔案結尾的換行 (echo 或編輯器加上的) 不屬於值。
+			raw = strings.TrimRight(string(b), "\r\n")
+		}
+		if err := setValue(ld.dst.FieldByIndex(l.index), raw); err != nil {
+			return fmt.Errorf("config: %s: %s: %w", source, l.key, err)
+		}
+	}
+	return nil
+}
+
+// parsers 依副檔名選擇設定檔的解析器。解析器回傳巢狀的 map，值為 string、bool、json.Number、
+// []any、[]string、map[string]any 或 nil (表示沒有設定)。
+var parsers = map[string]func([]byte) (map[string]any, error){
+	".yaml": parseYAML,
+	".yml":  parseYAML,
+	".toml": parseTOML,
+	".json": parseJSON,
+}
+
+func (ld *loader) loadFile(f file) error {
+	parse, ok := parsers[strings.ToLower(filepath.Ext(f.path))]
+	if !ok {
+		return fmt.Errorf("config: %s: unknown file format", f.path)
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if f.optional && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("config: %w", err)
+	}
+	tree, err := parse(data)
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", f.path, err)
+	}
+	var entries []entry
+	if err := flatten("", tree, &entries); err != nil {
+		return fmt.Errorf("config: %s: %w", f.path, err)
+	}
+	return ld.apply(f.path, entries)
+}
+
+// flatten 把巢狀的 map 展開成以點連接的鍵，依鍵排序以產生穩定的錯誤訊息。
+func flatten(prefix string, m map[string]any, out *[]entry) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		key := join(prefix, k)
+		switch v := m[k].(type) {
+		case nil:
+		case map[string]any:
+			if err := flatten(key, v, out); err != nil {
+				return err
+			}
+		case []string:
+			*out = append(*out, entry{key, v})
+		case []any:
+			list := make([]string, len(v))
+			for i, item := range v {
+				s, ok := scalarString(item)
+				if !ok {
+					return fmt.Errorf("%s: lists may only contain plain values", key)
+				}
+				list[i] = s
+			}
+			*out = append(*out, entry{key, list})
+		default:
+			s, ok := scalarString(v)
+			if !ok {
+				return fmt.Errorf("%s: unsupported value %v", key, v)
+			}
+			*out = append(*out, entry{key, s})
+		}
+	}
+	return nil
+}
+
+func scalarString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case fmt.Stringer: // json.Number
+		return v.String(), true
+	}
+	return "", false
+}
+
+// envName 回傳 key 對應的環境變數名稱。
+func envName(prefix, key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if prefix == "" {
+		return name
+	}
+	return strings.ToUpper(prefix) + "_" + name
+}
+
+func (ld *loader) loadEnv(prefix string) error {
+	// 不同的鍵可能對應到同一個變數 (例如 admin_addr 與 admin.addr 都是 APP_ADMIN_ADDR)，
+	// 這時無法判斷變數屬於哪一個鍵，回傳錯誤而不是任選一個。
+	owners := make(map[string]string, 2*len(ld.leaves))
+	for _, l := range ld.leaves {
+		name := envName(prefix, l.key)
+		for _, n := range []string{name, name + "_FILE"} {
+			if other, ok := owners[n]; ok {
+				return fmt.Errorf("config: keys %q and %q both map to environment variable %s", other, l.key, n)
+			}
+			owners[n] = l.key
+		}
+	}
+	for _, l := range ld.leaves {
+		name := envName(prefix, l.key)
+		var entries []entry
+		if v, ok := os.LookupEnv(name); ok {
+			entries = append(entries, entry{l.key, v})
+		}
+		if v, ok := os.LookupEnv(name + "_FILE"); ok {
+			entries = append(entries, entry{l.key + "_file", v})
+		}
+		if err := ld.apply("env "+name, entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flagValue 記錄旗標收到的值；預設值只用來顯示在 -h 的說明中。
+type flagValue struct {
+	def    string
+	isBool bool
+	set    *string
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.def
+}
+
+func (f *flagValue) Set(s string) error {
+	f.set = &s
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool { return f.isBool }
+
+func (ld *loader) loadFlags(args []string) error {
+	fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	values := make([]*flagValue, len(ld.leaves))
+	for i, l := range ld.leaves {
+		values[i] = &flagValue{def: l.def, isBool: l.typ.Kind() == reflect.Bool}
+		fs.Var(values[i], strings.ToLower(l.key), l.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	for i, l := range ld.leaves {
+		if values[i].set == nil {
+			continue
+		}
+		if err := ld.apply("flag -"+strings.ToLower(l.key), []entry{{l.key, *values[i].set}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setValue 把 raw (string 或 []string) 寫入欄位 v。字串寫入 slice 欄位時以逗號分隔。
+func setValue(v reflect.Value, raw any) error {
+	if list, ok := raw.([]string); ok {
+		if v.Kind() != reflect.Slice || isScalar(v.Type()) {
+			return errors.New("expected a single value, got a list")
+		}
+		return setList(v, list)
+	}
+	s := raw.(string)
+	if v.Kind() == reflect.Slice && !isScalar(v.Type()) {
+		var list []string
+		if strings.TrimSpace(s) != "" {
+			list = strings.Split(s, ",")
+			for i := range list {
+				list[i] = strings.TrimSpace(list[i])
+			}
+		}
+		return setList(v, list)
+	}
+	return setScalar(v, s)
+}
+
+func setList(v reflect.Value, list []string) error {
+	sv := reflect.MakeSlice(v.Type(), len(list), len(list))
+	for i, s := range list {
+		if err := setScalar(sv.Index(i), s); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	v.Set(sv)
+	return nil
+}
+
+func setScalar(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as a duration", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, v.Type().Bits()); err == nil {
+			v.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, v.Type().Bits()); err == nil {
+			v.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot parse %q as %s", s, v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/validate"
+)
+
+type testConfig struct {
+	HTTP struct {
+		Addr        string        `json:"addr" default:":8080" validate:"required" usage:"listen address"`
+		ReadTimeout time.Duration `json:"read_timeout" default:"15s"`
+	} `json:"http"`
+	Log struct {
+		Level string `json:"level" default:"info" validate:"oneof=debug info warn error"`
+	} `json:"log"`
+	Debug   bool         `json:"debug"`
+	Workers int          `json:"workers" default:"4"`
+	Ratio   float64      `json:"ratio"`
+	Origins []string     `json:"origins"`
+	Trusted []netip.Addr `json:"trusted"`
+	DB      struct {
+		Password Secret `json:"password"`
+	} `json:"db"`
+}
+
+func (c *testConfig) Validate() error {
+	if c.Workers < 1 {
+		return errors.New("workers must be at least 1")
+	}
+	return nil
+}
+
+// writeFile 在暫存目錄中建立檔案並回傳路徑。
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	var cfg testConfig
+	if err := Load(&cfg); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.HTTP.Addr != ":8080" || cfg.HTTP.ReadTimeout != 15*time.Second || cfg.Log.Level != "info" || cfg.Workers != 4 {
+		t.Errorf("Load() = %+v; 預期為 default tag 的值", cfg)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlFile := writeFile(t, "base.yaml", `
+http:
+  addr: ":9000"
+  read_timeout: 5s
+log:
+  level: warn
+workers: 8
+`)
+	jsonFile := writeFile(t, "override.json", `{"http": {"addr": ":9100"}, "ratio": 0.5}`)
+	t.Setenv("APP_HTTP_ADDR", ":9200")
+	t.Setenv("APP_WORKERS", "16")
+
+	var cfg testConfig
+	err := Load(&cfg, WithFile(yamlFile), WithFile(jsonFile), WithEnv("app"), WithArgs([]string{"-workers=32", "-debug"}))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"http.addr (環境變數覆蓋設定檔)", cfg.HTTP.Addr, ":9200"},
+		{"http.read_timeout (設定檔覆蓋預設值)", cfg.HTTP.ReadTimeout, 5 * time.Second},
+		{"log.level", cfg.Log.Level, "warn"},
+		{"ratio (後面的設定檔)", cfg.Ratio, 0.5},
+		{"workers (旗標覆蓋環境變數)", cfg.Workers, 32},
+		{"debug (布林旗標)", cfg.Debug, true},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v; 預期為 %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadFormats(t *testing.T) {
+	want := []string{"https://a.example", "https://b.example"}
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"c.yaml", "origins:\n  - https://a.example\n  - https://b.example\n"},
+		{"c.yml", "origins: [https://a.example, 'https://b.example']\n"},
+		{"c.toml", "origins = [\n  \"https://a.example\", # 第一個\n  'https://b.example',\n]\n"},
+		{"c.json", `{"origins": ["https://a.example", "https://b.example"]}`},
+	}
+	for _, tt := range tests {
+		var cfg testConfig
+		if err := Load(&cfg, WithFile(writeFile(t, tt.name, tt.content))); err != nil {
+			t.Errorf("%s: Load() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(cfg.Origins, want) {
+			t.Errorf("%s: origins = %q; 預期為 %q", tt.name, cfg.Origins, want)
+		}
+	}
+}
+
+func TestLoadEnvAndFlagLists(t *testing.T) {
+	t.Setenv("ORIGINS", "https://a.example, https://b.example")
+	var cfg testConfig
+	if err := Load(&cfg, WithEnv(""), WithArgs([]string{"-trusted", "10.0.0.1,::1"})); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []string{"https://a.example", "https://b.example"}; !reflect.DeepEqual(cfg.Origins, want) {
+		t.Errorf("origins = %q; 預期為 %q", cfg.Origins, want)
+	}
+	if want := []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("::1")}; !reflect.DeepEqual(cfg.Trusted, want) {
+		t.Errorf("trusted = %v; 預期為 %v", cfg.Trusted, want)
+	}
+}
+
+func TestLoadSecretFile(t *testing.T) {
+	secret := writeFile(t, "db_password", "s3cr3t\n")
+	tests := []struct {
+		name string
+		opts func(t *testing.T) []Option
+	}{
+		{"環境變數 _FILE", func(t *testing.T) []Option {
+			t.Setenv("APP_DB_PASSWORD_FILE", secret)
+			return []Option{WithEnv("APP")}
+		}},
+		{"設定檔 _file", func(t *testing.T) []Option {
+			return []Option{WithFile(writeFile(t, "c.yaml", "db:\n  password_file: "+secret+"\n"))}
+		}},
+		{"環境變數覆蓋設定檔的值", func(t *testing.T) []Option {
+			t.Setenv("APP_DB_PASSWORD", "s3cr3t")
+			return []Option{WithFile(writeFile(t, "c.toml", "db.password = 'old'\n")), WithEnv("APP")}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg testConfig
+			if err := Load(&cfg, tt.opts(t)...); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got := cfg.DB.Password.Value(); got != "s3cr3t" {
+				t.Errorf("db.password = %q; 預期為 \"s3cr3t\"", got)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	secret := writeFile(t, "pw", "x")
+	tests := []struct {
+		name string
+		opts func(t *testing.T) []Option
+		want string
+	}{
+		{"不認得的鍵", func(t *testing.T) []Option {
+			return []Option{WithFile(writeFile(t, "c.yaml", "htp:\n  addr: x\n"))}
+		}, `unknown key "htp.addr"`},
+		{"型別錯誤", func(t *testing.T) []Option {
+			return []Option{WithFile(writeFile(t, "c.json", `{"workers": "many"}`))}
+		}, `workers: cannot parse "many" as int`},
+		{"期間格式錯誤", func(t *testing.T) []Option {
+			t.Setenv("APP_HTTP_READ_TIMEOUT", "5")
+			return []Option{WithEnv("APP")}
+		}, `env APP_HTTP_READ_TIMEOUT: http.read_timeout: cannot parse "5" as a duration`},
+		{"單一值收到 list", func(t *testing.T) []Option {
+			return []Option{WithFile(writeFile(t, "c.yaml", "workers: [1, 2]\n"))}
+		}, "expected a single value"},
+		{"同時設定值與 _file", func(t *testing.T) []Option {
+			return []Option{WithFile(writeFile(t, "c.yaml", "db:\n  password: a\n  password_file: "+secret+"\n"))}
+		}, `both "db.password" and "db.password_file"`},
+		{"_file 不存在", func(t *testing.T) []Option {
+			t.Setenv("APP_DB_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+			return []Option{WithEnv("APP")}
+		}, "no such file"},
+		{"必要的設定檔不存在", func(t *testing.T) []Option {
+			return []Option{WithFile(filepath.Join(t.TempDir(), "missing.yaml"))}
+		}, "no such file"},
+		{"不認得的格式", func(t *testing.T) []Option {
+			return []Option{WithFile(writeFile(t, "c.ini", ""))}
+		}, "unknown file format"},
+		{"解析錯誤", func(t *testing.T) []Option {
+			return []Option{WithFile(writeFile(t, "c.toml", "[http]\naddr = \n"))}
+		}, "c.toml: line 2: missing value"},
+		{"validate tag", func(t *testing.T) []Option {
+			return []Option{WithArgs([]string{"-log.level=verbose"})}
+		}, "log.level must be one of"},
+		{"Validate 方法", func(t *testing.T) []Option {
+			return []Option{WithArgs([]string{"-workers=0"})}
+		}, "workers must be at least 1"},
+		{"不認得的旗標", func(t *testing.T) []Option {
+			return []Option{WithArgs([]string{"-nope"})}
+		}, "flag provided but not defined"},
+	}
+	quietStderr(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig{Workers: 99}
+			err := Load(&cfg, tt.opts(t)...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() error = %v; 預期包含 %q", err, tt.want)
+			}
+			if cfg.Workers != 99 || cfg.HTTP.Addr != "" {
+				t.Errorf("失敗的 Load 修改了 dst: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestLoadEnvNameCollision(t *testing.T) {
+	var cfg struct {
+		AdminAddr string `json:"admin_addr"`
+		Admin     struct {
+			Addr string `json:"addr"`
+		} `json:"admin"`
+	}
+	t.Setenv("APP_ADMIN_ADDR", ":9090")
+	err := Load(&cfg, WithEnv("APP"))
+	want := `keys "admin_addr" and "admin.addr" both map to environment variable APP_ADMIN_ADDR`
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("Load() error = %v; 預期包含 %q", err, want)
+	}
+	// 不使用環境變數時沒有衝突。
+	if err := Load(&cfg, WithArgs([]string{"-admin.addr=:1"})); err != nil || cfg.Admin.Addr != ":1" {
+		t.Errorf("Load(WithArgs) = %+v, %v; 預期 admin.addr 為 :1", cfg, err)
+	}
+}
+
+func TestLoadListen(t *testing.T) {
+	testCases := []struct {
+		name string
+		env  string
+		args []string
+		want string
+	}{
+		{"預設值", "", nil, ":8080"},
+		{"環境變數", ":9000", nil, ":9000"},
+		{"旗標覆蓋環境變數", ":9000", []string{"-addr=:9100"}, ":9100"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.env != "" {
+				t.Setenv("APP_ADDR", tc.env)
+			}
+			got, err := LoadListen("APP", tc.args)
+			if err != nil || got.Addr != tc.want {
+				t.Errorf("LoadListen() = %+v, %v; 預期 Addr 為 %q", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadValidationErrors(t *testing.T) {
+	var cfg testConfig
+	err := Load(&cfg, WithArgs([]string{"-http.addr="}))
+	var errs validate.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != "http.addr" {
+		t.Errorf("Load() error = %v; 預期為 http.addr 的 validate.Errors", err)
+	}
+}
+
+// quietStderr 暫時把 stderr 導向 /dev/null，flag 套件的說明與錯誤訊息不會干擾測試輸出。
+func quietStderr(t *testing.T) {
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr := os.Stderr
+	os.Stderr = devNull
+	t.Cleanup(func() {
+		os.Stderr = stderr
+		devNull.Close()
+	})
+}
+
+func TestLoadHelp(t *testing.T) {
+	quietStderr(t)
+	var cfg testConfig
+	if err := Load(&cfg, WithArgs([]string{"-h"})); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Load(-h) error = %v; 預期為 flag.ErrHelp", err)
+	}
+}
+
+func TestLoadBadTarget(t *testing.T) {
+	var unsupported struct {
+		M map[string]string `json:"m"`
+	}
+	tests := []struct {
+		name string
+		dst  any
+	}{
+		{"不是指標", testConfig{}},
+		{"nil 指標", (*testConfig)(nil)},
+		{"不是 struct", new(int)},
+		{"不支援的型別", &unsupported},
+	}
+	for _, tt := range tests {
+		if err := Load(tt.dst); err == nil {
+			t.Errorf("%s: Load() error = nil; 預期為錯誤", tt.name)
+		}
+	}
+}
+
+func TestSecret(t *testing.T) {
+	s := Secret("hunter2")
+	b, _ := json.Marshal(struct{ P Secret }{s})
+	var logs bytes.Buffer
+	slog.New(slog.NewTextHandler(&logs, nil)).Info("login", "password", s)
+	for name, got := range map[string]string{
+		"%v":   fmt.Sprintf("%v", s),
+		"%s":   fmt.Sprintf("%s", s),
+		"%q":   fmt.Sprintf("%q", s),
+		"%#v":  fmt.Sprintf("%#v", s),
+		"json": string(b),
+		"slog": logs.String(),
+	} {
+		if strings.Contains(got, "hunter2") || !strings.Contains(got, redacted) {
+			t.Errorf("%s = %s; 預期遮蔽內容", name, got)
+		}
+	}
+	if s.Value() != "hunter2" {
+		t.Errorf("Value() = %q; 預期為 \"hunter2\"", s.Value())
+	}
+	if Secret("").String() != "" {
+		t.Error("空的 Secret 預期顯示為空字串")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	var cfg testConfig
+	if err := Load(&cfg, WithArgs([]string{"-db.password=hunter2", "-origins=a,b", "-ratio=0.25"})); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Print(&buf, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := `http.addr = ":8080"
+http.read_timeout = "15s"
+log.level = "info"
+debug = false
+workers = 4
+ratio = 0.25
+origins = ["a", "b"]
+trusted = []
+db.password = "[REDACTED]"
+`
+	if buf.String() != want {
+		t.Errorf("Print() =\n%s\n預期為\n%s", buf.String(), want)
+	}
+}
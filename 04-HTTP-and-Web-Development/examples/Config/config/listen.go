@@ -0,0 +1,13 @@
+package config
+
+// Listen 是只需要一個 HTTP 監聽位址的服務所使用的設定，位址預設為 ":8080"。
+type Listen struct {
+	Addr string `json:"addr" default:":8080" validate:"required" usage:"HTTP 監聽位址"`
+}
+
+// LoadListen 從環境變數 (prefix 加上 _ADDR，例如 APP_ADDR) 與 args 中的 -addr 旗標載入 Listen。
+func LoadListen(prefix string, args []string) (Listen, error) {
+	var l Listen
+	err := Load(&l, WithEnv(prefix), WithArgs(args))
+	return l, err
+}
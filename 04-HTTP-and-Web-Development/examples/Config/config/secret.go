@@ -0,0 +1,87 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// Secret 是不應該出現在日誌或輸出中的字串，例如密碼或 API 金鑰。
+// fmt、encoding/json、slog 與 Print 都只會顯示 "[REDACTED]" (空的 Secret 顯示為空字串)，
+// 需要真正的值時請呼叫 Value。
+type Secret string
+
+// Value 回傳真正的值。
+func (s Secret) Value() string { return string(s) }
+
+// String 回傳遮蔽後的值，讓 %v 與 %s 不會洩漏內容。
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString 讓 %#v 也只顯示遮蔽後的值。
+func (s Secret) GoString() string { return strconv.Quote(s.String()) }
+
+// MarshalText 讓 encoding/json 與 encoding/xml 輸出遮蔽後的值。
+func (s Secret) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+// LogValue 讓 slog 輸出遮蔽後的值。
+func (s Secret) LogValue() slog.Value { return slog.StringValue(s.String()) }
+
+// Print 以 "鍵 = 值" 的格式逐行印出 cfg (struct 或指向 struct 的指標) 的每個欄位，順序與宣告相同。
+// Secret 欄位只顯示 "[REDACTED]"，所以可以放心地在啟動時印出生效的設定。
+// 輸出是合法的 TOML，可以作為設定檔的範本。
+func Print(w io.Writer, cfg any) error {
+	rv := reflect.ValueOf(cfg)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("config: Print(%T): not a struct", cfg)
+	}
+	leaves, err := leavesOf(rv.Type(), "", nil)
+	if err != nil {
+		return err
+	}
+	for _, l := range leaves {
+		if _, err := fmt.Fprintf(w, "%s = %s\n", l.key, format(rv.FieldByIndex(l.index))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// format 把欄位的值格式化成 TOML 的值。
+func format(v reflect.Value) string {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		if err != nil {
+			return strconv.Quote("!" + err.Error())
+		}
+		return strconv.Quote(string(b))
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return strconv.Quote(d.String())
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = format(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(v.Interface())
+}
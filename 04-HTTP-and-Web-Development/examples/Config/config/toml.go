@@ -0,0 +1,311 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// errIncomplete 表示陣列還沒有結束，需要讀取下一行。
+var errIncomplete = errors.New("unterminated array")
+
+// parseTOML 解析設定檔常用的 TOML 子集合：
+//
+//   - [table] 與 [a.b] 表頭、key = value 與 a.b = value 的點分隔鍵 (鍵可以加引號)
+//   - "基本字串" (含 \uXXXX 等跳脫字元) 與 '字面字串'
+//   - 整數 (含 _ 分隔與 0x、0o、0b)、浮點數、布林值與日期時間 (以字串回傳)
+//   - 純量的陣列，可以跨多行並包含註解與結尾的逗號
+//
+// 不支援的語法 (多行字串、inline table、[[array of tables]]、巢狀陣列) 會回傳錯誤。
+// 所有的值都以字串回傳，由欄位的型別決定如何解析。
+func parseTOML(data []byte) (map[string]any, error) {
+	root := map[string]any{}
+	cur := root
+	headers := map[string]bool{}
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		num := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "[[") {
+			return nil, fmt.Errorf("line %d: arrays of tables are not supported", num)
+		}
+
+		if line[0] == '[' {
+			keys, rest, err := tomlKey(line[1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", num, err)
+			}
+			if !strings.HasPrefix(rest, "]") || !isTOMLEnd(rest[1:]) {
+				return nil, fmt.Errorf("line %d: invalid table header", num)
+			}
+			name := strings.Join(keys, ".")
+			if headers[name] {
+				return nil, fmt.Errorf("line %d: table [%s] defined twice", num, name)
+			}
+			headers[name] = true
+			if cur, err = tomlTable(root, keys); err != nil {
+				return nil, fmt.Errorf("line %d: %w", num, err)
+			}
+			continue
+		}
+
+		keys, rest, err := tomlKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+		if !strings.HasPrefix(rest, "=") {
+			return nil, fmt.Errorf(`line %d: expected "=" after the key`, num)
+		}
+		text := strings.TrimSpace(rest[1:])
+		v, rest, err := tomlValue(text)
+		// 跨多行的陣列：接上下一行再解析一次。
+		for errors.Is(err, errIncomplete) && i+1 < len(lines) {
+			i++
+			text += "\n" + lines[i]
+			v, rest, err = tomlValue(text)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+		if !isTOMLEnd(rest) {
+			return nil, fmt.Errorf("line %d: unexpected text %q after the value", num, strings.TrimSpace(rest))
+		}
+		t, err := tomlTable(cur, keys[:len(keys)-1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", num, err)
+		}
+		last := keys[len(keys)-1]
+		if _, dup := t[last]; dup {
+			return nil, fmt.Errorf("line %d: key %q defined twice", num, strings.Join(keys, "."))
+		}
+		t[last] = v
+	}
+	return root, nil
+}
+
+// isTOMLEnd 回傳 s 是否只剩下空白或註解。
+func isTOMLEnd(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s[0] == '#'
+}
+
+// tomlTable 回傳 (必要時建立) t 之下以 keys 為路徑的 table。
+func tomlTable(t map[string]any, keys []string) (map[string]any, error) {
+	for _, k := range keys {
+		switch v := t[k].(type) {
+		case nil:
+			next := map[string]any{}
+			t[k] = next
+			t = next
+		case map[string]any:
+			t = v
+		default:
+			return nil, fmt.Errorf("key %q is a value, not a table", k)
+		}
+	}
+	return t, nil
+}
+
+// tomlKey 解析 a."b".c 形式的鍵，回傳各段與剩下的文字。
+func tomlKey(s string) ([]string, string, error) {
+	var keys []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		var k string
+		switch {
+		case s == "":
+			return nil, "", errors.New("missing key")
+		case s[0] == '"' || s[0] == '\'':
+			var err error
+			if k, s, err = tomlString(s); err != nil {
+				return nil, "", err
+			}
+		default:
+			n := 0
+			for n < len(s) && isBareKeyChar(s[n]) {
+				n++
+			}
+			if n == 0 {
+				return nil, "", fmt.Errorf("invalid key at %q", s)
+			}
+			k, s = s[:n], s[n:]
+		}
+		keys = append(keys, k)
+		s = strings.TrimLeft(s, " \t")
+		if !strings.HasPrefix(s, ".") {
+			return keys, s, nil
+		}
+		s = s[1:]
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+// tomlValue 解析 s 開頭的一個值，回傳字串、[]string 與剩下的文字。
+func tomlValue(s string) (any, string, error) {
+	if s == "" {
+		return nil, "", errors.New("missing value")
+	}
+	switch s[0] {
+	case '"', '\'':
+		return tomlString(s)
+	case '[':
+		return tomlArray(s)
+	case '{':
+		return nil, "", errors.New("inline tables are not supported")
+	}
+	n := strings.IndexAny(s, " \t\r\n,]#")
+	if n < 0 {
+		n = len(s)
+	}
+	tok, rest := s[:n], s[n:]
+	v, err := tomlScalar(tok)
+	return v, rest, err
+}
+
+// tomlScalar 解析沒有引號的值：布林值、整數、浮點數或日期時間。
+func tomlScalar(tok string) (string, error) {
+	if tok == "true" || tok == "false" {
+		return tok, nil
+	}
+	// 日期與時間 (1979-05-27、07:32:00、1979-05-27T07:32:00Z) 以原本的文字回傳。
+	if len(tok) >= 8 && (tok[4] == '-' || tok[2] == ':') {
+		return tok, nil
+	}
+	digits := strings.TrimLeft(tok, "+-")
+	if len(digits) > 1 && digits[0] == '0' && '0' <= digits[1] && digits[1] <= '9' {
+		return "", fmt.Errorf("invalid number %q: leading zeros are not allowed", tok)
+	}
+	// base 0 接受 0x、0o、0b 前綴與 _ 分隔。
+	if n, err := strconv.ParseInt(tok, 0, 64); err == nil {
+		return strconv.FormatInt(n, 10), nil
+	}
+	switch digits {
+	case "inf", "nan":
+		return tok, nil
+	}
+	f := strings.ReplaceAll(tok, "_", "")
+	if _, err := strconv.ParseFloat(f, 64); err == nil && !strings.HasPrefix(digits, "0x") {
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid value %q", tok)
+}
+
+// tomlArray 解析 [a, b, ...]，元素只能是純量；陣列還沒結束時回傳 errIncomplete。
+func tomlArray(s string) ([]string, string, error) {
+	list := []string{}
+	s = s[1:]
+	for {
+		s = skipTOMLSpace(s)
+		if s == "" {
+			return nil, "", errIncomplete
+		}
+		if s[0] == ']' {
+			return list, s[1:], nil
+		}
+		if s[0] == '[' {
+			return nil, "", errors.New("nested arrays are not supported")
+		}
+		v, rest, err := tomlValue(s)
+		if err != nil {
+			return nil, "", err
+		}
+		list = append(list, v.(string))
+		s = skipTOMLSpace(rest)
+		switch {
+		case s == "":
+			return nil, "", errIncomplete
+		case s[0] == ',':
+			s = s[1:]
+		case s[0] != ']':
+			return nil, "", fmt.Errorf(`expected "," or "]" in array, found %q`, s[:1])
+		}
+	}
+}
+
+// skipTOMLSpace 略過空白、換行與註解。
+func skipTOMLSpace(s string) string {
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if !strings.HasPrefix(s, "#") {
+			return s
+		}
+		i := strings.IndexByte(s, '\n')
+		if i < 0 {
+			return ""
+		}
+		s = s[i:]
+	}
+}
+
+// tomlString 解析 s 開頭的基本字串或字面字串，回傳內容與剩下的文字。
+func tomlString(s string) (string, string, error) {
+	q := s[0]
+	if strings.HasPrefix(s, string([]byte{q, q, q})) {
+		return "", "", errors.New("multi-line strings are not supported")
+	}
+	if q == '\'' {
+		end := strings.IndexAny(s[1:], "'\n")
+		if end < 0 || s[1+end] != '\'' {
+			return "", "", errors.New("unterminated string")
+		}
+		return s[1 : 1+end], s[2+end:], nil
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\n':
+			return "", "", errors.New("unterminated string")
+		case '\\':
+			if i+1 >= len(s) {
+				return "", "", errors.New("unterminated string")
+			}
+			i++
+			switch s[i] {
+			case 'b':
+				b.WriteByte('\b')
+			case 't':
+				b.WriteByte('\t')
+			case 'n':
+				b.WriteByte('\n')
+			case 'f':
+				b.WriteByte('\f')
+			case 'r':
+				b.WriteByte('\r')
+			case 'e':
+				b.WriteByte('\x1b')
+			case '"', '\\':
+				b.WriteByte(s[i])
+			case 'u', 'U':
+				size := 4
+				if s[i] == 'U' {
+					size = 8
+				}
+				if i+size >= len(s) {
+					return "", "", errors.New("invalid unicode escape")
+				}
+				n, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(n)) {
+					return "", "", fmt.Errorf("invalid unicode escape \\%s", s[i:i+1+size])
+				}
+				b.WriteRune(rune(n))
+				i += size
+			default:
+				return "", "", fmt.Errorf("invalid escape \\%c", s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]any
+	}{
+		{"表頭與點分隔鍵", "title = \"demo\" # 註解\n\n[http]\naddr = ':8080'\ntls.cert = \"a.pem\"\n\n[log]\nlevel = \"info\"\n", map[string]any{
+			"title": "demo",
+			"http":  map[string]any{"addr": ":8080", "tls": map[string]any{"cert": "a.pem"}},
+			"log":   map[string]any{"level": "info"},
+		}},
+		{"巢狀表頭", "[a.\"b c\"]\nd = true\n", map[string]any{
+			"a": map[string]any{"b c": map[string]any{"d": "true"}},
+		}},
+		{"數字", "a = 1_000\nb = 0x1F\nc = -0o17\nd = 0b101\ne = 3.14\nf = 1e3\ng = +inf\nh = -42\n", map[string]any{
+			"a": "1000", "b": "31", "c": "-15", "d": "5", "e": "3.14", "f": "1e3", "g": "+inf", "h": "-42",
+		}},
+		{"日期時間", "a = 1979-05-27T07:32:00Z\nb = 07:32:00\n", map[string]any{
+			"a": "1979-05-27T07:32:00Z", "b": "07:32:00",
+		}},
+		{"跳脫字元", `a = "tab\there \"q\" \u00e9 \U0001F600"` + "\nb = 'C:\\path\\no\\escape'\n", map[string]any{
+			"a": "tab\there \"q\" é 😀", "b": `C:\path\no\escape`,
+		}},
+		{"陣列", "a = [1, 2, 3]\nb = []\nc = [\n  \"x\", # 註解\n  \"y\",\n]\n", map[string]any{
+			"a": []string{"1", "2", "3"}, "b": []string{}, "c": []string{"x", "y"},
+		}},
+		{"CRLF", "[a]\r\nb = 1\r\nc = [\r\n  2,\r\n]\r\n", map[string]any{
+			"a": map[string]any{"b": "1", "c": []string{"2"}},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := parseTOML([]byte(tt.in))
+		if err != nil {
+			t.Errorf("%s: parseTOML() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseTOML() = %#v; 預期為 %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"a = 1\na = 2\n", `line 2: key "a" defined twice`},
+		{"[a]\n[a]\n", "line 2: table [a] defined twice"},
+		{"a = 1\n[a]\n", `key "a" is a value`},
+		{"a 1\n", `expected "="`},
+		{"a =\n", "missing value"},
+		{"a = 012\n", "leading zeros"},
+		{"a = yes\n", `invalid value "yes"`},
+		{"a = 1 2\n", "unexpected text"},
+		{"a = \"b\n", "unterminated string"},
+		{"a = \"\\q\"\n", `invalid escape \q`},
+		{"a = \"\"\"b\"\"\"\n", "multi-line strings"},
+		{"a = {b = 1}\n", "inline tables"},
+		{"a = [[1]]\n", "nested arrays"},
+		{"a = [1, 2\n", "unterminated array"},
+		{"[[a]]\n", "arrays of tables"},
+		{"[a\n", "invalid table header"},
+	}
+	for _, tt := range tests {
+		_, err := parseTOML([]byte(tt.in))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseTOML(%q) error = %v; 預期包含 %q", tt.in, err, tt.want)
+		}
+	}
+}
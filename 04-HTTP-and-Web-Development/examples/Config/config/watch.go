@@ -0,0 +1,159 @@
+package config
+
+import (
+	"context"
+	"log"
+	"maps"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+var (
+	defaultPollInterval = 2 * time.Second
+	defaultSignals      = []os.Signal{syscall.SIGHUP}
+)
+
+// WithPollInterval 設定 Watch 檢查設定檔是否被修改的間隔，預設為 2 秒；0 表示不檢查。
+// 只比對修改時間與大小，不需要 fsnotify 這類平台相關的套件。
+func WithPollInterval(d time.Duration) Option {
+	return func(s *settings) { s.pollInterval = d }
+}
+
+// WithReloadSignals 設定觸發 Watch 重新載入的訊號，預設為 SIGHUP；沒有參數表示不監聽訊號。
+func WithReloadSignals(sigs ...os.Signal) Option {
+	return func(s *settings) { s.signals = sigs }
+}
+
+// Watcher 保存目前生效的設定，並在收到訊號或設定檔被修改時重新載入。
+// 新的設定載入或驗證失敗時保留舊的設定並記錄錯誤，所以打錯字的設定檔不會讓執行中的服務停擺。
+type Watcher[T any] struct {
+	opts    []Option
+	s       settings
+	current atomic.Pointer[T]
+
+	mu     sync.Mutex // 讓重新載入依序執行，並保護 subs 與 stamps
+	subs   []func(old, new *T)
+	stamps map[string]stamp
+}
+
+// stamp 是設定檔在某個時間點的狀態。
+type stamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+// Watch 以 opts 載入 T，並在背景監看，直到 ctx 被取消。第一次載入失敗時回傳錯誤。
+//
+// 每次重新載入都從新的 T 開始，依相同的來源再套用一次；環境變數與旗標在執行期間通常不會改變，
+// 所以實際上是設定檔的內容生效。
+func Watch[T any](ctx context.Context, opts ...Option) (*Watcher[T], error) {
+	w := &Watcher[T]{opts: opts, s: newSettings(opts)}
+	cfg := new(T)
+	if err := Load(cfg, opts...); err != nil {
+		return nil, err
+	}
+	w.current.Store(cfg)
+	w.stamps = w.snapshot()
+
+	// 在返回之前註冊訊號，否則呼叫者立即送出的 SIGHUP 會以預設的行為結束程序。
+	var sig chan os.Signal
+	if len(w.s.signals) > 0 {
+		sig = make(chan os.Signal, 1)
+		signal.Notify(sig, w.s.signals...)
+	}
+	go w.loop(ctx, sig)
+	return w, nil
+}
+
+// Get 回傳目前的設定。回傳的值不可以修改；重新載入會換成新的指標而不會改動舊的。
+func (w *Watcher[T]) Get() *T {
+	return w.current.Load()
+}
+
+// OnChange 註冊設定改變時呼叫的函式。fn 在重新載入的 goroutine 中依序呼叫，不可以呼叫 Reload。
+func (w *Watcher[T]) OnChange(fn func(old, new *T)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subs = append(w.subs, fn)
+}
+
+// Reload 立即重新載入。失敗時保留目前的設定並回傳錯誤；內容沒有改變時不會通知 OnChange。
+func (w *Watcher[T]) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// 先記錄檔案的狀態：載入期間又被修改的話，下一次檢查會再載入一次。
+	w.stamps = w.snapshot()
+	cfg := new(T)
+	if err := Load(cfg, w.opts...); err != nil {
+		return err
+	}
+	old := w.current.Load()
+	if reflect.DeepEqual(old, cfg) {
+		return nil
+	}
+	w.current.Store(cfg)
+	for _, fn := range w.subs {
+		fn(old, cfg)
+	}
+	return nil
+}
+
+func (w *Watcher[T]) loop(ctx context.Context, sig chan os.Signal) {
+	if sig != nil {
+		defer signal.Stop(sig)
+	}
+	var tick <-chan time.Time
+	if w.s.pollInterval > 0 {
+		t := time.NewTicker(w.s.pollInterval)
+		defer t.Stop()
+		tick = t.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s := <-sig:
+			w.reload("signal " + s.String())
+		case <-tick:
+			if w.changed() {
+				w.reload("file change")
+			}
+		}
+	}
+}
+
+func (w *Watcher[T]) reload(reason string) {
+	if err := w.Reload(); err != nil {
+		log.Printf("config: reload on %s failed, keeping the previous config: %v", reason, err)
+		return
+	}
+	log.Printf("config: reloaded on %s", reason)
+}
+
+// snapshot 記錄所有設定檔目前的狀態。
+func (w *Watcher[T]) snapshot() map[string]stamp {
+	stamps := make(map[string]stamp, len(w.s.files))
+	for _, f := range w.s.files {
+		var st stamp
+		if fi, err := os.Stat(f.path); err == nil {
+			st = stamp{exists: true, size: fi.Size(), modTime: fi.ModTime()}
+		}
+		stamps[f.path] = st
+	}
+	return stamps
+}
+
+func (w *Watcher[T]) changed() bool {
+	now := w.snapshot()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !maps.EqualFunc(now, w.stamps, func(a, b stamp) bool {
+		return a.exists == b.exists && a.size == b.size && a.modTime.Equal(b.modTime)
+	})
+}
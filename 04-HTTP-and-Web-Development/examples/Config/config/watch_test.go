@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+type flags struct {
+	Greeting string `json:"greeting" default:"hello"`
+	Beta     bool   `json:"beta"`
+}
+
+// quietLog 在測試期間丟棄 log 的輸出。
+func quietLog(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+}
+
+// waitFor 等待 cond 成立，逾時則讓測試失敗。
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s逾時", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWatchFileChange(t *testing.T) {
+	leakcheck.Check(t)
+	quietLog(t)
+	path := writeFile(t, "flags.yaml", "beta: false\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := Watch[flags](ctx, WithFile(path), WithPollInterval(10*time.Millisecond), WithReloadSignals())
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := w.Get()
+	if first.Beta || first.Greeting != "hello" {
+		t.Fatalf("Get() = %+v; 預期為 {hello false}", first)
+	}
+	changes := make(chan [2]flags, 10)
+	w.OnChange(func(old, new *flags) { changes <- [2]flags{*old, *new} })
+
+	// 內容的長度不同，即使修改時間的精度很粗也能偵測到。
+	if err := os.WriteFile(path, []byte("beta: true\ngreeting: hi\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-changes; got[0] != *first || got[1] != (flags{Greeting: "hi", Beta: true}) {
+		t.Errorf("OnChange(old, new) = %+v; 預期為 {hello false} → {hi true}", got)
+	}
+	if first.Beta {
+		t.Error("重新載入修改了舊的設定")
+	}
+
+	// 錯誤的設定檔不會取代目前的設定。
+	if err := os.WriteFile(path, []byte("beta: maybe\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "重新載入失敗", func() bool { return w.Reload() != nil })
+	if got := *w.Get(); got != (flags{Greeting: "hi", Beta: true}) {
+		t.Errorf("載入失敗後 Get() = %+v; 預期保留 {hi true}", got)
+	}
+	select {
+	case got := <-changes:
+		t.Errorf("載入失敗時呼叫了 OnChange(%+v)", got)
+	default:
+	}
+}
+
+func TestWatchSignal(t *testing.T) {
+	leakcheck.Check(t, leakcheck.IgnoreTopFunction("os/signal.signal_recv"))
+	quietLog(t)
+	path := writeFile(t, "flags.toml", "beta = false\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := Watch[flags](ctx, WithFile(path), WithPollInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("beta = true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// 沒有輪詢：只有 SIGHUP 會觸發重新載入。
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, " SIGHUP 重新載入", func() bool { return w.Get().Beta })
+}
+
+func TestWatchInitialError(t *testing.T) {
+	path := writeFile(t, "flags.json", `{"beta": "maybe"}`)
+	if _, err := Watch[flags](context.Background(), WithFile(path)); err == nil {
+		t.Error("Watch() error = nil; 預期為第一次載入的錯誤")
+	}
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML 解析設定檔常用的 YAML 子集合：
+//
+//   - 以空白縮排的巢狀 mapping (不可以使用 tab)
+//   - 純量：未加引號、'單引號' (連續兩個單引號表示一個單引號) 與 "雙引號" (使用 Go 的跳脫字元)；~ 與 null 表示沒有設定
+//   - 純量的 list：區塊形式 (- item) 或流程形式 ([a, b])
+//   - # 註解與開頭的 ---
+//
+// 不支援的語法 (anchor、alias、tag、多行字串、mapping 的 list、多份文件…) 會回傳錯誤，而不是默默地解析錯誤。
+// 所有的純量都以字串回傳，由欄位的型別決定如何解析。
+func parseYAML(data []byte) (map[string]any, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		text := strings.TrimLeft(raw, " ")
+		indent := len(raw) - len(text)
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		text = strings.TrimSpace(stripComment(text))
+		if text == "" {
+			continue
+		}
+		if text == "---" || text == "..." {
+			if len(lines) == 0 && text == "---" {
+				continue
+			}
+			return nil, fmt.Errorf("line %d: multiple documents are not supported", i+1)
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: indent, text: text})
+	}
+	if len(lines) == 0 {
+		return map[string]any{}, nil
+	}
+	p := &yamlParser{lines: lines}
+	m, err := p.mapping(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", lines[p.pos].num)
+	}
+	return m, nil
+}
+
+// yamlLine 是去掉縮排與註解之後不是空白的一行。
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func isItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// mapping 解析縮排為 indent 的一連串 "key: value"。
+func (p *yamlParser) mapping(indent int) (map[string]any, error) {
+	m := map[string]any{}
+	for p.pos < len(p.lines) {
+		ln := p.lines[p.pos]
+		if ln.indent < indent {
+			break
+		}
+		if ln.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", ln.num)
+		}
+		if isItem(ln.text) {
+			return nil, fmt.Errorf("line %d: expected a key, found a list item", ln.num)
+		}
+		key, rest, err := splitYAMLKey(ln.text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", ln.num, err)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", ln.num, key)
+		}
+		p.pos++
+
+		if rest != "" {
+			if m[key], err = yamlValue(rest); err != nil {
+				return nil, fmt.Errorf("line %d: %w", ln.num, err)
+			}
+			continue
+		}
+		// 沒有值：下一行縮排較深時是巢狀的區塊；list 也可以與鍵對齊。
+		m[key] = nil
+		if p.pos < len(p.lines) {
+			next := p.lines[p.pos]
+			switch {
+			case next.indent >= indent && isItem(next.text):
+				m[key], err = p.sequence(next.indent)
+			case next.indent > indent:
+				m[key], err = p.mapping(next.indent)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// sequence 解析縮排為 indent 的一連串 "- item"；項目只能是純量。
+func (p *yamlParser) sequence(indent int) ([]string, error) {
+	list := []string{}
+	for p.pos < len(p.lines) {
+		ln := p.lines[p.pos]
+		if ln.indent != indent || !isItem(ln.text) {
+			if ln.indent > indent {
+				return nil, fmt.Errorf("line %d: nested blocks in lists are not supported", ln.num)
+			}
+			break
+		}
+		p.pos++
+		item := strings.TrimSpace(strings.TrimPrefix(ln.text, "-"))
+		if item == "" || isItem(item) || strings.HasPrefix(item, "[") {
+			return nil, fmt.Errorf("line %d: nested lists are not supported", ln.num)
+		}
+		if _, _, err := splitYAMLKey(item); err == nil {
+			return nil, fmt.Errorf("line %d: lists of mappings are not supported", ln.num)
+		}
+		v, err := yamlValue(item)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", ln.num, err)
+		}
+		s, _ := v.(string) // null 的項目視為空字串
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// splitYAMLKey 把 "key: value" 分成鍵與 (可能為空的) 值。
+func splitYAMLKey(text string) (key, rest string, err error) {
+	if text[0] == '"' || text[0] == '\'' {
+		end, err := quotedEnd(text)
+		if err != nil {
+			return "", "", err
+		}
+		if key, err = unquoteYAML(text[:end]); err != nil {
+			return "", "", err
+		}
+		rest = text[end:]
+		if rest != ":" && !strings.HasPrefix(rest, ": ") {
+			return "", "", errors.New(`expected ":" after the key`)
+		}
+		return key, strings.TrimSpace(rest[1:]), nil
+	}
+	i := strings.Index(text, ": ")
+	if i < 0 {
+		if !strings.HasSuffix(text, ":") {
+			return "", "", errors.New(`expected "key: value"`)
+		}
+		i = len(text) - 1
+	}
+	key = strings.TrimSpace(text[:i])
+	if key == "" {
+		return "", "", errors.New("empty key")
+	}
+	return key, strings.TrimSpace(text[i+1:]), nil
+}
+
+// yamlValue 解析鍵或 list 項目之後的值。
+func yamlValue(s string) (any, error) {
+	switch s[0] {
+	case '[':
+		return yamlFlowList(s)
+	case '{':
+		return nil, errors.New("flow mappings are not supported")
+	case '|', '>':
+		return nil, errors.New("block scalars are not supported")
+	case '&', '*', '!':
+		return nil, errors.New("anchors, aliases and tags are not supported")
+	case '"', '\'':
+		end, err := quotedEnd(s)
+		if err != nil {
+			return nil, err
+		}
+		if end != len(s) {
+			return nil, errors.New("unexpected text after the quoted string")
+		}
+		return unquoteYAML(s)
+	}
+	switch s {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	}
+	return s, nil
+}
+
+// yamlFlowList 解析 [a, "b", 'c']。
+func yamlFlowList(s string) ([]string, error) {
+	if !strings.HasSuffix(s, "]") {
+		return nil, errors.New(`unterminated list: missing "]"`)
+	}
+	body := strings.TrimSpace(s[1 : len(s)-1])
+	list := []string{}
+	for body != "" {
+		var item string
+		if body[0] == '"' || body[0] == '\'' {
+			end, err := quotedEnd(body)
+			if err != nil {
+				return nil, err
+			}
+			if item, err = unquoteYAML(body[:end]); err != nil {
+				return nil, err
+			}
+			body = strings.TrimSpace(body[end:])
+			if body != "" && body[0] != ',' {
+				return nil, errors.New("unexpected text after the quoted string")
+			}
+		} else {
+			i := strings.IndexByte(body, ',')
+			if i < 0 {
+				i = len(body)
+			}
+			item = strings.TrimSpace(body[:i])
+			body = body[i:]
+			if strings.ContainsAny(item, "[]{}") {
+				return nil, errors.New("nested collections are not supported")
+			}
+		}
+		list = append(list, item)
+		body = strings.TrimSpace(strings.TrimPrefix(body, ","))
+	}
+	return list, nil
+}
+
+// quotedEnd 回傳 s 開頭的引號字串結束之後的位置。
+func quotedEnd(s string) (int, error) {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case q == '"' && s[i] == '\\':
+			i++
+		case s[i] == q:
+			if q == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, errors.New("unterminated quoted string")
+}
+
+// unquoteYAML 去掉 quotedEnd 找到的字串的引號。
+func unquoteYAML(s string) (string, error) {
+	if s[0] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	u, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid double-quoted string %s", s)
+	}
+	return u, nil
+}
+
+// stripComment 去掉引號之外、位於開頭或空白之後的 # 註解。
+// 只有在值的開頭 (開頭、空白、[ 或逗號之後) 的引號才是引號字串，"it's" 中的單引號不是。
+func stripComment(s string) string {
+	var q byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case q == 0 && (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[,", s[i-1]) >= 0):
+			q = c
+		case q == '"' && c == '\\':
+			i++
+		case q != 0 && c == q:
+			q = 0
+		case q == 0 && c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
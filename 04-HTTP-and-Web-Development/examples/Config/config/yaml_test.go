@@ -0,0 +1,72 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string]any
+	}{
+		{"空檔案", "# 只有註解\n", map[string]any{}},
+		{"巢狀 mapping", "---\nhttp:\n  addr: :8080 # 註解\n  tls:\n    cert: a.pem\nlog: info\n", map[string]any{
+			"http": map[string]any{"addr": ":8080", "tls": map[string]any{"cert": "a.pem"}},
+			"log":  "info",
+		}},
+		{"引號", `a: "x # y\t"` + "\nb: 'it''s'\nc: it's # 註解\n\"d e\": 1\n", map[string]any{
+			"a": "x # y\t", "b": "it's", "c": "it's", "d e": "1",
+		}},
+		{"null", "a: ~\nb: null\nc:\n", map[string]any{"a": nil, "b": nil, "c": nil}},
+		{"區塊 list", "list:\n  - a\n  - 'b'\nsame_indent:\n- c\nnext: d\n", map[string]any{
+			"list": []string{"a", "b"}, "same_indent": []string{"c"}, "next": "d",
+		}},
+		{"流程 list", "a: [x, \"y, z\", 'w']\nb: []\n", map[string]any{
+			"a": []string{"x", "y, z", "w"}, "b": []string{},
+		}},
+		{"值中的冒號", "url: http://example.com:8080/x\n", map[string]any{"url": "http://example.com:8080/x"}},
+		{"CRLF", "a: 1\r\nb:\r\n  c: 2\r\n", map[string]any{"a": "1", "b": map[string]any{"c": "2"}}},
+	}
+	for _, tt := range tests {
+		got, err := parseYAML([]byte(tt.in))
+		if err != nil {
+			t.Errorf("%s: parseYAML() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseYAML() = %#v; 預期為 %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"a:\n\tb: 1\n", "line 2: tabs"},
+		{"a: 1\n  b: 2\n", "line 2: unexpected indentation"},
+		{"a: 1\na: 2\n", `line 2: duplicate key "a"`},
+		{"just text\n", `line 1: expected "key: value"`},
+		{"- a\n", "line 1: expected a key"},
+		{"a:\n  - x: 1\n", "lists of mappings"},
+		{"a:\n  - - x\n", "nested lists"},
+		{"a: &anchor 1\n", "anchors"},
+		{"a: |\n  text\n", "block scalars"},
+		{"a: {b: 1}\n", "flow mappings"},
+		{"a: [b, [c]]\n", "nested collections"},
+		{"a: [b\n", "unterminated list"},
+		{"a: \"b\n", "unterminated quoted string"},
+		{"a: 'b' c\n", "unexpected text"},
+		{"a: 1\n---\nb: 2\n", "multiple documents"},
+	}
+	for _, tt := range tests {
+		_, err := parseYAML([]byte(tt.in))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseYAML(%q) error = %v; 預期包含 %q", tt.in, err, tt.want)
+		}
+	}
+}
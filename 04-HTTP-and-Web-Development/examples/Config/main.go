@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/Config/config"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/net-http/server"
+)
+
+// Config 是這個服務所有的設定。每個欄位都可以由 config.yaml、APP_ 開頭的環境變數或旗標設定，
+// 例如 http.addr 對應 APP_HTTP_ADDR 與 -http.addr
+type Config struct {
+	HTTP struct {
+		Addr            string        `json:"addr" default:":8080" validate:"required" usage:"HTTP 監聽位址"`
+		ReadTimeout     time.Duration `json:"read_timeout" default:"15s" usage:"讀取整個請求的期限"`
+		WriteTimeout    time.Duration `json:"write_timeout" default:"30s" usage:"寫出回應的期限"`
+		ShutdownTimeout time.Duration `json:"shutdown_timeout" default:"20s" usage:"優雅關閉的期限"`
+	} `json:"http"`
+	Log struct {
+		// slog.Level 實作了 encoding.TextUnmarshaler，可以直接寫 debug、info、warn 或 error
+		Level slog.Level `json:"level" default:"info" usage:"日誌等級"`
+	} `json:"log"`
+	// Features 是功能開關，修改設定檔後不需要重新啟動就會生效
+	Features struct {
+		Greeting string `json:"greeting" default:"Hello" validate:"required,max=32" usage:"問候語"`
+		Shout    bool   `json:"shout" usage:"以大寫回應問候"`
+	} `json:"features"`
+	DB struct {
+		URL string `json:"url" default:"postgres://app@localhost/app" usage:"資料庫位址"`
+		// 密碼不應該寫在設定檔中：使用 APP_DB_PASSWORD_FILE=/run/secrets/db_password
+		Password config.Secret `json:"password" usage:"資料庫密碼"`
+	} `json:"db"`
+}
+
+// Validate 檢查無法以 validate tag 表達的跨欄位條件
+func (c *Config) Validate() error {
+	if c.HTTP.ShutdownTimeout <= 0 {
+		return errors.New("http.shutdown_timeout must be positive")
+	}
+	return nil
+}
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 優先順序由低到高：default tag、config.yaml、APP_* 環境變數、命令列旗標
+	// 修改 config.yaml 或送出 SIGHUP (kill -HUP <pid>) 都會重新載入
+	cfg, err := config.Watch[Config](ctx,
+		config.WithOptionalFile("config.yaml"),
+		config.WithEnv("APP"),
+		config.WithArgs(os.Args[1:]),
+	)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	// 印出生效的設定；密碼只會顯示 [REDACTED]
+	config.Print(os.Stdout, cfg.Get())
+	slog.SetLogLoggerLevel(cfg.Get().Log.Level)
+	cfg.OnChange(func(old, new *Config) {
+		slog.SetLogLoggerLevel(new.Log.Level)
+		log.Printf("features changed: %+v -> %+v", old.Features, new.Features)
+		if new.HTTP != old.HTTP {
+			log.Print("http settings take effect after a restart")
+		}
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /hello", func(w http.ResponseWriter, r *http.Request) {
+		// 每個請求都讀取最新的設定；不要把 cfg.Get() 的結果保存起來
+		f := cfg.Get().Features
+		msg := f.Greeting + ", World!"
+		if f.Shout {
+			msg = strings.ToUpper(msg)
+		}
+		slog.Debug("hello", "greeting", msg)
+		fmt.Fprintln(w, msg)
+	})
+
+	// 監聽位址與逾時只在啟動時讀取一次
+	c := cfg.Get()
+	srv := server.New(
+		server.WithTimeouts(server.Timeouts{
+			ReadHeader: server.DefaultTimeouts.ReadHeader,
+			Read:       c.HTTP.ReadTimeout,
+			Write:      c.HTTP.WriteTimeout,
+			Idle:       server.DefaultTimeouts.Idle,
+		}),
+		server.WithShutdownTimeout(c.HTTP.ShutdownTimeout),
+	)
+	if _, err := srv.Listen("http", c.HTTP.Addr, mux); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Try: curl " + srv.URL("http") + "/hello")
+	fmt.Println("     then edit features.greeting in config.yaml and try again")
+	if err := srv.Run(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
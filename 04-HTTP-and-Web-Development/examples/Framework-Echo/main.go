@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/Config/config"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/validate"
+)
+
+// userHandler 以 Echo 提供與 JSON 範例 (net/http) 相同的使用者 API。
+// 驗證與儲存都交給 users.Service，這裡只負責讀取路徑參數、解碼請求與寫出回應。
+type userHandler struct {
+	svc *users.Service
+}
+
+// newServer 建立 Echo 實例並註冊路由；*echo.Echo 實作了 http.Handler
+func newServer(svc *users.Service) *echo.Echo {
+	e := echo.New()
+	e.HideBanner = true
+	e.HTTPErrorHandler = errorHandler(e)
+
+	// Echo 的 handler 回傳 error，沒有處理的錯誤交給 e.HTTPErrorHandler
+	e.GET("/ping", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"message": "pong"})
+	})
+
+	h := &userHandler{svc: svc}
+	e.GET("/users", h.list)
+	e.POST("/users", h.create)
+	e.GET("/users/:id", h.get)
+	e.PUT("/users/:id", h.replace)
+	e.PATCH("/users/:id", h.patch)
+	e.DELETE("/users/:id", h.delete)
+	return e
+}
+
+func (h *userHandler) list(c echo.Context) error {
+	list, err := h.svc.List(c.Request().Context())
+	if err != nil {
+		return writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+func (h *userHandler) get(c echo.Context) error {
+	id, err := paramID(c)
+	if err != nil {
+		return writeError(c, err)
+	}
+	u, err := h.svc.Get(c.Request().Context(), id)
+	if err != nil {
+		return writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, u)
+}
+
+func (h *userHandler) create(c echo.Context) error {
+	var u users.User
+	// c.Bind 會默默接受未知的欄位，也不限制主體大小，所以改用 validate.Decode
+	if err := validate.Decode(c.Response(), c.Request(), &u, 0); err != nil {
+		return writeError(c, err)
+	}
+	u, err := h.svc.Create(c.Request().Context(), u)
+	if err != nil {
+		return writeError(c, err)
+	}
+	c.Response().Header().Set("Location", path.Join(c.Request().URL.Path, strconv.Itoa(u.ID)))
+	return c.JSON(http.StatusCreated, u)
+}
+
+func (h *userHandler) replace(c echo.Context) error {
+	id, err := paramID(c)
+	if err != nil {
+		return writeError(c, err)
+	}
+	var next users.User
+	if err := validate.Decode(c.Response(), c.Request(), &next, 0); err != nil {
+		return writeError(c, err)
+	}
+	u, err := h.svc.Replace(c.Request().Context(), id, next)
+	if err != nil {
+		return writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, u)
+}
+
+func (h *userHandler) patch(c echo.Context) error {
+	id, err := paramID(c)
+	if err != nil {
+		return writeError(c, err)
+	}
+	var p users.Patch
+	if err := validate.Decode(c.Response(), c.Request(), &p, 0); err != nil {
+		return writeError(c, err)
+	}
+	u, err := h.svc.Patch(c.Request().Context(), id, p)
+	if err != nil {
+		return writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, u)
+}
+
+func (h *userHandler) delete(c echo.Context) error {
+	id, err := paramID(c)
+	if err != nil {
+		return writeError(c, err)
+	}
+	if err := h.svc.Delete(c.Request().Context(), id); err != nil {
+		return writeError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// paramID 解析路徑參數 id。路由最後的參數在 Echo 中會比對剩下的整段路徑 (包括 /)，
+// /users/1/extra 也會進到 /users/:id，所以這種情況與其他框架一樣當成沒有符合的路由
+func paramID(c echo.Context) (int, error) {
+	s := c.Param("id")
+	if strings.Contains(s, "/") {
+		return 0, users.ErrNoRoute
+	}
+	return users.ParseID(s)
+}
+
+// writeError 寫出 users.ErrorFor 對應的狀態碼與主體；回傳的是寫入回應的錯誤，handler 直接把它 return 給 Echo
+func writeError(c echo.Context, err error) error {
+	status, body := users.ErrorFor(err)
+	return c.JSON(status, body)
+}
+
+// errorHandler 包裝 e.DefaultHTTPErrorHandler。沒有符合的路由時 Echo 以 echo.ErrNotFound 或
+// echo.ErrMethodNotAllowed 呼叫它 (405 時 Allow 標頭已經設定好)，預設的主體是 {"message": ...}，
+// 這裡改成與其他錯誤相同的 users.ErrorFor 主體
+func errorHandler(e *echo.Echo) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		switch {
+		case c.Response().Committed:
+			return
+		case errors.Is(err, echo.ErrNotFound):
+			err = writeError(c, users.ErrNoRoute)
+		case errors.Is(err, echo.ErrMethodNotAllowed):
+			err = writeError(c, users.ErrMethodNotAllowed)
+		default:
+			e.DefaultHTTPErrorHandler(err, c)
+			return
+		}
+		if err != nil {
+			e.Logger.Error(err)
+		}
+	}
+}
+
+func main() {
+	cfg, err := config.LoadListen("APP", os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	svc := users.NewService(users.NewMemoryStore())
+	if _, err := svc.Create(context.Background(), users.User{Name: "Alice", Email: "alice@example.com"}); err != nil {
+		log.Fatal(err)
+	}
+
+	// 與 Gin 不同，Echo 在處理請求時才組合中介軟體，所以註冊路由之後再 Use 也會套用到所有路由
+	e := newServer(svc)
+	e.Use(middleware.Logger(), middleware.Recover())
+
+	// 在執行前，請先使用 `go get github.com/labstack/echo/v4` 安裝 Echo
+	if err := e.Start(cfg.Addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
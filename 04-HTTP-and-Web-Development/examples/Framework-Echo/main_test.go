@@ -0,0 +1,15 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users/userstest"
+)
+
+func TestConformance(t *testing.T) {
+	userstest.Run(t, func(svc *users.Service) http.Handler {
+		return newServer(svc)
+	})
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/Config/config"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/validate"
+)
+
+// userHandler 以 Fiber 提供與 JSON 範例 (net/http) 相同的使用者 API。
+// 驗證與儲存都交給 users.Service，這裡只負責讀取路徑參數、解碼請求與寫出回應。
+//
+// Fiber 建立在 fasthttp 而不是 net/http 之上：*fiber.Ctx 沒有 *http.Request，
+// 而且 c.Params 等回傳的字串在 handler 回傳後就會被重複使用，不可以保存下來。
+type userHandler struct {
+	svc *users.Service
+}
+
+// newApp 建立 Fiber 應用程式並註冊路由。Fiber 依註冊的順序比對，
+// 在路由之後才 Use 的中介軟體不會在這些路由之前執行，所以由參數傳入
+func newApp(svc *users.Service, middleware ...fiber.Handler) *fiber.App {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true, ErrorHandler: errorHandler})
+	for _, m := range middleware {
+		app.Use(m)
+	}
+
+	app.Get("/ping", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"message": "pong"})
+	})
+
+	h := &userHandler{svc: svc}
+	app.Get("/users", h.list)
+	app.Post("/users", h.create)
+	app.Get("/users/:id", h.get)
+	app.Put("/users/:id", h.replace)
+	app.Patch("/users/:id", h.patch)
+	app.Delete("/users/:id", h.delete)
+	return app
+}
+
+func (h *userHandler) list(c *fiber.Ctx) error {
+	list, err := h.svc.List(c.UserContext())
+	if err != nil {
+		return writeError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(list)
+}
+
+func (h *userHandler) get(c *fiber.Ctx) error {
+	id, err := users.ParseID(c.Params("id"))
+	if err != nil {
+		return writeError(c, err)
+	}
+	u, err := h.svc.Get(c.UserContext(), id)
+	if err != nil {
+		return writeError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(u)
+}
+
+func (h *userHandler) create(c *fiber.Ctx) error {
+	var u users.User
+	if err := decode(c, &u); err != nil {
+		return writeError(c, err)
+	}
+	u, err := h.svc.Create(c.UserContext(), u)
+	if err != nil {
+		return writeError(c, err)
+	}
+	c.Location(path.Join(c.Path(), strconv.Itoa(u.ID)))
+	return c.Status(fiber.StatusCreated).JSON(u)
+}
+
+func (h *userHandler) replace(c *fiber.Ctx) error {
+	id, err := users.ParseID(c.Params("id"))
+	if err != nil {
+		return writeError(c, err)
+	}
+	var next users.User
+	if err := decode(c, &next); err != nil {
+		return writeError(c, err)
+	}
+	u, err := h.svc.Replace(c.UserContext(), id, next)
+	if err != nil {
+		return writeError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(u)
+}
+
+func (h *userHandler) patch(c *fiber.Ctx) error {
+	id, err := users.ParseID(c.Params("id"))
+	if err != nil {
+		return writeError(c, err)
+	}
+	var p users.Patch
+	if err := decode(c, &p); err != nil {
+		return writeError(c, err)
+	}
+	u, err := h.svc.Patch(c.UserContext(), id, p)
+	if err != nil {
+		return writeError(c, err)
+	}
+	return c.Status(fiber.StatusOK).JSON(u)
+}
+
+func (h *userHandler) delete(c *fiber.Ctx) error {
+	id, err := users.ParseID(c.Params("id"))
+	if err != nil {
+		return writeError(c, err)
+	}
+	if err := h.svc.Delete(c.UserContext(), id); err != nil {
+		return writeError(c, err)
+	}
+	// c.SendStatus 會在主體為空時寫入狀態的說明文字，204 不可以有主體
+	c.Status(fiber.StatusNoContent)
+	return nil
+}
+
+// decode 以 validate.Decode 解碼請求主體。c.BodyParser 會默默接受未知的欄位，
+// 所以先把請求轉成 *http.Request；沒有 http.ResponseWriter 可以傳入，超過大小時仍然回傳 413
+func decode(c *fiber.Ctx, dst any) error {
+	r, err := adaptor.ConvertRequest(c, false)
+	if err != nil {
+		return err
+	}
+	return validate.Decode(nil, r, dst, 0)
+}
+
+// writeError 以 c.Status 設定 users.ErrorFor 對應的狀態碼再寫出主體；Fiber 預設的狀態碼是 200，不能只呼叫 JSON
+func writeError(c *fiber.Ctx, err error) error {
+	status, body := users.ErrorFor(err)
+	return c.Status(status).JSON(body)
+}
+
+// errorHandler 處理 handler 回傳的錯誤。沒有符合的路由時 Fiber 回傳 404 或 405 的 *fiber.Error
+// (405 時 Allow 標頭已經設定好)，預設以純文字回應；這裡改成與其他錯誤相同的 users.ErrorFor 主體
+func errorHandler(c *fiber.Ctx, err error) error {
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		switch fe.Code {
+		case fiber.StatusNotFound:
+			return writeError(c, users.ErrNoRoute)
+		case fiber.StatusMethodNotAllowed:
+			return writeError(c, users.ErrMethodNotAllowed)
+		}
+	}
+	return fiber.DefaultErrorHandler(c, err)
+}
+
+func main() {
+	cfg, err := config.LoadListen("APP", os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	svc := users.NewService(users.NewMemoryStore())
+	if _, err := svc.Create(context.Background(), users.User{Name: "Alice", Email: "alice@example.com"}); err != nil {
+		log.Fatal(err)
+	}
+
+	app := newApp(svc, logger.New(), recover.New())
+
+	// 在執行前，請先使用 `go get github.com/gofiber/fiber/v2` 安裝 Fiber
+	if err := app.Listen(cfg.Addr); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users/userstest"
+)
+
+// *fiber.App 不是 http.Handler；adaptor.FiberApp 把 net/http 的請求轉給它，
+// 所以同一組以 httptest 撰寫的一致性測試也能用在 Fiber 上
+func TestConformance(t *testing.T) {
+	userstest.Run(t, func(svc *users.Service) http.Handler {
+		return adaptor.FiberApp(newApp(svc))
+	})
+}
@@ -1,20 +1,37 @@
 package main
 
 import (
+	"context"
+	"log"
 	"net/http"
+	"os"
+	"path"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/Config/config"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/validate"
 )
 
-// User struct 用於定義我們的資料模型
-type User struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+// userHandler 以 Gin 提供與 JSON 範例 (net/http) 相同的使用者 API。
+// 驗證與儲存都交給 users.Service，這裡只負責讀取路徑參數、解碼請求與寫出回應。
+type userHandler struct {
+	svc *users.Service
 }
 
-func main() {
-	// 初始化 Gin 引擎，使用預設的中介軟體 (Logger 和 Recovery)
-	r := gin.Default()
+// newRouter 建立 Gin 引擎並註冊路由。中介軟體必須在註冊路由之前 Use，
+// 之後才加入的不會套用到已經註冊的路由，所以由參數傳入；測試時不傳入 Logger
+func newRouter(svc *users.Service, middleware ...gin.HandlerFunc) *gin.Engine {
+	r := gin.New()
+	r.Use(middleware...)
+
+	// Gin 預設以純文字回應 404，方法不符也當成 404；
+	// 打開 HandleMethodNotAllowed 後才會回應 405 與 Allow 標頭。兩者都改成與其他錯誤相同的 JSON 主體
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(func(c *gin.Context) { writeError(c, users.ErrNoRoute) })
+	r.NoMethod(func(c *gin.Context) { writeError(c, users.ErrMethodNotAllowed) })
 
 	// 定義一個 GET 路由
 	r.GET("/ping", func(c *gin.Context) {
@@ -24,31 +41,131 @@ func main() {
 		})
 	})
 
-	// 帶有路徑參數的路由
-	r.GET("/users/:id", func(c *gin.Context) {
-		id := c.Param("id")
-		c.JSON(http.StatusOK, gin.H{
-			"message": "Getting user with ID: " + id,
-		})
-	})
+	// :id 是路徑參數，以 c.Param("id") 取得
+	h := &userHandler{svc: svc}
+	r.GET("/users", h.list)
+	r.POST("/users", h.create)
+	r.GET("/users/:id", h.get)
+	r.PUT("/users/:id", h.replace)
+	r.PATCH("/users/:id", h.patch)
+	r.DELETE("/users/:id", h.delete)
+	return r
+}
 
-	// 定義一個 POST 路由
-	r.POST("/users", func(c *gin.Context) {
-		var user User
+func (h *userHandler) list(c *gin.Context) {
+	list, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
 
-		// c.ShouldBindJSON 會將請求的 JSON 主體綁定到 user struct 上
-		if err := c.ShouldBindJSON(&user); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
+func (h *userHandler) get(c *gin.Context) {
+	id, err := users.ParseID(c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	u, err := h.svc.Get(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
 
-		c.JSON(http.StatusCreated, gin.H{
-			"status":  "success",
-			"message": "User created: " + user.Name,
-		})
-	})
+func (h *userHandler) create(c *gin.Context) {
+	var u users.User
+	// c.ShouldBindJSON 會默默接受 {"name":""} 與未知的欄位；
+	// validate.Decode 拒絕未知的欄位、限制主體大小，並回傳帶有狀態碼的 *validate.RequestError
+	if err := validate.Decode(c.Writer, c.Request, &u, 0); err != nil {
+		writeError(c, err)
+		return
+	}
+	u, err := h.svc.Create(c.Request.Context(), u)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Header("Location", path.Join(c.Request.URL.Path, strconv.Itoa(u.ID)))
+	c.JSON(http.StatusCreated, u)
+}
+
+func (h *userHandler) replace(c *gin.Context) {
+	id, err := users.ParseID(c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	var next users.User
+	if err := validate.Decode(c.Writer, c.Request, &next, 0); err != nil {
+		writeError(c, err)
+		return
+	}
+	u, err := h.svc.Replace(c.Request.Context(), id, next)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+func (h *userHandler) patch(c *gin.Context) {
+	id, err := users.ParseID(c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	var p users.Patch
+	if err := validate.Decode(c.Writer, c.Request, &p, 0); err != nil {
+		writeError(c, err)
+		return
+	}
+	u, err := h.svc.Patch(c.Request.Context(), id, p)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+func (h *userHandler) delete(c *gin.Context) {
+	id, err := users.ParseID(c.Param("id"))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), id); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// writeError 以 c.JSON 寫出 users.ErrorFor 對應的狀態碼與主體；c.JSON 不會中止 handler，呼叫之後仍然要 return
+func writeError(c *gin.Context, err error) {
+	status, body := users.ErrorFor(err)
+	c.JSON(status, body)
+}
+
+func main() {
+	cfg, err := config.LoadListen("APP", os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	svc := users.NewService(users.NewMemoryStore())
+	if _, err := svc.Create(context.Background(), users.User{Name: "Alice", Email: "alice@example.com"}); err != nil {
+		log.Fatal(err)
+	}
+
+	// 使用 gin.Default 預設的中介軟體 (Logger 和 Recovery)
+	r := newRouter(svc, gin.Logger(), gin.Recovery())
 
 	// 啟動伺服器，預設監聽在 :8080
 	// 在執行前，請確保您已經使用 `go get github.com/gin-gonic/gin` 安裝了 Gin
-	r.Run()
+	if err := r.Run(cfg.Addr); err != nil {
+		log.Fatal(err)
+	}
 }
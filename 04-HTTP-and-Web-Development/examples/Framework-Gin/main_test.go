@@ -0,0 +1,18 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users/userstest"
+)
+
+func TestConformance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userstest.Run(t, func(svc *users.Service) http.Handler {
+		return newRouter(svc)
+	})
+}
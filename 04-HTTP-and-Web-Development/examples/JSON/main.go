@@ -1,69 +1,49 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
 	"net/http"
-)
-
-// User struct 用於定義我們的資料模型
-type User struct {
-	// struct tag 用於控制 JSON 的欄位名稱
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-}
+	"os"
+	"time"
 
-// usersHandler 處理對 /users 路徑的請求
-func usersHandler(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		getUser(w, r)
-	case http.MethodPost:
-		createUser(w, r)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-	}
-}
-
-// getUser 處理 GET 請求，回傳一個 User 的 JSON
-func getUser(w http.ResponseWriter, r *http.Request) {
-	user := User{ID: 1, Name: "Alice"}
-
-	// 設定回應的 Content-Type 為 application/json
-	w.Header().Set("Content-Type", "application/json")
-
-	// 使用 json.NewEncoder 將 user struct 編碼為 JSON 並寫入 ResponseWriter
-	if err := json.NewEncoder(w).Encode(user); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-}
-
-// createUser 處理 POST 請求，從請求主體中解碼 User 的 JSON
-func createUser(w http.ResponseWriter, r *http.Request) {
-	var user User
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/Config/config"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/net-http/router"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/net-http/server"
+)
 
-	// 使用 json.NewDecoder 從請求的 Body 中讀取並解碼 JSON
-	err := json.NewDecoder(r.Body).Decode(&user)
+func main() {
+	cfg, err := config.LoadListen("APP", os.Args[1:])
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		log.Fatal(err)
 	}
 
-	log.Printf("Created user: %+v\n", user)
+	// 使用記憶體中的 UserStore；換成資料庫只需要提供另一個 UserStore 實作
+	store := users.NewMemoryStore()
+	store.Create(context.Background(), users.User{Name: "Alice", Email: "alice@example.com"})
 
-	// 回傳 201 Created 狀態碼
-	w.WriteHeader(http.StatusCreated)
-	fmt.Fprintf(w, "User created: %s", user.Name)
-}
-
-func main() {
-	http.HandleFunc("/users", usersHandler)
+	r := router.New()
+	// 讓 404 與 405 也依 Accept 回應 JSON 或其他格式
+	r.NotFound = http.HandlerFunc(users.NotFound)
+	r.MethodNotAllowed = http.HandlerFunc(users.MethodNotAllowed)
+	// 同時查詢同一個 User 的請求共用一次查詢，並把結果快取一分鐘；寫入時快取會失效
+	users.NewHandler(users.NewService(users.Cached(store, time.Minute))).Mount(r.Group(""))
 
-	fmt.Println("Server starting on http://localhost:8080")
-	fmt.Println(`Try GET and POST on http://localhost:8080/users`)
-	fmt.Println(`POST example: curl -X POST -d '{"name":"Bob"}' http://localhost:8080/users`)
-	if err := http.ListenAndServe(":8080", nil); err != nil {
-		log.Fatal("ListenAndServe: ", err)
+	srv := server.New()
+	if _, err := srv.Listen("http", cfg.Addr, r); err != nil {
+		log.Fatal(err)
+	}
+	base := srv.URL("http")
+	fmt.Println("Server starting on " + cfg.Addr)
+	fmt.Println("Try GET " + base + "/users and " + base + "/users/1")
+	fmt.Println(`POST example:  curl -i -X POST -H 'Content-Type: application/json' -d '{"name":"Bob"}' ` + base + "/users")
+	fmt.Println(`PATCH example: curl -X PATCH -H 'Content-Type: application/json' -d '{"email":"bob@example.com"}' ` + base + "/users/2")
+	// curl -d 預設的 Content-Type 是表單，也可以直接送出
+	fmt.Println(`Form example:  curl -X POST -d 'name=Carol' ` + base + "/users")
+	fmt.Println(`XML example:   curl -H 'Accept: application/xml' ` + base + "/users")
+	if err := srv.Run(context.Background()); err != nil {
+		log.Fatal(err)
 	}
 }
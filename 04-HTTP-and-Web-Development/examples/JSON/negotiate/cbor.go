@@ -0,0 +1,352 @@
+package negotiate
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// CBOR 是 RFC 8949 的 Concise Binary Object Representation，媒體類型 application/cbor。
+//
+// 每個資料項目以一個位元組開頭：高 3 位元是主要型別 (major type)，低 5 位元是參數；
+// 參數 < 24 時就是值本身，24 到 27 表示後面接著 1、2、4、8 位元組的值。
+//
+//	0 非負整數  1 負整數 (-1-n)  2 位元組  3 UTF-8 字串
+//	4 陣列      5 map           6 tag     7 浮點數與 false、true、null
+//
+// 編碼時浮點數一律使用 8 位元組；解碼支援 2、4、8 位元組的浮點數、不定長度的項目，並略過 tag。
+type CBOR struct{}
+
+// MediaTypes 實作 Codec。
+func (CBOR) MediaTypes() []string { return []string{"application/cbor"} }
+
+// Encode 實作 Codec。
+func (CBOR) Encode(w io.Writer, v any) error {
+	data, err := MarshalCBOR(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Decode 實作 Codec。
+func (CBOR) Decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return UnmarshalCBOR(data, v)
+}
+
+// DecodeStrict 實作 StrictDecoder。
+func (CBOR) DecodeStrict(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return unmarshalCBOR(data, v, true)
+}
+
+// MarshalCBOR 回傳 v 的 CBOR 編碼。
+func MarshalCBOR(v any) ([]byte, error) {
+	node, err := toValue(reflect.ValueOf(v), 0)
+	if err != nil {
+		return nil, err
+	}
+	return appendCBOR(nil, node), nil
+}
+
+// UnmarshalCBOR 把剛好一個 CBOR 項目解碼到 v 指向的變數。
+func UnmarshalCBOR(data []byte, v any) error {
+	return unmarshalCBOR(data, v, false)
+}
+
+func unmarshalCBOR(data []byte, v any, strict bool) error {
+	rv, err := decodeTarget(v)
+	if err != nil {
+		return err
+	}
+	d := cborDecoder{data: data}
+	node, err := d.item(0)
+	if err != nil {
+		return err
+	}
+	if d.off != len(data) {
+		return fmt.Errorf("cbor: %d trailing bytes after the first item", len(data)-d.off)
+	}
+	return assign(node, rv, strict)
+}
+
+const (
+	cborUint   = 0 << 5
+	cborNeg    = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+
+	cborFalse   = cborSimple | 20
+	cborTrue    = cborSimple | 21
+	cborNull    = cborSimple | 22
+	cborUndef   = cborSimple | 23
+	cborFloat16 = cborSimple | 25
+	cborFloat32 = cborSimple | 26
+	cborFloat64 = cborSimple | 27
+	cborBreak   = cborSimple | 31
+
+	cborIndefinite = 31
+)
+
+// appendHead 附加主要型別 major 與參數 n，使用能容納 n 的最短形式。
+func appendHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), n)
+	}
+}
+
+func appendCBOR(b []byte, node any) []byte {
+	switch x := node.(type) {
+	case nil:
+		return append(b, cborNull)
+	case bool:
+		if x {
+			return append(b, cborTrue)
+		}
+		return append(b, cborFalse)
+	case uint64:
+		return appendHead(b, cborUint, x)
+	case int64:
+		return appendHead(b, cborNeg, uint64(-1-x))
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, cborFloat64), math.Float64bits(x))
+	case string:
+		return append(appendHead(b, cborText, uint64(len(x))), x...)
+	case []byte:
+		return append(appendHead(b, cborBytes, uint64(len(x))), x...)
+	case []any:
+		b = appendHead(b, cborArray, uint64(len(x)))
+		for _, item := range x {
+			b = appendCBOR(b, item)
+		}
+		return b
+	case []entry:
+		b = appendHead(b, cborMap, uint64(len(x)))
+		for _, e := range x {
+			b = appendCBOR(appendCBOR(b, e.key), e.value)
+		}
+		return b
+	}
+	panic(fmt.Sprintf("cbor: unexpected value %T", node))
+}
+
+var errCBORTruncated = errors.New("cbor: unexpected end of data")
+
+type cborDecoder struct {
+	data []byte
+	off  int
+}
+
+// head 讀取一個項目的開頭，回傳主要型別、低 5 位元與參數的值。
+func (d *cborDecoder) head() (major, info byte, n uint64, err error) {
+	if d.off >= len(d.data) {
+		return 0, 0, 0, errCBORTruncated
+	}
+	b := d.data[d.off]
+	d.off++
+	major, info = b&0xe0, b&0x1f
+	if info < 24 || info == cborIndefinite {
+		return major, info, uint64(info), nil
+	}
+	if info > 27 {
+		return 0, 0, 0, fmt.Errorf("cbor: reserved additional information %d at offset %d", info, d.off-1)
+	}
+	size := 1 << (info - 24)
+	if len(d.data)-d.off < size {
+		return 0, 0, 0, errCBORTruncated
+	}
+	p := d.data[d.off : d.off+size]
+	d.off += size
+	switch size {
+	case 1:
+		n = uint64(p[0])
+	case 2:
+		n = uint64(binary.BigEndian.Uint16(p))
+	case 4:
+		n = uint64(binary.BigEndian.Uint32(p))
+	case 8:
+		n = binary.BigEndian.Uint64(p)
+	}
+	return major, info, n, nil
+}
+
+// count 檢查長度 n 不超過剩餘的資料；每個項目至少 1 位元組，所以元素數也適用這個上限。
+func (d *cborDecoder) count(n uint64) (int, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return 0, errCBORTruncated
+	}
+	return int(n), nil
+}
+
+func (d *cborDecoder) atBreak() bool {
+	if d.off < len(d.data) && d.data[d.off] == cborBreak {
+		d.off++
+		return true
+	}
+	return false
+}
+
+func (d *cborDecoder) item(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("cbor: data nested deeper than %d", maxDepth)
+	}
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	indefinite := info == cborIndefinite
+	switch major {
+	case cborUint, cborNeg:
+		if indefinite {
+			return nil, fmt.Errorf("cbor: invalid integer at offset %d", d.off-1)
+		}
+		if major == cborUint {
+			return n, nil
+		}
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("cbor: negative integer -1-%d overflows int64", n)
+		}
+		return -1 - int64(n), nil
+	case cborBytes, cborText:
+		var s []byte
+		if indefinite {
+			// 不定長度的字串是一連串相同型別的定長片段，以 break 結尾。
+			for !d.atBreak() {
+				chunkMajor, chunkInfo, size, err := d.head()
+				if err != nil {
+					return nil, err
+				}
+				if chunkMajor != major || chunkInfo == cborIndefinite {
+					return nil, fmt.Errorf("cbor: invalid chunk in indefinite-length string at offset %d", d.off)
+				}
+				chunk, err := d.bytes(size)
+				if err != nil {
+					return nil, err
+				}
+				s = append(s, chunk...)
+			}
+		} else if s, err = d.bytes(n); err != nil {
+			return nil, err
+		}
+		if major == cborText {
+			return string(s), nil
+		}
+		if s == nil {
+			s = []byte{}
+		}
+		return s, nil
+	case cborArray:
+		list := []any{}
+		if !indefinite {
+			if _, err := d.count(n); err != nil {
+				return nil, err
+			}
+		}
+		for i := uint64(0); indefinite || i < n; i++ {
+			if indefinite && d.atBreak() {
+				break
+			}
+			item, err := d.item(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	case cborMap:
+		entries := []entry{}
+		if !indefinite {
+			if _, err := d.count(n); err != nil {
+				return nil, err
+			}
+		}
+		for i := uint64(0); indefinite || i < n; i++ {
+			if indefinite && d.atBreak() {
+				break
+			}
+			key, err := d.item(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.item(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry{key, value})
+		}
+		return entries, nil
+	case cborTag:
+		// tag (例如 1 代表 epoch 時間) 只是語意上的標註，直接解碼被標註的項目。
+		return d.item(depth + 1)
+	}
+
+	switch major | info {
+	case cborFalse:
+		return false, nil
+	case cborTrue:
+		return true, nil
+	case cborNull, cborUndef:
+		return nil, nil
+	case cborFloat16:
+		return float16(uint16(n)), nil
+	case cborFloat32:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case cborFloat64:
+		return math.Float64frombits(n), nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported item 0x%02x at offset %d", major|info, d.off-1)
+}
+
+func (d *cborDecoder) bytes(n uint64) ([]byte, error) {
+	size, err := d.count(n)
+	if err != nil {
+		return nil, err
+	}
+	b := d.data[d.off : d.off+size]
+	d.off += size
+	return b, nil
+}
+
+// float16 把 IEEE 754 半精度浮點數轉成 float64。
+func float16(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	}
+	return sign * math.Ldexp(mant+1024, exp-25)
+}
@@ -0,0 +1,214 @@
+package negotiate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 測試資料取自 RFC 8949 附錄 A。
+func TestMarshalCBOR(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{100, "1864"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{uint64(18446744073709551615), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-1000, "3903e7"},
+		{int64(math.MinInt64), "3b7fffffffffffffff"},
+		{1.1, "fb3ff199999999999a"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{"", "60"},
+		{"IETF", "6449455446"},
+		{"水", "63e6b0b4"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[]int{}, "80"},
+		{[]int{1, 2, 3}, "83010203"},
+		{[]any{1, []int{2, 3}, []int{4, 5}}, "8301820203820405"},
+		{map[string]any{"a": 1, "b": []int{2, 3}}, "a26161016162820203"},
+		{struct {
+			A int    `json:"a"`
+			B string `json:"b,omitempty"`
+			C bool   `json:"-"`
+		}{A: 1, C: true}, "a1616101"},
+	}
+	for _, tt := range tests {
+		got, err := MarshalCBOR(tt.v)
+		if err != nil {
+			t.Errorf("MarshalCBOR(%v) error = %v", tt.v, err)
+			continue
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("MarshalCBOR(%v) = %x; 預期為 %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalCBORAny(t *testing.T) {
+	tests := []struct {
+		data string
+		want any
+	}{
+		{"00", uint64(0)},
+		{"3903e7", int64(-1000)},
+		{"f93e00", 1.5},
+		{"f9c400", -4.0},
+		{"f90001", 5.960464477539063e-08},
+		{"f97c00", math.Inf(1)},
+		{"fa47c35000", 100000.0},
+		{"f7", nil},
+		{"c11a514b67b0", uint64(1363896240)}, // tag 1 (epoch 時間) 被略過
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9fff", []any{}},
+		{"9f018202039f0405ffff", []any{uint64(1), []any{uint64(2), uint64(3)}, []any{uint64(4), uint64(5)}}},
+		{"bf61610161629f0203ffff", map[string]any{"a": uint64(1), "b": []any{uint64(2), uint64(3)}}},
+		{"a201020304", map[any]any{uint64(1): uint64(2), uint64(3): uint64(4)}},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.data)
+		var got any
+		if err := UnmarshalCBOR(data, &got); err != nil {
+			t.Errorf("UnmarshalCBOR(%s) error = %v", tt.data, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("UnmarshalCBOR(%s) = %#v; 預期為 %#v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalCBORErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		dst  any
+	}{
+		{"空的輸入", "", new(any)},
+		{"截斷的整數", "19", new(any)},
+		{"截斷的字串", "6449", new(any)},
+		{"長度超過資料", "9bffffffffffffffff", new(any)},
+		{"保留的參數", "1c", new(any)},
+		{"多餘的資料", "0000", new(any)},
+		{"沒有 break", "9f01", new(any)},
+		{"負數溢位", "3bffffffffffffffff", new(any)},
+		{"型別不符", "6161", new(int)},
+		{"整數溢位", "190100", new(int8)},
+		{"負數到無號整數", "20", new(uint)},
+		{"非指標", "00", 0},
+		{"陣列作為 map 的鍵", "a181010200", new(map[any]any)},
+		{"嵌入未匯出型別的 nil 指標", "a1617801", new(embedsHidden)},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.data)
+		if err := UnmarshalCBOR(data, tt.dst); err == nil {
+			t.Errorf("%s: UnmarshalCBOR(%s) error = nil; 預期為錯誤", tt.name, tt.data)
+		}
+	}
+
+	// 深度巢狀的陣列不能耗盡堆疊。
+	deep := bytes.Repeat([]byte{0x81}, maxDepth+10)
+	if err := UnmarshalCBOR(append(deep, 0x00), new(any)); err == nil {
+		t.Error("UnmarshalCBOR(深度巢狀) error = nil; 預期為錯誤")
+	}
+}
+
+type profile struct {
+	Tags    []string          `json:"tags"`
+	Scores  map[string]int    `json:"scores"`
+	Avatar  []byte            `json:"avatar"`
+	Ratio   float32           `json:"ratio"`
+	Manager *user             `json:"manager,omitempty"`
+	Joined  time.Time         `json:"joined"`
+	Extra   map[string]any    `json:"extra"`
+	Fixed   [2]int8           `json:"fixed"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// hidden 是未匯出的型別；embedsHidden 以指標嵌入它，解碼時無法配置這個指標。
+type hidden struct {
+	X int `json:"x"`
+}
+
+type embedsHidden struct {
+	*hidden
+	Y int `json:"y"`
+}
+
+type account struct {
+	user // 嵌入的欄位提升到外層
+	profile
+	Balance int64 `json:"balance"`
+}
+
+func sampleAccount() account {
+	return account{
+		user: user{ID: 1, Name: "Alice"},
+		profile: profile{
+			Tags:    []string{"admin", "ops"},
+			Scores:  map[string]int{"go": 10, "rust": -3},
+			Avatar:  []byte{0, 1, 0xff},
+			Ratio:   0.5,
+			Manager: &user{ID: 2, Name: "Bob", Email: "bob@example.com"},
+			Joined:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			Extra:   map[string]any{"nested": []any{"x", true, nil}},
+			Fixed:   [2]int8{-128, 127},
+		},
+		Balance: -12345678901,
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	want := sampleAccount()
+	data, err := MarshalCBOR(want)
+	if err != nil {
+		t.Fatalf("MarshalCBOR error = %v", err)
+	}
+	var got account
+	if err := UnmarshalCBOR(data, &got); err != nil {
+		t.Fatalf("UnmarshalCBOR error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalCBOR(MarshalCBOR(v)) = %+v; 預期為 %+v", got, want)
+	}
+}
+
+// 嵌入的指標已經配置時可以寫入；nil 時回傳錯誤而不是 panic (與 encoding/json 相同)。
+func TestDecodeEmbeddedUnexportedPointer(t *testing.T) {
+	cborData, _ := hex.DecodeString("a2617801617902")    // {"x": 1, "y": 2}
+	msgpackData, _ := hex.DecodeString("82a17801a17902") // {"x": 1, "y": 2}
+	decoders := []struct {
+		name   string
+		decode func(v any) error
+	}{
+		{"CBOR", func(v any) error { return UnmarshalCBOR(cborData, v) }},
+		{"MessagePack", func(v any) error { return UnmarshalMsgPack(msgpackData, v) }},
+		{"Form", func(v any) error { return (Form{}).Decode(strings.NewReader("x=1&y=2"), v) }},
+	}
+	for _, d := range decoders {
+		var nilPtr embedsHidden
+		if err := d.decode(&nilPtr); err == nil || !strings.Contains(err.Error(), "unexported") {
+			t.Errorf("%s: 解碼到 nil 的嵌入指標 error = %v; 預期為 unexported 的錯誤", d.name, err)
+		}
+		got := embedsHidden{hidden: &hidden{}}
+		if err := d.decode(&got); err != nil {
+			t.Errorf("%s: 解碼到已配置的嵌入指標 error = %v", d.name, err)
+			continue
+		}
+		if got.X != 1 || got.Y != 2 {
+			t.Errorf("%s: 解碼結果 = {X:%d Y:%d}; 預期為 {X:1 Y:2}", d.name, got.X, got.Y)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+package negotiate
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/validate"
+)
+
+// JSON 以 encoding/json 編碼，媒體類型 application/json。
+type JSON struct{}
+
+// MediaTypes 實作 Codec。
+func (JSON) MediaTypes() []string { return []string{"application/json"} }
+
+// Encode 實作 Codec。
+func (JSON) Encode(w io.Writer, v any) error { return json.NewEncoder(w).Encode(v) }
+
+// Decode 實作 Codec；主體只能包含一個 JSON 值。
+func (JSON) Decode(r io.Reader, v any) error {
+	return decodeJSON(json.NewDecoder(r), v)
+}
+
+// DecodeStrict 實作 StrictDecoder。
+func (JSON) DecodeStrict(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err := decodeJSON(dec, v)
+	if field, ok := validate.UnknownJSONField(err); ok {
+		return &UnknownFieldError{Field: field}
+	}
+	return err
+}
+
+func decodeJSON(dec *json.Decoder, v any) error {
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("json: body must contain a single JSON value")
+	}
+	return nil
+}
+
+// XML 以 encoding/xml 編碼，媒體類型 application/xml，也接受 text/xml。
+// 根元素的名稱來自 XMLName 欄位或型別名稱；slice 沒有根元素，會以 <list> 包起來。
+type XML struct{}
+
+// MediaTypes 實作 Codec。
+func (XML) MediaTypes() []string { return []string{"application/xml", "text/xml"} }
+
+// Encode 實作 Codec。
+func (XML) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return enc.Encode(v)
+	}
+	// XML 文件只能有一個根元素，slice 的每個元素以 <list> 包起來。
+	list := xml.StartElement{Name: xml.Name{Local: "list"}}
+	if err := enc.EncodeToken(list); err != nil {
+		return err
+	}
+	for i := range rv.Len() {
+		if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	if err := enc.EncodeToken(list.End()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// Decode 實作 Codec。
+func (XML) Decode(r io.Reader, v any) error { return xml.NewDecoder(r).Decode(v) }
+
+// DecodeStrict 實作 StrictDecoder。encoding/xml 沒有拒絕未知元素的選項，
+// 所以解碼之後再讀一次主體，依 v 的型別檢查每個子元素與屬性的名稱。
+func (XML) DecodeStrict(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return err
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return checkXML(dec, start, reflect.TypeOf(v), "")
+		}
+	}
+}
+
+// xmlFields 是 struct 中可以出現的子元素與屬性；elems 的值為 nil 時不檢查該元素的內容。
+type xmlFields struct {
+	elems            map[string]reflect.Type
+	attrs            map[string]bool
+	anyElem, anyAttr bool
+}
+
+func xmlFieldsOf(t reflect.Type) xmlFields {
+	f := xmlFields{elems: map[string]reflect.Type{}, attrs: map[string]bool{}}
+	collectXMLFields(t, &f)
+	return f
+}
+
+func collectXMLFields(t reflect.Type, f *xmlFields) {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("xml")
+		if tag == "-" || sf.Name == "XMLName" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		// "ns name" 形式的 tag 只比較 local name。
+		if i := strings.LastIndex(name, " "); i >= 0 {
+			name = name[i+1:]
+		}
+		has := func(opt string) bool { return strings.Contains(","+opts+",", ","+opt+",") }
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch {
+		case sf.Anonymous && tag == "" && ft.Kind() == reflect.Struct:
+			collectXMLFields(ft, f)
+		case !sf.IsExported(), has("chardata"), has("cdata"), has("comment"):
+		case has("innerxml"):
+			f.anyElem, f.anyAttr = true, true
+		case has("attr") && has("any"):
+			f.anyAttr = true
+		case has("attr"):
+			f.attrs[cmp.Or(name, sf.Name)] = true
+		case has("any"):
+			f.anyElem = true
+		default:
+			// "a>b" 只檢查第一層的 a，不檢查它的內容。
+			if first, _, nested := strings.Cut(name, ">"); nested {
+				f.elems[first] = nil
+				continue
+			}
+			f.elems[cmp.Or(name, sf.Name)] = sf.Type
+		}
+	}
+}
+
+// checkXML 檢查 start 元素的屬性與子元素都對應到 t 的欄位，並讀到對應的結束標籤。
+func checkXML(dec *xml.Decoder, start xml.StartElement, t reflect.Type, path string) error {
+	for t != nil && (t.Kind() == reflect.Pointer || (t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8)) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || reflect.PointerTo(t).Implements(textUnmarshalerType) ||
+		reflect.PointerTo(t).Implements(xmlUnmarshalerType) {
+		return dec.Skip()
+	}
+	f := xmlFieldsOf(t)
+	for _, a := range start.Attr {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" || f.anyAttr || f.attrs[a.Name.Local] {
+			continue
+		}
+		return &UnknownFieldError{Field: path + a.Name.Local}
+	}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			ft, ok := f.elems[tok.Name.Local]
+			if !ok && !f.anyElem {
+				return &UnknownFieldError{Field: path + tok.Name.Local}
+			}
+			if err := checkXML(dec, tok, ft, path+tok.Name.Local+"."); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+var xmlUnmarshalerType = reflect.TypeFor[xml.Unmarshaler]()
+
+// Form 是 application/x-www-form-urlencoded，HTML 表單預設的格式。
+//
+// 欄位名稱來自 form tag，沒有時使用 json tag 的名稱。支援的欄位型別是字串、布林、數字、
+// 實作 encoding.TextUnmarshaler 的型別，以及它們的指標與 slice (同名的欄位出現多次)。
+// 也可以解碼到 url.Values、map[string][]string 或 map[string]string。
+type Form struct{}
+
+// MediaTypes 實作 Codec。
+func (Form) MediaTypes() []string { return []string{"application/x-www-form-urlencoded"} }
+
+// Encode 實作 Codec。
+func (Form) Encode(w io.Writer, v any) error {
+	values, err := formValues(reflect.ValueOf(v))
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, values.Encode())
+	return err
+}
+
+// Decode 實作 Codec。
+func (Form) Decode(r io.Reader, v any) error { return decodeForm(r, v, false) }
+
+// DecodeStrict 實作 StrictDecoder；解碼到 struct 時，沒有對應欄位的名稱回傳 *UnknownFieldError。
+func (Form) DecodeStrict(r io.Reader, v any) error { return decodeForm(r, v, true) }
+
+func decodeForm(r io.Reader, v any, strict bool) error {
+	rv, err := decodeTarget(v)
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return fmt.Errorf("form: %w", err)
+	}
+
+	switch dst := v.(type) {
+	case *url.Values:
+		*dst = values
+		return nil
+	case *map[string][]string:
+		*dst = values
+		return nil
+	case *map[string]string:
+		*dst = make(map[string]string, len(values))
+		for k := range values {
+			(*dst)[k] = values.Get(k)
+		}
+		return nil
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("form: cannot decode into %s", rv.Type())
+	}
+	fields := formFields(rv.Type())
+	if strict {
+		for _, name := range slices.Sorted(maps.Keys(values)) {
+			if !slices.ContainsFunc(fields, func(f field) bool { return f.name == name }) {
+				return &UnknownFieldError{Field: name}
+			}
+		}
+	}
+	for _, f := range fields {
+		list, ok := values[f.name]
+		if !ok {
+			continue
+		}
+		fv, err := settableField(rv, f.index)
+		if err != nil {
+			return fmt.Errorf("form: %w", err)
+		}
+		if err := setFormField(fv, list); err != nil {
+			return fmt.Errorf("form: %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// formFields 與 fieldsOf 相同，但 form tag 優先於 json tag。
+func formFields(t reflect.Type) []field {
+	fields := fieldsOf(t)
+	out := make([]field, 0, len(fields))
+	for _, f := range fields {
+		sf := t.FieldByIndex(f.index)
+		if tag, ok := sf.Tag.Lookup("form"); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			if name == "-" {
+				continue
+			}
+			if name != "" {
+				f.name = name
+			}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func formValues(v reflect.Value) (url.Values, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return url.Values{}, nil
+		}
+		v = v.Elem()
+	}
+	switch x := v.Interface().(type) {
+	case url.Values:
+		return x, nil
+	case map[string][]string:
+		return x, nil
+	case map[string]string:
+		values := url.Values{}
+		for k, s := range x {
+			values.Set(k, s)
+		}
+		return values, nil
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("form: cannot encode %s", v.Type())
+	}
+	values := url.Values{}
+	for _, f := range formFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || (f.omitEmpty && fv.IsZero()) {
+			continue
+		}
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		items := []reflect.Value{fv}
+		if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+			items = items[:0]
+			for i := range fv.Len() {
+				items = append(items, fv.Index(i))
+			}
+		}
+		for _, item := range items {
+			node, err := toValue(item, 0)
+			if err != nil {
+				return nil, err
+			}
+			switch node.(type) {
+			case []any, []entry:
+				return nil, fmt.Errorf("form: %s: cannot encode %s", f.name, item.Type())
+			case nil:
+				continue
+			}
+			values.Add(f.name, fmt.Sprint(node))
+		}
+	}
+	return values, nil
+}
+
+// setFormField 把表單中同名的值寫入欄位；slice 接收所有的值，其他型別只取第一個。
+func setFormField(v reflect.Value, list []string) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, text := range list {
+			if err := setFormValue(s.Index(i), text); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+	return setFormValue(v, list[0])
+}
+
+func setFormValue(v reflect.Value, text string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return assign(text, v, false)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+	case reflect.Bool:
+		// 核取方塊勾選時常送出 "on"。
+		b, err := strconv.ParseBool(text)
+		if text == "on" {
+			b, err = true, nil
+		}
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", text)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", text, v.Type())
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", text, v.Type())
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a valid %s", text, v.Type())
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("cannot decode into %s", v.Type())
+	}
+	return nil
+}
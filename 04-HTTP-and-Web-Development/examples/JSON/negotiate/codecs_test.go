@@ -0,0 +1,101 @@
+package negotiate
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type signup struct {
+	Name     string    `json:"name"`
+	Age      int       `json:"age"`
+	Admin    bool      `json:"admin"`
+	Rating   *float64  `json:"rating,omitempty"`
+	Tags     []string  `json:"tags"`
+	Birthday time.Time `json:"birthday" form:"born"`
+	Password string    `json:"-"`
+	Note     string    `form:"-"`
+}
+
+func TestFormDecode(t *testing.T) {
+	body := "name=Alice&age=30&admin=on&rating=4.5&tags=a&tags=b&born=2000-01-02T00%3A00%3A00Z&Password=x&Note=y&other=1"
+	var got signup
+	if err := (Form{}).Decode(strings.NewReader(body), &got); err != nil {
+		t.Fatalf("Decode error = %v", err)
+	}
+	rating := 4.5
+	want := signup{
+		Name: "Alice", Age: 30, Admin: true, Rating: &rating, Tags: []string{"a", "b"},
+		Birthday: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode = %+v; 預期為 %+v", got, want)
+	}
+}
+
+func TestFormDecodeErrors(t *testing.T) {
+	tests := []struct {
+		body string
+		dst  any
+	}{
+		{"age=abc", &signup{}},
+		{"admin=maybe", &signup{}},
+		{"born=yesterday", &signup{}},
+		{"name=%zz", &signup{}},
+		{"a=1", new([]string)},
+		{"a=1", signup{}},
+	}
+	for _, tt := range tests {
+		if err := (Form{}).Decode(strings.NewReader(tt.body), tt.dst); err == nil {
+			t.Errorf("Decode(%q, %T) error = nil; 預期為錯誤", tt.body, tt.dst)
+		}
+	}
+}
+
+func TestFormMaps(t *testing.T) {
+	var values url.Values
+	if err := (Form{}).Decode(strings.NewReader("a=1&a=2&b=3"), &values); err != nil {
+		t.Fatalf("Decode(url.Values) error = %v", err)
+	}
+	if got := values["a"]; !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf(`values["a"] = %v; 預期為 [1 2]`, got)
+	}
+	var m map[string]string
+	if err := (Form{}).Decode(strings.NewReader("a=1&a=2&b=3"), &m); err != nil {
+		t.Fatalf("Decode(map[string]string) error = %v", err)
+	}
+	if want := map[string]string{"a": "1", "b": "3"}; !reflect.DeepEqual(m, want) {
+		t.Errorf("map = %v; 預期為 %v", m, want)
+	}
+}
+
+func TestFormEncode(t *testing.T) {
+	var buf bytes.Buffer
+	v := signup{Name: "Bob Lee", Age: 7, Tags: []string{"x", "y"}, Password: "secret", Note: "n",
+		Birthday: time.Date(2001, 2, 3, 0, 0, 0, 0, time.UTC)}
+	if err := (Form{}).Encode(&buf, &v); err != nil {
+		t.Fatalf("Encode error = %v", err)
+	}
+	want := "admin=false&age=7&born=2001-02-03T00%3A00%3A00Z&name=Bob+Lee&tags=x&tags=y"
+	if buf.String() != want {
+		t.Errorf("Encode = %q; 預期為 %q", buf.String(), want)
+	}
+
+	if err := (Form{}).Encode(&buf, []int{1}); err == nil {
+		t.Error("Encode([]int) error = nil; 預期為錯誤")
+	}
+}
+
+func TestXMLEncodeSlice(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (XML{}).Encode(&buf, []user{{ID: 1, Name: "A"}, {ID: 2, Name: "B"}}); err != nil {
+		t.Fatalf("Encode error = %v", err)
+	}
+	want := `<list><user><id>1</id><name>A</name></user><user><id>2</id><name>B</name></user></list>`
+	if !strings.HasSuffix(buf.String(), want) || !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Errorf("Encode = %q; 預期為 XML 宣告加上 %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,342 @@
+package negotiate
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// MsgPack 是 MessagePack (https://msgpack.org) 格式，媒體類型 application/msgpack，
+// 也接受常見的別名 application/x-msgpack 與 application/vnd.msgpack。
+//
+// 與 CBOR 不同，MessagePack 以第一個位元組的值域區分型別，小的值直接放在這個位元組中：
+//
+//	0x00-0x7f 正 fixint   0x80-0x8f fixmap    0x90-0x9f fixarray  0xa0-0xbf fixstr
+//	0xc0 nil  0xc2 false  0xc3 true           0xe0-0xff 負 fixint
+//
+// 其餘的型別在第一個位元組後面接著 1、2、4 或 8 位元組的長度或值。擴充型別 (ext) 不支援。
+type MsgPack struct{}
+
+// MediaTypes 實作 Codec。
+func (MsgPack) MediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+}
+
+// Encode 實作 Codec。
+func (MsgPack) Encode(w io.Writer, v any) error {
+	data, err := MarshalMsgPack(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Decode 實作 Codec。
+func (MsgPack) Decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return UnmarshalMsgPack(data, v)
+}
+
+// DecodeStrict 實作 StrictDecoder。
+func (MsgPack) DecodeStrict(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return unmarshalMsgPack(data, v, true)
+}
+
+// MarshalMsgPack 回傳 v 的 MessagePack 編碼。
+func MarshalMsgPack(v any) ([]byte, error) {
+	node, err := toValue(reflect.ValueOf(v), 0)
+	if err != nil {
+		return nil, err
+	}
+	return appendMsgPack(nil, node), nil
+}
+
+// UnmarshalMsgPack 把剛好一個 MessagePack 物件解碼到 v 指向的變數。
+func UnmarshalMsgPack(data []byte, v any) error {
+	return unmarshalMsgPack(data, v, false)
+}
+
+func unmarshalMsgPack(data []byte, v any, strict bool) error {
+	rv, err := decodeTarget(v)
+	if err != nil {
+		return err
+	}
+	d := msgpackDecoder{data: data}
+	node, err := d.object(0)
+	if err != nil {
+		return err
+	}
+	if d.off != len(data) {
+		return fmt.Errorf("msgpack: %d trailing bytes after the first object", len(data)-d.off)
+	}
+	return assign(node, rv, strict)
+}
+
+const (
+	mpNil     = 0xc0
+	mpFalse   = 0xc2
+	mpTrue    = 0xc3
+	mpBin8    = 0xc4
+	mpBin16   = 0xc5
+	mpBin32   = 0xc6
+	mpFloat32 = 0xca
+	mpFloat64 = 0xcb
+	mpUint8   = 0xcc
+	mpUint16  = 0xcd
+	mpUint32  = 0xce
+	mpUint64  = 0xcf
+	mpInt8    = 0xd0
+	mpInt16   = 0xd1
+	mpInt32   = 0xd2
+	mpInt64   = 0xd3
+	mpStr8    = 0xd9
+	mpStr16   = 0xda
+	mpStr32   = 0xdb
+	mpArray16 = 0xdc
+	mpArray32 = 0xdd
+	mpMap16   = 0xde
+	mpMap32   = 0xdf
+
+	mpFixMap   = 0x80
+	mpFixArray = 0x90
+	mpFixStr   = 0xa0
+)
+
+// appendLength 附加長度 n：fix 為 fix 形式的前綴 (n < fixMax 時使用)，其餘依序是 8、16、32 位元的前綴；
+// 前綴為 0 表示該型別沒有這個大小。
+func appendLength(b []byte, n int, fix byte, fixMax int, p8, p16, p32 byte) []byte {
+	switch {
+	case fix != 0 && n < fixMax:
+		return append(b, fix|byte(n))
+	case p8 != 0 && n <= math.MaxUint8:
+		return append(b, p8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, p16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, p32), uint32(n))
+	}
+}
+
+func appendMsgPack(b []byte, node any) []byte {
+	switch x := node.(type) {
+	case nil:
+		return append(b, mpNil)
+	case bool:
+		if x {
+			return append(b, mpTrue)
+		}
+		return append(b, mpFalse)
+	case uint64:
+		switch {
+		case x <= 0x7f:
+			return append(b, byte(x))
+		case x <= math.MaxUint8:
+			return append(b, mpUint8, byte(x))
+		case x <= math.MaxUint16:
+			return binary.BigEndian.AppendUint16(append(b, mpUint16), uint16(x))
+		case x <= math.MaxUint32:
+			return binary.BigEndian.AppendUint32(append(b, mpUint32), uint32(x))
+		default:
+			return binary.BigEndian.AppendUint64(append(b, mpUint64), x)
+		}
+	case int64:
+		switch {
+		case x >= -32:
+			return append(b, byte(x))
+		case x >= math.MinInt8:
+			return append(b, mpInt8, byte(x))
+		case x >= math.MinInt16:
+			return binary.BigEndian.AppendUint16(append(b, mpInt16), uint16(x))
+		case x >= math.MinInt32:
+			return binary.BigEndian.AppendUint32(append(b, mpInt32), uint32(x))
+		default:
+			return binary.BigEndian.AppendUint64(append(b, mpInt64), uint64(x))
+		}
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, mpFloat64), math.Float64bits(x))
+	case string:
+		return append(appendLength(b, len(x), mpFixStr, 32, mpStr8, mpStr16, mpStr32), x...)
+	case []byte:
+		return append(appendLength(b, len(x), 0, 0, mpBin8, mpBin16, mpBin32), x...)
+	case []any:
+		b = appendLength(b, len(x), mpFixArray, 16, 0, mpArray16, mpArray32)
+		for _, item := range x {
+			b = appendMsgPack(b, item)
+		}
+		return b
+	case []entry:
+		b = appendLength(b, len(x), mpFixMap, 16, 0, mpMap16, mpMap32)
+		for _, e := range x {
+			b = appendMsgPack(appendMsgPack(b, e.key), e.value)
+		}
+		return b
+	}
+	panic(fmt.Sprintf("msgpack: unexpected value %T", node))
+}
+
+var errMsgPackTruncated = errors.New("msgpack: unexpected end of data")
+
+type msgpackDecoder struct {
+	data []byte
+	off  int
+}
+
+// next 讀取接下來的 n 個位元組。
+func (d *msgpackDecoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errMsgPackTruncated
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+// uint 讀取 size 位元組的大端序無號整數。
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	p, err := d.next(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(p[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(p)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(p)), nil
+	default:
+		return binary.BigEndian.Uint64(p), nil
+	}
+}
+
+func (d *msgpackDecoder) object(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("msgpack: data nested deeper than %d", maxDepth)
+	}
+	p, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	b := p[0]
+	switch {
+	case b <= 0x7f:
+		return uint64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == mpFixMap:
+		return d.mapOf(uint64(b&0x0f), depth)
+	case b&0xf0 == mpFixArray:
+		return d.arrayOf(uint64(b&0x0f), depth)
+	case b&0xe0 == mpFixStr:
+		s, err := d.next(uint64(b & 0x1f))
+		return string(s), err
+	}
+
+	// 其餘的型別每一組依序是 1、2、4 (、8) 位元組的變化，sizeOf 由第一個位元組與該組的起點算出大小。
+	sizeOf := func(first byte) int { return 1 << (b - first) }
+	switch b {
+	case mpNil:
+		return nil, nil
+	case mpFalse:
+		return false, nil
+	case mpTrue:
+		return true, nil
+	case mpUint8, mpUint16, mpUint32, mpUint64:
+		return d.uint(sizeOf(mpUint8))
+	case mpInt8, mpInt16, mpInt32, mpInt64:
+		size := sizeOf(mpInt8)
+		n, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		// 以 size 位元組做符號延伸。
+		shift := 64 - 8*size
+		v := int64(n<<shift) >> shift
+		if v >= 0 {
+			return uint64(v), nil
+		}
+		return v, nil
+	case mpFloat32:
+		n, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case mpFloat64:
+		n, err := d.uint(8)
+		return math.Float64frombits(n), err
+	case mpStr8, mpStr16, mpStr32, mpBin8, mpBin16, mpBin32:
+		first := byte(mpStr8)
+		if b <= mpBin32 {
+			first = mpBin8
+		}
+		n, err := d.uint(sizeOf(first))
+		if err != nil {
+			return nil, err
+		}
+		s, err := d.next(n)
+		if err != nil {
+			return nil, err
+		}
+		if first == mpStr8 {
+			return string(s), nil
+		}
+		return s, nil
+	case mpArray16, mpArray32:
+		n, err := d.uint(sizeOf(mpArray16) * 2)
+		if err != nil {
+			return nil, err
+		}
+		return d.arrayOf(n, depth)
+	case mpMap16, mpMap32:
+		n, err := d.uint(sizeOf(mpMap16) * 2)
+		if err != nil {
+			return nil, err
+		}
+		return d.mapOf(n, depth)
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type 0x%02x at offset %d", b, d.off-1)
+}
+
+// arrayOf 讀取 n 個物件。每個物件至少 1 位元組，n 超過剩餘的資料時一定是截斷的輸入。
+func (d *msgpackDecoder) arrayOf(n uint64, depth int) (any, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errMsgPackTruncated
+	}
+	list := make([]any, n)
+	for i := range list {
+		item, err := d.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = item
+	}
+	return list, nil
+}
+
+func (d *msgpackDecoder) mapOf(n uint64, depth int) (any, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, errMsgPackTruncated
+	}
+	entries := make([]entry, n)
+	for i := range entries {
+		key, err := d.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry{key, value}
+	}
+	return entries, nil
+}
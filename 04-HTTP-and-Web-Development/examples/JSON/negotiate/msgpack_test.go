@@ -0,0 +1,134 @@
+package negotiate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalMsgPack(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{0, "00"},
+		{127, "7f"},
+		{128, "cc80"},
+		{256, "cd0100"},
+		{65536, "ce00010000"},
+		{uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{-1, "ff"},
+		{-32, "e0"},
+		{-33, "d0df"},
+		{-129, "d1ff7f"},
+		{-32769, "d2ffff7fff"},
+		{int64(math.MinInt64), "d38000000000000000"},
+		{1.5, "cb3ff8000000000000"},
+		{nil, "c0"},
+		{false, "c2"},
+		{true, "c3"},
+		{"", "a0"},
+		{"abc", "a3616263"},
+		{strings.Repeat("a", 32), "d920" + strings.Repeat("61", 32)},
+		{[]byte{1, 2}, "c4020102"},
+		{[]int{1, 2, 3}, "93010203"},
+		{make([]int, 16), "dc0010" + strings.Repeat("00", 16)},
+		// msgpack.org 首頁的範例。
+		{struct {
+			Compact bool `json:"compact"`
+			Schema  int  `json:"schema"`
+		}{true, 0}, "82a7636f6d70616374c3a6736368656d6100"},
+	}
+	for _, tt := range tests {
+		got, err := MarshalMsgPack(tt.v)
+		if err != nil {
+			t.Errorf("MarshalMsgPack(%v) error = %v", tt.v, err)
+			continue
+		}
+		if hex.EncodeToString(got) != tt.want {
+			t.Errorf("MarshalMsgPack(%v) = %x; 預期為 %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalMsgPackAny(t *testing.T) {
+	tests := []struct {
+		data string
+		want any
+	}{
+		{"05", uint64(5)},
+		{"e0", int64(-32)},
+		{"d0df", int64(-33)},
+		{"d001", uint64(1)},
+		{"d1ff7f", int64(-129)},
+		{"d3ffffffffffffffff", int64(-1)},
+		{"ca3fc00000", 1.5},
+		{"c0", nil},
+		{"a3616263", "abc"},
+		{"da0003616263", "abc"},
+		{"c5000101", []byte{1}},
+		{"92c3c2", []any{true, false}},
+		{"de0001a16101", map[string]any{"a": uint64(1)}},
+		{"81c3a178", map[any]any{true: "x"}},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.data)
+		var got any
+		if err := UnmarshalMsgPack(data, &got); err != nil {
+			t.Errorf("UnmarshalMsgPack(%s) error = %v", tt.data, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("UnmarshalMsgPack(%s) = %#v; 預期為 %#v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalMsgPackErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		dst  any
+	}{
+		{"空的輸入", "", new(any)},
+		{"截斷的整數", "cd01", new(any)},
+		{"截斷的字串", "a361", new(any)},
+		{"長度超過資料", "ddffffffff", new(any)},
+		{"不支援的 ext", "d40100", new(any)},
+		{"未使用的位元組", "c1", new(any)},
+		{"多餘的資料", "0000", new(any)},
+		{"型別不符", "c3", new(string)},
+		{"整數溢位", "cd0100", new(uint8)},
+		{"陣列作為 map 的鍵", "81920102c0", new(map[any]any)},
+		{"嵌入未匯出型別的 nil 指標", "81a17801", new(embedsHidden)},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.data)
+		if err := UnmarshalMsgPack(data, tt.dst); err == nil {
+			t.Errorf("%s: UnmarshalMsgPack(%s) error = nil; 預期為錯誤", tt.name, tt.data)
+		}
+	}
+
+	deep := bytes.Repeat([]byte{0x91}, maxDepth+10)
+	if err := UnmarshalMsgPack(append(deep, 0x00), new(any)); err == nil {
+		t.Error("UnmarshalMsgPack(深度巢狀) error = nil; 預期為錯誤")
+	}
+}
+
+func TestMsgPackRoundTrip(t *testing.T) {
+	want := sampleAccount()
+	data, err := MarshalMsgPack(want)
+	if err != nil {
+		t.Fatalf("MarshalMsgPack error = %v", err)
+	}
+	var got account
+	if err := UnmarshalMsgPack(data, &got); err != nil {
+		t.Fatalf("UnmarshalMsgPack error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalMsgPack(MarshalMsgPack(v)) = %+v; 預期為 %+v", got, want)
+	}
+}
@@ -0,0 +1,303 @@
+// Package negotiate 實作 HTTP 內容協商 (content negotiation)：
+// 同一個 handler 依請求的 Accept 標頭選擇回應的格式，依 Content-Type 標頭選擇解碼請求主體的方式。
+//
+//	func getUser(w http.ResponseWriter, r *http.Request) {
+//		negotiate.Render(w, r, http.StatusOK, user) // JSON、XML、CBOR 或 MessagePack
+//	}
+//
+//	func createUser(w http.ResponseWriter, r *http.Request) {
+//		var u User
+//		if err := negotiate.Bind(r, &u); err != nil {
+//			negotiate.RenderError(w, r, err) // 415、413 或 400
+//			return
+//		}
+//	}
+//
+// Accept 的每個媒體範圍 (media range) 可以帶有 q 值，例如
+// "application/xml;q=0.9, */*;q=0.1"。每個格式的品質取最具體的符合範圍的 q 值，
+// 選出品質最高的格式；同分時依 Negotiator 註冊的順序。沒有 Accept 標頭時使用第一個格式，
+// 所有格式的品質都是 0 時 Render 回應 406 Not Acceptable。
+// Bind 遇到沒有註冊的 Content-Type 時回傳 ErrUnsupportedMediaType (415)；
+// 沒有 Content-Type 時視為第一個格式。
+//
+// CBOR 與 MessagePack 以 json tag 決定欄位名稱，與 encoding/json 的行為一致。
+// 解碼時預設略過不認識的欄位；Strict (或 DisallowUnknownFields 為 true 的 Negotiator)
+// 則以 *UnknownFieldError 拒絕它們，讓每一種格式都和 validate.Decode 處理 JSON 時一樣嚴格。
+package negotiate
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotAcceptable 表示沒有任何格式符合請求的 Accept 標頭。
+	ErrNotAcceptable = errors.New("negotiate: not acceptable")
+	// ErrUnsupportedMediaType 表示請求主體的 Content-Type 沒有對應的格式。
+	ErrUnsupportedMediaType = errors.New("negotiate: unsupported media type")
+)
+
+// DefaultMaxBytes 是 Bind 預設允許的請求主體大小。
+const DefaultMaxBytes = 1 << 20
+
+// Codec 是一種資料格式。
+type Codec interface {
+	// MediaTypes 回傳這個格式的媒體類型，第一個用於回應的 Content-Type，其餘為別名。
+	MediaTypes() []string
+	// Encode 把 v 編碼後寫入 w。
+	Encode(w io.Writer, v any) error
+	// Decode 從 r 解碼一個值到 v 指向的變數。
+	Decode(r io.Reader, v any) error
+}
+
+// StrictDecoder 是可以拒絕未知欄位的 Codec。內建的格式都實作了它。
+type StrictDecoder interface {
+	// DecodeStrict 與 Decode 相同，但遇到目標中沒有的欄位時回傳 *UnknownFieldError。
+	DecodeStrict(r io.Reader, v any) error
+}
+
+// UnknownFieldError 表示請求主體中有目標 struct 沒有的欄位。
+// Field 是欄位的名稱，巢狀的欄位以 "." 連接外層的名稱。
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return "negotiate: unknown field " + strconv.Quote(e.Field)
+}
+
+// Negotiator 在註冊的格式之間進行協商。
+type Negotiator struct {
+	codecs []Codec
+	// MaxBytes 是 Bind 允許的請求主體大小，<= 0 時使用 DefaultMaxBytes。
+	MaxBytes int64
+	// DisallowUnknownFields 為 true 時，Bind 以 StrictDecoder 解碼並拒絕未知的欄位；
+	// 沒有實作 StrictDecoder 的格式一律視為不支援 (415)，而不是默默接受未知的欄位。
+	DisallowUnknownFields bool
+}
+
+// New 建立一個 Negotiator；codecs 的順序就是同分時的偏好順序，第一個也是預設格式。
+func New(codecs ...Codec) *Negotiator {
+	if len(codecs) == 0 {
+		panic("negotiate: New needs at least one codec")
+	}
+	return &Negotiator{codecs: codecs}
+}
+
+// Default 支援 JSON (預設)、XML、CBOR、MessagePack 與 form-urlencoded。
+var Default = New(JSON{}, XML{}, CBOR{}, MsgPack{}, Form{})
+
+// Strict 與 Default 支援相同的格式，但 Bind 會拒絕未知的欄位。
+var Strict = &Negotiator{codecs: Default.codecs, DisallowUnknownFields: true}
+
+// MediaTypes 回傳所有格式的主要媒體類型。
+func (n *Negotiator) MediaTypes() []string {
+	types := make([]string, len(n.codecs))
+	for i, c := range n.codecs {
+		types[i] = c.MediaTypes()[0]
+	}
+	return types
+}
+
+// Select 依 r 的 Accept 標頭選出回應的格式，沒有符合的格式時回傳 ErrNotAcceptable。
+func (n *Negotiator) Select(r *http.Request) (Codec, error) {
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return n.codecs[0], nil
+	}
+	ranges := parseAccept(strings.Join(accept, ","))
+	var best Codec
+	bestQ := 0.0
+	for _, c := range n.codecs {
+		if q := quality(ranges, c.MediaTypes()); q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("%w: %q (available: %s)", ErrNotAcceptable,
+			strings.Join(accept, ","), strings.Join(n.MediaTypes(), ", "))
+	}
+	return best, nil
+}
+
+// Render 以協商出的格式回應 status 與 v，並設定 Content-Type 與 Vary 標頭。
+// v 會先編碼到緩衝區，編碼失敗時回應 500 並回傳錯誤；沒有可接受的格式時回應 406 並回傳 ErrNotAcceptable。
+func (n *Negotiator) Render(w http.ResponseWriter, r *http.Request, status int, v any) error {
+	w.Header().Add("Vary", "Accept")
+	c, err := n.Select(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotAcceptable)
+		return err
+	}
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, v); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return fmt.Errorf("negotiate: encode %s: %w", c.MediaTypes()[0], err)
+	}
+	w.Header().Set("Content-Type", c.MediaTypes()[0])
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(status)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// Bind 依 r 的 Content-Type 選擇格式，把請求主體解碼到 v 指向的變數。
+// 可能的錯誤 (及 StatusCode 對應的狀態碼)：ErrUnsupportedMediaType (415)、
+// *http.MaxBytesError (413)、*UnknownFieldError (422)，以及格式本身的解碼錯誤 (400)。
+func (n *Negotiator) Bind(r *http.Request, v any) error {
+	c, err := n.codecFor(r.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+	limit := n.MaxBytes
+	if limit <= 0 {
+		limit = DefaultMaxBytes
+	}
+	// MaxBytesReader 的 ResponseWriter 只用來在超過上限時通知伺服器關閉連線，可以是 nil。
+	body := http.MaxBytesReader(nil, r.Body, limit)
+	if n.DisallowUnknownFields {
+		sd, ok := c.(StrictDecoder)
+		if !ok {
+			return fmt.Errorf("%w: %s cannot reject unknown fields", ErrUnsupportedMediaType, c.MediaTypes()[0])
+		}
+		return sd.DecodeStrict(body, v)
+	}
+	return c.Decode(body, v)
+}
+
+func (n *Negotiator) codecFor(contentType string) (Codec, error) {
+	if contentType == "" {
+		return n.codecs[0], nil
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedMediaType, contentType)
+	}
+	for _, c := range n.codecs {
+		if slices.Contains(c.MediaTypes(), mt) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s (supported: %s)", ErrUnsupportedMediaType, mt, strings.Join(n.MediaTypes(), ", "))
+}
+
+// errorBody 是 RenderError 的回應格式。
+type errorBody struct {
+	XMLName xml.Name `json:"-" xml:"error" form:"-"`
+	Error   string   `json:"error" xml:"message"`
+}
+
+// RenderError 以協商出的格式回應 {"error": err.Error()}，狀態碼由 StatusCode 決定。
+func (n *Negotiator) RenderError(w http.ResponseWriter, r *http.Request, err error) error {
+	return n.Render(w, r, StatusCode(err), errorBody{Error: err.Error()})
+}
+
+// Render 以 Default 回應，見 Negotiator.Render。
+func Render(w http.ResponseWriter, r *http.Request, status int, v any) error {
+	return Default.Render(w, r, status, v)
+}
+
+// Bind 以 Default 解碼請求主體，見 Negotiator.Bind。
+func Bind(r *http.Request, v any) error {
+	return Default.Bind(r, v)
+}
+
+// RenderError 以 Default 回應錯誤，見 Negotiator.RenderError。
+func RenderError(w http.ResponseWriter, r *http.Request, err error) error {
+	return Default.RenderError(w, r, err)
+}
+
+// StatusCode 回傳 Bind 或 Select 的錯誤對應的 HTTP 狀態碼。
+func StatusCode(err error) int {
+	var (
+		maxErr     *http.MaxBytesError
+		unknownErr *UnknownFieldError
+	)
+	switch {
+	case errors.Is(err, ErrNotAcceptable):
+		return http.StatusNotAcceptable
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.As(err, &maxErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &unknownErr):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// MediaType 回傳 r 的 Content-Type 中的媒體類型 (不含參數、小寫)；沒有或無法解析時回傳空字串。
+func MediaType(r *http.Request) string {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return mt
+}
+
+// mediaRange 是 Accept 標頭中的一個項目，例如 "text/*;q=0.5"。
+type mediaRange struct {
+	typ, sub string
+	q        float64
+}
+
+// parseAccept 解析 Accept 標頭，略過無法解析的項目。
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		mt, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		typ, sub, ok := strings.Cut(mt, "/")
+		if !ok || (typ == "*" && sub != "*") {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		ranges = append(ranges, mediaRange{typ, sub, q})
+	}
+	return ranges
+}
+
+// quality 回傳 types 中任一個媒體類型的最高品質：每個類型取最具體的符合範圍的 q 值。
+func quality(ranges []mediaRange, types []string) float64 {
+	best := 0.0
+	for _, t := range types {
+		typ, sub, _ := strings.Cut(t, "/")
+		specificity, q := 0, 0.0
+		for _, mr := range ranges {
+			s := 0
+			switch {
+			case mr.typ == typ && mr.sub == sub:
+				s = 3
+			case mr.typ == typ && mr.sub == "*":
+				s = 2
+			case mr.typ == "*":
+				s = 1
+			}
+			if s > specificity || (s == specificity && s > 0 && mr.q > q) {
+				specificity, q = s, mr.q
+			}
+		}
+		best = max(best, q)
+	}
+	return best
+}
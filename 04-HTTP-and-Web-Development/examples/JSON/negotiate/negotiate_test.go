@@ -0,0 +1,289 @@
+package negotiate
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type user struct {
+	ID    int    `json:"id" xml:"id"`
+	Name  string `json:"name" xml:"name"`
+	Email string `json:"email,omitempty" xml:"email,omitempty"`
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string // 空字串表示 406
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/xml", "application/xml"},
+		{"text/xml", "application/xml"},
+		{"application/x-msgpack", "application/msgpack"},
+		{"application/cbor, application/json", "application/json"},
+		{"application/json;q=0.5, application/cbor", "application/cbor"},
+		{"application/*;q=0.2, application/xml;q=0.9", "application/xml"},
+		// 最具體的範圍決定品質：application/json 被明確排除，其餘的 application/* 都可以。
+		{"application/json;q=0, application/*", "application/xml"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "application/xml"},
+		{"APPLICATION/CBOR", "application/cbor"},
+		{"text/html", ""},
+		{"application/json;q=0", ""},
+		// 無法解析的項目被略過。
+		{"application/json;q=abc, application/cbor;q=2, application/xml;q=0.1", "application/xml"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		c, err := Default.Select(r)
+		if tt.want == "" {
+			if !errors.Is(err, ErrNotAcceptable) {
+				t.Errorf("Select(%q) error = %v; 預期為 ErrNotAcceptable", tt.accept, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Select(%q) error = %v", tt.accept, err)
+			continue
+		}
+		if got := c.MediaTypes()[0]; got != tt.want {
+			t.Errorf("Select(%q) = %s; 預期為 %s", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestRenderBindRoundTrip(t *testing.T) {
+	want := user{ID: 7, Name: "Alice", Email: "alice@example.com"}
+	for _, mt := range Default.MediaTypes() {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", mt)
+		w := httptest.NewRecorder()
+		if err := Render(w, r, http.StatusCreated, want); err != nil {
+			t.Fatalf("%s: Render error = %v", mt, err)
+		}
+		if w.Code != http.StatusCreated {
+			t.Errorf("%s: status = %d; 預期為 %d", mt, w.Code, http.StatusCreated)
+		}
+		if got := w.Header().Get("Content-Type"); got != mt {
+			t.Errorf("%s: Content-Type = %q; 預期為 %q", mt, got, mt)
+		}
+		if got := w.Header().Get("Vary"); got != "Accept" {
+			t.Errorf("%s: Vary = %q; 預期為 Accept", mt, got)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(w.Body.Bytes()))
+		req.Header.Set("Content-Type", mt+"; charset=utf-8")
+		var got user
+		if err := Bind(req, &got); err != nil {
+			t.Fatalf("%s: Bind error = %v (body %q)", mt, err, w.Body.String())
+		}
+		if got != want {
+			t.Errorf("%s: Bind = %+v; 預期為 %+v", mt, got, want)
+		}
+	}
+}
+
+func TestRenderNotAcceptable(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "image/png")
+	w := httptest.NewRecorder()
+	err := Render(w, r, http.StatusOK, user{})
+	if !errors.Is(err, ErrNotAcceptable) {
+		t.Errorf("Render error = %v; 預期為 ErrNotAcceptable", err)
+	}
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("status = %d; 預期為 %d", w.Code, http.StatusNotAcceptable)
+	}
+	if !strings.Contains(w.Body.String(), "application/json") {
+		t.Errorf("body = %q; 預期列出可用的格式", w.Body.String())
+	}
+}
+
+func TestRenderEncodeError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/cbor")
+	w := httptest.NewRecorder()
+	if err := Render(w, r, http.StatusOK, make(chan int)); err == nil {
+		t.Error("Render(chan) error = nil; 預期為錯誤")
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d; 預期為 %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	n := New(JSON{}, CBOR{})
+	n.MaxBytes = 16
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"沒有 Content-Type 時使用 JSON", "", `{"id":1}`, 0},
+		{"不支援的格式", "text/plain", "hello", http.StatusUnsupportedMediaType},
+		{"無法解析的 Content-Type", "application/", "{}", http.StatusUnsupportedMediaType},
+		{"沒有註冊的格式", "application/xml", "<user/>", http.StatusUnsupportedMediaType},
+		{"主體太大", "application/json", `{"name":"` + strings.Repeat("a", 32) + `"}`, http.StatusRequestEntityTooLarge},
+		{"不合法的 JSON", "application/json", `{"id":`, http.StatusBadRequest},
+		{"多個 JSON 值", "application/json", `{} {}`, http.StatusBadRequest},
+		{"不合法的 CBOR", "application/cbor", "\xa1", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			r.Header.Set("Content-Type", tt.contentType)
+		}
+		var u user
+		err := n.Bind(r, &u)
+		if tt.status == 0 {
+			if err != nil {
+				t.Errorf("%s: Bind error = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: Bind error = nil; 預期為 %d", tt.name, tt.status)
+			continue
+		}
+		if got := StatusCode(err); got != tt.status {
+			t.Errorf("%s: StatusCode(%v) = %d; 預期為 %d", tt.name, err, got, tt.status)
+		}
+	}
+}
+
+type order struct {
+	ID    int    `json:"id" xml:"id,attr"`
+	Buyer user   `json:"buyer" xml:"buyer"`
+	Items []user `json:"items" xml:"item"`
+}
+
+func TestDecodeStrict(t *testing.T) {
+	mustCBOR := func(v any) string { b, _ := MarshalCBOR(v); return string(b) }
+	mustMsgPack := func(v any) string { b, _ := MarshalMsgPack(v); return string(b) }
+	buyer := map[string]any{"id": 1, "name": "A"}
+	tests := []struct {
+		name  string
+		codec Codec
+		body  string
+		want  string // 未知的欄位；空字串表示沒有錯誤
+	}{
+		{"JSON", JSON{}, `{"id":1,"buyer":{"name":"A"},"items":[{"id":2}]}`, ""},
+		{"JSON 未知的欄位", JSON{}, `{"id":1,"admin":true}`, "admin"},
+		{"JSON 巢狀的未知欄位", JSON{}, `{"buyer":{"admin":true}}`, "admin"},
+		{"XML", XML{}, `<order id="1"><buyer><name>A</name></buyer><item><id>2</id></item></order>`, ""},
+		{"XML 命名空間", XML{}, `<order xmlns="urn:x" xmlns:a="urn:a" id="1"/>`, ""},
+		{"XML 未知的元素", XML{}, `<order><admin>true</admin></order>`, "admin"},
+		{"XML 未知的屬性", XML{}, `<order admin="true"/>`, "admin"},
+		{"XML 巢狀的未知元素", XML{}, `<order><item><id>2</id><admin/></item></order>`, "item.admin"},
+		{"CBOR", CBOR{}, mustCBOR(map[string]any{"id": 1, "buyer": buyer}), ""},
+		{"CBOR 未知的欄位", CBOR{}, mustCBOR(map[string]any{"id": 1, "admin": true}), "admin"},
+		{"CBOR 巢狀的未知欄位", CBOR{}, mustCBOR(map[string]any{"buyer": map[string]any{"admin": true}}), "buyer.admin"},
+		{"CBOR 非字串的鍵", CBOR{}, mustCBOR(map[any]any{uint64(7): true}), "7"},
+		{"MessagePack", MsgPack{}, mustMsgPack(map[string]any{"id": 1, "buyer": buyer}), ""},
+		{"MessagePack 未知的欄位", MsgPack{}, mustMsgPack(map[string]any{"admin": true}), "admin"},
+		{"MessagePack 巢狀的未知欄位", MsgPack{}, mustMsgPack(map[string]any{"items": []any{map[string]any{"admin": true}}}), "items.admin"},
+	}
+	for _, tt := range tests {
+		var o order
+		err := tt.codec.(StrictDecoder).DecodeStrict(strings.NewReader(tt.body), &o)
+		checkUnknownField(t, tt.name, err, tt.want)
+		// 沒有 strict 時略過未知的欄位。
+		if err := tt.codec.Decode(strings.NewReader(tt.body), new(order)); err != nil {
+			t.Errorf("%s: Decode error = %v", tt.name, err)
+		}
+	}
+
+	formTests := []struct{ body, want string }{
+		{"id=1&name=A&email=a%40example.com", ""},
+		{"name=A&admin=true&zzz=1", "admin"},
+	}
+	for _, tt := range formTests {
+		err := (Form{}).DecodeStrict(strings.NewReader(tt.body), new(user))
+		checkUnknownField(t, "Form "+tt.body, err, tt.want)
+	}
+	// 解碼到 map 時沒有未知的欄位。
+	if err := (Form{}).DecodeStrict(strings.NewReader("admin=true"), new(url.Values)); err != nil {
+		t.Errorf("Form: DecodeStrict(url.Values) error = %v", err)
+	}
+}
+
+func checkUnknownField(t *testing.T, name string, err error, want string) {
+	t.Helper()
+	if want == "" {
+		if err != nil {
+			t.Errorf("%s: DecodeStrict error = %v", name, err)
+		}
+		return
+	}
+	var unknown *UnknownFieldError
+	if !errors.As(err, &unknown) || unknown.Field != want {
+		t.Errorf("%s: DecodeStrict error = %v; 預期為未知的欄位 %q", name, err, want)
+	}
+}
+
+func TestBindStrict(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=A&admin=true"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	err := Strict.Bind(r, new(user))
+	if got := StatusCode(err); got != http.StatusUnprocessableEntity {
+		t.Errorf("Strict.Bind error = %v, StatusCode = %d; 預期為 %d", err, got, http.StatusUnprocessableEntity)
+	}
+
+	// 沒有實作 StrictDecoder 的格式不能默默接受未知的欄位。
+	n := New(looseCodec{})
+	n.DisallowUnknownFields = true
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	if err := n.Bind(r, new(user)); !errors.Is(err, ErrUnsupportedMediaType) {
+		t.Errorf("Bind(沒有 StrictDecoder) error = %v; 預期為 ErrUnsupportedMediaType", err)
+	}
+}
+
+// looseCodec 只實作 Codec，沒有實作 StrictDecoder。
+type looseCodec struct{}
+
+func (looseCodec) MediaTypes() []string            { return []string{"application/json"} }
+func (looseCodec) Encode(w io.Writer, v any) error { return JSON{}.Encode(w, v) }
+func (looseCodec) Decode(r io.Reader, v any) error { return JSON{}.Decode(r, v) }
+
+func TestRenderError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	RenderError(w, r, ErrUnsupportedMediaType)
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("status = %d; 預期為 %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+	want := "<error><message>negotiate: unsupported media type</message></error>"
+	if !strings.Contains(w.Body.String(), want) {
+		t.Errorf("body = %q; 預期包含 %q", w.Body.String(), want)
+	}
+}
+
+func TestMediaType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{"", ""},
+		{"application/JSON; charset=UTF-8", "application/json"},
+		{"text/plain", "text/plain"},
+		{"application/", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Content-Type", tt.contentType)
+		if got := MediaType(r); got != tt.want {
+			t.Errorf("MediaType(%q) = %q; 預期為 %q", tt.contentType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,464 @@
+package negotiate
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CBOR 與 MessagePack 都是「自我描述」的二進位格式，資料模型也幾乎相同：
+// null、布林、整數、浮點數、字串、位元組、陣列與 map。
+// 所以兩者共用同一層反射：Go 的值先轉成下列的中間值，再由各自的 writer 編碼；
+// 解碼時 reader 產生中間值，再由 assign 寫入 Go 的變數。
+//
+//	nil、bool、int64、uint64、float64、string、[]byte、[]any、[]entry
+//
+// int64 只用於負數，非負整數一律是 uint64。
+
+// entry 是 map 的一個鍵值對；以 slice 保存可以維持 struct 欄位的順序。
+type entry struct {
+	key, value any
+}
+
+// maxDepth 限制巢狀的深度，避免惡意的輸入用完堆疊。
+const maxDepth = 1000
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// toValue 把 Go 的值轉成中間值。
+func toValue(v reflect.Value, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("negotiate: value nested deeper than %d", maxDepth)
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.Kind() != reflect.Pointer && v.Kind() != reflect.Interface && v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return toValue(v.Elem(), depth+1)
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := v.Int(); n < 0 {
+			return n, nil
+		}
+		return uint64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return b, nil
+		}
+		list := make([]any, v.Len())
+		for i := range list {
+			item, err := toValue(v.Index(i), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = item
+		}
+		return list, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		entries := make([]entry, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key, err := toValue(iter.Key(), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			value, err := toValue(iter.Value(), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry{key, value})
+		}
+		// map 的迭代順序是隨機的，排序後同樣的值永遠編碼成同樣的位元組。
+		slices.SortFunc(entries, func(a, b entry) int {
+			return strings.Compare(fmt.Sprint(a.key), fmt.Sprint(b.key))
+		})
+		return entries, nil
+	case reflect.Struct:
+		var entries []entry
+		for _, f := range fieldsOf(v.Type()) {
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok || (f.omitEmpty && fv.IsZero()) {
+				continue
+			}
+			value, err := toValue(fv, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry{f.name, value})
+		}
+		if entries == nil {
+			entries = []entry{}
+		}
+		return entries, nil
+	}
+	return nil, fmt.Errorf("negotiate: unsupported type %s", v.Type())
+}
+
+// fieldByIndex 與 v.FieldByIndex 相同，但經過 nil 的嵌入指標時回傳 false 而不是 panic。
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// settableField 與 v.FieldByIndex 相同，但會配置經過的 nil 嵌入指標。
+// 嵌入的指標指向未匯出的型別時無法設定，與 encoding/json 相同回傳錯誤而不是 panic。
+func settableField(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("negotiate: cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// field 是 struct 中一個要編碼的欄位。
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+// fieldsOf 依 json tag 列出 t 的欄位；嵌入的 struct 沒有 tag 名稱時，它的欄位會提升到外層。
+func fieldsOf(t reflect.Type) []field {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field)
+	}
+	fields := collectFields(t, nil)
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, index []int) []field {
+	var fields []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		idx := append(slices.Clone(index), i)
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, collectFields(ft, idx)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, field{name: name, index: idx, omitEmpty: strings.Contains(","+opts+",", ",omitempty,")})
+	}
+	return fields
+}
+
+// lookupField 依名稱找出欄位，先比對完全相同的名稱，再不分大小寫 (與 encoding/json 相同)。
+func lookupField(fields []field, name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// assign 把中間值寫入 v；v 必須是可以設定的。strict 為 true 時 struct 中沒有的鍵回傳 *UnknownFieldError。
+func assign(node any, v reflect.Value, strict bool) error {
+	if node == nil {
+		v.SetZero()
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return assign(node, v.Elem(), strict)
+	}
+	if s, ok := node.(string); ok && reflect.PointerTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	mismatch := func() error {
+		return fmt.Errorf("negotiate: cannot decode %s into %s", kindOf(node), v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return mismatch()
+		}
+		v.Set(reflect.ValueOf(plain(node)))
+	case reflect.Bool:
+		b, ok := node.(bool)
+		if !ok {
+			return mismatch()
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch x := node.(type) {
+		case int64:
+			n = x
+		case uint64:
+			if x > math.MaxInt64 {
+				return fmt.Errorf("negotiate: %d overflows %s", x, v.Type())
+			}
+			n = int64(x)
+		case float64:
+			if x != math.Trunc(x) || x < math.MinInt64 || x >= math.MaxInt64 {
+				return mismatch()
+			}
+			n = int64(x)
+		default:
+			return mismatch()
+		}
+		if v.OverflowInt(n) {
+			return fmt.Errorf("negotiate: %d overflows %s", n, v.Type())
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch x := node.(type) {
+		case uint64:
+			n = x
+		case float64:
+			if x != math.Trunc(x) || x < 0 || x >= math.MaxUint64 {
+				return mismatch()
+			}
+			n = uint64(x)
+		default:
+			return mismatch()
+		}
+		if v.OverflowUint(n) {
+			return fmt.Errorf("negotiate: %d overflows %s", n, v.Type())
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		switch x := node.(type) {
+		case float64:
+			v.SetFloat(x)
+		case int64:
+			v.SetFloat(float64(x))
+		case uint64:
+			v.SetFloat(float64(x))
+		default:
+			return mismatch()
+		}
+	case reflect.String:
+		s, ok := node.(string)
+		if !ok {
+			return mismatch()
+		}
+		v.SetString(s)
+	case reflect.Slice:
+		if b, ok := node.([]byte); ok && v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(slices.Clone(b))
+			return nil
+		}
+		list, ok := node.([]any)
+		if !ok {
+			return mismatch()
+		}
+		s := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, item := range list {
+			if err := assign(item, s.Index(i), strict); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Array:
+		list, ok := node.([]any)
+		if !ok {
+			return mismatch()
+		}
+		if len(list) != v.Len() {
+			return fmt.Errorf("negotiate: cannot decode %d items into %s", len(list), v.Type())
+		}
+		for i, item := range list {
+			if err := assign(item, v.Index(i), strict); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		entries, ok := node.([]entry)
+		if !ok {
+			return mismatch()
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(entries)))
+		}
+		for _, e := range entries {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := assign(e.key, key, strict); err != nil {
+				return err
+			}
+			// 解碼到 any 的鍵可能是陣列或 map，它們不能當作 Go 的 map 鍵。
+			if !key.Comparable() {
+				return fmt.Errorf("negotiate: cannot use %s as a key of %s", kindOf(e.key), v.Type())
+			}
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := assign(e.value, value, strict); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, value)
+		}
+	case reflect.Struct:
+		entries, ok := node.([]entry)
+		if !ok {
+			return mismatch()
+		}
+		fields := fieldsOf(v.Type())
+		for _, e := range entries {
+			name, ok := e.key.(string)
+			if !ok {
+				if strict {
+					return &UnknownFieldError{Field: fmt.Sprint(plain(e.key))}
+				}
+				continue
+			}
+			// 與 encoding/json 相同，預設略過不認識的欄位。
+			f, ok := lookupField(fields, name)
+			if !ok {
+				if strict {
+					return &UnknownFieldError{Field: name}
+				}
+				continue
+			}
+			fv, err := settableField(v, f.index)
+			if err != nil {
+				return err
+			}
+			if err := assign(e.value, fv, strict); err != nil {
+				var unknown *UnknownFieldError
+				if errors.As(err, &unknown) {
+					return &UnknownFieldError{Field: name + "." + unknown.Field}
+				}
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	default:
+		return mismatch()
+	}
+	return nil
+}
+
+// plain 把中間值轉成解碼到 any 時的結果：map 的鍵都是字串時為 map[string]any，否則為 map[any]any。
+func plain(node any) any {
+	switch x := node.(type) {
+	case []any:
+		list := make([]any, len(x))
+		for i, item := range x {
+			list[i] = plain(item)
+		}
+		return list
+	case []entry:
+		strKeys := make(map[string]any, len(x))
+		for _, e := range x {
+			key, ok := e.key.(string)
+			if !ok {
+				anyKeys := make(map[any]any, len(x))
+				for _, e := range x {
+					k := plain(e.key)
+					if k != nil && !reflect.TypeOf(k).Comparable() {
+						k = fmt.Sprint(k)
+					}
+					anyKeys[k] = plain(e.value)
+				}
+				return anyKeys
+			}
+			strKeys[key] = plain(e.value)
+		}
+		return strKeys
+	}
+	return node
+}
+
+// kindOf 回傳中間值在錯誤訊息中的名稱。
+func kindOf(node any) string {
+	switch node.(type) {
+	case bool:
+		return "boolean"
+	case int64, uint64:
+		return "integer"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case []byte:
+		return "bytes"
+	case []any:
+		return "array"
+	case []entry:
+		return "map"
+	}
+	return fmt.Sprintf("%T", node)
+}
+
+// decodeTarget 檢查 Decode 的目標是非 nil 的指標。
+func decodeTarget(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return reflect.Value{}, fmt.Errorf("negotiate: decode target must be a non-nil pointer, got %T", v)
+	}
+	return rv.Elem(), nil
+}
@@ -0,0 +1,35 @@
+package users
+
+import (
+	"context"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Sync/singleflight"
+)
+
+// cachedStore 把 Get 的結果快取起來，並在寫入時讓對應的項目失效。
+type cachedStore struct {
+	UserStore
+	users *singleflight.Memo[int, User]
+}
+
+// Cached 包裝 store：同時查詢同一位使用者的請求共用一次 Get，成功的結果快取 ttl 的時間。
+// 透過回傳的 UserStore 進行的 Update 與 Delete 會讓快取失效；
+// 直接寫入底層 store 的修改則最多要等 ttl 之後才看得到。
+func Cached(store UserStore, ttl time.Duration) UserStore {
+	return &cachedStore{UserStore: store, users: singleflight.NewMemo(ttl, store.Get)}
+}
+
+func (s *cachedStore) Get(ctx context.Context, id int) (User, error) {
+	return s.users.Get(ctx, id)
+}
+
+func (s *cachedStore) Update(ctx context.Context, id int, fn func(u *User) error) (User, error) {
+	defer s.users.Invalidate(id)
+	return s.UserStore.Update(ctx, id, fn)
+}
+
+func (s *cachedStore) Delete(ctx context.Context, id int) error {
+	defer s.users.Invalidate(id)
+	return s.UserStore.Delete(ctx, id)
+}
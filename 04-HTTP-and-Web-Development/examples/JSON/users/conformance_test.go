@@ -0,0 +1,20 @@
+package users_test
+
+import (
+	"net/http"
+	"testing"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users/userstest"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/net-http/router"
+)
+
+func TestConformance(t *testing.T) {
+	userstest.Run(t, func(svc *users.Service) http.Handler {
+		r := router.New()
+		r.NotFound = http.HandlerFunc(users.NotFound)
+		r.MethodNotAllowed = http.HandlerFunc(users.MethodNotAllowed)
+		users.NewHandler(svc).Mount(r.Group(""))
+		return r
+	})
+}
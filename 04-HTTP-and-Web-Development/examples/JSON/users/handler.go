@@ -0,0 +1,173 @@
+package users
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/negotiate"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/validate"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/net-http/router"
+)
+
+// Handler 以 net/http 提供使用者的 CRUD API；業務邏輯都在 Service 中。
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler 建立使用 svc 的 Handler。
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// Mount 在 g 底下註冊 /users 與 /users/{id} 的路由。
+func (h *Handler) Mount(g *router.Group) {
+	g.GET("/users", h.list)
+	g.POST("/users", h.create)
+	g.GET("/users/{id}", h.get)
+	g.PUT("/users/{id}", h.replace)
+	g.PATCH("/users/{id}", h.patch)
+	g.DELETE("/users/{id}", h.delete)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	render(w, r, http.StatusOK, list)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	u, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	render(w, r, http.StatusOK, u)
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var u User
+	if err := bind(w, r, &u); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	u, err := h.svc.Create(r.Context(), u)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	// Location 指向新資源：請求路徑加上新的 ID，不論 Handler 掛在哪個前綴底下都正確。
+	w.Header().Set("Location", path.Join(r.URL.Path, strconv.Itoa(u.ID)))
+	render(w, r, http.StatusCreated, u)
+}
+
+func (h *Handler) replace(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var next User
+	if err := bind(w, r, &next); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	u, err := h.svc.Replace(r.Context(), id, next)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	render(w, r, http.StatusOK, u)
+}
+
+func (h *Handler) patch(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	var p Patch
+	if err := bind(w, r, &p); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	u, err := h.svc.Patch(r.Context(), id, p)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	render(w, r, http.StatusOK, u)
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseID(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bind 依 Content-Type 解碼請求主體，每一種格式都拒絕未知的欄位。
+// JSON (或沒有 Content-Type) 使用 validate.Decode；其他格式 (XML、CBOR、MessagePack、表單)
+// 由 negotiate.Strict 解碼，未知的欄位與 JSON 一樣以 422 回應，驗證則交給 Service。
+func bind(w http.ResponseWriter, r *http.Request, dst any) error {
+	if r.Header.Get("Content-Type") == "" || negotiate.MediaType(r) == "application/json" {
+		return validate.Decode(w, r, dst, 0)
+	}
+	err := negotiate.Strict.Bind(r, dst)
+	var unknown *negotiate.UnknownFieldError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &unknown):
+		return &validate.RequestError{Status: http.StatusUnprocessableEntity, Message: "validation failed", Fields: validate.Errors{{
+			Field: unknown.Field, Rule: "unknown", Message: "is not allowed",
+		}}}
+	}
+	return &validate.RequestError{Status: negotiate.StatusCode(err), Message: err.Error()}
+}
+
+// writeError 以 ErrorFor 決定的狀態碼與主體回應 err。
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status, body := ErrorFor(err)
+	render(w, r, status, body)
+}
+
+// render 以 Accept 協商出的格式 (預設 JSON) 回應 status 與 v。
+// 沒有可接受的格式時 negotiate.Render 已經回應 406，不需要記錄。
+func render(w http.ResponseWriter, r *http.Request, status int, v any) {
+	if err := negotiate.Render(w, r, status, v); err != nil && !errors.Is(err, negotiate.ErrNotAcceptable) {
+		log.Printf("users: render response: %v", err)
+	}
+}
+
+// WriteError 以 {"error": msg} 回應 (格式依 Accept 協商)。
+func WriteError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	render(w, r, status, ErrorResponse{Error: msg})
+}
+
+// NotFound 以 ErrNoRoute 的狀態碼與主體回應，用於 router.Router 的 NotFound。
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, ErrNoRoute)
+}
+
+// MethodNotAllowed 以 ErrMethodNotAllowed 的狀態碼與主體回應，用於 router.Router 的 MethodNotAllowed；
+// router 在呼叫它之前已經設定好 Allow 標頭。
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, ErrMethodNotAllowed)
+}
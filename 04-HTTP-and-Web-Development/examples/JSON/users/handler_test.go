@@ -0,0 +1,144 @@
+package users
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/negotiate"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/net-http/router"
+)
+
+func newServer() http.Handler {
+	r := router.New()
+	NewHandler(NewService(NewMemoryStore())).Mount(r.Group("/api"))
+	return r
+}
+
+func TestCRUD(t *testing.T) {
+	srv := newServer()
+	testCases := []struct {
+		name         string
+		method, path string
+		body         string
+		wantCode     int
+		wantBody     string // 比較去掉結尾換行的 JSON
+		wantLocation string
+	}{
+		{"空的清單", "GET", "/api/users", "", 200, `[]`, ""},
+		{"建立", "POST", "/api/users", `{"id":9,"name":"Alice","email":"alice@example.com"}`, 201,
+			`{"id":1,"name":"Alice","email":"alice@example.com"}`, "/api/users/1"},
+		{"建立第二位", "POST", "/api/users", `{"name":"Bob"}`, 201, `{"id":2,"name":"Bob"}`, "/api/users/2"},
+		{"無效的 JSON", "POST", "/api/users", `{"name":`, 400, "", ""},
+		{"空的名稱", "POST", "/api/users", `{"name":""}`, 422,
+			`{"error":"validation failed","fields":[{"field":"name","rule":"required","message":"is required"}]}`, ""},
+		{"未知的欄位", "POST", "/api/users", `{"name":"Eve","admin":true}`, 422,
+			`{"error":"validation failed","fields":[{"field":"admin","rule":"unknown","message":"is not allowed"}]}`, ""},
+		{"清單", "GET", "/api/users", "", 200, `[{"id":1,"name":"Alice","email":"alice@example.com"},{"id":2,"name":"Bob"}]`, ""},
+		{"取得", "GET", "/api/users/2", "", 200, `{"id":2,"name":"Bob"}`, ""},
+		{"取代", "PUT", "/api/users/2", `{"name":"Robert"}`, 200, `{"id":2,"name":"Robert"}`, ""},
+		{"部分更新", "PATCH", "/api/users/1", `{"email":"a@example.com"}`, 200, `{"id":1,"name":"Alice","email":"a@example.com"}`, ""},
+		{"部分更新為無效的值", "PATCH", "/api/users/1", `{"name":"","email":"nope"}`, 422,
+			`{"error":"validation failed","fields":[{"field":"name","rule":"min","param":"1","message":"must be at least 1 characters"},{"field":"email","rule":"email","message":"must be a valid email address"}]}`, ""},
+		{"取代為無效的值", "PUT", "/api/users/2", `{"email":"r@example.com"}`, 422, "", ""},
+		{"刪除", "DELETE", "/api/users/1", "", 204, "", ""},
+		{"刪除後取得", "GET", "/api/users/1", "", 404, `{"error":"user not found"}`, ""},
+		{"取代不存在的使用者", "PUT", "/api/users/7", `{"name":"X"}`, 404, `{"error":"user not found"}`, ""},
+		{"部分更新不存在的使用者", "PATCH", "/api/users/7", `{}`, 404, `{"error":"user not found"}`, ""},
+		{"刪除不存在的使用者", "DELETE", "/api/users/1", "", 404, `{"error":"user not found"}`, ""},
+		{"無效的 ID", "GET", "/api/users/abc", "", 400, `{"error":"invalid user id \"abc\""}`, ""},
+		{"最後的清單", "GET", "/api/users", "", 200, `[{"id":2,"name":"Robert"}]`, ""},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+		if w.Code != tc.wantCode {
+			t.Errorf("%s: %s %s 狀態碼 = %d; 預期為 %d (%s)", tc.name, tc.method, tc.path, w.Code, tc.wantCode, w.Body)
+			continue
+		}
+		if tc.wantBody != "" {
+			if got := strings.TrimSpace(w.Body.String()); got != tc.wantBody {
+				t.Errorf("%s: 主體 = %s; 預期為 %s", tc.name, got, tc.wantBody)
+			}
+		}
+		if got := w.Header().Get("Location"); got != tc.wantLocation {
+			t.Errorf("%s: Location = %q; 預期為 %q", tc.name, got, tc.wantLocation)
+		}
+		if w.Code != http.StatusNoContent && w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("%s: Content-Type = %q; 預期為 application/json", tc.name, w.Header().Get("Content-Type"))
+		}
+		if tc.wantCode == http.StatusBadRequest {
+			var body ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error == "" {
+				t.Errorf("%s: 錯誤主體 = %s; 預期為 {\"error\": ...}", tc.name, w.Body)
+			}
+		}
+	}
+}
+
+func TestNegotiation(t *testing.T) {
+	srv := newServer()
+	cborUser, _ := negotiate.MarshalCBOR(map[string]any{"name": "Carol", "email": "carol@example.com"})
+	cborAdmin, _ := negotiate.MarshalCBOR(map[string]any{"name": "Eve", "admin": true})
+	testCases := []struct {
+		name         string
+		method, path string
+		contentType  string
+		accept       string
+		body         string
+		wantCode     int
+		wantType     string
+		wantBody     string // 包含於主體中的字串
+	}{
+		{"以表單建立", "POST", "/api/users", "application/x-www-form-urlencoded", "",
+			"name=Alice&email=alice%40example.com", 201, "application/json", `"name":"Alice"`},
+		{"以 CBOR 建立", "POST", "/api/users", "application/cbor", "application/cbor",
+			string(cborUser), 201, "application/cbor", "\x65Carol"},
+		{"以 XML 部分更新", "PATCH", "/api/users/1", "application/xml", "application/xml",
+			"<user><name>Alicia</name></user>", 200, "application/xml", "<name>Alicia</name>"},
+		{"以 XML 取得清單", "GET", "/api/users", "", "application/xml", "", 200, "application/xml",
+			"<list><User><id>1</id><name>Alicia</name>"},
+		{"以 MessagePack 取得", "GET", "/api/users/2", "", "application/msgpack", "", 200, "application/msgpack",
+			"\xa2id\x02"},
+		{"表單驗證失敗", "POST", "/api/users", "application/x-www-form-urlencoded", "application/xml",
+			"email=nope", 422, "application/xml", `<field name="name" rule="required" message="is required"></field>`},
+		{"表單中重複的欄位取第一個", "PATCH", "/api/users/1", "application/x-www-form-urlencoded", "", "name=a&name=", 200,
+			"application/json", `"name":"a"`},
+		{"表單中未知的欄位", "POST", "/api/users", "application/x-www-form-urlencoded", "",
+			"name=Eve&admin=true", 422, "application/json",
+			`{"error":"validation failed","fields":[{"field":"admin","rule":"unknown","message":"is not allowed"}]}`},
+		{"CBOR 中未知的欄位", "POST", "/api/users", "application/cbor", "", string(cborAdmin), 422, "application/json",
+			`"fields":[{"field":"admin","rule":"unknown","message":"is not allowed"}]`},
+		{"XML 中未知的欄位", "PATCH", "/api/users/1", "application/xml", "application/xml",
+			"<user><admin>true</admin></user>", 422, "application/xml", `<field name="admin" rule="unknown" message="is not allowed"></field>`},
+		{"不支援的 Content-Type", "POST", "/api/users", "text/plain", "", "Dave", 415, "application/json",
+			`"error":"negotiate: unsupported media type: text/plain`},
+		{"不合法的 CBOR", "POST", "/api/users", "application/cbor", "", "\xa1", 400, "application/json", `"error":"cbor:`},
+		{"沒有可接受的格式", "GET", "/api/users/1", "", "text/html", "", 406, "text/plain; charset=utf-8", "not acceptable"},
+		{"錯誤也依 Accept 協商", "GET", "/api/users/9", "", "application/xml", "", 404, "application/xml",
+			"<error><message>user not found</message></error>"},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if w.Code != tc.wantCode {
+			t.Errorf("%s: 狀態碼 = %d; 預期為 %d (%q)", tc.name, w.Code, tc.wantCode, w.Body)
+			continue
+		}
+		if got := w.Header().Get("Content-Type"); got != tc.wantType {
+			t.Errorf("%s: Content-Type = %q; 預期為 %q", tc.name, got, tc.wantType)
+		}
+		if !strings.Contains(w.Body.String(), tc.wantBody) {
+			t.Errorf("%s: 主體 = %q; 預期包含 %q", tc.name, w.Body, tc.wantBody)
+		}
+	}
+}
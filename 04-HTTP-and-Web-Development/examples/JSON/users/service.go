@@ -0,0 +1,144 @@
+package users
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/validate"
+)
+
+// Service 是使用者 API 與 HTTP 框架無關的業務邏輯：驗證輸入，再交給 UserStore 保存。
+// net/http 的 Handler 與 Gin、Echo、Fiber 的範例都只負責解碼請求與寫出回應，
+// 規則都在這裡，所以不論使用哪個框架，API 的行為都相同 (由 userstest 套件驗證)。
+type Service struct {
+	store UserStore
+}
+
+// NewService 建立以 store 保存資料的 Service。
+func NewService(store UserStore) *Service {
+	return &Service{store: store}
+}
+
+// Patch 是部分更新的內容；nil 的欄位代表不修改，但出現的欄位必須合法。
+type Patch struct {
+	Name  *string `json:"name" xml:"name" validate:"min=1,max=64"`
+	Email *string `json:"email" xml:"email" validate:"email"`
+}
+
+func (p Patch) apply(u *User) {
+	if p.Name != nil {
+		u.Name = *p.Name
+	}
+	if p.Email != nil {
+		u.Email = *p.Email
+	}
+}
+
+// List 依 ID 遞增的順序回傳所有使用者。
+func (s *Service) List(ctx context.Context) ([]User, error) {
+	return s.store.List(ctx)
+}
+
+// Get 回傳 id 的使用者，不存在時回傳 ErrNotFound。
+func (s *Service) Get(ctx context.Context, id int) (User, error) {
+	return s.store.Get(ctx, id)
+}
+
+// Create 驗證並保存 u (忽略 u.ID)；沒有通過驗證時回傳 validate.Errors。
+func (s *Service) Create(ctx context.Context, u User) (User, error) {
+	if err := validate.Struct(u); err != nil {
+		return User{}, err
+	}
+	return s.store.Create(ctx, u)
+}
+
+// Replace 以 u 取代 id 的使用者 (忽略 u.ID)。
+func (s *Service) Replace(ctx context.Context, id int, u User) (User, error) {
+	if err := validate.Struct(u); err != nil {
+		return User{}, err
+	}
+	return s.store.Update(ctx, id, func(cur *User) error {
+		*cur = u
+		return nil
+	})
+}
+
+// Patch 只更新 p 中出現的欄位。
+func (s *Service) Patch(ctx context.Context, id int, p Patch) (User, error) {
+	if err := validate.Struct(p); err != nil {
+		return User{}, err
+	}
+	return s.store.Update(ctx, id, func(u *User) error {
+		p.apply(u)
+		return nil
+	})
+}
+
+// Delete 刪除 id 的使用者，不存在時回傳 ErrNotFound。
+func (s *Service) Delete(ctx context.Context, id int) error {
+	return s.store.Delete(ctx, id)
+}
+
+// InvalidIDError 表示路徑中的使用者 ID 不是正整數。
+type InvalidIDError struct {
+	Value string
+}
+
+func (e *InvalidIDError) Error() string {
+	return "users: invalid user id " + strconv.Quote(e.Value)
+}
+
+// ParseID 解析路徑參數中的使用者 ID，不是正整數時回傳 *InvalidIDError。
+func ParseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, &InvalidIDError{Value: s}
+	}
+	return id, nil
+}
+
+// ErrorResponse 是錯誤回應的主體；XML 的根元素為 <error>。
+//
+//	{"error": "validation failed", "fields": [{"field": "name", "rule": "required", "message": "is required"}]}
+type ErrorResponse struct {
+	XMLName xml.Name              `json:"-" xml:"error"`
+	Error   string                `json:"error" xml:"message"`
+	Fields  []validate.FieldError `json:"fields,omitempty" xml:"field,omitempty"`
+}
+
+// ErrorFor 把 Service、ParseID 或 validate.Decode 的錯誤轉成狀態碼與回應主體，
+// 讓每個框架以相同的方式回應錯誤：
+//
+//   - *validate.RequestError：它的狀態碼 (400、413 或 422)
+//   - validate.Errors：422
+//   - *InvalidIDError：400
+//   - ErrNotFound：404
+//   - ErrNoRoute：404；ErrMethodNotAllowed：405
+//   - 其他錯誤：500，細節只記錄在日誌中，不回傳給客戶端
+func ErrorFor(err error) (int, ErrorResponse) {
+	var (
+		reqErr *validate.RequestError
+		fields validate.Errors
+		idErr  *InvalidIDError
+	)
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr.Status, ErrorResponse{Error: reqErr.Message, Fields: reqErr.Fields}
+	case errors.As(err, &fields):
+		return http.StatusUnprocessableEntity, ErrorResponse{Error: "validation failed", Fields: fields}
+	case errors.As(err, &idErr):
+		return http.StatusBadRequest, ErrorResponse{Error: "invalid user id " + strconv.Quote(idErr.Value)}
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound, ErrorResponse{Error: "user not found"}
+	case errors.Is(err, ErrNoRoute):
+		return http.StatusNotFound, ErrorResponse{Error: "not found"}
+	case errors.Is(err, ErrMethodNotAllowed):
+		return http.StatusMethodNotAllowed, ErrorResponse{Error: "method not allowed"}
+	}
+	log.Printf("users: %v", err)
+	return http.StatusInternalServerError, ErrorResponse{Error: http.StatusText(http.StatusInternalServerError)}
+}
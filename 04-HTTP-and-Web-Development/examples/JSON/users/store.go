@@ -0,0 +1,127 @@
+// Package users 實作一個 JSON 使用者服務：可替換儲存方式的 UserStore，
+// 以及掛在 /users 與 /users/{id} 底下的 CRUD handler。
+//
+//	GET    /users       列出所有使用者
+//	POST   /users       建立使用者，回應 201 與 Location 標頭
+//	GET    /users/{id}  取得一位使用者
+//	PUT    /users/{id}  以請求主體取代整位使用者
+//	PATCH  /users/{id}  只更新請求主體中出現的欄位
+//	DELETE /users/{id}  刪除使用者，回應 204
+//
+// 回應 (包含錯誤) 的格式依 Accept 標頭協商，預設為 JSON，也支援 XML、CBOR 與 MessagePack；
+// 請求主體依 Content-Type 解碼，另外接受 HTML 表單。找不到使用者時回應 404。
+package users
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// ErrNotFound 表示使用者不存在。
+var ErrNotFound = errors.New("users: user not found")
+
+// ErrNoRoute 與 ErrMethodNotAllowed 表示請求沒有符合的路由，或路徑符合但方法不符。
+// 每個框架的 404 與 405 處理都把它們交給 ErrorFor，所以回應的主體與 net/http 的範例相同。
+var (
+	ErrNoRoute          = errors.New("users: no route")
+	ErrMethodNotAllowed = errors.New("users: method not allowed")
+)
+
+// User 是服務的資料模型。
+type User struct {
+	ID    int    `json:"id" xml:"id"`
+	Name  string `json:"name" xml:"name" validate:"required,max=64"`
+	Email string `json:"email,omitempty" xml:"email,omitempty" validate:"email"`
+}
+
+// UserStore 是使用者的儲存方式。實作必須可以同時被多個 Goroutine 使用。
+type UserStore interface {
+	// List 依 ID 遞增的順序回傳所有使用者。
+	List(ctx context.Context) ([]User, error)
+	// Get 回傳 id 的使用者，不存在時回傳 ErrNotFound。
+	Get(ctx context.Context, id int) (User, error)
+	// Create 為 u 配置新的 ID (忽略 u.ID) 並保存，回傳保存後的使用者。
+	Create(ctx context.Context, u User) (User, error)
+	// Update 以 fn 修改 id 的使用者並保存，讀取與寫入之間不會被其他更新插入。
+	// fn 回傳錯誤時不會保存，Update 回傳該錯誤；使用者不存在時回傳 ErrNotFound。
+	// fn 對 ID 的修改會被忽略。
+	Update(ctx context.Context, id int, fn func(u *User) error) (User, error)
+	// Delete 刪除 id 的使用者，不存在時回傳 ErrNotFound。
+	Delete(ctx context.Context, id int) error
+}
+
+// MemoryStore 是把使用者保存在記憶體中的 UserStore，ID 從 1 開始自動遞增。
+// 零值不能使用，請使用 NewMemoryStore。
+type MemoryStore struct {
+	mu    sync.RWMutex
+	users map[int]User
+	next  int
+}
+
+// NewMemoryStore 建立一個空的 MemoryStore。
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{users: map[int]User{}, next: 1}
+}
+
+// List 實作 UserStore。
+func (s *MemoryStore) List(ctx context.Context) ([]User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]User, 0, len(s.users))
+	for _, u := range s.users {
+		list = append(list, u)
+	}
+	slices.SortFunc(list, func(a, b User) int { return cmp.Compare(a.ID, b.ID) })
+	return list, nil
+}
+
+// Get 實作 UserStore。
+func (s *MemoryStore) Get(ctx context.Context, id int) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return u, nil
+}
+
+// Create 實作 UserStore。
+func (s *MemoryStore) Create(ctx context.Context, u User) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u.ID = s.next
+	s.next++
+	s.users[u.ID] = u
+	return u, nil
+}
+
+// Update 實作 UserStore。
+func (s *MemoryStore) Update(ctx context.Context, id int, fn func(u *User) error) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	if err := fn(&u); err != nil {
+		return User{}, err
+	}
+	u.ID = id
+	s.users[id] = u
+	return u, nil
+}
+
+// Delete 實作 UserStore。
+func (s *MemoryStore) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.users, id)
+	return nil
+}
@@ -0,0 +1,110 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+
+	// 同時建立的使用者都會拿到不重複、連續的 ID。
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Create(ctx, User{ID: 999, Name: "user"})
+		}()
+	}
+	wg.Wait()
+	list, _ := s.List(ctx)
+	if len(list) != 50 {
+		t.Fatalf("len(List()) = %d; 預期為 50", len(list))
+	}
+	for i, u := range list {
+		if u.ID != i+1 {
+			t.Fatalf("List()[%d].ID = %d; 預期依序為 %d", i, u.ID, i+1)
+		}
+	}
+
+	u, err := s.Update(ctx, 3, func(u *User) error {
+		u.ID = 42
+		u.Name = "Carol"
+		return nil
+	})
+	if err != nil || u != (User{ID: 3, Name: "Carol"}) {
+		t.Errorf("Update(3) = (%+v, %v); 預期 ID 不變且 Name 為 Carol", u, err)
+	}
+	errBad := errors.New("bad")
+	if _, err := s.Update(ctx, 3, func(u *User) error {
+		u.Name = "ignored"
+		return errBad
+	}); !errors.Is(err, errBad) {
+		t.Errorf("Update 錯誤 = %v; 預期為 fn 的錯誤", err)
+	}
+	if u, _ := s.Get(ctx, 3); u.Name != "Carol" {
+		t.Errorf("fn 失敗後 Get(3).Name = %q; 預期不被保存", u.Name)
+	}
+
+	if err := s.Delete(ctx, 3); err != nil {
+		t.Errorf("Delete(3) 錯誤 = %v", err)
+	}
+	for name, err := range map[string]error{
+		"Get":    func() error { _, err := s.Get(ctx, 3); return err }(),
+		"Update": func() error { _, err := s.Update(ctx, 3, func(*User) error { return nil }); return err }(),
+		"Delete": s.Delete(ctx, 3),
+	} {
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("刪除後 %s 錯誤 = %v; 預期為 ErrNotFound", name, err)
+		}
+	}
+	if u, _ := s.Create(ctx, User{Name: "next"}); u.ID != 51 {
+		t.Errorf("刪除後 Create 的 ID = %d; 預期 ID 不重複使用，為 51", u.ID)
+	}
+}
+
+// countingStore 記錄 Get 被呼叫的次數。
+type countingStore struct {
+	UserStore
+	mu   sync.Mutex
+	gets int
+}
+
+func (s *countingStore) Get(ctx context.Context, id int) (User, error) {
+	s.mu.Lock()
+	s.gets++
+	s.mu.Unlock()
+	return s.UserStore.Get(ctx, id)
+}
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+	base := &countingStore{UserStore: NewMemoryStore()}
+	s := Cached(base, time.Minute)
+	s.Create(ctx, User{Name: "Alice"})
+
+	for i := 0; i < 3; i++ {
+		s.Get(ctx, 1)
+	}
+	if base.gets != 1 {
+		t.Errorf("3 次 Get 查詢底層 store %d 次; 預期為 1 次", base.gets)
+	}
+
+	s.Update(ctx, 1, func(u *User) error {
+		u.Name = "Alicia"
+		return nil
+	})
+	if u, _ := s.Get(ctx, 1); u.Name != "Alicia" || base.gets != 2 {
+		t.Errorf("Update 後 Get = %q (查詢 %d 次); 預期快取失效並讀到 Alicia", u.Name, base.gets)
+	}
+
+	s.Delete(ctx, 1)
+	if _, err := s.Get(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete 後 Get 錯誤 = %v; 預期為 ErrNotFound", err)
+	}
+}
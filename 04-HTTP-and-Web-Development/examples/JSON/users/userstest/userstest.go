@@ -0,0 +1,239 @@
+// Package userstest 是使用者 API 的一致性測試 (conformance test)。
+//
+// net/http、Gin、Echo 與 Fiber 的範例都建立在 users.Service 之上，但解碼請求、
+// 路由參數與寫出回應的方式各不相同。Run 只透過 HTTP 觀察 handler (黑箱測試)，
+// 不依賴任何框架，所以每個實作都以同一組測試證明客戶端看到的行為相同：
+//
+//	func TestConformance(t *testing.T) {
+//		userstest.Run(t, func(svc *users.Service) http.Handler {
+//			return newRouter(svc)
+//		})
+//	}
+package userstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/users"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/JSON/validate"
+)
+
+// NewHandler 以 svc 建立受測的 handler，它必須在根路徑提供 /users 與 /users/{id}。
+type NewHandler func(svc *users.Service) http.Handler
+
+// Run 執行所有的一致性測試。每個子測試都以新的 MemoryStore 呼叫 newHandler，彼此不共用資料。
+func Run(t *testing.T, newHandler NewHandler) {
+	t.Helper()
+	newServer := func() http.Handler {
+		return newHandler(users.NewService(users.NewMemoryStore()))
+	}
+	t.Run("CRUD", func(t *testing.T) { testCRUD(t, newServer()) })
+	t.Run("無效的請求", func(t *testing.T) { testBadRequests(t, newServer()) })
+	t.Run("無效的 ID", func(t *testing.T) { testInvalidID(t, newServer()) })
+	t.Run("沒有符合的路由", func(t *testing.T) { testRouting(t, newServer()) })
+	t.Run("並行建立", func(t *testing.T) { testConcurrentCreate(t, newServer()) })
+}
+
+// step 是一個請求與預期的回應；wantBody 為空時不比較主體。
+type step struct {
+	name         string
+	method, path string
+	body         string
+	wantCode     int
+	wantBody     string
+	wantLocation string
+}
+
+// do 送出 JSON 請求並回傳回應。
+func do(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// check 依序執行 steps。主體以 JSON 的值比較，所以不在意空白與欄位順序；
+// Content-Type 只比較媒體類型，不在意 charset 等參數。
+func check(t *testing.T, h http.Handler, steps []step) {
+	t.Helper()
+	for _, s := range steps {
+		w := do(h, s.method, s.path, s.body)
+		if w.Code != s.wantCode {
+			t.Errorf("%s: %s %s 狀態碼 = %d; 預期為 %d (%s)", s.name, s.method, s.path, w.Code, s.wantCode, w.Body)
+			continue
+		}
+		if got := w.Header().Get("Location"); got != s.wantLocation {
+			t.Errorf("%s: Location = %q; 預期為 %q", s.name, got, s.wantLocation)
+		}
+		if w.Code == http.StatusNoContent {
+			if w.Body.Len() != 0 {
+				t.Errorf("%s: 204 的主體 = %q; 預期為空", s.name, w.Body)
+			}
+			continue
+		}
+		if mt, _, err := mime.ParseMediaType(w.Header().Get("Content-Type")); mt != "application/json" {
+			t.Errorf("%s: Content-Type = %q (%v); 預期為 application/json", s.name, w.Header().Get("Content-Type"), err)
+		}
+		if w.Code >= 400 {
+			var body users.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Error == "" {
+				t.Errorf("%s: 錯誤主體 = %s; 預期為 {\"error\": ...}", s.name, w.Body)
+			}
+		}
+		if s.wantBody != "" {
+			if err := jsonEqual(w.Body.Bytes(), s.wantBody); err != nil {
+				t.Errorf("%s: %v", s.name, err)
+			}
+		}
+	}
+}
+
+// jsonEqual 比較 got 與 want 是否為相同的 JSON 值。
+func jsonEqual(got []byte, want string) error {
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		return fmt.Errorf("主體 %q 不是合法的 JSON: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		return fmt.Errorf("預期的主體 %q 不是合法的 JSON: %v", want, err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		return fmt.Errorf("主體 = %s; 預期為 %s", bytes.TrimSpace(got), want)
+	}
+	return nil
+}
+
+func testCRUD(t *testing.T, h http.Handler) {
+	check(t, h, []step{
+		{"空的清單", "GET", "/users", "", 200, `[]`, ""},
+		{"建立時忽略 id", "POST", "/users", `{"id":9,"name":"Alice","email":"alice@example.com"}`, 201,
+			`{"id":1,"name":"Alice","email":"alice@example.com"}`, "/users/1"},
+		{"建立第二位", "POST", "/users", `{"name":"Bob"}`, 201, `{"id":2,"name":"Bob"}`, "/users/2"},
+		{"清單依 ID 排序", "GET", "/users", "", 200,
+			`[{"id":1,"name":"Alice","email":"alice@example.com"},{"id":2,"name":"Bob"}]`, ""},
+		{"取得", "GET", "/users/2", "", 200, `{"id":2,"name":"Bob"}`, ""},
+		{"取代", "PUT", "/users/2", `{"name":"Robert"}`, 200, `{"id":2,"name":"Robert"}`, ""},
+		{"部分更新", "PATCH", "/users/1", `{"email":"a@example.com"}`, 200,
+			`{"id":1,"name":"Alice","email":"a@example.com"}`, ""},
+		{"空的部分更新", "PATCH", "/users/1", `{}`, 200, `{"id":1,"name":"Alice","email":"a@example.com"}`, ""},
+		{"刪除", "DELETE", "/users/1", "", 204, "", ""},
+		{"刪除後取得", "GET", "/users/1", "", 404, `{"error":"user not found"}`, ""},
+		{"取代不存在的使用者", "PUT", "/users/7", `{"name":"X"}`, 404, `{"error":"user not found"}`, ""},
+		{"部分更新不存在的使用者", "PATCH", "/users/7", `{}`, 404, `{"error":"user not found"}`, ""},
+		{"刪除不存在的使用者", "DELETE", "/users/1", "", 404, `{"error":"user not found"}`, ""},
+		{"最後的清單", "GET", "/users", "", 200, `[{"id":2,"name":"Robert"}]`, ""},
+	})
+}
+
+func testBadRequests(t *testing.T, h http.Handler) {
+	tooLarge := `{"name":"` + strings.Repeat("a", validate.DefaultMaxBytes) + `"}`
+	check(t, h, []step{
+		{"建立", "POST", "/users", `{"name":"Alice"}`, 201, `{"id":1,"name":"Alice"}`, "/users/1"},
+		{"無效的 JSON", "POST", "/users", `{"name":`, 400, "", ""},
+		{"空的主體", "POST", "/users", "", 400, "", ""},
+		{"多個 JSON 值", "POST", "/users", `{"name":"A"}{"name":"B"}`, 400, "", ""},
+		{"主體太大", "POST", "/users", tooLarge, 413, "", ""},
+		{"空的名稱", "POST", "/users", `{"name":""}`, 422,
+			`{"error":"validation failed","fields":[{"field":"name","rule":"required","message":"is required"}]}`, ""},
+		{"未知的欄位", "POST", "/users", `{"name":"Eve","admin":true}`, 422,
+			`{"error":"validation failed","fields":[{"field":"admin","rule":"unknown","message":"is not allowed"}]}`, ""},
+		{"型別不符", "POST", "/users", `{"name":42}`, 422,
+			`{"error":"validation failed","fields":[{"field":"name","rule":"type","message":"must be a string"}]}`, ""},
+		{"取代為無效的值", "PUT", "/users/1", `{"email":"r@example.com"}`, 422,
+			`{"error":"validation failed","fields":[{"field":"name","rule":"required","message":"is required"}]}`, ""},
+		{"部分更新為無效的值", "PATCH", "/users/1", `{"name":"","email":"nope"}`, 422,
+			`{"error":"validation failed","fields":[{"field":"name","rule":"min","param":"1","message":"must be at least 1 characters"},{"field":"email","rule":"email","message":"must be a valid email address"}]}`, ""},
+		{"部分更新時未知的欄位", "PATCH", "/users/1", `{"id":2}`, 422,
+			`{"error":"validation failed","fields":[{"field":"id","rule":"unknown","message":"is not allowed"}]}`, ""},
+		// 失敗的請求不應該改變任何資料，也不應該消耗 ID
+		{"清單沒有改變", "GET", "/users", "", 200, `[{"id":1,"name":"Alice"}]`, ""},
+		{"下一個 ID", "POST", "/users", `{"name":"Bob"}`, 201, `{"id":2,"name":"Bob"}`, "/users/2"},
+	})
+}
+
+func testInvalidID(t *testing.T, h http.Handler) {
+	var steps []step
+	for _, id := range []string{"abc", "0", "-1", "1.5"} {
+		want, _ := json.Marshal(users.ErrorResponse{Error: "invalid user id " + strconv.Quote(id)})
+		for _, m := range []struct{ method, body string }{
+			{"GET", ""}, {"PUT", `{"name":"X"}`}, {"PATCH", `{}`}, {"DELETE", ""},
+		} {
+			steps = append(steps, step{m.method + " ID " + id, m.method, "/users/" + id, m.body, 400, string(want), ""})
+		}
+	}
+	check(t, h, steps)
+}
+
+// testRouting 檢查沒有符合的路徑 (404) 與方法不符 (405) 的回應；框架預設的回應通常是純文字，
+// 必須另外設定才會與其他錯誤一樣回應 JSON。405 還必須以 Allow 標頭列出允許的方法。
+func testRouting(t *testing.T, h http.Handler) {
+	const (
+		notFound   = `{"error":"not found"}`
+		notAllowed = `{"error":"method not allowed"}`
+	)
+	check(t, h, []step{
+		{"不存在的路徑", "GET", "/nope", "", 404, notFound, ""},
+		{"多出的路徑片段", "GET", "/users/1/extra", "", 404, notFound, ""},
+		{"清單不支援 DELETE", "DELETE", "/users", "", 405, notAllowed, ""},
+		{"使用者不支援 POST", "POST", "/users/1", `{"name":"X"}`, 405, notAllowed, ""},
+	})
+	for _, tc := range []struct{ method, path, want string }{
+		{"DELETE", "/users", "POST"},
+		{"POST", "/users/1", "PATCH"},
+	} {
+		w := do(h, tc.method, tc.path, "")
+		allow := w.Header().Get("Allow")
+		if !slices.Contains(strings.Split(strings.ReplaceAll(allow, " ", ""), ","), tc.want) {
+			t.Errorf("%s %s: Allow = %q; 預期包含 %s", tc.method, tc.path, allow, tc.want)
+		}
+	}
+}
+
+// testConcurrentCreate 同時建立多位使用者：每位都必須取得不同的 ID，以 -race 執行時也能發現資料競爭。
+func testConcurrentCreate(t *testing.T, h http.Handler) {
+	const n = 20
+	ids := make(chan int, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := do(h, "POST", "/users", fmt.Sprintf(`{"name":"user%d"}`, i))
+			var u users.User
+			if w.Code != http.StatusCreated || json.Unmarshal(w.Body.Bytes(), &u) != nil {
+				t.Errorf("建立 user%d: 狀態碼 = %d (%s); 預期為 201", i, w.Code, w.Body)
+				return
+			}
+			ids <- u.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("ID %d 被指派了兩次", id)
+		}
+		seen[id] = true
+	}
+	var list []users.User
+	w := do(h, "GET", "/users", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || len(list) != n {
+		t.Errorf("清單 = %s; 預期有 %d 位使用者", w.Body, n)
+	}
+}
@@ -0,0 +1,173 @@
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxBytes 是 Decode 預設允許的請求主體大小。
+const DefaultMaxBytes = 1 << 20
+
+// RequestError 是 Decode 回傳的錯誤，Status 是建議的 HTTP 狀態碼。
+type RequestError struct {
+	Status  int
+	Message string
+	Fields  Errors // 只有 422 時才有
+}
+
+func (e *RequestError) Error() string {
+	if len(e.Fields) > 0 {
+		return e.Fields.Error()
+	}
+	return "validate: " + e.Message
+}
+
+// Decode 把 r 的 JSON 主體解碼到 dst，dst 指向 struct 時再以 Struct 驗證它。maxBytes <= 0 時使用 DefaultMaxBytes。
+//
+// 請求有問題時回傳 *RequestError：
+//
+//   - 主體是空的、不是合法的 JSON，或包含一個以上的 JSON 值：400
+//   - 主體超過 maxBytes：413
+//   - 有未知的欄位、欄位的型別不符，或沒有通過驗證：422，Fields 列出每一個欄位
+//
+// tag 中的規則有誤時回傳包裝 ErrBadRule 的錯誤。
+func Decode(w http.ResponseWriter, r *http.Request, dst any, maxBytes int64) error {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	// 第一個值之後只能是空白。
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return decodeError(err)
+		}
+		return &RequestError{Status: http.StatusBadRequest, Message: "body must contain a single JSON value"}
+	}
+
+	// 只有 struct 有 tag 可以驗證；解碼到 map 或 slice 時略過。
+	rv := reflect.ValueOf(dst)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	if err := Struct(dst); err != nil {
+		var fields Errors
+		if errors.As(err, &fields) {
+			return &RequestError{Status: http.StatusUnprocessableEntity, Message: "validation failed", Fields: fields}
+		}
+		return err
+	}
+	return nil
+}
+
+// decodeError 把 json.Decoder 的錯誤轉成 *RequestError。
+func decodeError(err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		maxErr    *http.MaxBytesError
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return &RequestError{Status: http.StatusBadRequest, Message: "body must not be empty"}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &RequestError{Status: http.StatusBadRequest, Message: "body contains malformed JSON"}
+	case errors.As(err, &syntaxErr):
+		return &RequestError{Status: http.StatusBadRequest,
+			Message: fmt.Sprintf("body contains malformed JSON at offset %d", syntaxErr.Offset)}
+	case errors.As(err, &maxErr):
+		return &RequestError{Status: http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("body must not be larger than %d bytes", maxErr.Limit)}
+	case errors.As(err, &typeErr):
+		return &RequestError{Status: http.StatusUnprocessableEntity, Message: "validation failed", Fields: Errors{{
+			Field: typeErr.Field, Rule: "type", Message: "must be " + jsonType(typeErr.Type.Kind().String()),
+		}}}
+	}
+	if name, ok := UnknownJSONField(err); ok {
+		return &RequestError{Status: http.StatusUnprocessableEntity, Message: "validation failed", Fields: Errors{{
+			Field: name, Rule: "unknown", Message: "is not allowed",
+		}}}
+	}
+	return &RequestError{Status: http.StatusBadRequest, Message: err.Error()}
+}
+
+// UnknownJSONField 回報 err 是否為 json.Decoder.DisallowUnknownFields 拒絕的欄位，並回傳欄位名稱。
+// encoding/json 沒有為這個錯誤提供專屬的型別，只能比對 `json: unknown field "name"` 的格式，
+// 因此所有需要辨識它的地方都應該呼叫這個函式。
+func UnknownJSONField(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+	quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+	name, qerr := strconv.Unquote(quoted)
+	return name, qerr == nil
+}
+
+// jsonType 把 Go 的型別種類換成 JSON 使用者看得懂的名稱。
+func jsonType(kind string) string {
+	switch {
+	case kind == "string":
+		return "a string"
+	case kind == "bool":
+		return "a boolean"
+	case strings.HasPrefix(kind, "int"), strings.HasPrefix(kind, "uint"), strings.HasPrefix(kind, "float"):
+		return "a number"
+	case kind == "slice" || kind == "array":
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// errorBody 是錯誤回應的 JSON 格式。
+type errorBody struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// WriteError 把 Decode 的錯誤寫成 JSON 回應：
+//
+//	{"error": "validation failed", "fields": [{"field": "name", "rule": "required", "message": "is required"}]}
+//
+// 不是 *RequestError 的錯誤 (例如 ErrBadRule) 一律回應 500，細節只記錄在日誌中。
+func WriteError(w http.ResponseWriter, err error) {
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		log.Printf("validate: %v", err)
+		reqErr = &RequestError{Status: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError)}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(reqErr.Status)
+	json.NewEncoder(w).Encode(errorBody{Error: reqErr.Message, Fields: reqErr.Fields})
+}
+
+// Bind 以 Decode 解碼並驗證 r 的主體；失敗時以 WriteError 回應並回傳 false。
+//
+//	var u User
+//	if !validate.Bind(w, r, &u) {
+//		return
+//	}
+func Bind(w http.ResponseWriter, r *http.Request, dst any) bool {
+	if err := Decode(w, r, dst, 0); err != nil {
+		WriteError(w, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package validate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type signup struct {
+	Name  string   `json:"name" validate:"required,max=8"`
+	Email string   `json:"email" validate:"required,email"`
+	Age   int      `json:"age" validate:"min=13"`
+	Roles []string `json:"roles"`
+}
+
+func TestBind(t *testing.T) {
+	testCases := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantError  string
+		wantFields []string
+	}{
+		{"合法", `{"name":"gopher","email":"g@go.dev","age":14}`, 0, "", nil},
+		{"前後的空白", " \n{\"name\":\"g\",\"email\":\"g@go.dev\"}\n ", 0, "", nil},
+		{"空的主體", ``, 400, "body must not be empty", nil},
+		{"不完整的 JSON", `{"name":`, 400, "body contains malformed JSON", nil},
+		{"語法錯誤", `{"name" "g"}`, 400, "body contains malformed JSON at offset 9", nil},
+		{"多個 JSON 值", `{"name":"g","email":"g@go.dev"}{}`, 400, "body must contain a single JSON value", nil},
+		{"超過大小", `{"name":"` + strings.Repeat("x", 2<<20) + `"}`, 413, "body must not be larger than 1048576 bytes", nil},
+		{"未知的欄位", `{"name":"g","email":"g@go.dev","admin":true}`, 422, "validation failed", []string{"admin:unknown"}},
+		{"型別不符", `{"name":"g","email":"g@go.dev","age":"old"}`, 422, "validation failed", []string{"age:type"}},
+		{"驗證失敗", `{"name":"","email":"nope","age":3}`, 422, "validation failed",
+			[]string{"name:required", "email:email", "age:min"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			var dst signup
+			ok := Bind(w, httptest.NewRequest("POST", "/signup", strings.NewReader(tc.body)), &dst)
+			if tc.wantStatus == 0 {
+				if !ok {
+					t.Fatalf("Bind() = false; 預期成功 (%d %s)", w.Code, w.Body)
+				}
+				return
+			}
+			if ok || w.Code != tc.wantStatus {
+				t.Fatalf("Bind() = %v, 狀態碼 %d; 預期失敗並回應 %d", ok, w.Code, tc.wantStatus)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q; 預期為 application/json", ct)
+			}
+			var body struct {
+				Error  string       `json:"error"`
+				Fields []FieldError `json:"fields"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("回應不是 JSON: %v\n%s", err, w.Body)
+			}
+			var got []string
+			for _, f := range body.Fields {
+				got = append(got, f.Field+":"+f.Rule)
+			}
+			if body.Error != tc.wantError || !slices.Equal(got, tc.wantFields) {
+				t.Errorf("回應 = (%q, %v); 預期為 (%q, %v)", body.Error, got, tc.wantError, tc.wantFields)
+			}
+		})
+	}
+}
+
+func TestDecodeNonStruct(t *testing.T) {
+	var m map[string]int
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"a":1}`))
+	if err := Decode(httptest.NewRecorder(), r, &m, 0); err != nil || m["a"] != 1 {
+		t.Errorf("Decode(map) = (%v, %v); 預期解碼成功且不驗證", m, err)
+	}
+}
+
+func TestUnknownJSONField(t *testing.T) {
+	tests := []struct {
+		body string
+		want string // 空字串表示不是未知欄位的錯誤
+	}{
+		{`{"nickname": "x"}`, "nickname"},
+		{`{"a\"b": 1}`, `a"b`},
+		{`{"name": 1}`, ""},
+		{`{`, ""},
+		{`{"name": "ok"}`, ""},
+	}
+	for _, tt := range tests {
+		dec := json.NewDecoder(strings.NewReader(tt.body))
+		dec.DisallowUnknownFields()
+		var dst struct {
+			Name string `json:"name"`
+		}
+		name, ok := UnknownJSONField(dec.Decode(&dst))
+		if ok != (tt.want != "") || name != tt.want {
+			t.Errorf("UnknownJSONField(%s) = (%q, %v); 預期為 %q", tt.body, name, ok, tt.want)
+		}
+	}
+}
+
+func TestWriteErrorHidesInternalErrors(t *testing.T) {
+	type bad struct {
+		N int `json:"n" validate:"nosuchrule"`
+	}
+	w := httptest.NewRecorder()
+	var dst bad
+	if Bind(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"n":1}`)), &dst) {
+		t.Fatal("Bind() = true; 預期未知的規則會失敗")
+	}
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "nosuchrule") {
+		t.Errorf("回應 = (%d, %s); 預期為不含細節的 500", w.Code, w.Body)
+	}
+}
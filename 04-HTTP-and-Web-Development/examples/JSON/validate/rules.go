@@ -0,0 +1,145 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var builtin = map[string]Rule{
+	"min":   ruleMin,
+	"max":   ruleMax,
+	"len":   ruleLen,
+	"oneof": ruleOneOf,
+	"email": ruleEmail,
+	"url":   ruleURL,
+}
+
+// size 回傳比較 min、max、len 時使用的大小，以及訊息中的單位。
+func size(value any, rule string) (float64, string, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters", nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " items", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), "", nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", nil
+	}
+	return 0, "", fmt.Errorf("%w: %s does not apply to %T", ErrBadRule, rule, value)
+}
+
+func number(param, rule string) (float64, error) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s=%q: not a number", ErrBadRule, rule, param)
+	}
+	return n, nil
+}
+
+// bound 實作 min 與 max：ok 回報 got 與 limit 的比較是否通過。
+func bound(value any, param, rule, word string, ok func(got, limit float64) bool) error {
+	limit, err := number(param, rule)
+	if err != nil {
+		return err
+	}
+	got, unit, err := size(value, rule)
+	if err != nil {
+		return err
+	}
+	if !ok(got, limit) {
+		if unit == " items" {
+			return fmt.Errorf("must contain %s %s%s", word, param, unit)
+		}
+		return fmt.Errorf("must be %s %s%s", word, param, unit)
+	}
+	return nil
+}
+
+func ruleMin(value any, param string) error {
+	return bound(value, param, "min", "at least", func(got, limit float64) bool { return got >= limit })
+}
+
+func ruleMax(value any, param string) error {
+	return bound(value, param, "max", "at most", func(got, limit float64) bool { return got <= limit })
+}
+
+func ruleLen(value any, param string) error {
+	want, err := number(param, "len")
+	if err != nil {
+		return err
+	}
+	got, unit, err := size(value, "len")
+	if err != nil {
+		return err
+	}
+	if unit == "" {
+		return fmt.Errorf("%w: len does not apply to %T", ErrBadRule, value)
+	}
+	if got != want {
+		return fmt.Errorf("must be exactly %s%s", param, unit)
+	}
+	return nil
+}
+
+func ruleOneOf(value any, param string) error {
+	options := strings.Fields(param)
+	if len(options) == 0 {
+		return fmt.Errorf("%w: oneof needs at least one option", ErrBadRule)
+	}
+	if !slices.Contains(options, fmt.Sprint(value)) {
+		return fmt.Errorf("must be one of [%s]", strings.Join(options, " "))
+	}
+	return nil
+}
+
+// text 取出字串規則的值。
+func text(value any, rule string) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		// 允許以 string 為底層型別的自訂型別。
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.String {
+			return "", fmt.Errorf("%w: %s does not apply to %T", ErrBadRule, rule, value)
+		}
+		s = v.String()
+	}
+	return s, nil
+}
+
+var errEmail = errors.New("must be a valid email address")
+
+func ruleEmail(value any, param string) error {
+	s, err := text(value, "email")
+	if err != nil {
+		return err
+	}
+	// mail.ParseAddress 也接受 "Name <a@b>" 的形式，這裡只接受單純的位址。
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || !strings.Contains(s[strings.LastIndex(s, "@"):], ".") {
+		return errEmail
+	}
+	return nil
+}
+
+func ruleURL(value any, param string) error {
+	s, err := text(value, "url")
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.New("must be a valid absolute URL")
+	}
+	return nil
+}
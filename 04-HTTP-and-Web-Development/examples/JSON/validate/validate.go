@@ -0,0 +1,283 @@
+// Package validate 依 struct tag 驗證解碼後的請求內容，並提供解碼 JSON 請求主體的輔助函式。
+//
+//	type User struct {
+//		Name  string   `json:"name" validate:"required,max=64"`
+//		Email string   `json:"email" validate:"email"`
+//		Tags  []string `json:"tags" validate:"max=5"`
+//		Home  Address  `json:"home"` // 巢狀的 struct 會遞迴驗證
+//	}
+//
+// 規則以逗號分隔，參數接在等號之後。欄位是零值 (空字串、0、nil 指標或 slice…) 時，
+// 只檢查 required，其他規則都略過，因此沒有 required 的欄位就是選填的。
+// 巢狀 struct 的欄位即使外層是零值也會被檢查；選填的巢狀 struct 請使用指標。
+// 錯誤以 JSON 名稱組成的路徑標示欄位，例如 "home.zip" 或 "items[2].qty"。
+//
+// 內建規則：
+//
+//	required   不可以是零值
+//	min=N      字串至少 N 個字元、slice/map 至少 N 個項目、數字至少為 N
+//	max=N      同上，但為上限
+//	len=N      字串剛好 N 個字元，或 slice/map 剛好 N 個項目
+//	oneof=a b  值必須是以空白分隔的其中一個
+//	email      合法的電子郵件地址
+//	url        含有 scheme 與 host 的絕對 URL
+//
+// 可以用 Register 加入自訂規則。
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ErrBadRule 表示 tag 中的規則不存在、參數不正確或不適用於欄位的型別。
+// 這是程式的錯誤而不是輸入的錯誤，Struct 會直接回傳它而不是 Errors。
+var ErrBadRule = errors.New("validate: bad rule")
+
+// Rule 檢查一個欄位的值。value 是欄位的值 (指標會先被取值)，param 是等號之後的參數。
+// 回傳的錯誤訊息會成為 FieldError.Message；包裝 ErrBadRule 的錯誤代表規則本身用錯了。
+type Rule func(value any, param string) error
+
+// FieldError 是一個欄位沒有通過的規則。
+type FieldError struct {
+	Field   string `json:"field" xml:"name,attr"`                      // JSON 路徑，例如 "items[2].qty"
+	Rule    string `json:"rule" xml:"rule,attr"`                       // 沒有通過的規則名稱
+	Param   string `json:"param,omitempty" xml:"param,attr,omitempty"` // 規則的參數
+	Message string `json:"message" xml:"message,attr"`                 // 給使用者看的說明
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// Errors 是所有沒有通過驗證的欄位，依欄位在 struct 中的順序排列。
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return "validate: " + strings.Join(msgs, "; ")
+}
+
+// Validator 保存可以使用的規則。零值不能使用，請使用 New。
+type Validator struct {
+	mu     sync.RWMutex
+	rules  map[string]Rule
+	fields sync.Map // reflect.Type → []field
+}
+
+// New 建立一個只有內建規則的 Validator。
+func New() *Validator {
+	v := &Validator{rules: map[string]Rule{}}
+	for name, rule := range builtin {
+		v.rules[name] = rule
+	}
+	return v
+}
+
+// Register 加入 (或取代) 名為 name 的規則。name 不可以是 "required"。
+func (v *Validator) Register(name string, rule Rule) {
+	if name == "" || name == "required" || strings.ContainsAny(name, ",=") {
+		panic(fmt.Sprintf("validate: invalid rule name %q", name))
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = rule
+}
+
+// Struct 驗證 s (struct 或指向 struct 的指標)，沒有問題時回傳 nil，否則回傳 Errors。
+// tag 中的規則有誤時回傳包裝 ErrBadRule 的錯誤。
+func (v *Validator) Struct(s any) error {
+	rv := reflect.ValueOf(s)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: Struct(%T): not a struct", ErrBadRule, s)
+	}
+	var errs Errors
+	if err := v.walk(rv, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+var std = New()
+
+// Register 在預設的 Validator 中加入規則。
+func Register(name string, rule Rule) {
+	std.Register(name, rule)
+}
+
+// Struct 以預設的 Validator 驗證 s。
+func Struct(s any) error {
+	return std.Struct(s)
+}
+
+// field 是一個需要驗證的 struct 欄位。
+type field struct {
+	index    int
+	name     string // JSON 名稱；內嵌 struct 沒有 json 名稱時為空字串
+	embedded bool
+	required bool
+	rules    []ruleRef
+}
+
+type ruleRef struct {
+	name, param string
+}
+
+// fieldsOf 解析並快取 t 的欄位與規則。
+func (v *Validator) fieldsOf(t reflect.Type) []field {
+	if fs, ok := v.fields.Load(t); ok {
+		return fs.([]field)
+	}
+	var fs []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		f := field{index: i, name: name}
+		if name == "" {
+			if sf.Anonymous {
+				f.embedded = true
+			} else {
+				f.name = sf.Name
+			}
+		}
+		if tag := sf.Tag.Get("validate"); tag != "" {
+			for _, r := range strings.Split(tag, ",") {
+				name, param, _ := strings.Cut(strings.TrimSpace(r), "=")
+				switch name {
+				case "", "omitempty":
+				case "required":
+					f.required = true
+				default:
+					f.rules = append(f.rules, ruleRef{name, param})
+				}
+			}
+		}
+		fs = append(fs, f)
+	}
+	v.fields.Store(t, fs)
+	return fs
+}
+
+// walk 驗證 struct rv 的每一個欄位，path 是 rv 本身的路徑。
+func (v *Validator) walk(rv reflect.Value, path string, errs *Errors) error {
+	for _, f := range v.fieldsOf(rv.Type()) {
+		fv := rv.Field(f.index)
+		fpath := join(path, f.name)
+		if f.embedded {
+			fpath = path
+		}
+		if fv.IsZero() {
+			if f.required {
+				*errs = append(*errs, FieldError{Field: fpath, Rule: "required", Message: "is required"})
+				continue
+			}
+			// 非指標的巢狀 struct 即使是零值也要檢查它的 required 欄位；選填的 struct 請使用指標。
+			if fv.Kind() != reflect.Struct {
+				continue
+			}
+		}
+		for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
+		for _, r := range f.rules {
+			if err := v.check(r, fv); err != nil {
+				if errors.Is(err, ErrBadRule) {
+					return fmt.Errorf("%s: %w", fpath, err)
+				}
+				*errs = append(*errs, FieldError{Field: fpath, Rule: r.name, Param: r.param, Message: err.Error()})
+			}
+		}
+		if err := v.dive(fv, fpath, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dive 遞迴驗證巢狀的 struct，以及 slice、array、map 中的 struct 元素。
+func (v *Validator) dive(fv reflect.Value, path string, errs *Errors) error {
+	switch fv.Kind() {
+	case reflect.Struct:
+		if hasExported(fv.Type()) {
+			return v.walk(fv, path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range fv.Len() {
+			if err := v.diveElem(fv.Index(i), fmt.Sprintf("%s[%d]", path, i), errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := fv.MapRange()
+		for iter.Next() {
+			if err := v.diveElem(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *Validator) diveElem(ev reflect.Value, path string, errs *Errors) error {
+	for ev.Kind() == reflect.Pointer || ev.Kind() == reflect.Interface {
+		if ev.IsNil() {
+			return nil
+		}
+		ev = ev.Elem()
+	}
+	if ev.Kind() != reflect.Struct {
+		return nil
+	}
+	return v.walk(ev, path, errs)
+}
+
+// hasExported 回傳 t 是否有匯出的欄位；time.Time 這類只有未匯出欄位的 struct 不需要遞迴。
+func hasExported(t reflect.Type) bool {
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Validator) check(r ruleRef, fv reflect.Value) error {
+	v.mu.RLock()
+	rule, ok := v.rules[r.name]
+	v.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: unknown rule %q", ErrBadRule, r.name)
+	}
+	if !fv.IsValid() || !fv.CanInterface() {
+		return nil
+	}
+	return rule(fv.Interface(), r.param)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
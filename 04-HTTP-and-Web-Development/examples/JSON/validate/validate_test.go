@@ -0,0 +1,148 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"len=5"`
+}
+
+type item struct {
+	SKU string `json:"sku" validate:"required"`
+	Qty int    `json:"qty" validate:"min=1,max=99"`
+}
+
+type Audit struct {
+	CreatedBy string `json:"created_by" validate:"required"`
+}
+
+type order struct {
+	Audit
+	Name     string           `json:"name" validate:"required,min=2,max=8"`
+	Email    string           `json:"email" validate:"email"`
+	Site     string           `json:"site,omitempty" validate:"url"`
+	Status   string           `json:"status" validate:"oneof=new paid shipped"`
+	Nickname *string          `json:"nickname" validate:"min=1"`
+	Tags     []string         `json:"tags" validate:"max=2"`
+	Ship     address          `json:"ship"`
+	Bill     *address         `json:"bill"`
+	Items    []item           `json:"items" validate:"required"`
+	Extra    map[string]*item `json:"extra"`
+	When     time.Time        `json:"when"`
+	Ignored  string           `json:"-" validate:"required"`
+	NoTag    string           `validate:"max=1"`
+	meta     string           // 未匯出的欄位不會被檢查
+}
+
+// fields 回傳 err 中每個欄位錯誤的 "路徑:規則"。
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+	var errs Errors
+	if err != nil && !errors.As(err, &errs) {
+		t.Fatalf("Struct() 錯誤 = %v; 預期為 Errors", err)
+	}
+	var got []string
+	for _, fe := range errs {
+		got = append(got, fe.Field+":"+fe.Rule)
+	}
+	return got
+}
+
+func valid() order {
+	return order{
+		Audit:  Audit{CreatedBy: "admin"},
+		Name:   "gopher",
+		Status: "new",
+		Ship:   address{City: "Taipei", Zip: "10001"},
+		Items:  []item{{SKU: "a", Qty: 1}},
+	}
+}
+
+func TestStruct(t *testing.T) {
+	empty := ""
+	testCases := []struct {
+		name   string
+		modify func(o *order)
+		want   []string
+	}{
+		{"合法", func(o *order) {}, nil},
+		{"選填欄位的零值略過其他規則", func(o *order) { o.Email, o.Status, o.Tags = "", "", nil }, nil},
+		{"必填", func(o *order) { o.Name, o.Items = "", nil }, []string{"name:required", "items:required"}},
+		{"字串長度以字元計算", func(o *order) { o.Name = "地鼠" }, nil},
+		{"過短與過長", func(o *order) { o.Name = "g"; o.Tags = []string{"a", "b", "c"} }, []string{"name:min", "tags:max"}},
+		{"email", func(o *order) { o.Email = "Gopher <g@go.dev>" }, []string{"email:email"}},
+		{"url", func(o *order) { o.Site = "/relative" }, []string{"site:url"}},
+		{"oneof", func(o *order) { o.Status = "lost" }, []string{"status:oneof"}},
+		{"非 nil 指標會被檢查", func(o *order) { o.Nickname = &empty }, []string{"nickname:min"}},
+		{"內嵌 struct 的欄位沒有前綴", func(o *order) { o.CreatedBy = "" }, []string{"created_by:required"}},
+		{"巢狀 struct", func(o *order) { o.Ship.Zip = "123" }, []string{"ship.zip:len"}},
+		{"零值的巢狀 struct 仍檢查必填", func(o *order) { o.Ship = address{} }, []string{"ship.city:required"}},
+		{"指標的巢狀 struct", func(o *order) { o.Bill = &address{Zip: "1"} }, []string{"bill.city:required", "bill.zip:len"}},
+		{"slice 中的 struct", func(o *order) { o.Items = append(o.Items, item{Qty: 100}) },
+			[]string{"items[1].sku:required", "items[1].qty:max"}},
+		{"map 中的 struct", func(o *order) { o.Extra = map[string]*item{"gift": {SKU: "g"}, "nil": nil} }, nil},
+		{"沒有 json tag 時使用欄位名稱", func(o *order) { o.NoTag = "xx" }, []string{"NoTag:max"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := valid()
+			tc.modify(&o)
+			if got := fields(t, Struct(&o)); !slices.Equal(got, tc.want) {
+				t.Errorf("Struct() 錯誤欄位 = %v; 預期為 %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	o := valid()
+	o.Name = "g"
+	o.Tags = []string{"a", "b", "c"}
+	o.Items[0].Qty = 0 // 零值略過 min
+	o.Ship.Zip = "1"
+	err := Struct(o)
+	want := "validate: name must be at least 2 characters; tags must contain at most 2 items; ship.zip must be exactly 5 characters"
+	if err == nil || err.Error() != want {
+		t.Errorf("Error() = %v; 預期為 %q", err, want)
+	}
+}
+
+func TestCustomRule(t *testing.T) {
+	v := New()
+	v.Register("even", func(value any, param string) error {
+		n, ok := value.(int)
+		if !ok {
+			return fmt.Errorf("%w: even needs an int", ErrBadRule)
+		}
+		if n%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+	type pair struct {
+		N int    `json:"n" validate:"even"`
+		S string `json:"s" validate:"even"`
+	}
+
+	if got := fields(t, v.Struct(pair{N: 3})); !slices.Equal(got, []string{"n:even"}) {
+		t.Errorf("Struct(N: 3) = %v; 預期為 [n:even]", got)
+	}
+	// 規則用在不適用的型別上是程式錯誤。
+	if err := v.Struct(pair{N: 2, S: "x"}); !errors.Is(err, ErrBadRule) || !strings.Contains(err.Error(), "s:") {
+		t.Errorf("Struct(S: \"x\") 錯誤 = %v; 預期為欄位 s 的 ErrBadRule", err)
+	}
+	// 預設的 Validator 沒有這個規則。
+	if err := Struct(pair{N: 3}); !errors.Is(err, ErrBadRule) {
+		t.Errorf("預設 Validator 的錯誤 = %v; 預期為 ErrBadRule (未知的規則)", err)
+	}
+	if err := Struct(42); !errors.Is(err, ErrBadRule) {
+		t.Errorf("Struct(42) 錯誤 = %v; 預期為 ErrBadRule", err)
+	}
+}
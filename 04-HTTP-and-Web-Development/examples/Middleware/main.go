@@ -1,10 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
 	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Concurrency-Patterns/ratelimit"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/Config/config"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/Middleware/middleware"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/net-http/server"
 )
 
 // loggingMiddleware 是一個記錄請求日誌的中介軟體
@@ -12,12 +22,17 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	// http.HandlerFunc 是一個轉接器，讓普通函式可以作為 http.Handler 使用
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		log.Printf("Started %s %s", r.Method, r.URL.Path)
+
+		// http.ResponseWriter 沒有提供讀取狀態碼的方法，所以用 Recorder 包裹它，
+		// 記錄下一個處理器寫出的狀態碼與位元組數 (同時保留 Flusher、Hijacker 等介面)
+		rec := middleware.NewRecorder(w)
 
 		// 呼叫鏈中的下一個處理器 (可能是另一個中介軟體，或最終的處理函式)
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(rec, r)
 
-		log.Printf("Completed in %v", time.Since(start))
+		log.Printf("%s %s %d %dB in %v (request %s, client %s)",
+			r.Method, r.URL.Path, rec.Status(), rec.Written(), time.Since(start),
+			middleware.RequestIDFrom(r.Context()), middleware.ClientIP(r))
 	})
 }
 
@@ -26,15 +41,81 @@ func helloHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Hello with Middleware!")
 }
 
+// reportHandler 回應一份夠大的文字，用來觀察壓縮 (gzip；以 go run -tags brotli . 執行時也可以用 br)
+func reportHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, strings.Repeat("All systems operational.\n", 200))
+}
+
+// slowHandler 等待的時間超過請求的期限，Timeout 中介軟體會回應 503
+func slowHandler(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-time.After(5 * time.Second):
+		fmt.Fprint(w, "done")
+	case <-r.Context().Done():
+		// 期限到了就放棄工作；這裡不寫出回應，交給 Timeout 回應 503
+	}
+}
+
+// panicHandler 模擬程式錯誤，Recover 中介軟體會把它轉成 500 並記錄堆疊
+func panicHandler(w http.ResponseWriter, r *http.Request) {
+	var m map[string]int
+	m["boom"]++
+}
+
 func main() {
-	// 建立一個最終的處理器
-	finalHandler := http.HandlerFunc(helloHandler)
+	cfg, err := config.LoadListen("APP", os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", helloHandler)
+	mux.HandleFunc("/report", reportHandler)
+	mux.HandleFunc("/slow", slowHandler)
+	mux.HandleFunc("/panic", panicHandler)
 
-	// 使用 loggingMiddleware 包裹最終的處理器
-	http.Handle("/hello", loggingMiddleware(finalHandler))
+	// 每個 IP 平均每秒 5 個請求，最多突發 10 個；閒置 10 分鐘的 IP 會被清除
+	limiters := ratelimit.NewKeyed[string](func() *ratelimit.Limiter {
+		return ratelimit.NewTokenBucket(200*time.Millisecond, 10)
+	}, 10*time.Minute)
+	rateLimit := ratelimit.Middleware(limiters, ratelimit.ByIP)
 
-	fmt.Println("Server starting on http://localhost:8080")
-	if err := http.ListenAndServe(":8080", nil); err != nil {
-		log.Fatal("ListenAndServe: ", err)
+	// Chain 的第一個中介軟體在最外層：
+	//   - RealIP 放在最前面，之後的日誌與限流看到的都是真實的用戶端 IP (這裡信任本機的反向 Proxy)
+	//   - RequestID 要在日誌之前，日誌才能記錄 ID
+	//   - loggingMiddleware 在 Recover 之外，才能記錄到 panic 轉成的 500
+	//   - 被限流的請求會得到 429 與 Retry-After 標頭，仍然會被記錄在日誌中
+	handler := middleware.Chain(
+		middleware.RealIP(netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")),
+		middleware.RequestID,
+		loggingMiddleware,
+		middleware.Recover,
+		middleware.CORS(middleware.CORSOptions{
+			AllowedOrigins: []string{"http://localhost:3000"},
+			ExposedHeaders: []string{middleware.RequestIDHeader},
+			MaxAge:         time.Hour,
+		}),
+		rateLimit,
+		middleware.Compress(),
+		middleware.Timeout(2*time.Second),
+	)(mux)
+
+	// server 套用讀寫逾時，並在 Ctrl+C 時等待處理中的請求完成
+	srv := server.New()
+	if _, err := srv.Listen("http", cfg.Addr, handler); err != nil {
+		log.Fatal(err)
+	}
+	base := srv.URL("http")
+	fmt.Println("Server starting on " + cfg.Addr)
+	fmt.Println("Try: curl -i " + base + "/hello")
+	fmt.Println("     curl -i --compressed " + base + "/report")
+	if slices.ContainsFunc(middleware.DefaultEncodings, func(e middleware.Encoding) bool { return e.Name == "br" }) {
+		fmt.Println("     curl -i -H 'Accept-Encoding: br' " + base + "/report")
+	}
+	fmt.Println("     curl -i " + base + "/slow   (503 after 2s)")
+	fmt.Println("     curl -i " + base + "/panic  (500, stack trace in the log)")
+	if err := srv.Run(context.Background()); err != nil {
+		log.Fatal(err)
 	}
 }
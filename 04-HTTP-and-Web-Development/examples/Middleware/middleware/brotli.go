@@ -0,0 +1,18 @@
+//go:build brotli
+
+package middleware
+
+import (
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Brotli 是 "br" 編碼，只有以 brotli build tag 編譯時才存在 (需要 github.com/andybalholm/brotli)。
+// 文字內容壓縮後通常比 gzip 小 15% 到 25%，所有主流瀏覽器在 HTTPS 下都支援。
+var Brotli = Encoding{Name: "br", NewWriter: func(w io.Writer) io.WriteCloser {
+	return brotli.NewWriter(w)
+}}
+
+// DefaultEncodings 是 Compress 預設支援的編碼：用戶端同樣可接受時優先使用 Brotli。
+var DefaultEncodings = []Encoding{Brotli, Gzip}
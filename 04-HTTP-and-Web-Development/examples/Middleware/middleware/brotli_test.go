@@ -0,0 +1,46 @@
+//go:build brotli
+
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func TestCompressBrotli(t *testing.T) {
+	body := strings.Repeat("hello brotli ", 200)
+	h := Compress()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, body)
+	}))
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"gzip, br", "br"},
+		{"br;q=0.5, gzip", "gzip"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", tt.accept)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Content-Encoding"); got != tt.want {
+			t.Errorf("Accept-Encoding %q: Content-Encoding = %q; 預期為 %q", tt.accept, got, tt.want)
+			continue
+		}
+		if tt.want != "br" {
+			continue
+		}
+		got, err := io.ReadAll(brotli.NewReader(rec.Body))
+		if err != nil || string(got) != body {
+			t.Errorf("解壓縮後的主體長度 = %d, %v; 預期為 %d", len(got), err, len(body))
+		}
+	}
+}
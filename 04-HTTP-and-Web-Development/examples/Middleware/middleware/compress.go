@@ -0,0 +1,251 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Encoding 是一種 Content-Encoding。
+//
+// 標準函式庫只有 gzip 與 deflate，所以預設只內建 Gzip。以 brotli build tag 編譯時
+// (go build -tags brotli，需要 github.com/andybalholm/brotli) 會另外提供 Brotli，
+// 並加入 DefaultEncodings。其他編碼可以自行建立 Encoding，放在 gzip 前面表示同樣可接受時優先使用：
+//
+//	zstd := middleware.Encoding{Name: "zstd", NewWriter: newZstdWriter}
+//	middleware.Compress(middleware.WithEncodings(zstd, middleware.Gzip))
+type Encoding struct {
+	// Name 是 Accept-Encoding 與 Content-Encoding 中的名稱，例如 "gzip" 或 "br"。
+	Name string
+	// NewWriter 回傳把壓縮後的資料寫入 w 的 Writer。Writer 如果有 Flush() error 方法，
+	// Flush 時會呼叫它，讓串流的回應可以即時送出。
+	NewWriter func(w io.Writer) io.WriteCloser
+}
+
+// Gzip 是 "gzip" 編碼，永遠可以使用。
+var Gzip = Encoding{Name: "gzip", NewWriter: func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }}
+
+// DefaultCompressTypes 是預設會壓縮的 Content-Type。以 "/" 結尾的項目比對整個主要型別；
+// 另外 +json 與 +xml 結尾的型別 (例如 application/problem+json) 也會壓縮。
+// 圖片、影片與壓縮檔已經是壓縮過的格式，再壓縮只會浪費 CPU。
+var DefaultCompressTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/wasm",
+	"image/svg+xml",
+}
+
+// CompressOption 設定 Compress。
+type CompressOption func(*compressConfig)
+
+type compressConfig struct {
+	encodings []Encoding
+	minSize   int
+	types     []string
+}
+
+// WithEncodings 設定支援的編碼，順序就是用戶端同樣可接受時的偏好，預設為 DefaultEncodings。
+func WithEncodings(encodings ...Encoding) CompressOption {
+	return func(c *compressConfig) { c.encodings = encodings }
+}
+
+// WithMinSize 設定壓縮的最小主體大小 (預設 1024 位元組)；更小的回應壓縮後的節省不值得 CPU 與標頭的成本。
+func WithMinSize(n int) CompressOption {
+	return func(c *compressConfig) { c.minSize = n }
+}
+
+// WithTypes 設定要壓縮的 Content-Type，格式同 DefaultCompressTypes。
+func WithTypes(types ...string) CompressOption {
+	return func(c *compressConfig) { c.types = types }
+}
+
+// Compress 回傳依 Accept-Encoding 壓縮回應的中介軟體。
+//
+// 回應會先緩衝到 MinSize 位元組才決定是否壓縮：Content-Type (沒有設定時從內容判斷) 不在清單中、
+// 已經有 Content-Encoding、狀態碼沒有主體 (204、304)，或主體小於 MinSize 時不壓縮。
+// handler 呼叫 Flush 時會立即決定，之後每次 Flush 都會把已壓縮的資料送出，所以串流仍然可以使用。
+// HEAD 與帶有 Range 的請求不壓縮，因為長度與位移都是以未壓縮的內容計算的。
+func Compress(opts ...CompressOption) Middleware {
+	cfg := compressConfig{encodings: DefaultEncodings, minSize: 1024, types: DefaultCompressTypes}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 回應會因 Accept-Encoding 而不同，快取必須把它納入鍵值。
+			w.Header().Add("Vary", "Accept-Encoding")
+			enc, ok := selectEncoding(r.Header.Get("Accept-Encoding"), cfg.encodings)
+			if !ok || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, cfg: &cfg, enc: enc}
+			next.ServeHTTP(cw, r)
+			// 不使用 defer：handler panic 時丟棄緩衝的主體，讓外層的 Recover 還能回應 500。
+			cw.close()
+		})
+	}
+}
+
+// selectEncoding 依 Accept-Encoding 的 q 值選出編碼，同分時依 encodings 的順序。
+func selectEncoding(accept string, encodings []Encoding) (Encoding, bool) {
+	qs := map[string]float64{}
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		qs[name] = q
+	}
+	var best Encoding
+	bestQ := 0.0
+	for _, enc := range encodings {
+		q, ok := qs[enc.Name]
+		if !ok {
+			q = qs["*"]
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// compressWriter 緩衝回應的開頭，決定之後直接寫出 (passthrough) 或經過壓縮。
+type compressWriter struct {
+	http.ResponseWriter
+	cfg *compressConfig
+	enc Encoding
+
+	status  int    // handler 寫出的狀態碼，在決定之前先保留
+	buf     []byte // 決定之前的主體
+	decided bool
+	zw      io.WriteCloser // 壓縮時不為 nil
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if cw.status != 0 {
+		return
+	}
+	cw.status = code
+	// 不會有主體的回應不需要等待主體。
+	if code == http.StatusNoContent || code == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.zw != nil {
+			return cw.zw.Write(b)
+		}
+		return cw.ResponseWriter.Write(b)
+	}
+	cw.buf = append(cw.buf, b...)
+	// handler 已經自行編碼時 decide 不會再壓縮，不需要繼續緩衝。
+	if len(cw.buf) >= cw.cfg.minSize || cw.Header().Get("Content-Encoding") != "" {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// decide 送出標頭與緩衝的主體；large 表示主體夠大，值得壓縮。
+func (cw *compressWriter) decide(large bool) error {
+	cw.decided = true
+	h := cw.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		// 與 net/http 相同，從內容判斷型別；必須在這裡設定，之後的 Write 看到的是壓縮後的資料。
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	if large && h.Get("Content-Encoding") == "" && cw.status != http.StatusNoContent &&
+		cw.status != http.StatusNotModified && compressible(h.Get("Content-Type"), cw.cfg.types) {
+		h.Set("Content-Encoding", cw.enc.Name)
+		h.Del("Content-Length")
+		cw.ResponseWriter.WriteHeader(cw.status)
+		cw.zw = cw.enc.NewWriter(cw.ResponseWriter)
+		_, err := cw.zw.Write(cw.buf)
+		cw.buf = nil
+		return err
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	_, err := cw.ResponseWriter.Write(cw.buf)
+	cw.buf = nil
+	return err
+}
+
+// Flush 實作 http.Flusher：立即決定是否壓縮，並把已壓縮的資料送出。
+func (cw *compressWriter) Flush() {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.decided {
+		cw.decide(true)
+	}
+	if f, ok := cw.zw.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Hijack 實作 http.Hijacker。
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(cw.ResponseWriter).Hijack()
+}
+
+// Unwrap 回傳被包裹的 ResponseWriter，供 http.ResponseController 使用。
+func (cw *compressWriter) Unwrap() http.ResponseWriter { return cw.ResponseWriter }
+
+// close 在 handler 返回後送出還在緩衝中的主體，並結束壓縮串流。
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		if cw.status == 0 {
+			// handler 沒有寫出任何東西，交給 net/http 回應 200。
+			return
+		}
+		cw.decide(false)
+	}
+	if cw.zw != nil {
+		cw.zw.Close()
+	}
+}
+
+func compressible(contentType string, types []string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasSuffix(mt, "+json") || strings.HasSuffix(mt, "+xml") {
+		return true
+	}
+	return slices.ContainsFunc(types, func(t string) bool {
+		if strings.HasSuffix(t, "/") {
+			return strings.HasPrefix(mt, t)
+		}
+		return mt == t
+	})
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSelectEncoding(t *testing.T) {
+	br := Encoding{Name: "br"}
+	encodings := []Encoding{br, Gzip}
+	tests := []struct {
+		accept string
+		want   string // 空字串表示不壓縮
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"gzip, br", "br"},
+		{"gzip;q=1.0, br;q=0.5", "gzip"},
+		{"*", "br"},
+		{"br;q=0, *", "gzip"},
+		{"gzip;q=0", ""},
+		{"identity", ""},
+		{"deflate, gzip;q=abc", ""},
+	}
+	for _, tt := range tests {
+		enc, ok := selectEncoding(tt.accept, encodings)
+		if !ok {
+			enc.Name = ""
+		}
+		if enc.Name != tt.want {
+			t.Errorf("selectEncoding(%q) = %q; 預期為 %q", tt.accept, enc.Name, tt.want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("hello, compression! ", 100)
+	tests := []struct {
+		name         string
+		method       string
+		accept       string
+		range_       string
+		handler      http.HandlerFunc
+		wantEncoding string
+		wantType     string
+	}{
+		{"大的文字回應", "GET", "gzip", "", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "2000")
+			io.WriteString(w, large)
+		}, "gzip", "text/plain; charset=utf-8"},
+		{"分成多次寫入", "GET", "gzip", "", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			for range 100 {
+				io.WriteString(w, `"hello, compression!"`)
+			}
+		}, "gzip", "application/json"},
+		{"+json 型別", "GET", "gzip", "", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/problem+json")
+			io.WriteString(w, large)
+		}, "gzip", "application/problem+json"},
+		{"小的回應", "GET", "gzip", "", func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "tiny")
+		}, "", "text/plain; charset=utf-8"},
+		{"不壓縮的型別", "GET", "gzip", "", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, large)
+		}, "", "image/png"},
+		{"用戶端不接受", "GET", "", "", func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, large)
+		}, "", "text/plain; charset=utf-8"},
+		{"已經編碼", "GET", "gzip", "", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "identity")
+			io.WriteString(w, large)
+		}, "identity", "text/plain; charset=utf-8"},
+		{"Range 請求", "GET", "gzip", "bytes=0-9", func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, large)
+		}, "", "text/plain; charset=utf-8"},
+		{"HEAD", "HEAD", "gzip", "", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+		}, "", "text/plain"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept-Encoding", tt.accept)
+		}
+		if tt.range_ != "" {
+			r.Header.Set("Range", tt.range_)
+		}
+		w := httptest.NewRecorder()
+		Compress()(tt.handler).ServeHTTP(w, r)
+
+		if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+			t.Errorf("%s: Content-Encoding = %q; 預期為 %q", tt.name, got, tt.wantEncoding)
+		}
+		if got := w.Header().Get("Content-Type"); got != tt.wantType {
+			t.Errorf("%s: Content-Type = %q; 預期為 %q", tt.name, got, tt.wantType)
+		}
+		if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("%s: Vary = %q; 預期為 Accept-Encoding", tt.name, got)
+		}
+		if tt.wantEncoding != "gzip" {
+			continue
+		}
+		if w.Header().Get("Content-Length") != "" {
+			t.Errorf("%s: 壓縮後不應該有 Content-Length", tt.name)
+		}
+		compressed := w.Body.Len()
+		if body := gunzip(t, w.Body); len(body) < len(large) || !strings.Contains(body, "hello, compression!") {
+			t.Errorf("%s: 解壓縮後 = %q", tt.name, body)
+		}
+		if compressed >= len(large) {
+			t.Errorf("%s: 壓縮後 %d 位元組; 預期小於 %d", tt.name, compressed, len(large))
+		}
+	}
+}
+
+func gunzip(t *testing.T, r io.Reader) string {
+	t.Helper()
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("gzip.NewReader error = %v", err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("讀取 gzip 主體 error = %v", err)
+	}
+	return string(b)
+}
+
+func TestCompressStatusWithoutBody(t *testing.T) {
+	for _, code := range []int{http.StatusNoContent, http.StatusNotModified, http.StatusCreated} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		Compress()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(code)
+		})).ServeHTTP(w, r)
+		if w.Code != code || w.Header().Get("Content-Encoding") != "" || w.Body.Len() != 0 {
+			t.Errorf("WriteHeader(%d): 狀態碼 %d, Content-Encoding %q, 主體 %d 位元組; 預期沒有主體也不壓縮",
+				code, w.Code, w.Header().Get("Content-Encoding"), w.Body.Len())
+		}
+	}
+}
+
+func TestCompressFlush(t *testing.T) {
+	// 串流的回應：每次 Flush 後用戶端都能讀到目前為止的事件。
+	next := make(chan struct{})
+	srv := httptest.NewServer(Compress()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := range 3 {
+			io.WriteString(w, "data: event\n\n")
+			w.(http.Flusher).Flush()
+			if i < 2 {
+				<-next
+			}
+		}
+	})))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip") // 自行設定時 Transport 不會自動解壓縮
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q; 預期為 gzip", resp.Header.Get("Content-Encoding"))
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, len("data: event\n\n"))
+	for i := range 3 {
+		if _, err := io.ReadFull(zr, buf); err != nil {
+			t.Fatalf("第 %d 個事件: %v", i, err)
+		}
+		if i < 2 {
+			next <- struct{}{}
+		}
+	}
+}
+
+func TestCompressWithRecover(t *testing.T) {
+	captureLog(t)
+	// handler 寫出一部分 (仍在緩衝中) 後 panic：Recover 仍然可以回應 500。
+	h := Chain(Recover, Compress())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		panic("boom")
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "partial") {
+		t.Errorf("狀態碼 = %d, 主體 %q; 預期為 500 且不含部分的主體", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions 設定 CORS 允許的跨來源請求。
+type CORSOptions struct {
+	// AllowedOrigins 是允許的來源，例如 "https://example.com"。
+	// "*" 允許所有來源；"https://*.example.com" 允許 example.com 的所有子網域。
+	AllowedOrigins []string
+	// AllowedMethods 是預檢請求允許的方法，預設為 GET、HEAD 與 POST。
+	AllowedMethods []string
+	// AllowedHeaders 是預檢請求允許的請求標頭；空的時候允許預檢請求列出的所有標頭。
+	AllowedHeaders []string
+	// ExposedHeaders 是瀏覽器允許 JavaScript 讀取的回應標頭 (預設只有少數幾個)。
+	ExposedHeaders []string
+	// AllowCredentials 允許請求帶有 Cookie 等憑證。這時 "*" 會改為回應請求的來源，
+	// 因為瀏覽器不接受憑證請求的 Access-Control-Allow-Origin 為 "*"。
+	AllowCredentials bool
+	// MaxAge 是瀏覽器可以快取預檢結果的時間，0 表示使用瀏覽器的預設值。
+	MaxAge time.Duration
+}
+
+// CORS 回傳處理跨來源資源共用 (Cross-Origin Resource Sharing) 的中介軟體。
+//
+// 來源允許時，一般請求的回應會加上 Access-Control-Allow-Origin 等標頭再交給 next；
+// 預檢請求 (帶有 Access-Control-Request-Method 的 OPTIONS) 由 CORS 直接回應 204，
+// 不會交給 next。來源不允許時不加任何 CORS 標頭，由瀏覽器拒絕讓 JavaScript 讀取回應。
+//
+// CORS 應該放在路由之前，否則沒有註冊 OPTIONS 的路徑無法通過預檢。
+func CORS(opts CORSOptions) Middleware {
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(opts.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := ""
+	if opts.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(opts.MaxAge.Seconds()))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			h := w.Header()
+			// 回應會因 Origin 而不同，快取必須把它納入鍵值。
+			h.Add("Vary", "Origin")
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			allowed, wildcard := matchOrigin(opts.AllowedOrigins, origin)
+			if origin == "" || !allowed {
+				if preflight {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if wildcard && !opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposeHeaders != "" {
+					h.Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !slices.Contains(methods, r.Header.Get("Access-Control-Request-Method")) {
+				// 不列出 Allow-Methods，瀏覽器就會拒絕實際的請求。
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			h.Set("Access-Control-Allow-Methods", allowMethods)
+			if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				if allowHeaders == "" {
+					h.Set("Access-Control-Allow-Headers", requested)
+				} else {
+					h.Set("Access-Control-Allow-Headers", allowHeaders)
+				}
+			}
+			if maxAge != "" {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// matchOrigin 回報 origin 是否被允許，以及是否因為 "*" 而允許。
+func matchOrigin(allowed []string, origin string) (ok, wildcard bool) {
+	for _, a := range allowed {
+		if a == "*" {
+			return true, true
+		}
+		if strings.EqualFold(a, origin) {
+			return true, false
+		}
+		// "https://*.example.com" 比對 "https://api.example.com"，但不比對 "https://example.com"。
+		if prefix, suffix, ok := strings.Cut(a, "*"); ok && len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(strings.ToLower(origin), strings.ToLower(prefix)) &&
+			strings.HasSuffix(strings.ToLower(origin), strings.ToLower(suffix)) {
+			return true, false
+		}
+	}
+	return false, false
+}
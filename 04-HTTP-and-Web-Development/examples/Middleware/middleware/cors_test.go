@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	opts := CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods: []string{"GET", "PUT", "DELETE"},
+		ExposedHeaders: []string{"X-Request-ID"},
+		MaxAge:         10 * time.Minute,
+	}
+	tests := []struct {
+		name        string
+		opts        CORSOptions
+		method      string
+		origin      string
+		reqMethod   string // Access-Control-Request-Method
+		reqHeaders  string // Access-Control-Request-Headers
+		wantCode    int
+		wantNext    bool
+		wantHeaders map[string]string // 空字串表示不應該出現
+	}{
+		{"同源請求", opts, "GET", "", "", "", 200, true, map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}},
+		{"允許的來源", opts, "GET", "https://app.example.com", "", "", 200, true, map[string]string{
+			"Access-Control-Allow-Origin":   "https://app.example.com",
+			"Access-Control-Expose-Headers": "X-Request-ID",
+			"Vary":                          "Origin",
+		}},
+		{"子網域萬用字元", opts, "GET", "https://api.example.org", "", "", 200, true, map[string]string{
+			"Access-Control-Allow-Origin": "https://api.example.org",
+		}},
+		{"萬用字元不比對上層網域", opts, "GET", "https://example.org", "", "", 200, true, map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}},
+		{"不允許的來源", opts, "GET", "https://evil.com", "", "", 200, true, map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}},
+		{"預檢", opts, "OPTIONS", "https://app.example.com", "PUT", "Content-Type, X-Token", 204, false, map[string]string{
+			"Access-Control-Allow-Origin":  "https://app.example.com",
+			"Access-Control-Allow-Methods": "GET, PUT, DELETE",
+			"Access-Control-Allow-Headers": "Content-Type, X-Token",
+			"Access-Control-Max-Age":       "600",
+		}},
+		{"預檢不允許的方法", opts, "OPTIONS", "https://app.example.com", "PATCH", "", 204, false, map[string]string{
+			"Access-Control-Allow-Methods": "",
+		}},
+		{"預檢不允許的來源", opts, "OPTIONS", "https://evil.com", "PUT", "", 204, false, map[string]string{
+			"Access-Control-Allow-Origin":  "",
+			"Access-Control-Allow-Methods": "",
+		}},
+		{"不是預檢的 OPTIONS", opts, "OPTIONS", "https://app.example.com", "", "", 200, true, nil},
+		{"限制的請求標頭", CORSOptions{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"Content-Type"}},
+			"OPTIONS", "https://a.test", "POST", "X-Token", 204, false, map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Headers": "Content-Type",
+				"Access-Control-Allow-Methods": "GET, HEAD, POST",
+				"Access-Control-Max-Age":       "",
+			}},
+		{"憑證不能使用 *", CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			"GET", "https://a.test", "", "", 200, true, map[string]string{
+				"Access-Control-Allow-Origin":      "https://a.test",
+				"Access-Control-Allow-Credentials": "true",
+			}},
+	}
+	for _, tt := range tests {
+		called := false
+		h := CORS(tt.opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+		}))
+		r := httptest.NewRequest(tt.method, "/", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if tt.reqMethod != "" {
+			r.Header.Set("Access-Control-Request-Method", tt.reqMethod)
+		}
+		if tt.reqHeaders != "" {
+			r.Header.Set("Access-Control-Request-Headers", tt.reqHeaders)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.wantCode || called != tt.wantNext {
+			t.Errorf("%s: 狀態碼 = %d, 呼叫 next = %v; 預期為 %d, %v", tt.name, w.Code, called, tt.wantCode, tt.wantNext)
+		}
+		for k, want := range tt.wantHeaders {
+			if got := w.Header().Get(k); got != want {
+				t.Errorf("%s: %s = %q; 預期為 %q", tt.name, k, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package middleware 提供常用的 net/http 中介軟體：
+//
+//   - Recorder：包裹 http.ResponseWriter，記錄狀態碼與寫入的位元組數，同時保留 Flusher、Hijacker 與 io.ReaderFrom。
+//   - RequestID：為每個請求配置 ID，放在 context 與回應標頭中。
+//   - Recover：把 handler 的 panic 轉成 500，並記錄堆疊。
+//   - CORS：處理跨來源請求與預檢 (preflight)。
+//   - Compress：依 Accept-Encoding 壓縮回應。預設只有 gzip；以 brotli build tag 編譯時也支援 br。
+//   - Timeout：為每個請求的 context 加上期限。
+//   - RealIP：從受信任的 Proxy 轉送的標頭取得用戶端的真實 IP。
+//
+// 以 Chain 組合，第一個中介軟體在最外層：
+//
+//	handler := middleware.Chain(
+//		middleware.RequestID,
+//		middleware.Recover,
+//		middleware.Compress(),
+//	)(mux)
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"slices"
+)
+
+// Middleware 包裹一個 http.Handler，在它之前或之後做額外的處理。
+// 它是型別別名，所以可以直接交給 router.Group.Use 或 ratelimit.Middleware 等其他套件使用。
+type Middleware = func(http.Handler) http.Handler
+
+// Chain 把多個中介軟體組合成一個；mws[0] 在最外層，最先看到請求，也最後看到回應。
+func Chain(mws ...Middleware) Middleware {
+	return func(h http.Handler) http.Handler {
+		for _, mw := range slices.Backward(mws) {
+			h = mw(h)
+		}
+		return h
+	}
+}
+
+// RequestIDHeader 是 RequestID 讀取與設定的標頭。
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestID 為每個請求配置 ID：沿用請求中合法的 X-Request-ID (例如上游的 Proxy 已經配置的 ID)，
+// 否則產生一個隨機的 ID。ID 會放在 context 中 (以 RequestIDFrom 取得) 並設定在回應的 X-Request-ID 標頭。
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestIDFrom 回傳 RequestID 放在 ctx 中的 ID，沒有時回傳空字串。
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID 只接受長度合理的可見 ASCII 字元，避免用戶端把任意內容注入日誌。
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := range len(id) {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name+">")
+				next.ServeHTTP(w, r)
+				order = append(order, "<"+name)
+			})
+		}
+	}
+	h := Chain(tag("a"), tag("b"), tag("c"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	want := "a> b> c> handler <c <b <a"
+	if got := strings.Join(order, " "); got != want {
+		t.Errorf("順序 = %s; 預期為 %s", got, want)
+	}
+
+	// 沒有中介軟體時回傳原本的 handler。
+	Chain()(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFrom(r.Context())
+	}))
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"沒有 ID", "", false},
+		{"沿用上游的 ID", "abc-123", true},
+		{"含有空白", "abc 123", false},
+		{"含有換行", "abc\n123", false},
+		{"太長", strings.Repeat("a", 129), false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.incoming != "" {
+			r.Header.Set(RequestIDHeader, tt.incoming)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		got := w.Header().Get(RequestIDHeader)
+		if got != seen {
+			t.Errorf("%s: 標頭 %q 與 context %q 不同", tt.name, got, seen)
+		}
+		if tt.keep && got != tt.incoming {
+			t.Errorf("%s: ID = %q; 預期為 %q", tt.name, got, tt.incoming)
+		}
+		if !tt.keep && (got == tt.incoming || len(got) != 32) {
+			t.Errorf("%s: ID = %q; 預期為新的 32 字元 ID", tt.name, got)
+		}
+	}
+	if RequestIDFrom(context.Background()) != "" {
+		t.Error("RequestIDFrom(沒有 ID 的 context) 預期為空字串")
+	}
+}
+
+// captureLog 把 log 的輸出導向緩衝區，測試結束時還原。
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+func TestRecover(t *testing.T) {
+	logs := captureLog(t)
+	h := Chain(RequestID, Recover)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/explode", nil)
+	r.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("狀態碼 = %d; 預期為 500", w.Code)
+	}
+	for _, want := range []string{"panic serving GET /explode", `"req-1"`, "boom", "middleware_test.go"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("日誌 = %q; 預期包含 %q", logs, want)
+		}
+	}
+}
+
+func TestRecoverAfterWrite(t *testing.T) {
+	captureLog(t)
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		panic("boom")
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recover() = %v; 預期為 http.ErrAbortHandler", v)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestRecoverKeepsAbortHandler(t *testing.T) {
+	logs := captureLog(t)
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recover() = %v; 預期為 http.ErrAbortHandler", v)
+		}
+		if logs.Len() != 0 {
+			t.Errorf("日誌 = %q; 預期 ErrAbortHandler 不被記錄", logs)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		wantCode int
+	}{
+		{"在期限內完成", func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, "fast")
+		}, http.StatusOK},
+		{"超過期限且沒有回應", func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}, http.StatusServiceUnavailable},
+		{"超過期限但已經回應", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			<-r.Context().Done()
+		}, http.StatusAccepted},
+		{"handler 自行處理逾時", func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+			if errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+				http.Error(w, "slow database", http.StatusGatewayTimeout)
+			}
+		}, http.StatusGatewayTimeout},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		Timeout(20*time.Millisecond)(tt.handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: 狀態碼 = %d; 預期為 %d", tt.name, w.Code, tt.wantCode)
+		}
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		xRealIP    string
+		want       string
+	}{
+		{"沒有 Proxy", "203.0.113.7:5000", nil, "", "203.0.113.7"},
+		{"不受信任的連線不能偽造", "203.0.113.7:5000", []string{"1.2.3.4"}, "5.6.7.8", "203.0.113.7"},
+		{"經過一個 Proxy", "10.0.0.1:80", []string{"198.51.100.2"}, "", "198.51.100.2"},
+		{"略過受信任的 Proxy", "10.0.0.1:80", []string{"1.2.3.4, 198.51.100.2, 10.0.0.9"}, "", "198.51.100.2"},
+		{"多個標頭依序串起來", "10.0.0.1:80", []string{"1.2.3.4", "198.51.100.2"}, "", "198.51.100.2"},
+		{"全部都是 Proxy", "10.0.0.1:80", []string{"10.1.1.1, 10.0.0.9"}, "", "10.1.1.1"},
+		{"無法解析的位址", "10.0.0.1:80", []string{"1.2.3.4, garbage"}, "", "10.0.0.1"},
+		{"X-Real-IP", "[::1]:80", nil, "2001:db8::1", "2001:db8::1"},
+		{"IPv4 對應的 IPv6", "[::ffff:10.0.0.1]:80", []string{"198.51.100.2"}, "", "198.51.100.2"},
+	}
+	for _, tt := range tests {
+		var got netip.Addr
+		var remote string
+		h := RealIP(trusted...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, remote = ClientIP(r), r.RemoteAddr
+		}))
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		for _, v := range tt.xff {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+		if tt.xRealIP != "" {
+			r.Header.Set("X-Real-IP", tt.xRealIP)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		if got.String() != tt.want {
+			t.Errorf("%s: ClientIP = %s; 預期為 %s", tt.name, got, tt.want)
+		}
+		// RemoteAddr 只有在採用轉送的位址時才會被取代。
+		if rewritten := netip.AddrPortFrom(netip.MustParseAddr(tt.want), 0).String(); remote != tt.remoteAddr && remote != rewritten {
+			t.Errorf("%s: RemoteAddr = %s; 預期為 %s 或 %s", tt.name, remote, tt.remoteAddr, rewritten)
+		}
+	}
+}
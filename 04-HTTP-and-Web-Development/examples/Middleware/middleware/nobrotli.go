@@ -0,0 +1,7 @@
+//go:build !brotli
+
+package middleware
+
+// DefaultEncodings 是 Compress 預設支援的編碼。標準函式庫沒有 brotli，
+// 以 brotli build tag 編譯時才會加入 Brotli (見 brotli.go)。
+var DefaultEncodings = []Encoding{Gzip}
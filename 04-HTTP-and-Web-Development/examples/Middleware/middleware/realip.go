@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// RealIP 從受信任的 Proxy 轉送的標頭取得用戶端的 IP，並以它取代 r.RemoteAddr
+// (埠號為 0)，讓之後以 RemoteAddr 限流或記錄的程式碼 (例如 ratelimit.ByIP) 不必修改。
+//
+// X-Forwarded-For 與 X-Real-IP 可以由用戶端任意設定，所以只有直接連線的位址
+// 屬於 trusted 時才會讀取。X-Forwarded-For 從右往左讀，每經過一個 Proxy 就多一個位址，
+// 第一個不屬於 trusted 的位址就是用戶端；沒有 X-Forwarded-For 時使用 X-Real-IP。
+//
+//	middleware.RealIP(netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("::1/128"))
+//
+// 沒有 trusted 時 RealIP 不讀取任何標頭，只把位址記錄下來供 ClientIP 使用。
+func RealIP(trusted ...netip.Prefix) Middleware {
+	isTrusted := func(addr netip.Addr) bool {
+		return slices.ContainsFunc(trusted, func(p netip.Prefix) bool { return p.Contains(addr) })
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip, err := remoteIP(r.RemoteAddr)
+			if err == nil && isTrusted(ip) {
+				if client, ok := forwardedIP(r.Header, isTrusted); ok {
+					ip = client
+					r = r.Clone(r.Context())
+					r.RemoteAddr = netip.AddrPortFrom(ip, 0).String()
+				}
+			}
+			if ip.IsValid() {
+				r = r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIP 回傳 RealIP 判斷的用戶端 IP；沒有經過 RealIP 時從 r.RemoteAddr 解析，無法解析時回傳零值。
+func ClientIP(r *http.Request) netip.Addr {
+	if ip, ok := r.Context().Value(clientIPKey{}).(netip.Addr); ok {
+		return ip
+	}
+	ip, _ := remoteIP(r.RemoteAddr)
+	return ip
+}
+
+func remoteIP(remoteAddr string) (netip.Addr, error) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip, err := netip.ParseAddr(host)
+	// IPv4 對應的 IPv6 位址 (::ffff:1.2.3.4) 換回 IPv4，才能與 IPv4 的 Prefix 比對。
+	return ip.Unmap(), err
+}
+
+func forwardedIP(h http.Header, isTrusted func(netip.Addr) bool) (netip.Addr, bool) {
+	// 同一個標頭可能出現多次，依序串起來就是完整的清單。
+	var hops []string
+	for _, v := range h.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	for _, hop := range slices.Backward(hops) {
+		ip, err := remoteIP(strings.TrimSpace(hop))
+		if err != nil {
+			// 無法解析的位址不能相信，它左邊的位址也一樣。
+			return netip.Addr{}, false
+		}
+		if !isTrusted(ip) {
+			return ip, true
+		}
+	}
+	if len(hops) > 0 {
+		// 清單中全部都是受信任的 Proxy，最左邊的就是最早的來源。
+		ip, _ := remoteIP(strings.TrimSpace(hops[0]))
+		return ip, true
+	}
+	if ip, err := remoteIP(strings.TrimSpace(h.Get("X-Real-IP"))); err == nil {
+		return ip, true
+	}
+	return netip.Addr{}, false
+}
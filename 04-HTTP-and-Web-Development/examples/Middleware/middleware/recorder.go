@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// Recorder 包裹 http.ResponseWriter，記錄 handler 寫出的狀態碼與主體的位元組數，
+// 讓日誌等中介軟體在 handler 返回之後取得這些資訊。
+//
+// 包裹 ResponseWriter 最常見的問題是遺失原本支援的介面：串流需要 http.Flusher，
+// WebSocket 需要 http.Hijacker，http.ServeFile 等使用 io.ReaderFrom 做零複製傳送。
+// Recorder 實作這三個介面並轉交給被包裹的 ResponseWriter；不支援時 Flush 不做任何事、
+// Hijack 回傳錯誤、ReadFrom 改用一般的 Write。Recorder 也實作 Unwrap，
+// 所以 http.ResponseController 可以找到被包裹的 ResponseWriter。
+type Recorder struct {
+	http.ResponseWriter
+	status      int
+	written     int64
+	wroteHeader bool
+	hijacked    bool
+}
+
+var (
+	_ http.Flusher  = (*Recorder)(nil)
+	_ http.Hijacker = (*Recorder)(nil)
+	_ io.ReaderFrom = (*Recorder)(nil)
+)
+
+// NewRecorder 包裹 w；w 本身已經是 *Recorder 時直接回傳 w，避免重複計算。
+func NewRecorder(w http.ResponseWriter) *Recorder {
+	if rec, ok := w.(*Recorder); ok {
+		return rec
+	}
+	return &Recorder{ResponseWriter: w}
+}
+
+// Status 回傳寫出的狀態碼。handler 沒有寫出任何東西時回傳 200，與 net/http 實際送出的一致。
+func (rec *Recorder) Status() int {
+	if rec.status == 0 {
+		return http.StatusOK
+	}
+	return rec.status
+}
+
+// Written 回傳寫出的主體位元組數。
+func (rec *Recorder) Written() int64 { return rec.written }
+
+// WroteHeader 回報狀態碼是否已經送出 (明確呼叫 WriteHeader，或第一次 Write 時隱含送出)。
+// 送出之後就不能再改變狀態碼或標頭。
+func (rec *Recorder) WroteHeader() bool { return rec.wroteHeader }
+
+// Hijacked 回報連線是否已經被 Hijack 接管。
+func (rec *Recorder) Hijacked() bool { return rec.hijacked }
+
+// WriteHeader 實作 http.ResponseWriter。
+func (rec *Recorder) WriteHeader(code int) {
+	// 1xx (例如 103 Early Hints) 之後還會有最終的狀態碼；101 則是最終的狀態碼。
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		rec.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if !rec.wroteHeader {
+		rec.status = code
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+// Write 實作 http.ResponseWriter。
+func (rec *Recorder) Write(b []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.written += int64(n)
+	return n, err
+}
+
+// Flush 實作 http.Flusher。
+func (rec *Recorder) Flush() {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+// Hijack 實作 http.Hijacker；被包裹的 ResponseWriter 不支援時回傳錯誤 (例如 HTTP/2)。
+func (rec *Recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(rec.ResponseWriter).Hijack()
+	if err == nil {
+		rec.hijacked = true
+		if !rec.wroteHeader {
+			rec.status = http.StatusSwitchingProtocols
+			rec.wroteHeader = true
+		}
+	}
+	return conn, brw, err
+}
+
+// ReadFrom 實作 io.ReaderFrom；被包裹的 ResponseWriter 支援時使用它的 ReadFrom (例如以 sendfile 傳送檔案)。
+func (rec *Recorder) ReadFrom(src io.Reader) (int64, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	var (
+		n   int64
+		err error
+	)
+	if rf, ok := rec.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		// 包一層只有 Write 的型別，避免 io.Copy 又呼叫回 rec.ReadFrom。
+		n, err = io.Copy(struct{ io.Writer }{rec.ResponseWriter}, src)
+	}
+	rec.written += n
+	return n, err
+}
+
+// Unwrap 回傳被包裹的 ResponseWriter，供 http.ResponseController 使用。
+func (rec *Recorder) Unwrap() http.ResponseWriter { return rec.ResponseWriter }
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     func(w http.ResponseWriter)
+		wantStatus  int
+		wantWritten int64
+		wantHeader  bool
+	}{
+		{"沒有寫出", func(w http.ResponseWriter) {}, 200, 0, false},
+		{"只有 Write", func(w http.ResponseWriter) { io.WriteString(w, "hello") }, 200, 5, true},
+		{"WriteHeader", func(w http.ResponseWriter) { w.WriteHeader(http.StatusTeapot) }, 418, 0, true},
+		{"重複的 WriteHeader 只記錄第一個", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusCreated)
+			w.WriteHeader(http.StatusInternalServerError)
+			io.WriteString(w, "ok")
+		}, 201, 2, true},
+		{"1xx 不是最終的狀態碼", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusEarlyHints)
+			w.WriteHeader(http.StatusAccepted)
+		}, 202, 0, true},
+		{"ReadFrom", func(w http.ResponseWriter) {
+			w.(io.ReaderFrom).ReadFrom(strings.NewReader("streamed"))
+		}, 200, 8, true},
+		{"http.Error", func(w http.ResponseWriter) { http.Error(w, "nope", http.StatusNotFound) }, 404, 5, true},
+	}
+	for _, tt := range tests {
+		rec := NewRecorder(httptest.NewRecorder())
+		tt.handler(rec)
+		if got := rec.Status(); got != tt.wantStatus {
+			t.Errorf("%s: Status() = %d; 預期為 %d", tt.name, got, tt.wantStatus)
+		}
+		if got := rec.Written(); got != tt.wantWritten {
+			t.Errorf("%s: Written() = %d; 預期為 %d", tt.name, got, tt.wantWritten)
+		}
+		if got := rec.WroteHeader(); got != tt.wantHeader {
+			t.Errorf("%s: WroteHeader() = %v; 預期為 %v", tt.name, got, tt.wantHeader)
+		}
+	}
+}
+
+func TestNewRecorderReusesRecorder(t *testing.T) {
+	rec := NewRecorder(httptest.NewRecorder())
+	if NewRecorder(rec) != rec {
+		t.Error("NewRecorder(*Recorder) 預期回傳同一個 Recorder")
+	}
+}
+
+// hijackWriter 是支援 Hijack 但不支援 Flush 與 ReadFrom 的 ResponseWriter。
+type hijackWriter struct {
+	http.ResponseWriter
+	conn net.Conn
+}
+
+func (w hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.conn, bufio.NewReadWriter(bufio.NewReader(w.conn), bufio.NewWriter(w.conn)), nil
+}
+
+// plainWriter 只有 http.ResponseWriter 的方法。
+type plainWriter struct{ http.ResponseWriter }
+
+func TestRecorderOptionalInterfaces(t *testing.T) {
+	// Flush 轉交給 httptest.ResponseRecorder。
+	inner := httptest.NewRecorder()
+	rec := NewRecorder(inner)
+	rec.Flush()
+	if !inner.Flushed || rec.Status() != http.StatusOK {
+		t.Errorf("Flush: Flushed = %v, Status() = %d; 預期為 true, 200", inner.Flushed, rec.Status())
+	}
+
+	// 被包裹的 ResponseWriter 不支援 Hijack 時回傳錯誤。
+	if _, _, err := NewRecorder(inner).Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Hijack() error = %v; 預期為 http.ErrNotSupported", err)
+	}
+
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+	rec = NewRecorder(hijackWriter{httptest.NewRecorder(), c1})
+	conn, _, err := rec.Hijack()
+	if err != nil || conn != c1 {
+		t.Fatalf("Hijack() = %v, %v; 預期為被包裹的連線", conn, err)
+	}
+	if !rec.Hijacked() || rec.Status() != http.StatusSwitchingProtocols {
+		t.Errorf("Hijack 之後 Hijacked() = %v, Status() = %d; 預期為 true, 101", rec.Hijacked(), rec.Status())
+	}
+
+	// 被包裹的 ResponseWriter 沒有 ReadFrom 時改用 Write。
+	plain := httptest.NewRecorder()
+	rec = NewRecorder(plainWriter{plain})
+	n, err := rec.ReadFrom(strings.NewReader("copy me"))
+	if n != 7 || err != nil || plain.Body.String() != "copy me" {
+		t.Errorf("ReadFrom = %d, %v, 主體 %q; 預期為 7, nil, %q", n, err, plain.Body, "copy me")
+	}
+	// 不支援 Flush 時 Flush 不做任何事，也不會 panic。
+	rec.Flush()
+}
+
+func TestRecorderWithServer(t *testing.T) {
+	var status int
+	var written int64
+	srv := httptest.NewServer(Chain(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := NewRecorder(w)
+			next.ServeHTTP(rec, r)
+			status, written = rec.Status(), rec.Written()
+		})
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// http.ResponseController 經由 Unwrap 找到真正的 ResponseWriter。
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("ResponseController.Flush() error = %v", err)
+		}
+		w.(io.ReaderFrom).ReadFrom(strings.NewReader("hello"))
+	})))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello" || status != http.StatusOK || written != 5 {
+		t.Errorf("主體 %q, Status() = %d, Written() = %d; 預期為 hello, 200, 5", body, status, written)
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover 攔截 handler 的 panic，記錄 panic 的值、請求與堆疊，並回應 500 Internal Server Error。
+//
+// 如果 panic 時回應已經開始送出，狀態碼無法再改變；這時 Recover 以 http.ErrAbortHandler
+// 再次 panic，讓伺服器中斷連線，用戶端才不會把截斷的主體當成成功的回應。
+// handler 自己以 http.ErrAbortHandler panic 表示要中斷連線，Recover 不會攔截它。
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := NewRecorder(w)
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(v)
+			}
+			log.Printf("middleware: panic serving %s %s (request %q): %v\n%s",
+				r.Method, r.URL.Path, RequestIDFrom(r.Context()), v, debug.Stack())
+			if rec.WroteHeader() {
+				panic(http.ErrAbortHandler)
+			}
+			http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
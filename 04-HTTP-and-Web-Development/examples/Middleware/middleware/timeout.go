@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Timeout 為每個請求的 context 加上期限 d。
+//
+// Go 無法從外部停止一個 Goroutine，所以期限是合作式的：handler 應該把 r.Context()
+// 傳給資料庫查詢、下游的 HTTP 請求等，讓它們在期限到時回傳 context.DeadlineExceeded。
+// handler 返回時如果期限已過，而且還沒有送出任何回應，Timeout 回應 503 Service Unavailable。
+//
+// 與 http.TimeoutHandler 不同，Timeout 不會把回應緩衝起來，所以串流與 Hijack 仍然可以使用。
+func Timeout(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			rec := NewRecorder(w)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+			if !rec.WroteHeader() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				http.Error(rec, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
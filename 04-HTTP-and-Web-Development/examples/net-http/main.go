@@ -1,29 +1,99 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/Config/config"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/net-http/router"
+	"golang-Roadmap-2025/04-HTTP-and-Web-Development/examples/net-http/server"
 )
 
 // helloHandler 處理對 /hello 路徑的請求
+// 路由只把 GET (與 HEAD) 交給它，其他方法由 router 回應 405 與 Allow 標頭
 func helloHandler(w http.ResponseWriter, r *http.Request) {
-	// 檢查請求方法，只允許 GET
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
 	fmt.Fprintf(w, "Hello, World from net/http!")
 }
 
+// greetHandler 處理 /hello/{name}，以 r.PathValue 取得路徑參數
+func greetHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, "Hello, %s!", r.PathValue("name"))
+}
+
+// settings 是這個範例的設定，可以用 APP_ADDR 或 -addr 等方式覆蓋預設值
+type settings struct {
+	Addr      string `json:"addr" default:":8080" validate:"required" usage:"HTTP 監聽位址"`
+	AdminAddr string `json:"admin_addr" default:"127.0.0.1:9090" validate:"required" usage:"管理介面 (健康檢查) 的監聽位址"`
+	// DrainDelay 應該大於負載平衡器檢查 /readyz 的間隔乘以判定失敗的次數
+	DrainDelay time.Duration `json:"drain_delay" default:"5s" usage:"關閉前回報未就緒並繼續服務的時間"`
+}
+
 func main() {
-	// 將 helloHandler 函式註冊到 "/hello" 路徑
-	http.HandleFunc("/hello", helloHandler)
-
-	// 啟動伺服器，監聽 8080 埠
-	fmt.Println("Server starting on http://localhost:8080")
-	// ListenAndServe 會一直阻塞，直到發生錯誤
-	if err := http.ListenAndServe(":8080", nil); err != nil {
-		log.Fatal("ListenAndServe: ", err)
+	var cfg settings
+	if err := config.Load(&cfg, config.WithEnv("APP"), config.WithArgs(os.Args[1:])); err != nil {
+		log.Fatal(err)
+	}
+
+	r := router.New()
+	// 將 helloHandler 函式註冊到 "GET /hello"
+	r.GET("/hello", helloHandler)
+	// 為帶有參數的路由命名，之後可以用名稱產生 URL
+	r.GET("/hello/{name}", greetHandler).Name("greet")
+
+	greetURL, err := r.URL("greet", "name", "Gopher")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 管理介面只在本機監聽，提供給負載平衡器或 Kubernetes 的健康檢查
+	var ready atomic.Bool
+	admin := http.NewServeMux()
+	admin.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	admin.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready.Load() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ready")
+	})
+
+	srv := server.New(server.WithShutdownTimeout(20 * time.Second))
+	if _, err := srv.Listen("http", cfg.Addr, r); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := srv.Listen("admin", cfg.AdminAddr, admin); err != nil {
+		log.Fatal(err)
+	}
+	// 收到 SIGTERM 後先回報尚未就緒，並在 DrainDelay 內照常服務 (包括 /readyz)，
+	// 讓負載平衡器有時間發現並停止送來新的請求，然後才關閉 listener。
+	// 這段時間也計入 WithShutdownTimeout 的期限，ctx 結束時不再等待
+	srv.BeforeShutdown(func(ctx context.Context) error {
+		ready.Store(false)
+		log.Printf("not ready; draining for %v before closing listeners", cfg.DrainDelay)
+		t := time.NewTimer(cfg.DrainDelay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	ready.Store(true)
+
+	fmt.Printf("Server starting on %s (admin on %s)\n", cfg.Addr, cfg.AdminAddr)
+	base := srv.URL("http")
+	fmt.Println("Try " + base + "/hello and " + base + greetURL)
+	fmt.Println("Press Ctrl+C to shut down gracefully")
+	// Run 會一直阻塞，直到收到 SIGINT/SIGTERM，並在處理中的請求完成後才返回
+	if err := srv.Run(context.Background()); err != nil {
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,342 @@
+// Package router 在 http.ServeMux 的路由模式 (pattern) 之上加上常用的路由功能：
+//
+//   - 依 HTTP 方法分派：r.GET("/users/{id}", h)，不必在 handler 中 switch r.Method。
+//   - 路徑參數沿用 ServeMux 的 {name} 與 {name...}，在 handler 中以 r.PathValue("id") 取得。
+//   - 群組 (Group)：共用路徑前綴與中介軟體 (middleware)。
+//   - 路徑存在但方法不符時自動回應 405，並在 Allow 標頭列出允許的方法。
+//   - 有 GET 的路徑自動支援 HEAD；沒有註冊 OPTIONS 的路徑自動以 204 與 Allow 標頭回應。
+//   - 具名路由 (named route)：以名稱與參數反向產生 URL，路徑改變時不必修改每個連結。
+//
+// 路徑的比對完全交給 ServeMux (最具體的模式優先)，方法則在最符合的路徑之內比對。
+// 所有路由都必須在開始處理請求之前註冊完成。
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Middleware 包裹一個 http.Handler，在它之前或之後做額外的處理。
+type Middleware func(http.Handler) http.Handler
+
+// chain 讓 mws[0] 成為最外層。
+func chain(h http.Handler, mws []Middleware) http.Handler {
+	for _, mw := range slices.Backward(mws) {
+		h = mw(h)
+	}
+	return h
+}
+
+// Router 是一個 http.Handler。零值不能使用，請使用 New。
+type Router struct {
+	// NotFound 處理沒有任何路徑符合的請求，預設為 http.NotFound。
+	NotFound http.Handler
+	// MethodNotAllowed 處理路徑符合但方法不符的請求，呼叫時 Allow 標頭已經設定好。
+	// 預設回應 405 Method Not Allowed。
+	MethodNotAllowed http.Handler
+
+	root      *Group
+	mux       *http.ServeMux
+	endpoints map[string]*endpoint // 不含方法的模式 → 該路徑的所有方法
+	names     map[string]*Route
+	handler   http.Handler // mux 加上 Use 註冊的全域中介軟體
+	global    []Middleware
+}
+
+// New 建立一個空的 Router。
+func New() *Router {
+	r := &Router{
+		mux:       http.NewServeMux(),
+		endpoints: map[string]*endpoint{},
+		names:     map[string]*Route{},
+	}
+	r.root = &Group{router: r}
+	r.handler = http.HandlerFunc(r.serve)
+	return r
+}
+
+// Use 加入全域中介軟體。與 Group.Use 不同，它也會包裹 404、405 與自動產生的 OPTIONS 回應，
+// 適合用於日誌、復原 (recovery) 等必須看到每一個請求的中介軟體。
+func (r *Router) Use(mws ...Middleware) {
+	r.global = append(r.global, mws...)
+	r.handler = chain(http.HandlerFunc(r.serve), r.global)
+}
+
+// Group 建立一個路徑前綴為 prefix 的群組，見 Group.Group。
+func (r *Router) Group(prefix string, mws ...Middleware) *Group {
+	return r.root.Group(prefix, mws...)
+}
+
+// Handle 為 method 與 pattern 註冊 h，見 Group.Handle。pattern 可以包含主機名稱。
+func (r *Router) Handle(method, pattern string, h http.Handler) *Route {
+	return r.root.Handle(method, pattern, h)
+}
+
+// HandleFunc 與 Handle 相同，但接受一個函式。
+func (r *Router) HandleFunc(method, pattern string, h http.HandlerFunc) *Route {
+	return r.root.Handle(method, pattern, h)
+}
+
+// GET 註冊 GET 路由 (同時支援 HEAD)。
+func (r *Router) GET(pattern string, h http.HandlerFunc) *Route {
+	return r.root.GET(pattern, h)
+}
+
+// POST 註冊 POST 路由。
+func (r *Router) POST(pattern string, h http.HandlerFunc) *Route {
+	return r.root.POST(pattern, h)
+}
+
+// PUT 註冊 PUT 路由。
+func (r *Router) PUT(pattern string, h http.HandlerFunc) *Route {
+	return r.root.PUT(pattern, h)
+}
+
+// PATCH 註冊 PATCH 路由。
+func (r *Router) PATCH(pattern string, h http.HandlerFunc) *Route {
+	return r.root.PATCH(pattern, h)
+}
+
+// DELETE 註冊 DELETE 路由。
+func (r *Router) DELETE(pattern string, h http.HandlerFunc) *Route {
+	return r.root.DELETE(pattern, h)
+}
+
+// ServeHTTP 實作 http.Handler。
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.handler.ServeHTTP(w, req)
+}
+
+func (r *Router) serve(w http.ResponseWriter, req *http.Request) {
+	if _, pattern := r.mux.Handler(req); pattern == "" && r.NotFound != nil {
+		r.NotFound.ServeHTTP(w, req)
+		return
+	}
+	r.mux.ServeHTTP(w, req)
+}
+
+// URL 以名稱找出路由，並把 params (依序為名稱與值的配對) 代入路徑參數，回傳路徑。
+// 值會經過路徑跳脫 (escape)；{name...} 的值可以包含 "/"。
+//
+//	r.GET("/users/{id}", h).Name("user")
+//	r.URL("user", "id", "42") // "/users/42"
+func (r *Router) URL(name string, params ...string) (string, error) {
+	route, ok := r.names[name]
+	if !ok {
+		return "", fmt.Errorf("router: no route named %q", name)
+	}
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("router: route %q: odd number of params", name)
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+	return route.build(values)
+}
+
+func (r *Router) register(method, pattern string, h http.Handler) *Route {
+	if strings.ContainsAny(method, " \t") {
+		panic(fmt.Sprintf("router: invalid method %q", method))
+	}
+	e, ok := r.endpoints[pattern]
+	if !ok {
+		e = &endpoint{router: r, handlers: map[string]http.Handler{}}
+		// ServeMux 會在模式無效或與其他模式衝突時 panic。
+		r.mux.Handle(pattern, e)
+		r.endpoints[pattern] = e
+	}
+	if _, dup := e.handlers[method]; dup {
+		panic(fmt.Sprintf("router: %s %s registered twice", method, pattern))
+	}
+	e.handlers[method] = h
+	e.updateAllow()
+	return &Route{router: r, Method: method, Pattern: pattern}
+}
+
+// Group 是一組共用路徑前綴與中介軟體的路由。
+type Group struct {
+	router *Router
+	prefix string
+	mws    []Middleware
+}
+
+// Group 建立一個子群組：路徑前綴接在 g 的前綴之後，並繼承 g 目前的中介軟體。
+func (g *Group) Group(prefix string, mws ...Middleware) *Group {
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		panic(fmt.Sprintf("router: group prefix %q must begin with /", prefix))
+	}
+	return &Group{
+		router: g.router,
+		prefix: g.prefix + strings.TrimSuffix(prefix, "/"),
+		mws:    append(slices.Clip(g.mws), mws...),
+	}
+}
+
+// Use 加入群組的中介軟體。只影響之後在這個群組 (及之後建立的子群組) 註冊的路由。
+func (g *Group) Use(mws ...Middleware) {
+	g.mws = append(g.mws, mws...)
+}
+
+// Handle 為 method 與 pattern 註冊 h。pattern 是 ServeMux 的路徑模式 (不含方法)，
+// 在群組中必須以 "/" 開頭；method 為空字串時符合所有沒有另外註冊的方法。
+// 同一個方法與模式重複註冊，或模式與其他路由衝突時會 panic。
+func (g *Group) Handle(method, pattern string, h http.Handler) *Route {
+	if g.prefix != "" && !strings.HasPrefix(pattern, "/") {
+		panic(fmt.Sprintf("router: pattern %q in group %q must begin with /", pattern, g.prefix))
+	}
+	return g.router.register(method, g.prefix+pattern, chain(h, g.mws))
+}
+
+// HandleFunc 與 Handle 相同，但接受一個函式。
+func (g *Group) HandleFunc(method, pattern string, h http.HandlerFunc) *Route {
+	return g.Handle(method, pattern, h)
+}
+
+// GET 註冊 GET 路由 (同時支援 HEAD)。
+func (g *Group) GET(pattern string, h http.HandlerFunc) *Route {
+	return g.Handle(http.MethodGet, pattern, h)
+}
+
+// POST 註冊 POST 路由。
+func (g *Group) POST(pattern string, h http.HandlerFunc) *Route {
+	return g.Handle(http.MethodPost, pattern, h)
+}
+
+// PUT 註冊 PUT 路由。
+func (g *Group) PUT(pattern string, h http.HandlerFunc) *Route {
+	return g.Handle(http.MethodPut, pattern, h)
+}
+
+// PATCH 註冊 PATCH 路由。
+func (g *Group) PATCH(pattern string, h http.HandlerFunc) *Route {
+	return g.Handle(http.MethodPatch, pattern, h)
+}
+
+// DELETE 註冊 DELETE 路由。
+func (g *Group) DELETE(pattern string, h http.HandlerFunc) *Route {
+	return g.Handle(http.MethodDelete, pattern, h)
+}
+
+// Route 是一個已註冊的路由。
+type Route struct {
+	router  *Router
+	Method  string
+	Pattern string // 包含群組前綴的完整模式
+}
+
+// Name 為路由命名，之後可以用 Router.URL 產生它的路徑。名稱重複時會 panic。
+func (rt *Route) Name(name string) *Route {
+	if _, dup := rt.router.names[name]; dup {
+		panic(fmt.Sprintf("router: route name %q registered twice", name))
+	}
+	rt.router.names[name] = rt
+	return rt
+}
+
+// build 把 values 代入模式中的路徑參數。模式中的主機名稱會被忽略。
+func (rt *Route) build(values map[string]string) (string, error) {
+	p := rt.Pattern
+	if i := strings.Index(p, "/"); i > 0 {
+		p = p[i:]
+	}
+	var b strings.Builder
+	used := 0
+	for {
+		open := strings.Index(p, "{")
+		if open < 0 {
+			b.WriteString(p)
+			break
+		}
+		end := strings.Index(p[open:], "}") + open
+		b.WriteString(p[:open])
+		name := p[open+1 : end]
+		p = p[end+1:]
+		if name == "$" {
+			continue
+		}
+		name, rest := strings.CutSuffix(name, "...")
+		v, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("router: route %s: missing param %q", rt.Pattern, name)
+		}
+		used++
+		if !rest {
+			b.WriteString(url.PathEscape(v))
+			continue
+		}
+		segments := strings.Split(v, "/")
+		for i, s := range segments {
+			segments[i] = url.PathEscape(s)
+		}
+		b.WriteString(strings.Join(segments, "/"))
+	}
+	if used != len(values) {
+		return "", fmt.Errorf("router: route %s: unknown params in %v", rt.Pattern, values)
+	}
+	return b.String(), nil
+}
+
+// endpoint 是同一個路徑模式底下、依方法分派的所有 handler。
+type endpoint struct {
+	router   *Router
+	handlers map[string]http.Handler // 方法 → handler；"" 代表其他所有方法
+	allow    string
+}
+
+func (e *endpoint) updateAllow() {
+	methods := []string{http.MethodOptions}
+	for m := range e.handlers {
+		if m != "" {
+			methods = append(methods, m)
+		}
+	}
+	if _, ok := e.handlers[http.MethodGet]; ok {
+		methods = append(methods, http.MethodHead)
+	}
+	slices.Sort(methods)
+	e.allow = strings.Join(slices.Compact(methods), ", ")
+}
+
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if h, ok := e.handlers[req.Method]; ok {
+		h.ServeHTTP(w, req)
+		return
+	}
+	if h, ok := e.handlers[http.MethodGet]; ok && req.Method == http.MethodHead {
+		h.ServeHTTP(&headWriter{w}, req)
+		return
+	}
+	if h, ok := e.handlers[""]; ok {
+		h.ServeHTTP(w, req)
+		return
+	}
+
+	w.Header().Set("Allow", e.allow)
+	if req.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if e.router.MethodNotAllowed != nil {
+		e.router.MethodNotAllowed.ServeHTTP(w, req)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// headWriter 讓 GET handler 處理 HEAD 請求：保留標頭與狀態碼，丟棄主體。
+type headWriter struct {
+	http.ResponseWriter
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// Unwrap 讓 http.ResponseController 可以取得原本的 ResponseWriter。
+func (w *headWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
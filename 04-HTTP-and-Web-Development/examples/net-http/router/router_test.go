@@ -0,0 +1,225 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// text 回傳一個寫出固定文字的 handler。
+func text(s string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, s)
+	}
+}
+
+// tag 是一個在回應標頭 X-Trace 中附加 name 的中介軟體，用來觀察中介軟體的順序。
+func tag(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func serve(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestRouting(t *testing.T) {
+	r := New()
+	r.GET("/users", text("list"))
+	r.POST("/users", text("create"))
+	r.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "user "+r.PathValue("id"))
+	})
+	r.GET("/users/new", text("form"))
+	r.DELETE("/users/{id}", text("delete"))
+	r.GET("/files/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "file "+r.PathValue("path"))
+	})
+	r.Handle("", "/any", text("any"))
+
+	testCases := []struct {
+		method, target string
+		wantCode       int
+		wantBody       string
+		wantAllow      string
+	}{
+		{"GET", "/users", 200, "list", ""},
+		{"POST", "/users", 200, "create", ""},
+		{"GET", "/users/42", 200, "user 42", ""},
+		{"GET", "/users/new", 200, "form", ""}, // 最具體的模式優先
+		{"DELETE", "/users/42", 200, "delete", ""},
+		{"GET", "/files/a/b.txt", 200, "file a/b.txt", ""},
+		{"PATCH", "/any", 200, "any", ""},
+		{"GET", "/nope", 404, "404 page not found\n", ""},
+		{"PUT", "/users", 405, "Method Not Allowed\n", "GET, HEAD, OPTIONS, POST"},
+		{"POST", "/users/42", 405, "Method Not Allowed\n", "DELETE, GET, HEAD, OPTIONS"},
+		{"HEAD", "/users/42", 200, "", ""},
+		{"OPTIONS", "/users/42", 204, "", "DELETE, GET, HEAD, OPTIONS"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.target, func(t *testing.T) {
+			w := serve(r, tc.method, tc.target)
+			if w.Code != tc.wantCode || w.Body.String() != tc.wantBody {
+				t.Errorf("回應 = (%d, %q); 預期為 (%d, %q)", w.Code, w.Body.String(), tc.wantCode, tc.wantBody)
+			}
+			if got := w.Header().Get("Allow"); got != tc.wantAllow {
+				t.Errorf("Allow = %q; 預期為 %q", got, tc.wantAllow)
+			}
+		})
+	}
+}
+
+func TestHeadKeepsHeaders(t *testing.T) {
+	r := New()
+	r.GET("/ping", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, "pong")
+	})
+	w := serve(r, http.MethodHead, "/ping")
+	if w.Code != http.StatusAccepted || w.Header().Get("Content-Type") != "text/plain" || w.Body.Len() != 0 {
+		t.Errorf("HEAD 回應 = (%d, %q, %q); 預期保留狀態碼與標頭並且沒有主體",
+			w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
+
+func TestCustomHandlers(t *testing.T) {
+	r := New()
+	r.GET("/items", text("items"))
+	r.HandleFunc(http.MethodOptions, "/items", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Custom", "yes")
+	})
+	r.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+	})
+	r.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+	})
+
+	if w := serve(r, "GET", "/missing"); w.Code != 404 || !strings.Contains(w.Body.String(), `"not found"`) {
+		t.Errorf("NotFound 回應 = (%d, %q)", w.Code, w.Body.String())
+	}
+	w := serve(r, "DELETE", "/items")
+	if w.Code != 405 || !strings.Contains(w.Body.String(), `"method not allowed"`) || w.Header().Get("Allow") != "GET, HEAD, OPTIONS" {
+		t.Errorf("MethodNotAllowed 回應 = (%d, %q, Allow %q)", w.Code, w.Body.String(), w.Header().Get("Allow"))
+	}
+	// 明確註冊的 OPTIONS 取代自動回應。
+	if w := serve(r, "OPTIONS", "/items"); w.Code != 200 || w.Header().Get("X-Custom") != "yes" {
+		t.Errorf("OPTIONS 回應 = (%d, X-Custom %q); 預期由註冊的 handler 處理", w.Code, w.Header().Get("X-Custom"))
+	}
+}
+
+func TestGroupsAndMiddleware(t *testing.T) {
+	r := New()
+	r.Use(tag("global"))
+	r.GET("/health", text("ok"))
+
+	api := r.Group("/api/", tag("api"))
+	api.GET("/users", text("users"))
+	admin := api.Group("/admin", tag("admin"))
+	admin.GET("/stats", text("stats"))
+	// 之後才加入的中介軟體只影響之後註冊的路由，也不影響已經建立的子群組。
+	api.Use(tag("late"))
+	api.POST("/users", text("created"))
+
+	testCases := []struct {
+		method, target string
+		wantCode       int
+		wantTrace      string
+	}{
+		{"GET", "/health", 200, "global"},
+		{"GET", "/api/users", 200, "global,api"},
+		{"POST", "/api/users", 200, "global,api,late"},
+		{"GET", "/api/admin/stats", 200, "global,api,admin"},
+		{"GET", "/api/missing", 404, "global"},
+		{"DELETE", "/api/users", 405, "global"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.target, func(t *testing.T) {
+			w := serve(r, tc.method, tc.target)
+			got := strings.Join(w.Header().Values("X-Trace"), ",")
+			if w.Code != tc.wantCode || got != tc.wantTrace {
+				t.Errorf("回應 = (%d, %q); 預期為 (%d, %q)", w.Code, got, tc.wantCode, tc.wantTrace)
+			}
+		})
+	}
+}
+
+func TestURL(t *testing.T) {
+	r := New()
+	r.GET("/users/{id}", text("")).Name("user")
+	r.Group("/repos").GET("/{owner}/{repo}/blob/{path...}", text("")).Name("blob")
+	r.GET("/{$}", text("")).Name("home")
+
+	testCases := []struct {
+		name    string
+		params  []string
+		want    string
+		wantErr bool
+	}{
+		{"user", []string{"id", "42"}, "/users/42", false},
+		{"user", []string{"id", "a b/c"}, "/users/a%20b%2Fc", false},
+		{"blob", []string{"owner", "go", "repo", "net", "path", "http/server go.txt"}, "/repos/go/net/blob/http/server%20go.txt", false},
+		{"home", nil, "/", false},
+		{"user", nil, "", true},                               // 缺少參數
+		{"user", []string{"id"}, "", true},                    // 參數不成對
+		{"user", []string{"id", "1", "extra", "2"}, "", true}, // 多餘的參數
+		{"nope", nil, "", true},
+	}
+	for _, tc := range testCases {
+		got, err := r.URL(tc.name, tc.params...)
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("URL(%q, %q) = (%q, %v); 預期為 %q (錯誤: %v)", tc.name, tc.params, got, err, tc.want, tc.wantErr)
+		}
+	}
+
+	// 產生的路徑可以被同一個 Router 解析回相同的參數。
+	r2 := New()
+	var gotID string
+	r2.GET("/users/{id}", func(w http.ResponseWriter, r *http.Request) { gotID = r.PathValue("id") }).Name("user")
+	u, _ := r2.URL("user", "id", "a b/c")
+	serve(r2, "GET", u)
+	if gotID != "a b/c" {
+		t.Errorf("PathValue(\"id\") = %q; 預期為 \"a b/c\"", gotID)
+	}
+}
+
+func TestRegistrationPanics(t *testing.T) {
+	testCases := []struct {
+		name     string
+		register func(r *Router)
+	}{
+		{"重複的方法與模式", func(r *Router) {
+			r.GET("/a", text(""))
+			r.GET("/a", text(""))
+		}},
+		{"衝突的模式", func(r *Router) {
+			r.GET("/a/{x}", text(""))
+			r.GET("/a/{y}", text(""))
+		}},
+		{"重複的名稱", func(r *Router) {
+			r.GET("/a", text("")).Name("a")
+			r.GET("/b", text("")).Name("a")
+		}},
+		{"群組前綴不以 / 開頭", func(r *Router) { r.Group("api") }},
+		{"無效的方法", func(r *Router) { r.Handle("GET /x", "/y", text("")) }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("預期會 panic")
+				}
+			}()
+			tc.register(New())
+		})
+	}
+}
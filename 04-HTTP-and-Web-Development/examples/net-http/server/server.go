@@ -0,0 +1,296 @@
+// Package server 管理一個或多個 http.Server 的生命週期：設定逾時、同時服務多個位址
+// (例如對外的 API 與內部的管理介面)，並在收到 SIGINT 或 SIGTERM 時優雅地關閉 (graceful shutdown)。
+//
+// http.ListenAndServe 在程序收到 SIGTERM (例如 Kubernetes 滾動更新) 時直接結束，
+// 處理到一半的請求會被中斷。Run 改為依序：
+//
+//  1. 停止接受新的連線之前，執行前置 hook (例如把 readiness 設為失敗，等待負載平衡器移除這個實例)。
+//  2. 以 http.Server.Shutdown 關閉所有 listener，等待處理中的請求完成；閒置的 keep-alive 連線立即關閉。
+//  3. 超過期限 (drain deadline) 仍未完成的連線以 http.Server.Close 強制關閉。
+//  4. 執行後置 hook (例如關閉資料庫連線池)，順序與註冊相反。
+//
+// 用法：
+//
+//	srv := server.New(server.WithShutdownTimeout(20 * time.Second))
+//	if _, err := srv.Listen("http", ":8080", mux); err != nil {
+//		log.Fatal(err)
+//	}
+//	if _, err := srv.Listen("admin", "127.0.0.1:9090", adminMux); err != nil {
+//		log.Fatal(err)
+//	}
+//	srv.AfterShutdown(func(ctx context.Context) error { return db.Close() })
+//	if err := srv.Run(context.Background()); err != nil {
+//		log.Fatal(err)
+//	}
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrAlreadyRun 表示 Run 已經被呼叫過；Server 只能執行一次。
+var ErrAlreadyRun = errors.New("server: Run called twice")
+
+// Timeouts 是套用到每個 http.Server 的逾時。http.Server 的零值沒有任何逾時，
+// 一個緩慢 (或惡意) 的用戶端可以一直佔用連線 (Slowloris 攻擊)。
+type Timeouts struct {
+	// ReadHeader 是讀取請求標頭的期限。
+	ReadHeader time.Duration
+	// Read 是讀取整個請求 (包含主體) 的期限。
+	Read time.Duration
+	// Write 是從讀完請求標頭到寫完回應的期限；串流回應的 handler 可以用
+	// http.ResponseController.SetWriteDeadline 延長。
+	Write time.Duration
+	// Idle 是 keep-alive 連線等待下一個請求的期限。
+	Idle time.Duration
+}
+
+// DefaultTimeouts 是 New 預設的逾時。
+var DefaultTimeouts = Timeouts{
+	ReadHeader: 5 * time.Second,
+	Read:       15 * time.Second,
+	Write:      30 * time.Second,
+	Idle:       2 * time.Minute,
+}
+
+// Hook 是關閉時執行的函式；ctx 在關閉的期限到時被取消。
+type Hook func(ctx context.Context) error
+
+// Option 用來調整 Server 的行為。
+type Option func(*Server)
+
+// WithTimeouts 設定之後 Listen 與 Serve 建立的 http.Server 的逾時。
+func WithTimeouts(t Timeouts) Option {
+	return func(s *Server) { s.timeouts = t }
+}
+
+// WithShutdownTimeout 設定整個關閉流程 (前置 hook、排空連線與後置 hook) 的期限，預設為 30 秒。
+// Kubernetes 預設在 SIGTERM 之後 30 秒送出 SIGKILL，期限應該比它短。
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(s *Server) { s.shutdownTimeout = d }
+}
+
+// WithSignals 設定觸發關閉的訊號，預設為 SIGINT 與 SIGTERM。
+func WithSignals(sigs ...os.Signal) Option {
+	return func(s *Server) { s.signals = sigs }
+}
+
+// Server 協調多個 http.Server 的啟動與關閉。零值不能使用，請使用 New。
+type Server struct {
+	timeouts        Timeouts
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	before    []Hook
+	after     []Hook
+	started   bool
+}
+
+// endpoint 是一個具名的 listener 與服務它的 http.Server。
+type endpoint struct {
+	name string
+	ln   net.Listener
+	srv  *http.Server
+}
+
+// New 建立一個 Server。
+func New(opts ...Option) *Server {
+	s := &Server{
+		timeouts:        DefaultTimeouts,
+		shutdownTimeout: 30 * time.Second,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Listen 立即在 addr 上監聽 (所以位址被佔用等錯誤在 Run 之前就會回報)，並在 Run 時以 h 服務。
+// addr 的埠號為 0 時由系統選擇，實際的位址可以從 Addr 取得。
+// 回傳的 http.Server 已經套用 Timeouts，可以在 Run 之前再調整其他欄位。
+func (s *Server) Listen(name, addr string, h http.Handler) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("server: %s: %w", name, err)
+	}
+	return s.Serve(name, ln, h), nil
+}
+
+// Serve 與 Listen 相同，但使用已經建立的 listener (例如 systemd socket activation 或測試)。
+func (s *Server) Serve(name string, ln net.Listener, h http.Handler) *http.Server {
+	srv := &http.Server{
+		Handler:           h,
+		ReadHeaderTimeout: s.timeouts.ReadHeader,
+		ReadTimeout:       s.timeouts.Read,
+		WriteTimeout:      s.timeouts.Write,
+		IdleTimeout:       s.timeouts.Idle,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		panic("server: Serve called after Run")
+	}
+	s.endpoints = append(s.endpoints, &endpoint{name: name, ln: ln, srv: srv})
+	return srv
+}
+
+// Addr 回傳名為 name 的 listener 的位址，不存在時回傳 nil。
+func (s *Server) Addr(name string) net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ep := range s.endpoints {
+		if ep.name == name {
+			return ep.ln.Addr()
+		}
+	}
+	return nil
+}
+
+// URL 回傳連到名為 name 的 listener 的 http:// URL，用於在啟動時印出提示。
+// 監聽所有介面 (例如 ":8080") 時主機為 localhost；listener 不存在或不是 TCP 時回傳空字串。
+func (s *Server) URL(name string) string {
+	addr, ok := s.Addr(name).(*net.TCPAddr)
+	if !ok {
+		return ""
+	}
+	host := addr.IP.String()
+	if addr.IP == nil || addr.IP.IsUnspecified() {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(addr.Port))
+}
+
+// BeforeShutdown 註冊在關閉 listener 之前執行的 hook，依註冊的順序執行。
+// 這時 listener 仍然接受新的請求。
+func (s *Server) BeforeShutdown(h Hook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.before = append(s.before, h)
+}
+
+// AfterShutdown 註冊在所有連線都結束之後執行的 hook，與註冊的順序相反 (與 defer 相同)，
+// 所以先建立的資源最後才關閉。
+func (s *Server) AfterShutdown(h Hook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.after = append(s.after, h)
+}
+
+// Run 開始服務所有的 listener，並阻塞直到以下任一件事發生，然後優雅地關閉：
+//
+//   - 收到 WithSignals 設定的訊號 (預設 SIGINT、SIGTERM)
+//   - ctx 被取消
+//   - 任一個 listener 發生錯誤 (其他 listener 也會一起關閉)
+//
+// 關閉開始之後，第二次的訊號會以預設的行為立即結束程序。
+// 正常關閉時回傳 nil；否則回傳 listener 的錯誤、hook 的錯誤，以及超過期限時包裝
+// context.DeadlineExceeded 的錯誤 (以 errors.Join 合併)。
+func (s *Server) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return ErrAlreadyRun
+	}
+	s.started = true
+	endpoints := slices.Clone(s.endpoints)
+	s.mu.Unlock()
+
+	ctx, stop := signal.NotifyContext(ctx, s.signals...)
+	defer stop()
+
+	serveErr := make(chan error, len(endpoints))
+	for _, ep := range endpoints {
+		log.Printf("server: %s listening on %s", ep.name, ep.ln.Addr())
+		go func() {
+			if err := ep.srv.Serve(ep.ln); !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- fmt.Errorf("server: %s: %w", ep.name, err)
+				return
+			}
+			serveErr <- nil
+		}()
+	}
+
+	var errs []error
+	pending := len(endpoints)
+	select {
+	case <-ctx.Done():
+		log.Printf("server: shutting down: %v", context.Cause(ctx))
+	case err := <-serveErr:
+		pending--
+		if err != nil {
+			log.Printf("server: shutting down: %v", err)
+			errs = append(errs, err)
+		}
+	}
+	// 恢復訊號的預設行為：關閉卡住時，再按一次 Ctrl+C 就能結束程序。
+	stop()
+
+	errs = append(errs, s.shutdown(endpoints)...)
+	// Shutdown 返回後 Serve 也會返回；等待它們，確保沒有遺留的 goroutine。
+	for range pending {
+		if err := <-serveErr; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// shutdown 依序執行前置 hook、排空所有的 http.Server 與後置 hook，全部共用同一個期限。
+// 期限到了之後，後置 hook 收到的是已經取消的 ctx；需要釋放資源的 hook 仍然應該完成清理。
+func (s *Server) shutdown(endpoints []*endpoint) []error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	before, after := slices.Clone(s.before), slices.Clone(s.after)
+	s.mu.Unlock()
+
+	var errs []error
+	for _, h := range before {
+		if err := h(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("server: before shutdown: %w", err))
+		}
+	}
+
+	// 所有的 http.Server 同時排空，總時間不超過期限。
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, ep := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ep.srv.Shutdown(ctx); err != nil {
+				// 期限到了還有處理中的請求：強制關閉剩下的連線。
+				ep.srv.Close()
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("server: %s: drain: %w", ep.name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, h := range slices.Backward(after) {
+		if err := h(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("server: after shutdown: %w", err))
+		}
+	}
+	return errs
+}
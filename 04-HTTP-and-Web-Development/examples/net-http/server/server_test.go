@@ -0,0 +1,312 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang-Roadmap-2025/03-Concurrency-Programming/examples/Goroutine-Leaks/leakcheck"
+)
+
+// quietLog 在測試期間丟棄 log 的輸出。
+func quietLog(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+}
+
+// run 在背景執行 s.Run，回傳取得結果的 channel。
+func run(ctx context.Context, s *Server) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	return done
+}
+
+// get 以獨立的 Transport 送出請求，避免留下閒置的 keep-alive 連線。
+func get(t *testing.T, url string) (int, string, error) {
+	t.Helper()
+	tr := &http.Transport{}
+	defer tr.CloseIdleConnections()
+	resp, err := (&http.Client{Transport: tr}).Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), err
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	leakcheck.Check(t)
+	quietLog(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s := New(WithShutdownTimeout(5 * time.Second))
+	if _, err := s.Listen("http", "127.0.0.1:0", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Listen("admin", "127.0.0.1:0", http.NotFoundHandler()); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) Hook {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+	s.BeforeShutdown(record("before-1"))
+	s.BeforeShutdown(func(ctx context.Context) error {
+		// 前置 hook 執行時處理中的請求還沒結束，然後才讓它完成。
+		close(release)
+		return record("before-2")(ctx)
+	})
+	s.AfterShutdown(record("after-1"))
+	s.AfterShutdown(record("after-2"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := run(ctx, s)
+
+	type result struct {
+		code int
+		body string
+		err  error
+	}
+	resp := make(chan result, 1)
+	go func() {
+		code, body, err := get(t, "http://"+s.Addr("http").String())
+		resp <- result{code, body, err}
+	}()
+	<-started
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v; 預期為 nil", err)
+	}
+	if r := <-resp; r.err != nil || r.code != http.StatusOK || r.body != "done" {
+		t.Errorf("處理中的請求 = %d %q, %v; 預期為 200 \"done\"", r.code, r.body, r.err)
+	}
+	want := []string{"before-1", "before-2", "after-2", "after-1"}
+	if !slices.Equal(order, want) {
+		t.Errorf("hook 順序 = %v; 預期為 %v", order, want)
+	}
+	// 關閉之後兩個 listener 都不再接受連線。
+	for _, name := range []string{"http", "admin"} {
+		if _, _, err := get(t, "http://"+s.Addr(name).String()); err == nil {
+			t.Errorf("%s: 關閉後的請求成功了; 預期連線失敗", name)
+		}
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	leakcheck.Check(t)
+	quietLog(t)
+
+	started := make(chan struct{})
+	s := New(WithShutdownTimeout(50 * time.Millisecond))
+	if _, err := s.Listen("http", "127.0.0.1:0", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		// 永遠不會自行結束；只有強制關閉連線後 (請求的 context 被取消) 才返回。
+		<-r.Context().Done()
+	})); err != nil {
+		t.Fatal(err)
+	}
+	var afterErr error
+	s.AfterShutdown(func(ctx context.Context) error {
+		afterErr = ctx.Err()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := run(ctx, s)
+	reqErr := make(chan error, 1)
+	go func() {
+		_, _, err := get(t, "http://"+s.Addr("http").String())
+		reqErr <- err
+	}()
+	<-started
+	cancel()
+
+	err := <-done
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "http: drain") {
+		t.Errorf("Run() error = %v; 預期包裝 context.DeadlineExceeded", err)
+	}
+	if err := <-reqErr; err == nil {
+		t.Error("被強制關閉的請求成功了; 預期連線錯誤")
+	}
+	if !errors.Is(afterErr, context.DeadlineExceeded) {
+		t.Errorf("後置 hook 的 ctx.Err() = %v; 預期為 context.DeadlineExceeded", afterErr)
+	}
+}
+
+func TestHookErrors(t *testing.T) {
+	leakcheck.Check(t)
+	quietLog(t)
+
+	errBefore := errors.New("deregister failed")
+	errAfter := errors.New("close db failed")
+	s := New()
+	if _, err := s.Listen("http", "127.0.0.1:0", http.NotFoundHandler()); err != nil {
+		t.Fatal(err)
+	}
+	s.BeforeShutdown(func(ctx context.Context) error { return errBefore })
+	s.AfterShutdown(func(ctx context.Context) error { return errAfter })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := s.Run(ctx)
+	if !errors.Is(err, errBefore) || !errors.Is(err, errAfter) {
+		t.Errorf("Run() error = %v; 預期包含兩個 hook 的錯誤", err)
+	}
+	if err := s.Run(context.Background()); err != ErrAlreadyRun {
+		t.Errorf("第二次 Run() error = %v; 預期為 ErrAlreadyRun", err)
+	}
+}
+
+func TestListenerFailure(t *testing.T) {
+	leakcheck.Check(t)
+	quietLog(t)
+
+	broken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New()
+	if _, err := s.Listen("http", "127.0.0.1:0", http.NotFoundHandler()); err != nil {
+		t.Fatal(err)
+	}
+	s.Serve("admin", broken, http.NotFoundHandler())
+	shutdown := make(chan struct{})
+	s.BeforeShutdown(func(ctx context.Context) error {
+		close(shutdown)
+		return nil
+	})
+
+	done := run(context.Background(), s)
+	// 關閉 admin 的 listener 讓它的 Serve 失敗；http 也必須跟著關閉。
+	broken.Close()
+	err = <-done
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) || !strings.Contains(err.Error(), "server: admin:") {
+		t.Errorf("Run() error = %v; 預期為 admin listener 的 *net.OpError", err)
+	}
+	select {
+	case <-shutdown:
+	default:
+		t.Error("listener 失敗後沒有執行關閉流程")
+	}
+}
+
+func TestListenError(t *testing.T) {
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+	if _, err := New().Listen("http", taken.Addr().String(), http.NotFoundHandler()); err == nil {
+		t.Error("Listen(已被佔用的位址) error = nil; 預期為錯誤")
+	}
+	if addr := New().Addr("missing"); addr != nil {
+		t.Errorf("Addr(不存在的名稱) = %v; 預期為 nil", addr)
+	}
+}
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string // %d 換成實際的埠號
+	}{
+		{":0", "http://localhost:%d"},
+		{"0.0.0.0:0", "http://localhost:%d"},
+		{"127.0.0.1:0", "http://127.0.0.1:%d"},
+	}
+	for _, tt := range tests {
+		ln, err := net.Listen("tcp", tt.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := New()
+		s.Serve("http", ln, http.NotFoundHandler())
+		want := fmt.Sprintf(tt.want, ln.Addr().(*net.TCPAddr).Port)
+		if got := s.URL("http"); got != want {
+			t.Errorf("URL() 監聽 %q = %q; 預期為 %q", tt.addr, got, want)
+		}
+		ln.Close()
+	}
+	if got := New().URL("missing"); got != "" {
+		t.Errorf("URL(不存在的名稱) = %q; 預期為空字串", got)
+	}
+}
+
+func TestSignal(t *testing.T) {
+	leakcheck.Check(t, leakcheck.IgnoreTopFunction("os/signal.signal_recv"))
+	quietLog(t)
+
+	s := New(WithSignals(syscall.SIGUSR1))
+	if _, err := s.Listen("http", "127.0.0.1:0", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})); err != nil {
+		t.Fatal(err)
+	}
+	done := run(context.Background(), s)
+	// 等到伺服器可以回應 (訊號處理已經註冊) 後再送出訊號。
+	if code, _, err := get(t, "http://"+s.Addr("http").String()); err != nil || code != http.StatusOK {
+		t.Fatalf("請求 = %d, %v; 預期為 200", code, err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v; 預期為 nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("收到訊號後 Run 沒有返回")
+	}
+}
+
+func TestTimeouts(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want Timeouts
+	}{
+		{"預設", nil, DefaultTimeouts},
+		{"自訂", []Option{WithTimeouts(Timeouts{ReadHeader: time.Second, Idle: time.Minute})},
+			Timeouts{ReadHeader: time.Second, Idle: time.Minute}},
+	}
+	for _, tt := range tests {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv := New(tt.opts...).Serve("http", ln, http.NotFoundHandler())
+		ln.Close()
+		got := Timeouts{srv.ReadHeaderTimeout, srv.ReadTimeout, srv.WriteTimeout, srv.IdleTimeout}
+		if got != tt.want {
+			t.Errorf("%s: 逾時 = %+v; 預期為 %+v", tt.name, got, tt.want)
+		}
+	}
+}